@@ -0,0 +1,148 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// SourcifyURL Sourcify 服务地址，钱包没有设置 Explorer 或浏览器中没有已验证的 ABI 时使用
+	SourcifyURL = "https://sourcify.dev/server"
+	// ABICacheDir 自动获取的 ABI 的本地缓存目录，按 <链ID>/<地址>.json 保存，为空时不缓存
+	ABICacheDir = defaultCacheDir("abi")
+)
+
+// defaultCacheDir 返回用户缓存目录下 goether 的子目录，无法确定用户缓存目录时返回空
+func defaultCacheDir(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goether", name)
+}
+
+// NewContractFromAddress 自动获取已验证合约的 ABI 并创建合约实例，适用于知名合约，不再需要手动保存 ABI
+//
+// 依次查找本地缓存、wallet.Explorer（Etherscan 兼容浏览器，API Key 通过 NewExplorer 设置）与 Sourcify，
+// 获取成功后写入 ABICacheDir。代理合约返回的是代理本身的 ABI。
+func NewContractFromAddress(address common.Address, wallet *Wallet) (*Contract, error) {
+	return NewContractFromAddressContext(context.Background(), address, wallet)
+}
+
+// NewContractFromAddressContext 与 NewContractFromAddress 相同，但请求受 ctx 控制
+func NewContractFromAddressContext(ctx context.Context, address common.Address, wallet *Wallet) (*Contract, error) {
+	abiJSON, err := FetchABIContext(ctx, wallet, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewContract(address, abiJSON, "", wallet)
+}
+
+// FetchABI 获取 wallet 所在链上已验证合约的 ABI JSON，查找顺序与 NewContractFromAddress 相同
+func FetchABI(wallet *Wallet, address common.Address) (string, error) {
+	return FetchABIContext(context.Background(), wallet, address)
+}
+
+// FetchABIContext 与 FetchABI 相同，但请求受 ctx 控制
+func FetchABIContext(ctx context.Context, wallet *Wallet, address common.Address) (string, error) {
+	if wallet == nil {
+		return "", ErrWalletNil
+	}
+	if wallet.ChainID == nil {
+		return "", ErrInvalidChainID
+	}
+	cacheFile := ""
+	if ABICacheDir != "" {
+		cacheFile = filepath.Join(ABICacheDir, wallet.ChainID.String(), strings.ToLower(address.Hex())+".json")
+		if data, err := os.ReadFile(cacheFile); err == nil && len(data) > 0 {
+			log.Debug("Loaded contract abi from cache", "address", address.Hex(), "file", cacheFile)
+			return string(data), nil
+		}
+	}
+
+	var errs []error
+	abiJSON := ""
+	if wallet.Explorer != nil {
+		var err error
+		if abiJSON, err = wallet.Explorer.ContractABIContext(ctx, address); err != nil {
+			log.Warning("Failed to fetch abi from explorer", "address", address.Hex(), "error", err)
+			errs = append(errs, fmt.Errorf("explorer: %w", err))
+			abiJSON = ""
+		}
+	}
+	if abiJSON == "" {
+		var err error
+		if abiJSON, err = sourcifyABI(ctx, wallet.ChainID.String(), address); err != nil {
+			log.Warning("Failed to fetch abi from sourcify", "address", address.Hex(), "error", err)
+			errs = append(errs, fmt.Errorf("sourcify: %w", err))
+			abiJSON = ""
+		}
+	}
+	if abiJSON == "" {
+		return "", fmt.Errorf("%w: %s: %v", ErrABINotFound, address.Hex(), errors.Join(errs...))
+	}
+	if _, err := abi.JSON(strings.NewReader(abiJSON)); err != nil {
+		return "", fmt.Errorf("invalid abi for %s: %w", address.Hex(), err)
+	}
+
+	if cacheFile != "" {
+		err := os.MkdirAll(filepath.Dir(cacheFile), 0o755)
+		if err == nil {
+			err = os.WriteFile(cacheFile, []byte(abiJSON), 0o644)
+		}
+		if err != nil {
+			log.Warning("Failed to cache contract abi", "file", cacheFile, "error", err)
+		}
+	}
+	log.Debug("Fetched contract abi", "address", address.Hex())
+	return abiJSON, nil
+}
+
+// sourcifyABI 通过 Sourcify v2 API 查询已验证合约的 ABI
+func sourcifyABI(ctx context.Context, chainID string, address common.Address) (string, error) {
+	url := fmt.Sprintf("%s/v2/contract/%s/%s?fields=abi", strings.TrimSuffix(SourcifyURL, "/"), chainID, address.Hex())
+	var body struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	err := getJSON(ctx, url, &body)
+	if errors.Is(err, errHTTPNotFound) || err == nil && (len(body.ABI) == 0 || string(body.ABI) == "null") {
+		return "", errors.New("contract not verified")
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(body.ABI), nil
+}
+
+// errHTTPNotFound getJSON 收到 404 响应
+var errHTTPNotFound = errors.New("not found")
+
+// getJSON 发送 GET 请求并将 JSON 响应解码到 out
+func getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errHTTPNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
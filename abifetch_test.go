@@ -0,0 +1,83 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContractFromAddress(t *testing.T) {
+	verified := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	sourcified := common.HexToAddress("0x0000000000000000000000000000000000001234")
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if strings.HasPrefix(r.URL.Path, "/v2/contract/") {
+			if r.URL.Path != "/v2/contract/1/"+sourcified.Hex() {
+				http.NotFound(rw, r)
+				return
+			}
+			rw.Write([]byte(`{"abi":` + ERC20ABI + `}`))
+			return
+		}
+		q := r.URL.Query()
+		assert.Equal(t, "getabi", q.Get("action"))
+		assert.Equal(t, "KEY", q.Get("apikey"))
+		if q.Get("address") != verified.Hex() {
+			json.NewEncoder(rw).Encode(map[string]any{"status": "0", "message": "NOTOK", "result": "Contract source code not verified"})
+			return
+		}
+		json.NewEncoder(rw).Encode(map[string]any{"status": "1", "message": "OK", "result": ERC20ABI})
+	}))
+	t.Cleanup(server.Close)
+
+	defer func(url, dir string) { SourcifyURL, ABICacheDir = url, dir }(SourcifyURL, ABICacheDir)
+	SourcifyURL = server.URL
+	ABICacheDir = t.TempDir()
+
+	m := newMockRPC(t)
+	w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", m.URL,
+		WithChainID(big.NewInt(1)), WithExplorer(NewExplorer(server.URL, "KEY")))
+	assert.NoError(t, err)
+
+	c, err := NewContractFromAddress(verified, w)
+	assert.NoError(t, err)
+	assert.Equal(t, verified, c.Address)
+	assert.Contains(t, c.ABI.Methods, "transfer")
+	assert.FileExists(t, filepath.Join(ABICacheDir, "1", strings.ToLower(verified.Hex())+".json"))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// 第二次从本地缓存读取
+	_, err = NewContractFromAddress(verified, w)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// 浏览器中未验证时使用 Sourcify
+	c, err = NewContractFromAddress(sourcified, w)
+	assert.NoError(t, err)
+	assert.Contains(t, c.ABI.Events, "Transfer")
+	assert.Equal(t, int32(3), requests.Load())
+
+	// 没有设置 Explorer 时直接使用 Sourcify
+	os.RemoveAll(ABICacheDir)
+	w.Explorer = nil
+	_, err = NewContractFromAddress(sourcified, w)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), requests.Load())
+
+	_, err = NewContractFromAddress(common.HexToAddress("0x01"), w)
+	assert.ErrorIs(t, err, ErrABINotFound)
+	assert.Equal(t, CodeABINotFound, ErrorCodeOf(err))
+
+	_, err = NewContractFromAddress(verified, nil)
+	assert.ErrorIs(t, err, ErrWalletNil)
+}
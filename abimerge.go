@@ -0,0 +1,138 @@
+package goether
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NewContractFromABIs 使用多个 ABI 合并后的结果创建合约实例，适用于代理 + 逻辑合约、Diamond 等
+// 由多个合约共同提供接口的情况，合并规则见 MergeABIs
+func NewContractFromABIs(address common.Address, wallet *Wallet, abiStrs ...string) (*Contract, error) {
+	abis := make([]abi.ABI, len(abiStrs))
+	for i, abiStr := range abiStrs {
+		parsed, err := abi.JSON(strings.NewReader(abiStr))
+		if err != nil {
+			log.Error("Failed to parse contract ABI", "index", i, "error", err)
+			return nil, fmt.Errorf("abi %d: %w", i, err)
+		}
+		abis[i] = parsed
+	}
+	merged, err := MergeABIs(abis...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Contract{Address: address, ABI: merged, Wallet: wallet}
+	if wallet != nil {
+		c.Client = wallet.Client
+	}
+	log.Debug("Contract instance created from merged ABIs", "address", address.Hex(), "abis", len(abis))
+	return c, nil
+}
+
+// MergeABIs 合并多个 ABI
+//
+// 方法、事件与错误按签名去重，签名相同但返回值、状态可变性或 indexed 不同时返回 ErrABIConflict；
+// 不同签名的同名重载按 abi.JSON 的规则命名为 name0、name1。
+// constructor、fallback 与 receive 使用第一个定义了它们的 ABI。
+func MergeABIs(abis ...abi.ABI) (abi.ABI, error) {
+	merged := abi.ABI{
+		Methods: make(map[string]abi.Method),
+		Events:  make(map[string]abi.Event),
+		Errors:  make(map[string]abi.Error),
+	}
+	methods := make(map[string]abi.Method)
+	events := make(map[string]abi.Event)
+	errs := make(map[string]abi.Error)
+
+	for _, a := range abis {
+		// abi.Constructor 是 FunctionType 的零值，只能通过 String 判断是否定义了构造函数
+		if merged.Constructor.String() == "" {
+			merged.Constructor = a.Constructor
+		}
+		if !merged.HasFallback() {
+			merged.Fallback = a.Fallback
+		}
+		if !merged.HasReceive() {
+			merged.Receive = a.Receive
+		}
+
+		for _, name := range sortedKeys(a.Methods) {
+			m := a.Methods[name]
+			if existing, ok := methods[m.Sig]; ok {
+				if !sameMethod(existing, m) {
+					return abi.ABI{}, fmt.Errorf("%w: method %s", ErrABIConflict, m.Sig)
+				}
+				continue
+			}
+			methods[m.Sig] = m
+			m.Name = abi.ResolveNameConflict(m.RawName, func(s string) bool { _, ok := merged.Methods[s]; return ok })
+			merged.Methods[m.Name] = m
+		}
+
+		for _, name := range sortedKeys(a.Events) {
+			e := a.Events[name]
+			if existing, ok := events[e.Sig]; ok {
+				if !sameEvent(existing, e) {
+					return abi.ABI{}, fmt.Errorf("%w: event %s", ErrABIConflict, e.Sig)
+				}
+				continue
+			}
+			events[e.Sig] = e
+			e.Name = abi.ResolveNameConflict(e.RawName, func(s string) bool { _, ok := merged.Events[s]; return ok })
+			merged.Events[e.Name] = e
+		}
+
+		for _, name := range sortedKeys(a.Errors) {
+			e := a.Errors[name]
+			if _, ok := errs[e.Sig]; ok {
+				continue
+			}
+			errs[e.Sig] = e
+			e.Name = abi.ResolveNameConflict(name, func(s string) bool { _, ok := merged.Errors[s]; return ok })
+			merged.Errors[e.Name] = e
+		}
+	}
+	return merged, nil
+}
+
+// sameMethod 判断两个签名相同的方法是否一致
+func sameMethod(a, b abi.Method) bool {
+	return a.IsConstant() == b.IsConstant() && a.IsPayable() == b.IsPayable() &&
+		argumentTypes(a.Outputs) == argumentTypes(b.Outputs)
+}
+
+// sameEvent 判断两个签名相同的事件是否一致，indexed 不同时 topic 与 data 的布局不同
+func sameEvent(a, b abi.Event) bool {
+	if a.Anonymous != b.Anonymous {
+		return false
+	}
+	for i := range a.Inputs {
+		if a.Inputs[i].Indexed != b.Inputs[i].Indexed {
+			return false
+		}
+	}
+	return true
+}
+
+func argumentTypes(args abi.Arguments) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+	}
+	return strings.Join(types, ",")
+}
+
+// sortedKeys 返回按字母排序的键，保证合并结果与重载命名稳定
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
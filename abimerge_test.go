@@ -0,0 +1,55 @@
+package goether
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContractFromABIs(t *testing.T) {
+	proxyABI := `[
+		{"type":"constructor","inputs":[{"name":"impl","type":"address"}]},
+		{"type":"fallback","stateMutability":"payable"},
+		{"type":"function","name":"upgradeTo","inputs":[{"name":"impl","type":"address"}],"outputs":[]},
+		{"type":"event","name":"Upgraded","inputs":[{"indexed":true,"name":"implementation","type":"address"}]}
+	]`
+	legacyABI := `[
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[{"name":"","type":"bool"}]},
+		{"type":"event","name":"Transfer","inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}]},
+		{"type":"event","name":"Minted","inputs":[{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}]},
+		{"type":"error","name":"Unauthorized","inputs":[]}
+	]`
+	token := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	c, err := NewContractFromABIs(token, nil, proxyABI, ERC20ABI, legacyABI)
+	assert.NoError(t, err)
+	assert.Equal(t, token, c.Address)
+	assert.Equal(t, abi.Constructor, c.ABI.Constructor.Type)
+	assert.Len(t, c.ABI.Constructor.Inputs, 1)
+	assert.Equal(t, abi.Fallback, c.ABI.Fallback.Type)
+	assert.Contains(t, c.ABI.Methods, "upgradeTo")
+	assert.Contains(t, c.ABI.Methods, "balanceOf")
+	assert.Contains(t, c.ABI.Errors, "Unauthorized")
+	// 相同的 Transfer 事件只保留一个，重载方法按 abi.JSON 的规则命名
+	assert.Len(t, c.ABI.Events, 4)
+	assert.Equal(t, "transfer(address,uint256)", c.ABI.Methods["transfer"].Sig)
+	assert.Equal(t, "transfer(address,uint256,bytes)", c.ABI.Methods["transfer0"].Sig)
+	assert.Equal(t, "transfer0", c.ABI.Methods["transfer0"].Name)
+
+	data, err := c.EncodeData("transfer0", token, common.Big1, []byte{1})
+	assert.NoError(t, err)
+	method, err := c.ABI.MethodById(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256,bytes)", method.Sig)
+
+	// 签名相同但定义不同时报告冲突
+	_, err = NewContractFromABIs(token, nil, ERC20ABI,
+		`[{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint128"}]}]`)
+	assert.ErrorIs(t, err, ErrABIConflict)
+	_, err = NewContractFromABIs(token, nil, ERC20ABI,
+		`[{"type":"event","name":"Transfer","inputs":[{"indexed":false,"name":"from","type":"address"},{"indexed":false,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}]}]`)
+	assert.ErrorIs(t, err, ErrABIConflict)
+	_, err = NewContractFromABIs(token, nil, ERC20ABI, "not json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/ethrpc"
+)
+
+type accessListResult struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64   `json:"gasUsed"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// CreateAccessList 调用 eth_createAccessList 生成交易的访问列表，同时返回附带该列表时消耗的 gas
+func (w *Wallet) CreateAccessList(to common.Address, amount *big.Int, data []byte) (types.AccessList, int, error) {
+	return w.CreateAccessListContext(context.Background(), to, amount, data)
+}
+
+// CreateAccessListContext 与 CreateAccessList 相同，但查询受 ctx 控制
+func (w *Wallet) CreateAccessListContext(ctx context.Context, to common.Address, amount *big.Int, data []byte) (types.AccessList, int, error) {
+	return w.createAccessList(ctx, &to, amount, data)
+}
+
+// createAccessList to 为 nil 时按创建合约的交易生成访问列表
+func (w *Wallet) createAccessList(ctx context.Context, to *common.Address, amount *big.Int, data []byte) (types.AccessList, int, error) {
+	msg := ethrpc.T{
+		From:  w.Address.String(),
+		Value: amount,
+		Data:  hexutil.Encode(data),
+	}
+	if to != nil {
+		msg.To = to.String()
+	}
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_createAccessList", msg, "pending")
+	})
+	if err != nil {
+		log.Error("Failed to create access list", "error", err)
+		return nil, 0, err
+	}
+
+	var result accessListResult
+	if err = json.Unmarshal(raw, &result); err != nil {
+		log.Error("Failed to decode access list", "error", err)
+		return nil, 0, err
+	}
+	if result.Error != "" {
+		return nil, 0, errors.New(result.Error)
+	}
+	log.Debug("Access list created",
+		"addresses", len(result.AccessList),
+		"storageKeys", result.AccessList.StorageKeys(),
+		"gasUsed", uint64(result.GasUsed))
+	return result.AccessList, int(result.GasUsed), nil
+}
+
+// SendAccessListTx 发送 EIP-2930 交易，使用 GasPrice 计费并携带 opts.AccessList
+func (w *Wallet) SendAccessListTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	return w.SendAccessListTxContext(context.Background(), to, amount, data, opts)
+}
+
+// SendAccessListTxContext 与 SendAccessListTx 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) SendAccessListTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	log.Debug("Sending access list transaction",
+		"from", w.Address.Hex(),
+		"to", to.Hex(),
+		"amount", amount.String(),
+		"dataLength", len(data))
+
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	tx, err := w.BuildAccessListTxContext(ctx, to, amount, data, opts)
+	if err != nil {
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(int(tx.Nonce()), &err)
+	}
+
+	txHash, err = w.sendTx(ctx, tx, opts)
+	if err != nil {
+		log.Error("Failed to send raw access list transaction", "error", err)
+		return
+	}
+
+	log.Debug("Access list transaction sent successfully", "txHash", txHash)
+	return txHash, nil
+}
+
+// BuildAccessListTx 补全交易参数并签名 EIP-2930 交易，但不广播
+func (w *Wallet) BuildAccessListTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (*types.Transaction, error) {
+	return w.BuildAccessListTxContext(context.Background(), to, amount, data, opts)
+}
+
+// BuildAccessListTxContext 与 BuildAccessListTx 相同，但补全参数时的 RPC 调用受 ctx 控制
+func (w *Wallet) BuildAccessListTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (tx *types.Transaction, err error) {
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	opts, err = w.InitTxOptsContext(ctx, to, amount, data, opts)
+	if err != nil {
+		log.Error("Failed to initialize access list transaction options", "error", err)
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(*opts.Nonce, &err)
+	}
+
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	tx, err = signTxWith(w.signDigest(ctx), &types.AccessListTx{
+		ChainID:    w.ChainID,
+		Nonce:      uint64(*opts.Nonce),
+		GasPrice:   opts.GasPrice,
+		Gas:        uint64(*opts.GasLimit),
+		To:         &to,
+		Value:      amount,
+		Data:       data,
+		AccessList: opts.AccessList,
+	}, types.LatestSignerForChainID(w.ChainID))
+	if err != nil {
+		log.Error("Failed to sign access list transaction", "error", err)
+		return
+	}
+	return tx, nil
+}
@@ -0,0 +1,46 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAccessList(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 1, &sent)
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	slot := common.HexToHash("0x01")
+	m.Result("eth_createAccessList", map[string]any{
+		"accessList": []map[string]any{{"address": token, "storageKeys": []common.Hash{slot}}},
+		"gasUsed":    "0x6000",
+	})
+	w := newTestWallet(t, m)
+
+	list, gasUsed, err := w.CreateAccessList(token, nil, []byte{0x01})
+	assert.NoError(t, err)
+	assert.Equal(t, types.AccessList{{Address: token, StorageKeys: []common.Hash{slot}}}, list)
+	assert.Equal(t, 0x6000, gasUsed)
+
+	_, err = w.SendTx(token, nil, []byte{0x01}, &TxOpts{CreateAccessList: true})
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), sent[0].Type())
+	assert.Equal(t, list, sent[0].AccessList())
+	assert.Equal(t, uint64(0x6000), sent[0].Gas())
+
+	_, err = w.SendAccessListTx(token, nil, []byte{0x01}, &TxOpts{AccessList: list})
+	assert.NoError(t, err)
+	assert.Len(t, sent, 2)
+	assert.Equal(t, uint8(types.AccessListTxType), sent[1].Type())
+	assert.Equal(t, list, sent[1].AccessList())
+	assert.Equal(t, big.NewInt(1_000_000_000), sent[1].GasPrice())
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), sent[1])
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, from)
+	assert.Equal(t, 2, m.Calls("eth_createAccessList"))
+}
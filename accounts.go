@@ -0,0 +1,255 @@
+package goether
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Accounts keystore 目录账户管理器，相当于精简版的 geth personal 命名空间
+//
+// 目录中每个 Keystore V3 文件对应一个账户，账户解锁后可以取得签名器或钱包；
+// 重新锁定时签名器中的私钥会被清除，之前取得的签名器与钱包随之失效（签名返回 ErrSignerClosed），
+// 再次解锁后需要重新获取。
+type Accounts struct {
+	Dir string
+
+	mu       sync.Mutex
+	files    map[common.Address]string
+	unlocked map[common.Address]*unlockedAccount
+}
+
+type unlockedAccount struct {
+	signer *Signer
+	timer  *time.Timer
+}
+
+// NewAccounts 打开 keystore 目录并扫描其中的账户，目录不存在时会创建
+func NewAccounts(dir string) (*Accounts, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		log.Error("Failed to create keystore directory", "dir", dir, "error", err)
+		return nil, err
+	}
+	a := &Accounts{
+		Dir:      dir,
+		files:    make(map[common.Address]string),
+		unlocked: make(map[common.Address]*unlockedAccount),
+	}
+	if err := a.Refresh(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Refresh 重新扫描目录，无法识别的文件会被忽略
+func (a *Accounts) Refresh() error {
+	entries, err := os.ReadDir(a.Dir)
+	if err != nil {
+		log.Error("Failed to read keystore directory", "dir", a.Dir, "error", err)
+		return err
+	}
+	files := make(map[common.Address]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(a.Dir, entry.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			log.Warning("Failed to read keystore file", "path", path, "error", err)
+			continue
+		}
+		var key struct {
+			Address string `json:"address"`
+		}
+		if err = json.Unmarshal(b, &key); err != nil || !common.IsHexAddress(key.Address) {
+			log.Debug("Skipping non-keystore file", "path", path)
+			continue
+		}
+		files[common.HexToAddress(key.Address)] = path
+	}
+
+	a.mu.Lock()
+	a.files = files
+	a.mu.Unlock()
+	log.Debug("Keystore directory scanned", "dir", a.Dir, "accounts", len(files))
+	return nil
+}
+
+// Addresses 目录中的所有账户地址，按地址排序
+func (a *Accounts) Addresses() []common.Address {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	addresses := make([]common.Address, 0, len(a.files))
+	for address := range a.files {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Cmp(addresses[j]) < 0
+	})
+	return addresses
+}
+
+// Contains 目录中是否有该账户
+func (a *Accounts) Contains(address common.Address) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.files[address]
+	return ok
+}
+
+// NewAccount 生成新账户，使用密码加密后保存到目录中
+func (a *Accounts) NewAccount(password string, params ScryptParams) (common.Address, error) {
+	signer, _, err := NewRandomSigner()
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer signer.Close()
+	return a.Import(signer, password, params)
+}
+
+// Import 将签名器的私钥使用密码加密后保存到目录中
+func (a *Accounts) Import(signer *Signer, password string, params ScryptParams) (common.Address, error) {
+	keyJSON, err := signer.ExportKeystore(password, params)
+	if err != nil {
+		return common.Address{}, err
+	}
+	path := filepath.Join(a.Dir, keyFileName(signer.Address))
+	if err = os.WriteFile(path, keyJSON, 0o600); err != nil {
+		log.Error("Failed to write keystore file", "path", path, "error", err)
+		return common.Address{}, err
+	}
+
+	a.mu.Lock()
+	a.files[signer.Address] = path
+	a.mu.Unlock()
+	log.Info("Account saved to keystore directory", "address", signer.Address.Hex(), "path", path)
+	return signer.Address, nil
+}
+
+// Unlock 使用密码解锁账户，timeout 大于 0 时到期自动重新锁定，为 0 时保持解锁直到调用 Lock
+//
+// 已解锁的账户再次调用会重新设置超时时间。
+func (a *Accounts) Unlock(address common.Address, password string, timeout time.Duration) error {
+	a.mu.Lock()
+	path, ok := a.files[address]
+	a.mu.Unlock()
+	if !ok {
+		return ErrAccountNotFound
+	}
+
+	signer, err := NewSignerFromKeystorePath(path, password)
+	if err != nil {
+		return err
+	}
+	if signer.Address != address {
+		signer.Close()
+		return fmt.Errorf("keystore file %s contains %s, expected %s", path, signer.Address.Hex(), address.Hex())
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if u, ok := a.unlocked[address]; ok {
+		// 保留已经发出的签名器，只更新超时时间
+		signer.Close()
+		signer = u.signer
+		if u.timer != nil {
+			u.timer.Stop()
+		}
+	}
+	u := &unlockedAccount{signer: signer}
+	if timeout > 0 {
+		u.timer = time.AfterFunc(timeout, func() {
+			a.expire(address, u)
+		})
+	}
+	a.unlocked[address] = u
+	log.Info("Account unlocked", "address", address.Hex(), "timeout", timeout)
+	return nil
+}
+
+// expire 超时后重新锁定，u 已被新的解锁替换时不做处理
+func (a *Accounts) expire(address common.Address, u *unlockedAccount) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.unlocked[address] != u {
+		return
+	}
+	delete(a.unlocked, address)
+	u.signer.Close()
+	log.Info("Account relocked after timeout", "address", address.Hex())
+}
+
+// Lock 锁定账户并清除内存中的私钥
+func (a *Accounts) Lock(address common.Address) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	u, ok := a.unlocked[address]
+	if !ok {
+		return
+	}
+	if u.timer != nil {
+		u.timer.Stop()
+	}
+	delete(a.unlocked, address)
+	u.signer.Close()
+	log.Info("Account locked", "address", address.Hex())
+}
+
+// IsUnlocked 账户是否已解锁
+func (a *Accounts) IsUnlocked(address common.Address) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.unlocked[address]
+	return ok
+}
+
+// Signer 返回已解锁账户的签名器，账户未解锁时返回 ErrAccountLocked
+func (a *Accounts) Signer(address common.Address) (*Signer, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.files[address]; !ok {
+		return nil, ErrAccountNotFound
+	}
+	u, ok := a.unlocked[address]
+	if !ok {
+		return nil, ErrAccountLocked
+	}
+	return u.signer, nil
+}
+
+// Wallet 使用已解锁账户创建钱包，options 与 NewWallet 相同
+func (a *Accounts) Wallet(address common.Address, rpc string, options ...any) (*Wallet, error) {
+	signer, err := a.Signer(address)
+	if err != nil {
+		return nil, err
+	}
+	return NewWalletWithSigner(signer, rpc, options...)
+}
+
+// Close 锁定所有账户
+func (a *Accounts) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for address, u := range a.unlocked {
+		if u.timer != nil {
+			u.timer.Stop()
+		}
+		u.signer.Close()
+		delete(a.unlocked, address)
+	}
+}
+
+// keyFileName 与 geth 相同的 keystore 文件名：UTC--<创建时间>--<地址>
+func keyFileName(address common.Address) string {
+	ts := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	return fmt.Sprintf("UTC--%s--%s", ts, hex.EncodeToString(address[:]))
+}
@@ -0,0 +1,74 @@
+package goether
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccounts(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600))
+	accounts, err := NewAccounts(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, accounts.Addresses())
+
+	address, err := accounts.Import(TestSigner, "secret", LightScryptParams)
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, address)
+	created, err := accounts.NewAccount("other", LightScryptParams)
+	assert.NoError(t, err)
+
+	// 重新扫描目录可以找到保存的账户
+	accounts, err = NewAccounts(dir)
+	assert.NoError(t, err)
+	defer accounts.Close()
+	assert.Len(t, accounts.Addresses(), 2)
+	assert.True(t, accounts.Contains(address))
+	assert.True(t, accounts.Contains(created))
+
+	_, err = accounts.Signer(address)
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	_, err = accounts.Signer(common.HexToAddress("0x01"))
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+	assert.ErrorIs(t, accounts.Unlock(common.HexToAddress("0x01"), "secret", 0), ErrAccountNotFound)
+	assert.Error(t, accounts.Unlock(address, "wrong", 0))
+	assert.False(t, accounts.IsUnlocked(address))
+
+	assert.NoError(t, accounts.Unlock(address, "secret", 0))
+	assert.True(t, accounts.IsUnlocked(address))
+	w, err := accounts.Wallet(address, "", big.NewInt(1))
+	assert.NoError(t, err)
+	sig, err := w.Signer.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	expected, _ := TestSigner.SignMsg([]byte("hello"))
+	assert.Equal(t, expected, sig)
+
+	// 锁定后之前取得的钱包不能再签名
+	accounts.Lock(address)
+	assert.False(t, accounts.IsUnlocked(address))
+	_, err = w.Signer.SignMsg([]byte("hello"))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+}
+
+func TestAccountsUnlockTimeout(t *testing.T) {
+	accounts, err := NewAccounts(t.TempDir())
+	assert.NoError(t, err)
+	defer accounts.Close()
+	address, err := accounts.Import(TestSigner, "secret", LightScryptParams)
+	assert.NoError(t, err)
+
+	assert.NoError(t, accounts.Unlock(address, "secret", 50*time.Millisecond))
+	signer, err := accounts.Signer(address)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return !accounts.IsUnlocked(address)
+	}, time.Second, 10*time.Millisecond)
+	_, err = signer.SignMsg([]byte("hello"))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+}
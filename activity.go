@@ -0,0 +1,252 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// transferTopic ERC-20 Transfer(address,address,uint256) 事件的 topic0
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// ActivityType 地址活动的类型
+type ActivityType int
+
+const (
+	// ActivityNativeIn 转入原生币
+	ActivityNativeIn ActivityType = iota
+	// ActivityNativeOut 转出原生币
+	ActivityNativeOut
+	// ActivityTokenIn 转入 ERC-20 代币
+	ActivityTokenIn
+	// ActivityTokenOut 转出 ERC-20 代币
+	ActivityTokenOut
+)
+
+func (t ActivityType) String() string {
+	switch t {
+	case ActivityNativeIn:
+		return "native_in"
+	case ActivityNativeOut:
+		return "native_out"
+	case ActivityTokenIn:
+		return "token_in"
+	case ActivityTokenOut:
+		return "token_out"
+	default:
+		return "unknown"
+	}
+}
+
+// Activity 地址在某个区块中的一次转账
+type Activity struct {
+	Type ActivityType
+	// Token 代币合约地址，原生币转账为零地址
+	Token       common.Address
+	From        common.Address
+	To          common.Address
+	Value       *big.Int
+	TxHash      common.Hash
+	TxIndex     uint
+	LogIndex    uint
+	BlockNumber uint64
+	BlockHash   common.Hash
+}
+
+// AddressWatcher 监控地址在新区块中的原生币转账与 ERC-20 Transfer 事件
+//
+// 原生币转账只识别交易本身的 value，合约内部转账不会被识别；执行失败的交易会被忽略。
+// 给自己转账时只推送转入事件。
+type AddressWatcher struct {
+	Wallet  *Wallet
+	Address common.Address
+	// Tokens 只监控这些代币合约，为空时监控所有合约的 Transfer 事件
+	Tokens []common.Address
+	// Confirmations 区块达到该确认数后才扫描，0 表示扫描最新区块
+	Confirmations uint64
+	// FromBlock 从该区块开始扫描，用于服务重启后补扫，0 表示从当前区块开始
+	FromBlock uint64
+}
+
+// NewAddressWatcher 创建地址监控器
+func NewAddressWatcher(w *Wallet, address common.Address) *AddressWatcher {
+	return &AddressWatcher{Wallet: w, Address: address}
+}
+
+// Watch 开始监控，新区块来自 Wallet.SubscribeNewHeads，返回的通道在 ctx 结束后关闭
+//
+// 扫描某个区块失败时会在下一个区块头到来时重试，不会跳过区块。
+func (aw *AddressWatcher) Watch(ctx context.Context) (<-chan Activity, error) {
+	heads, err := aw.Wallet.SubscribeNewHeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Activity)
+	go func() {
+		defer close(out)
+		next := aw.FromBlock
+		for head := range heads {
+			number := head.Number.Uint64()
+			if number < aw.Confirmations {
+				continue
+			}
+			target := number - aw.Confirmations
+			if next == 0 {
+				next = target
+			}
+			for ; next <= target; next++ {
+				activities, err := aw.scan(ctx, next)
+				if err != nil {
+					log.Warning("Failed to scan block for address activity", "number", next, "address", aw.Address, "error", err)
+					break
+				}
+				for _, a := range activities {
+					select {
+					case out <- a:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// activityBlock eth_getBlockByNumber 返回的区块中需要用到的字段
+type activityBlock struct {
+	Hash         common.Hash `json:"hash"`
+	Transactions []struct {
+		Hash             common.Hash     `json:"hash"`
+		TransactionIndex hexutil.Uint    `json:"transactionIndex"`
+		From             common.Address  `json:"from"`
+		To               *common.Address `json:"to"`
+		Value            *hexutil.Big    `json:"value"`
+	} `json:"transactions"`
+}
+
+// scan 扫描一个区块中与地址相关的转账，按交易顺序返回
+func (aw *AddressWatcher) scan(ctx context.Context, number uint64) ([]Activity, error) {
+	w := aw.Wallet
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_getBlockByNumber", hexutil.EncodeUint64(number), true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errors.New("block not found")
+	}
+	var block activityBlock
+	if err = json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	}
+
+	var activities []Activity
+	for _, tx := range block.Transactions {
+		if tx.To == nil || tx.Value == nil || tx.Value.ToInt().Sign() == 0 {
+			continue
+		}
+		var typ ActivityType
+		switch aw.Address {
+		case *tx.To:
+			typ = ActivityNativeIn
+		case tx.From:
+			typ = ActivityNativeOut
+		default:
+			continue
+		}
+		receipt, err := w.GetReceiptContext(ctx, tx.Hash.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("get receipt %s: %w", tx.Hash.Hex(), err)
+		}
+		if !receipt.Succeeded() {
+			continue
+		}
+		activities = append(activities, Activity{
+			Type:        typ,
+			From:        tx.From,
+			To:          *tx.To,
+			Value:       tx.Value.ToInt(),
+			TxHash:      tx.Hash,
+			TxIndex:     uint(tx.TransactionIndex),
+			BlockNumber: number,
+			BlockHash:   block.Hash,
+		})
+	}
+
+	// from 与 to 分别查询，给自己转账时两次查询会返回同一条日志
+	seen := map[uint]bool{}
+	topic := common.BytesToHash(aw.Address.Bytes())
+	for _, topics := range [][]interface{}{
+		{transferTopic, topic},
+		{transferTopic, nil, topic},
+	} {
+		logs, err := aw.transferLogs(ctx, block.Hash, topics)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			// ERC-721 的 Transfer 事件 topic0 相同，但 tokenId 也是 indexed
+			if l.Removed || len(l.Topics) != 3 || len(l.Data) != 32 || seen[l.Index] {
+				continue
+			}
+			seen[l.Index] = true
+			a := Activity{
+				Type:        ActivityTokenOut,
+				Token:       l.Address,
+				From:        common.BytesToAddress(l.Topics[1].Bytes()),
+				To:          common.BytesToAddress(l.Topics[2].Bytes()),
+				Value:       new(big.Int).SetBytes(l.Data),
+				TxHash:      l.TxHash,
+				TxIndex:     l.TxIndex,
+				LogIndex:    l.Index,
+				BlockNumber: number,
+				BlockHash:   block.Hash,
+			}
+			if a.To == aw.Address {
+				a.Type = ActivityTokenIn
+			}
+			activities = append(activities, a)
+		}
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		if activities[i].TxIndex != activities[j].TxIndex {
+			return activities[i].TxIndex < activities[j].TxIndex
+		}
+		return activities[i].LogIndex < activities[j].LogIndex
+	})
+	return activities, nil
+}
+
+// transferLogs 查询区块中满足 topics 的 Transfer 日志
+func (aw *AddressWatcher) transferLogs(ctx context.Context, blockHash common.Hash, topics []interface{}) ([]types.Log, error) {
+	filter := map[string]interface{}{
+		"blockHash": blockHash,
+		"topics":    topics,
+	}
+	if len(aw.Tokens) > 0 {
+		filter["address"] = aw.Tokens
+	}
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return aw.Wallet.rpc(ctx).Call("eth_getLogs", filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var logs []types.Log
+	if err = json.Unmarshal(raw, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,151 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressWatcher(t *testing.T) {
+	HeadPollInterval = 5 * time.Millisecond
+	defer func() { HeadPollInterval = 2 * time.Second }()
+
+	watched := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	nft := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	addrTopic := func(a common.Address) common.Hash { return common.BytesToHash(a.Bytes()) }
+	amount := func(v int64) hexutil.Bytes { return common.BigToHash(big.NewInt(v)).Bytes() }
+
+	tx := func(hash string, index uint, from, to common.Address, value int64) map[string]any {
+		return map[string]any{
+			"hash":             common.HexToHash(hash),
+			"transactionIndex": hexutil.Uint(index),
+			"from":             from,
+			"to":               to,
+			"value":            (*hexutil.Big)(big.NewInt(value)),
+		}
+	}
+	blocks := map[uint64][]map[string]any{
+		102: {
+			tx("0x01", 0, other, watched, 5),
+			tx("0x02", 1, watched, other, 7),
+			tx("0x03", 2, other, token, 0),
+			tx("0x04", 3, other, other, 9),
+		},
+	}
+	logs := []map[string]any{
+		{"address": token, "topics": []common.Hash{transferTopic, addrTopic(other), addrTopic(watched)}, "data": amount(100), "logIndex": "0x1", "transactionIndex": "0x0"},
+		{"address": token, "topics": []common.Hash{transferTopic, addrTopic(watched), addrTopic(watched)}, "data": amount(3), "logIndex": "0x2", "transactionIndex": "0x1"},
+		{"address": nft, "topics": []common.Hash{transferTopic, addrTopic(other), addrTopic(watched), common.BigToHash(big.NewInt(1))}, "data": hexutil.Bytes{}, "logIndex": "0x3", "transactionIndex": "0x1"},
+	}
+	blockHash := func(n uint64) common.Hash { return common.BigToHash(new(big.Int).SetUint64(n)) }
+
+	m := newMockRPC(t)
+	head := uint64(100)
+	m.On("eth_blockNumber", func([]json.RawMessage) (any, error) {
+		// 每次轮询前进两个区块，监控器需要补扫中间的区块
+		head += 2
+		return hexutil.EncodeUint64(head), nil
+	})
+	m.On("eth_getBlockByNumber", func(params []json.RawMessage) (any, error) {
+		var number hexutil.Uint64
+		var full bool
+		json.Unmarshal(params[0], &number)
+		json.Unmarshal(params[1], &full)
+		if !full {
+			return &types.Header{Number: new(big.Int).SetUint64(uint64(number)), Difficulty: big.NewInt(0)}, nil
+		}
+		txs := blocks[uint64(number)]
+		if txs == nil {
+			txs = []map[string]any{}
+		}
+		return map[string]any{"hash": blockHash(uint64(number)), "transactions": txs}, nil
+	})
+	m.On("eth_getTransactionReceipt", func(params []json.RawMessage) (any, error) {
+		var hash common.Hash
+		json.Unmarshal(params[0], &hash)
+		status := types.ReceiptStatusSuccessful
+		if hash == common.HexToHash("0x02") {
+			status = types.ReceiptStatusFailed
+		}
+		return &types.Receipt{Status: status, TxHash: hash, BlockNumber: big.NewInt(102), Logs: []*types.Log{}}, nil
+	})
+	m.On("eth_getLogs", func(params []json.RawMessage) (any, error) {
+		var filter struct {
+			BlockHash common.Hash    `json:"blockHash"`
+			Topics    []*common.Hash `json:"topics"`
+		}
+		json.Unmarshal(params[0], &filter)
+		result := []map[string]any{}
+		if filter.BlockHash != blockHash(103) {
+			return result, nil
+		}
+		for _, l := range logs {
+			topics := l["topics"].([]common.Hash)
+			match := true
+			for i, topic := range filter.Topics {
+				if topic != nil && (i >= len(topics) || topics[i] != *topic) {
+					match = false
+				}
+			}
+			if match {
+				entry := map[string]any{"blockHash": filter.BlockHash, "blockNumber": "0x67", "transactionHash": common.HexToHash("0x05")}
+				for k, v := range l {
+					entry[k] = v
+				}
+				result = append(result, entry)
+			}
+		}
+		return result, nil
+	})
+	w := newTestWallet(t, m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watcher := NewAddressWatcher(w, watched)
+	watcher.FromBlock = 102
+	activities, err := watcher.Watch(ctx)
+	assert.NoError(t, err)
+
+	var got []Activity
+	for len(got) < 4 {
+		select {
+		case a := <-activities:
+			got = append(got, a)
+			continue
+		case <-time.After(200 * time.Millisecond):
+		}
+		break
+	}
+	cancel()
+	for range activities {
+	}
+	if !assert.Len(t, got, 3) {
+		return
+	}
+
+	assert.Equal(t, ActivityNativeIn, got[0].Type)
+	assert.Equal(t, common.HexToHash("0x01"), got[0].TxHash)
+	assert.Equal(t, int64(5), got[0].Value.Int64())
+	assert.Equal(t, uint64(102), got[0].BlockNumber)
+	assert.Equal(t, common.Address{}, got[0].Token)
+
+	assert.Equal(t, ActivityTokenIn, got[1].Type)
+	assert.Equal(t, token, got[1].Token)
+	assert.Equal(t, other, got[1].From)
+	assert.Equal(t, int64(100), got[1].Value.Int64())
+	assert.Equal(t, uint64(103), got[1].BlockNumber)
+
+	// 给自己转账只推送一次转入事件，ERC-721 的 Transfer 被忽略
+	assert.Equal(t, ActivityTokenIn, got[2].Type)
+	assert.Equal(t, uint(2), got[2].LogIndex)
+	assert.Equal(t, "token_in", got[2].Type.String())
+}
@@ -0,0 +1,72 @@
+// goether 命令行工具
+//
+//	goether gen -abi Token.json -pkg token -type Token -out token.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-enols/goether/gen"
+)
+
+const usage = `usage: goether <command> [flags]
+
+commands:
+  gen    根据合约 ABI 生成类型化 Go 绑定代码
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "gen":
+		if err := runGen(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "goether gen:", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	abiPath := fs.String("abi", "", "ABI JSON 文件，也可以是 Hardhat/Foundry 编译产物，- 表示标准输入")
+	pkg := fs.String("pkg", "", "生成代码的包名")
+	typ := fs.String("type", "", "合约类型名，例如 Token")
+	out := fs.String("out", "", "输出文件，为空时输出到标准输出")
+	fs.Parse(args)
+	if *abiPath == "" || *pkg == "" || *typ == "" {
+		fs.Usage()
+		return fmt.Errorf("-abi, -pkg and -type are required")
+	}
+
+	var (
+		input []byte
+		err   error
+	)
+	if *abiPath == "-" {
+		input, err = io.ReadAll(os.Stdin)
+	} else {
+		input, err = os.ReadFile(*abiPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	src, err := gen.Generate(gen.Config{Package: *pkg, Type: *typ, ABI: string(input)})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
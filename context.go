@@ -0,0 +1,180 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// callContext 在 ctx 的约束下执行一次阻塞调用
+//
+// ethrpc 客户端本身不感知 context，这里将调用放入独立的 goroutine 中执行，
+// 当 ctx 被取消或超时时立即返回 ctx.Err()，调用方不会再被阻塞。
+// fn 应当使用 Wallet.rpc(ctx) 返回的客户端，这样正在进行的 HTTP 请求也会随 ctx 中止，
+// goroutine 随之退出；否则请求会在后台继续执行直到完成。
+func callContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		ch <- result{value, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case r := <-ch:
+		return r.value, asRPCError(r.err)
+	}
+}
+
+// ContextClient 支持 context 的 HTTP 客户端，本包中的各种客户端都实现了该接口，
+// ctx 取消时正在进行的请求、重试与限流等待都会中止
+type ContextClient interface {
+	HTTPClient
+	PostContext(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error)
+}
+
+// postContext 使用 client 发送 POST 请求，client 为 *http.Client 或 ContextClient 时请求受 ctx 控制
+func postContext(ctx context.Context, client HTTPClient, url, contentType string, body io.Reader) (*http.Response, error) {
+	switch c := client.(type) {
+	case ContextClient:
+		return c.PostContext(ctx, url, contentType, body)
+	case *http.Client:
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		return c.Do(req)
+	}
+	return client.Post(url, contentType, body)
+}
+
+// boundClient 将一次调用的 ctx 绑定到 HTTP 客户端上，供 ethrpc 使用
+type boundClient struct {
+	ctx    context.Context
+	client HTTPClient
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口
+func (b boundClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return postContext(b.ctx, b.client, url, contentType, body)
+}
+
+// dataClient 记录 JSON-RPC 错误响应中的 data 字段
+//
+// ethrpc.EthError 只保留 code 与 message，节点把自定义错误与 Panic 的回滚数据放在 data 中，
+// 例如 {"code":3,"message":"execution reverted","data":"0x..."}。
+type dataClient struct {
+	ctx    context.Context
+	client HTTPClient
+	data   json.RawMessage
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口
+func (c *dataClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	resp, err := postContext(c.ctx, c.client, url, contentType, body)
+	if err != nil {
+		return resp, err
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var msg struct {
+		Error *RPCError `json:"error"`
+	}
+	if json.Unmarshal(b, &msg) == nil && msg.Error != nil && string(msg.Error.Data) != "null" {
+		c.data = msg.Error.Data
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+// callData 与 callContext 相同，但节点返回错误时 *RPCError 的 Data 为 JSON-RPC 错误中的 data 字段
+//
+// fn 必须使用传入的客户端发送请求。transport 为 client 实际使用的 HTTP 客户端，为 nil 时无法读取 data。
+func callData[T any](ctx context.Context, client *ethrpc.EthRPC, transport HTTPClient, fn func(rpc *ethrpc.EthRPC) (T, error)) (T, error) {
+	capture := &dataClient{ctx: ctx, client: transport}
+	if transport != nil {
+		bound := *client
+		ethrpc.WithHttpClient(capture)(&bound)
+		client = &bound
+	}
+	value, err := callContext(ctx, func() (T, error) {
+		return fn(client)
+	})
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && len(capture.data) > 0 {
+		rpcErr.Data = capture.data
+	}
+	return value, err
+}
+
+// rpc 返回请求受 ctx 控制的 RPC 客户端
+//
+// 只有 NewWallet 创建的客户端才知道实际发送请求的 HTTP 客户端，
+// 使用 WithClient、WithRPCOptions 或替换了 Wallet.Client 时返回 Wallet.Client 本身。
+func (w *Wallet) rpc(ctx context.Context) *ethrpc.EthRPC {
+	if w.transportOf != w.Client {
+		return w.Client
+	}
+	return bindRPC(ctx, w.Client, w.transport)
+}
+
+// bindRPC 复制 client 并让复制出的客户端通过 transport 发送受 ctx 控制的请求，transport 为 nil 时返回 client
+func bindRPC(ctx context.Context, client *ethrpc.EthRPC, transport HTTPClient) *ethrpc.EthRPC {
+	if transport == nil || ctx.Done() == nil {
+		return client
+	}
+	bound := *client
+	ethrpc.WithHttpClient(boundClient{ctx, transport})(&bound)
+	return &bound
+}
+
+// knownTransport 返回 Client 实际使用的 HTTP 客户端，使用 WithClient、WithRPCOptions 或替换了 Client 时返回 nil
+func (w *Wallet) knownTransport() HTTPClient {
+	if w.transportOf != w.Client {
+		return nil
+	}
+	return w.transport
+}
+
+// knownTransport 返回 Client 实际使用的 HTTP 客户端，Client 不是钱包或 NewContract 创建的客户端时返回 nil
+func (c *Contract) knownTransport() HTTPClient {
+	if c.Wallet != nil && c.Client == c.Wallet.Client {
+		return c.Wallet.knownTransport()
+	}
+	if c.transportOf != c.Client {
+		return nil
+	}
+	return c.transport
+}
+
+// sleepContext 等待 d 或直到 ctx 被取消
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallContext(t *testing.T) {
+	v, err := callContext(context.Background(), func() (int, error) {
+		return 1, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	_, err = callContext(context.Background(), func() (int, error) {
+		return 0, errors.New("rpc error")
+	})
+	assert.EqualError(t, err, "rpc error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = callContext(ctx, func() (int, error) {
+		time.Sleep(time.Second)
+		return 1, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	called := false
+	_, err = callContext(ctx, func() (int, error) {
+		called = true
+		return 1, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, called)
+}
+
+func TestWalletRPCContext(t *testing.T) {
+	aborted := make(chan struct{}, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// 读完请求体后服务端才能感知连接断开
+		io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			aborted <- struct{}{}
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	for _, opts := range [][]any{
+		{WithChainID(big.NewInt(1))},
+		{WithChainID(big.NewInt(1)), WithRetryPolicy(DefaultRetryPolicy), WithRateLimiter(NewRateLimiter(100, 1))},
+	} {
+		w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", srv.URL, opts...)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err = callContext(ctx, w.rpc(ctx).EthBlockNumber)
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		select {
+		case <-aborted:
+		case <-time.After(time.Second):
+			t.Fatal("http request was not cancelled")
+		}
+	}
+
+	// 外部传入的客户端无法绑定 ctx
+	client := ethrpc.New(srv.URL)
+	w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", "", WithClient(client), WithChainID(big.NewInt(1)))
+	assert.NoError(t, err)
+	assert.Same(t, client, w.rpc(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.Same(t, client, w.rpc(ctx))
+}
+
+func TestCallData(t *testing.T) {
+	m := newMockRPC(t)
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted", Data: "0x01020304"}
+	})
+	call := func(rpc *ethrpc.EthRPC) (string, error) {
+		return rpc.EthCall(ethrpc.T{To: "0x0000000000000000000000000000000000000001"}, "latest")
+	}
+
+	_, err := callData(context.Background(), ethrpc.New(m.URL), http.DefaultClient, call)
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, 3, rpcErr.Code)
+	assert.Equal(t, "execution reverted", rpcErr.Message)
+	assert.JSONEq(t, `"0x01020304"`, string(rpcErr.Data))
+
+	// 不知道实际的 HTTP 客户端时无法读取 data
+	_, err = callData(context.Background(), ethrpc.New(m.URL), nil, call)
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Empty(t, rpcErr.Data)
+}
@@ -1,44 +1,147 @@
 package goether
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-enols/ethrpc"
-	"github.com/go-enols/go-log"
 )
 
 type Contract struct {
 	Address common.Address
 	ABI     abi.ABI
+	// Bytecode 合约的创建字节码，仅部署合约时需要
+	Bytecode []byte
 
 	Wallet *Wallet
 	Client *ethrpc.EthRPC
+
+	// transport transportOf 实际使用的 HTTP 客户端，只有 NewContract 使用 rpc 创建客户端时设置
+	transport   HTTPClient
+	transportOf *ethrpc.EthRPC
 }
 
+// NewContract 创建合约实例，RPC 请求使用 wallet 的客户端；wallet 为 nil 时使用 rpc 创建客户端，
+// 此时只能调用只读方法。wallet 与 rpc 都为空时只能编码与解码 ABI 数据，发起调用时返回 ErrWalletNil
 func NewContract(address common.Address, abiStr, rpc string, wallet *Wallet) (*Contract, error) {
 	log.Debug("Creating new contract instance",
 		"address", address.Hex(),
-		"rpc", rpc,
-		"walletAddress", wallet.Address.Hex())
+		"rpc", rpc)
+
+	c, err := newContract(abiStr, wallet)
+	if err != nil {
+		return nil, err
+	}
+	c.Address = address
+	if c.Client == nil && rpc != "" {
+		c.Client = ethrpc.New(rpc)
+		c.transport, c.transportOf = http.DefaultClient, c.Client
+	}
+
+	log.Debug("Contract instance created successfully", "address", address.Hex())
+	return c, nil
+}
+
+// NewContractByName 与 NewContract 相同，但合约地址可以是十六进制地址或 ENS 名称，
+// 解析 ENS 名称时使用 wallet 的客户端与链ID
+func NewContractByName(nameOrAddress, abiStr string, wallet *Wallet) (*Contract, error) {
+	if IsENSName(nameOrAddress) && wallet == nil {
+		return nil, fmt.Errorf("%w: required to resolve ens name", ErrWalletNil)
+	}
+
+	var address common.Address
+	if wallet != nil {
+		var err error
+		address, err = wallet.ResolveAddress(nameOrAddress)
+		if err != nil {
+			log.Error("Failed to resolve contract address", "address", nameOrAddress, "error", err)
+			return nil, err
+		}
+	} else if common.IsHexAddress(nameOrAddress) {
+		address = common.HexToAddress(nameOrAddress)
+	} else {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, nameOrAddress)
+	}
+	return NewContract(address, abiStr, "", wallet)
+}
 
+// NewContractFromBytecode 使用 ABI 与创建字节码构造一个尚未部署的合约实例，
+// 调用 Deploy 部署后 Address 会被设置为新合约的地址
+func NewContractFromBytecode(abiStr string, bytecode []byte, wallet *Wallet) (*Contract, error) {
+	log.Debug("Creating contract instance from bytecode", "bytecodeLength", len(bytecode))
+	if len(bytecode) == 0 {
+		return nil, ErrBytecodeEmpty
+	}
+
+	c, err := newContract(abiStr, wallet)
+	if err != nil {
+		return nil, err
+	}
+	c.Bytecode = bytecode
+	return c, nil
+}
+
+func newContract(abiStr string, wallet *Wallet) (*Contract, error) {
 	Abi, err := abi.JSON(strings.NewReader(abiStr))
 	if err != nil {
 		log.Error("Failed to parse contract ABI", "error", err)
 		return nil, err
 	}
 
-	log.Debug("Contract instance created successfully", "address", address.Hex())
-	return &Contract{
-		Address: address,
-		ABI:     Abi,
-		Wallet:  wallet,
-		Client:  wallet.Client,
-	}, nil
+	c := &Contract{
+		ABI:    Abi,
+		Wallet: wallet,
+	}
+	if wallet != nil {
+		c.Client = wallet.Client
+	}
+	return c, nil
+}
+
+// Deploy 编码构造函数参数并通过 Wallet 发送创建合约的交易
+func (c *Contract) Deploy(opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.DeployContext(context.Background(), opts, args...)
+}
+
+// DeployContext 与 Deploy 相同，但交易的构建与广播受 ctx 控制
+func (c *Contract) DeployContext(ctx context.Context, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	log.Debug("Deploying contract", "argsCount", len(args))
+
+	if c.Wallet == nil {
+		err = ErrWalletNil
+		log.Error("Cannot deploy contract: wallet is nil")
+		return
+	}
+	if len(c.Bytecode) == 0 {
+		err = ErrBytecodeEmpty
+		log.Error("Cannot deploy contract: bytecode is empty")
+		return
+	}
+
+	data := make([]byte, len(c.Bytecode))
+	copy(data, c.Bytecode)
+	data, err = encodeConstructor(c.ABI, data, args...)
+	if err != nil {
+		log.Error("Failed to encode constructor arguments", "error", err)
+		return
+	}
+
+	address, txHash, err := c.Wallet.sendDeployTx(ctx, data, opts)
+	if err != nil {
+		return
+	}
+	c.Address = address
+
+	log.Debug("Contract deployed", "address", address.Hex(), "txHash", txHash)
+	return txHash, nil
 }
 
 // CallMethod Only read contract status
@@ -49,24 +152,37 @@ func NewContract(address common.Address, abiStr, rpc string, wallet *Wallet) (*C
 //	 String "latest" - for the latest mined block
 //	 String "pending" - for the pending state/transactions
 func (c *Contract) CallMethod(methodName, tag string, args ...interface{}) (res string, err error) {
+	return c.CallMethodContext(context.Background(), methodName, tag, args...)
+}
+
+// CallMethodContext 与 CallMethod 相同，但 eth_call 受 ctx 控制
+func (c *Contract) CallMethodContext(ctx context.Context, methodName, tag string, args ...interface{}) (res string, err error) {
 	log.Debug("Calling contract read method",
 		"contract", c.Address.Hex(),
 		"method", methodName,
 		"tag", tag,
 		"argsCount", len(args))
 
+	if c.Client == nil {
+		err = fmt.Errorf("%w: contract has no rpc client", ErrWalletNil)
+		log.Error("Cannot call contract method: client is nil", "method", methodName)
+		return
+	}
 	data, err := c.EncodeData(methodName, args...)
 	if err != nil {
 		log.Error("Failed to encode method data for call", "method", methodName, "error", err)
 		return
 	}
 
-	res, err = c.Client.EthCall(ethrpc.T{
-		Data: hexutil.Encode(data),
-		To:   c.Address.String(),
-		From: c.Address.String(),
-	}, tag)
+	res, err = callData(ctx, c.Client, c.knownTransport(), func(rpc *ethrpc.EthRPC) (string, error) {
+		return rpc.EthCall(ethrpc.T{
+			Data: hexutil.Encode(data),
+			To:   c.Address.String(),
+			From: c.Address.String(),
+		}, tag)
+	})
 	if err != nil {
+		err = c.decodeRevert(err)
 		log.Error("Failed to call contract method", "method", methodName, "error", err)
 		return
 	}
@@ -75,15 +191,99 @@ func (c *Contract) CallMethod(methodName, tag string, args ...interface{}) (res
 	return res, nil
 }
 
-// ExecMethod Execute tx
+// CallUnpack 调用只读方法并按 ABI 解码全部返回值
+func (c *Contract) CallUnpack(methodName string, args ...interface{}) ([]interface{}, error) {
+	return c.callUnpack(context.Background(), methodName, args...)
+}
+
+// CallUnpackContext 与 CallUnpack 相同，但 eth_call 受 ctx 控制
+func (c *Contract) CallUnpackContext(ctx context.Context, methodName string, args ...interface{}) ([]interface{}, error) {
+	return c.callUnpack(ctx, methodName, args...)
+}
+
+// callUnpack 调用只读方法并按 ABI 解码返回值
+func (c *Contract) callUnpack(ctx context.Context, methodName string, args ...interface{}) ([]interface{}, error) {
+	res, err := c.CallMethodContext(ctx, methodName, "latest", args...)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	if err = c.DecodeFromMethod(methodName, res, &results); err != nil {
+		log.Error("Failed to unpack method result", "method", methodName, "error", err)
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: method %s", ErrNoData, methodName)
+	}
+	return results, nil
+}
+
+// CallMethodInto 调用只读方法并将返回值解码到 out
+//
+// 只有一个返回值时 out 为对应类型的指针，例如 *common.Address；uint256 等整数的 Go 类型为 *big.Int，
+// 需要传入 **big.Int（var n *big.Int; &n）；
+// 多个返回值时 out 为结构体指针，字段按 abi.ToCamelCase(返回值名称) 对应。
+func (c *Contract) CallMethodInto(methodName, tag string, out any, args ...interface{}) error {
+	return c.CallMethodIntoContext(context.Background(), methodName, tag, out, args...)
+}
+
+// CallMethodIntoContext 与 CallMethodInto 相同，但 eth_call 受 ctx 控制
+func (c *Contract) CallMethodIntoContext(ctx context.Context, methodName, tag string, out any, args ...interface{}) error {
+	res, err := c.CallMethodContext(ctx, methodName, tag, args...)
+	if err != nil {
+		return err
+	}
+	if res == "" || res == "0x" {
+		return fmt.Errorf("%w: method %s", ErrNoData, methodName)
+	}
+	if err = c.DecodeFromMethod(methodName, res, &[]any{out}); err != nil {
+		log.Error("Failed to unpack method result into output", "method", methodName, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ExecMethod Execute tx，调用 payable 方法时通过 TxOpts.Value 或 ExecPayable 附带转账金额
 func (c *Contract) ExecMethod(methodName string, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.ExecMethodContext(context.Background(), methodName, opts, args...)
+}
+
+// ExecMethodContext 与 ExecMethod 相同，但交易的构建与广播受 ctx 控制
+func (c *Contract) ExecMethodContext(ctx context.Context, methodName string, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.exec(ctx, methodName, opts.value(), opts, false, args...)
+}
+
+// ExecPayable 执行 payable 方法，随交易转入 value（wei）
+func (c *Contract) ExecPayable(methodName string, value *big.Int, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.ExecPayableContext(context.Background(), methodName, value, opts, args...)
+}
+
+// ExecPayableContext 与 ExecPayable 相同，但交易的构建与广播受 ctx 控制
+func (c *Contract) ExecPayableContext(ctx context.Context, methodName string, value *big.Int, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.exec(ctx, methodName, value, opts, false, args...)
+}
+
+// ExecLegacyMethod 与 ExecMethod 相同，但通过 SendLegacyTx 发送旧版交易，用于不支持 EIP-1559 的链
+func (c *Contract) ExecLegacyMethod(methodName string, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.ExecLegacyMethodContext(context.Background(), methodName, opts, args...)
+}
+
+// ExecLegacyMethodContext 与 ExecLegacyMethod 相同，但交易的构建与广播受 ctx 控制
+func (c *Contract) ExecLegacyMethodContext(ctx context.Context, methodName string, opts *TxOpts, args ...interface{}) (txHash string, err error) {
+	return c.exec(ctx, methodName, opts.value(), opts, true, args...)
+}
+
+// exec 编码方法调用并发送交易，legacy 为 true 时发送旧版交易
+func (c *Contract) exec(ctx context.Context, methodName string, value *big.Int, opts *TxOpts, legacy bool, args ...interface{}) (txHash string, err error) {
 	log.Debug("Executing contract method",
 		"contract", c.Address.Hex(),
 		"method", methodName,
+		"value", value,
+		"legacy", legacy,
 		"argsCount", len(args))
 
 	if c.Wallet == nil {
-		err = errors.New("wallet is nil")
+		err = ErrWalletNil
 		log.Error("Cannot execute contract method: wallet is nil", "method", methodName)
 		return
 	}
@@ -94,8 +294,13 @@ func (c *Contract) ExecMethod(methodName string, opts *TxOpts, args ...interface
 		return
 	}
 
-	txHash, err = c.Wallet.SendTx(c.Address, big.NewInt(0), data, opts)
+	if legacy {
+		txHash, err = c.Wallet.SendLegacyTxContext(ctx, c.Address, value, data, opts)
+	} else {
+		txHash, err = c.Wallet.SendTxContext(ctx, c.Address, value, data, opts)
+	}
 	if err != nil {
+		err = c.decodeRevert(err)
 		log.Error("Failed to execute contract method", "method", methodName, "error", err)
 		return
 	}
@@ -104,6 +309,77 @@ func (c *Contract) ExecMethod(methodName string, opts *TxOpts, args ...interface
 	return txHash, nil
 }
 
+// GasEstimate 方法调用的 gas 与手续费预估
+type GasEstimate struct {
+	// Gas eth_estimateGas 的结果，不包含 GasBuffer
+	Gas uint64
+	// GasPrice 预计每单位 gas 的实际价格，EIP-1559 网络为 baseFee + 小费
+	GasPrice *big.Int
+	// Fee 预计手续费 Gas * GasPrice（wei）
+	Fee *big.Int
+	// MaxFee 按建议的最大手续费计算的上限 Gas * GasFeeCap（wei）
+	MaxFee *big.Int
+}
+
+// FeeEth 以 ETH 为单位的预计手续费
+func (e *GasEstimate) FeeEth() string {
+	return FormatUnits(e.Fee, 18)
+}
+
+// EstimateMethod 从钱包地址对方法调用执行 eth_estimateGas，返回 gas 与手续费预估，用于在 ExecMethod 前确认成本，
+// value 为附带的转账金额，可以为 nil。执行会失败时返回解码后的 revert 错误。
+func (c *Contract) EstimateMethod(methodName string, value *big.Int, args ...interface{}) (*GasEstimate, error) {
+	return c.EstimateMethodContext(context.Background(), methodName, value, args...)
+}
+
+// EstimateMethodContext 与 EstimateMethod 相同，但请求受 ctx 控制
+func (c *Contract) EstimateMethodContext(ctx context.Context, methodName string, value *big.Int, args ...interface{}) (*GasEstimate, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	w := c.Wallet
+	data, err := c.EncodeData(methodName, args...)
+	if err != nil {
+		log.Error("Failed to encode method data for estimation", "method", methodName, "error", err)
+		return nil, err
+	}
+
+	gas, err := callData(ctx, w.Client, w.knownTransport(), func(rpc *ethrpc.EthRPC) (int, error) {
+		return rpc.EthEstimateGas(ethrpc.T{
+			From:  w.Address.String(),
+			To:    c.Address.String(),
+			Value: value,
+			Data:  hexutil.Encode(data),
+		})
+	})
+	if err != nil {
+		err = c.decodeRevert(err)
+		log.Error("Failed to estimate contract method gas", "method", methodName, "error", err)
+		return nil, err
+	}
+
+	var price, feeCap *big.Int
+	if fees, err := w.SuggestFeesContext(ctx); err == nil {
+		price = new(big.Int).Add(fees.BaseFee, fees.GasTipCap)
+		feeCap = fees.GasFeeCap
+	} else {
+		gasPrice, err := callContext(ctx, w.rpc(ctx).EthGasPrice)
+		if err != nil {
+			return nil, err
+		}
+		price, feeCap = &gasPrice, &gasPrice
+	}
+
+	estimate := &GasEstimate{
+		Gas:      uint64(gas),
+		GasPrice: price,
+		Fee:      new(big.Int).Mul(big.NewInt(int64(gas)), price),
+		MaxFee:   new(big.Int).Mul(big.NewInt(int64(gas)), feeCap),
+	}
+	log.Debug("Contract method gas estimated", "method", methodName, "gas", gas, "fee", estimate.Fee.String())
+	return estimate, nil
+}
+
 func (c *Contract) GetAddress() string {
 	return c.Address.String()
 }
@@ -135,7 +411,7 @@ func (c *Contract) EncodeDataHex(methodName string, args ...interface{}) (hex st
 func (c *Contract) DecodeData(data []byte) (methodName string, params map[string]interface{}, err error) {
 	log.Debug("Decoding contract method data", "dataLength", len(data))
 	if len(data) < 4 {
-		err = errors.New("data is too short")
+		err = ErrDataTooShort
 		log.Error("Cannot decode data: too short", "dataLength", len(data))
 		return
 	}
@@ -164,6 +440,69 @@ func (c *Contract) DecodeDataHex(dataHex string) (methodName string, params map[
 	return c.DecodeData(data)
 }
 
+// ContractError 合约 ABI 中定义的自定义错误，例如 error InsufficientBalance(uint256 available)
+type ContractError struct {
+	Name   string
+	Params map[string]interface{}
+	// Data 原始回滚数据
+	Data []byte
+
+	inputs abi.Arguments
+}
+
+func (e *ContractError) Error() string {
+	args := make([]string, 0, len(e.inputs))
+	for i, input := range e.inputs {
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args = append(args, fmt.Sprintf("%s=%v", name, e.Params[input.Name]))
+	}
+	return fmt.Sprintf("execution reverted: %s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+// DecodeError 按 4 字节选择器在 ABI 的自定义错误中查找并解码回滚数据
+func (c *Contract) DecodeError(data []byte) (*ContractError, error) {
+	log.Debug("Decoding contract error", "dataLength", len(data))
+	if len(data) < 4 {
+		log.Error("Cannot decode error: too short", "dataLength", len(data))
+		return nil, ErrDataTooShort
+	}
+
+	errABI, err := c.ABI.ErrorByID([4]byte(data[:4]))
+	if err != nil {
+		log.Error("Failed to find error by ID", "error", err)
+		return nil, err
+	}
+
+	params := make(map[string]interface{})
+	if err = errABI.Inputs.UnpackIntoMap(params, data[4:]); err != nil {
+		log.Error("Failed to unpack error parameters", "name", errABI.Name, "error", err)
+		return nil, err
+	}
+
+	log.Debug("Contract error decoded successfully", "name", errABI.Name, "paramsCount", len(params))
+	return &ContractError{
+		Name:   errABI.Name,
+		Params: params,
+		Data:   data,
+		inputs: errABI.Inputs,
+	}, nil
+}
+
+// decodeRevert 将回滚错误中的自定义错误数据按 ABI 解码为 *ContractError，无法解码时返回 *RevertError 或原始错误
+func (c *Contract) decodeRevert(err error) error {
+	err = asRevertError(err)
+	var revert *RevertError
+	if errors.As(err, &revert) && revert.Reason == "" && len(revert.Data) >= 4 {
+		if contractErr, decodeErr := c.DecodeError(revert.Data); decodeErr == nil {
+			return contractErr
+		}
+	}
+	return err
+}
+
 func (c *Contract) DecodeEvent(topics []common.Hash, data []byte) (eventName string, values map[string]interface{}, err error) {
 	log.Debug("Decoding contract event", "topicsCount", len(topics), "dataLength", len(data))
 	if len(topics) < 1 {
@@ -216,6 +555,60 @@ func (c *Contract) DecodeEventHex(topicsHex []string, dataHex string) (eventName
 	return c.DecodeEvent(topics, common.FromHex(dataHex))
 }
 
+// DecodeEventInto 将事件解码到结构体中，out 必须为结构体指针
+//
+// 字段通过 `abi:"name"` 标签与事件参数对应，没有标签时按参数名的驼峰形式（abi.ToCamelCase）匹配，
+// indexed 与非 indexed 参数都会被赋值，没有对应字段的参数会被忽略。
+// 字符串、bytes 等动态类型的 indexed 参数在 topics 中只有哈希，对应字段应为 common.Hash。
+func (c *Contract) DecodeEventInto(topics []common.Hash, data []byte, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode event into %T: out must be a non-nil pointer to struct", out)
+	}
+	eventName, values, err := c.DecodeEvent(topics, data)
+	if err != nil {
+		return err
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value, ok := eventFieldValue(values, field)
+		if !ok || value == nil {
+			continue
+		}
+		v := reflect.ValueOf(value)
+		dst := rv.Field(i)
+		switch {
+		case v.Type().AssignableTo(dst.Type()):
+			dst.Set(v)
+		case v.Type().ConvertibleTo(dst.Type()):
+			dst.Set(v.Convert(dst.Type()))
+		default:
+			return fmt.Errorf("decode event %s: cannot assign %s to field %s of type %s", eventName, v.Type(), field.Name, dst.Type())
+		}
+	}
+	return nil
+}
+
+// eventFieldValue 按 abi 标签或驼峰名称查找字段对应的事件参数
+func eventFieldValue(values map[string]interface{}, field reflect.StructField) (interface{}, bool) {
+	if tag, ok := field.Tag.Lookup("abi"); ok {
+		value, ok := values[tag]
+		return value, ok
+	}
+	for name, value := range values {
+		if abi.ToCamelCase(name) == field.Name {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
 func (c *Contract) DecodeFromMethod(method string, output any, results *[]any) error {
 
 	if results == nil {
@@ -233,7 +626,7 @@ func (c *Contract) DecodeFromMethod(method string, output any, results *[]any) e
 	} else if value, ok := output.([]byte); ok {
 		data = value
 	} else {
-		return errors.New("output 无效的类型")
+		return ErrInvalidOutputType
 	}
 	if len(data) == 0 {
 		*results = make([]interface{}, 0)
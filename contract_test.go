@@ -1,11 +1,14 @@
 package goether
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,3 +56,241 @@ func TestDecodeEventHex(t *testing.T) {
 	assert.Equal(t, common.HexToAddress("0x3dd22a3ad30df8acaf12def3b27e085525a98065"), values["to"])
 	assert.Equal(t, big.NewInt(10000000), values["value"])
 }
+
+func TestDecodeEventInto(t *testing.T) {
+	abi := `[{"anonymous": false,"inputs": [{"indexed": true,"name": "from","type": "address"},{"indexed": true,"name": "to","type": "address"},{"indexed": false,"name": "value","type": "uint256"},{"indexed": false,"name": "fee_bps","type": "uint16"}],"name": "Transfer","type": "event"}]`
+	testContract, err := NewContract(common.HexToAddress("0x0"), abi, "", nil)
+	assert.NoError(t, err)
+	from := common.HexToAddress("0xa06b79e655db7d7c3b3e7b2cceeb068c3259d0c9")
+	to := common.HexToAddress("0x3dd22a3ad30df8acaf12def3b27e085525a98065")
+	event := testContract.ABI.Events["Transfer"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(10000000), uint16(30))
+	assert.NoError(t, err)
+	topics := []common.Hash{event.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}
+
+	type basisPoints uint16
+	var transfer struct {
+		From      common.Address
+		Recipient common.Address `abi:"to"`
+		Value     *big.Int
+		FeeBps    basisPoints
+		ignored   int
+	}
+	assert.NoError(t, testContract.DecodeEventInto(topics, data, &transfer))
+	assert.Equal(t, from, transfer.From)
+	assert.Equal(t, to, transfer.Recipient)
+	assert.Equal(t, big.NewInt(10000000), transfer.Value)
+	assert.Equal(t, basisPoints(30), transfer.FeeBps)
+
+	var wrong struct{ Value string }
+	assert.Error(t, testContract.DecodeEventInto(topics, data, &wrong))
+	assert.Error(t, testContract.DecodeEventInto(topics, data, transfer))
+	assert.Error(t, testContract.DecodeEventInto([]common.Hash{common.HexToHash("0x01")}, data, &transfer))
+}
+
+func TestContractDeploy(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 7, &sent)
+	w := newTestWallet(t, m)
+
+	_, err := NewContractFromBytecode(testConstructorABI, nil, w)
+	assert.Error(t, err)
+
+	c, err := NewContractFromBytecode(testConstructorABI, []byte{0x60, 0x80}, w)
+	assert.NoError(t, err)
+
+	txHash, err := c.Deploy(nil, common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA"), big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.CreateAddress(w.Address, 7), c.Address)
+	assert.Equal(t, txHash, sent[0].Hash().Hex())
+	assert.Equal(t, []byte{0x60, 0x80}, c.Bytecode)
+
+	_, err = c.Deploy(nil, "wrong type")
+	assert.Error(t, err)
+}
+
+func TestDecodeError(t *testing.T) {
+	abi := `[{"inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"},{"inputs":[],"name":"withdraw","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	c, err := NewContract(common.HexToAddress("0x0000000000000000000000000000000000000002"), abi, "", w)
+	assert.NoError(t, err)
+
+	data := append(crypto.Keccak256([]byte("InsufficientBalance(uint256,uint256)"))[:4],
+		append(common.LeftPadBytes([]byte{1}, 32), common.LeftPadBytes([]byte{2}, 32)...)...)
+	contractErr, err := c.DecodeError(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "InsufficientBalance", contractErr.Name)
+	assert.Equal(t, big.NewInt(1), contractErr.Params["available"])
+	assert.Equal(t, big.NewInt(2), contractErr.Params["required"])
+	assert.Equal(t, "execution reverted: InsufficientBalance(available=1, required=2)", contractErr.Error())
+
+	_, err = c.DecodeError([]byte{0x01, 0x02, 0x03, 0x04})
+	assert.Error(t, err)
+
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted", Data: hexutil.Encode(data)}
+	})
+	_, err = c.CallMethod("withdraw", "latest")
+	assert.ErrorAs(t, err, &contractErr)
+	assert.Equal(t, "InsufficientBalance", contractErr.Name)
+
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	m.On("eth_estimateGas", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted", Data: hexutil.Encode(data)}
+	})
+	_, err = c.ExecMethod("withdraw", nil)
+	assert.ErrorAs(t, err, &contractErr)
+	assert.Empty(t, sent)
+}
+
+func TestCallMethodInto(t *testing.T) {
+	pairABI := `[{"type":"function","name":"getReserves","stateMutability":"view","inputs":[],"outputs":[{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}]},
+		{"type":"function","name":"token0","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+		{"type":"function","name":"kLast","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"type":"function","name":"sync","stateMutability":"nonpayable","inputs":[],"outputs":[]}]`
+	token0 := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	c, err := NewContract(common.HexToAddress("0x01"), pairABI, "", w)
+	assert.NoError(t, err)
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			Data hexutil.Bytes `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		method, _ := c.ABI.MethodById(tx.Data)
+		switch method.Name {
+		case "getReserves":
+			out, _ := method.Outputs.Pack(big.NewInt(100), big.NewInt(200), uint32(1700000000))
+			return hexutil.Encode(out), nil
+		case "token0":
+			out, _ := method.Outputs.Pack(token0)
+			return hexutil.Encode(out), nil
+		case "kLast":
+			out, _ := method.Outputs.Pack(big.NewInt(20000))
+			return hexutil.Encode(out), nil
+		}
+		return "0x", nil
+	})
+
+	var reserves struct {
+		Reserve0           *big.Int
+		Reserve1           *big.Int
+		BlockTimestampLast uint32
+	}
+	assert.NoError(t, c.CallMethodInto("getReserves", "latest", &reserves))
+	assert.Equal(t, big.NewInt(200), reserves.Reserve1)
+	assert.Equal(t, uint32(1700000000), reserves.BlockTimestampLast)
+
+	var addr common.Address
+	assert.NoError(t, c.CallMethodInto("token0", "latest", &addr))
+	assert.Equal(t, token0, addr)
+	results, err := c.CallUnpack("token0")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{token0}, results)
+
+	var kLast *big.Int
+	assert.NoError(t, c.CallMethodInto("kLast", "latest", &kLast))
+	assert.Equal(t, big.NewInt(20000), kLast)
+
+	var wrong string
+	assert.Error(t, c.CallMethodInto("token0", "latest", &wrong))
+	assert.ErrorIs(t, c.CallMethodInto("sync", "latest", &addr), ErrNoData)
+}
+
+func TestNewContractRPC(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("eth_call", hexutil.Encode(common.LeftPadBytes([]byte{18}, 32)))
+
+	// 没有钱包时使用 rpc 创建客户端，只能调用只读方法
+	c, err := NewContract(common.HexToAddress("0x01"), ERC20ABI, m.URL, nil)
+	assert.NoError(t, err)
+	results, err := c.CallUnpack("decimals")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{uint8(18)}, results)
+	assert.Equal(t, 1, m.Calls("eth_call"))
+	_, err = c.ExecMethod("transfer", nil, common.Address{}, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrWalletNil)
+}
+
+func TestEstimateMethod(t *testing.T) {
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	token, err := NewERC20(common.HexToAddress("0x01"), w)
+	assert.NoError(t, err)
+	to := common.HexToAddress("0x02")
+	m.On("eth_estimateGas", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			From common.Address `json:"from"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		assert.Equal(t, w.Address, tx.From)
+		if method, _ := token.ABI.MethodById(tx.Data); method.Name == "approve" {
+			return nil, &mockError{Code: 3, Message: "execution reverted: paused"}
+		}
+		return "0xc350", nil
+	})
+	m.Result("eth_feeHistory", map[string]any{
+		"oldestBlock":   "0x10",
+		"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00"},
+		"gasUsedRatio":  []float64{0.5},
+		"reward":        [][]string{{"0x3b9aca00"}},
+	})
+
+	estimate, err := token.EstimateMethod("transfer", nil, to, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50000), estimate.Gas)
+	assert.Equal(t, big.NewInt(2e9), estimate.GasPrice)
+	assert.Equal(t, big.NewInt(1e14), estimate.Fee)
+	assert.Equal(t, big.NewInt(15e13), estimate.MaxFee)
+	assert.Equal(t, "0.0001", estimate.FeeEth())
+
+	// 不支持 EIP-1559 时使用 gasPrice
+	m.Result("eth_feeHistory", map[string]any{"oldestBlock": "0x0", "baseFeePerGas": []string{}})
+	m.Result("eth_gasPrice", "0x3b9aca00")
+	estimate, err = token.EstimateMethod("transfer", nil, to, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5e13), estimate.Fee)
+	assert.Equal(t, estimate.Fee, estimate.MaxFee)
+
+	_, err = token.EstimateMethod("approve", nil, to, big.NewInt(1))
+	var revert *RevertError
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, "paused", revert.Reason)
+	_, err = token.EstimateMethod("missing", nil)
+	assert.Error(t, err)
+}
+
+func TestExecMethodValue(t *testing.T) {
+	wethABI := `[{"type":"function","name":"deposit","stateMutability":"payable","inputs":[],"outputs":[]}]`
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+	c, err := NewContract(common.HexToAddress("0x01"), wethABI, "", w)
+	assert.NoError(t, err)
+
+	_, err = c.ExecMethod("deposit", nil)
+	assert.NoError(t, err)
+	_, err = c.ExecMethod("deposit", &TxOpts{Value: big.NewInt(1e18)})
+	assert.NoError(t, err)
+	_, err = c.ExecPayable("deposit", big.NewInt(5), nil)
+	assert.NoError(t, err)
+
+	_, err = c.ExecLegacyMethod("deposit", &TxOpts{Value: big.NewInt(7)})
+	assert.NoError(t, err)
+
+	assert.Len(t, sent, 4)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), sent[0].Type())
+	assert.Equal(t, big.NewInt(0), sent[0].Value())
+	assert.Equal(t, big.NewInt(1e18), sent[1].Value())
+	assert.Equal(t, big.NewInt(5), sent[2].Value())
+	assert.Equal(t, c.Address, *sent[1].To())
+	assert.Equal(t, uint8(types.LegacyTxType), sent[3].Type())
+	assert.Equal(t, big.NewInt(7), sent[3].Value())
+	assert.Equal(t, big.NewInt(1e9), sent[3].GasPrice())
+}
@@ -0,0 +1,132 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeployContract 部署合约
+//
+// bytecode 为合约的创建字节码，abiStr 用于编码构造函数参数 constructorArgs，
+// 没有构造参数时 abiStr 可以为空。返回根据发送者地址与 nonce 推算出的合约地址及交易哈希。
+func (w *Wallet) DeployContract(bytecode []byte, abiStr string, constructorArgs []interface{}, opts *TxOpts) (address common.Address, txHash string, err error) {
+	return w.DeployContractContext(context.Background(), bytecode, abiStr, constructorArgs, opts)
+}
+
+// DeployContractContext 与 DeployContract 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) DeployContractContext(ctx context.Context, bytecode []byte, abiStr string, constructorArgs []interface{}, opts *TxOpts) (address common.Address, txHash string, err error) {
+	log.Debug("Deploying contract",
+		"from", w.Address.Hex(),
+		"bytecodeLength", len(bytecode),
+		"argsCount", len(constructorArgs))
+
+	data, err := encodeDeployData(bytecode, abiStr, constructorArgs...)
+	if err != nil {
+		log.Error("Failed to encode constructor arguments", "error", err)
+		return
+	}
+	return w.sendDeployTx(ctx, data, opts)
+}
+
+// ContractAddressFromNonce 计算 deployer 使用 nonce 通过 CREATE 部署的合约地址：keccak256(rlp([deployer, nonce])) 的后 20 字节
+func ContractAddressFromNonce(deployer common.Address, nonce uint64) common.Address {
+	return crypto.CreateAddress(deployer, nonce)
+}
+
+// NextContractAddress 钱包下一笔交易为部署交易时的合约地址，
+// 启用 NonceManager 时基于其下一个分配的 nonce，否则基于 pending nonce 计算
+//
+// 多个合约的构造参数互相引用时，可以用 ContractAddressFromNonce(w.Address, nonce+i) 预先算出后续合约的地址。
+func (w *Wallet) NextContractAddress() (common.Address, error) {
+	return w.NextContractAddressContext(context.Background())
+}
+
+// NextContractAddressContext 与 NextContractAddress 相同，但请求受 ctx 控制
+func (w *Wallet) NextContractAddressContext(ctx context.Context) (common.Address, error) {
+	var nonce int
+	var err error
+	if w.NonceManager != nil {
+		nonce, err = w.NonceManager.Peek(ctx)
+	} else {
+		nonce, err = w.GetPendingNonceContext(ctx)
+	}
+	if err != nil {
+		return common.Address{}, err
+	}
+	return ContractAddressFromNonce(w.Address, uint64(nonce)), nil
+}
+
+// encodeDeployData 将 ABI 编码后的构造函数参数追加到字节码之后
+func encodeDeployData(bytecode []byte, abiStr string, args ...interface{}) ([]byte, error) {
+	if len(bytecode) == 0 {
+		return nil, ErrBytecodeEmpty
+	}
+	data := make([]byte, len(bytecode))
+	copy(data, bytecode)
+
+	if abiStr == "" {
+		if len(args) > 0 {
+			return nil, errors.New("abi is required to encode constructor arguments")
+		}
+		return data, nil
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(abiStr))
+	if err != nil {
+		return nil, err
+	}
+	return encodeConstructor(parsed, data, args...)
+}
+
+func encodeConstructor(parsed abi.ABI, bytecode []byte, args ...interface{}) ([]byte, error) {
+	packed, err := parsed.Pack("", args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(bytecode, packed...), nil
+}
+
+// sendDeployTx 签名并广播创建合约的交易
+func (w *Wallet) sendDeployTx(ctx context.Context, data []byte, opts *TxOpts) (address common.Address, txHash string, err error) {
+	amount := big.NewInt(0)
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	opts, err = w.initTxOpts(ctx, nil, amount, data, opts)
+	if err != nil {
+		log.Error("Failed to initialize deploy transaction options", "error", err)
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(*opts.Nonce, &err)
+	}
+
+	tx, err := signTxWith(w.signDigest(ctx), &types.DynamicFeeTx{
+		ChainID:   w.ChainID,
+		Nonce:     uint64(*opts.Nonce),
+		GasTipCap: opts.GasTipCap,
+		GasFeeCap: opts.GasFeeCap,
+		Gas:       uint64(*opts.GasLimit),
+		Value:     amount,
+		Data:      data,
+	}, types.LatestSignerForChainID(w.ChainID))
+	if err != nil {
+		log.Error("Failed to sign deploy transaction", "error", err)
+		return
+	}
+
+	txHash, err = w.sendTx(ctx, tx, opts)
+	if err != nil {
+		log.Error("Failed to send deploy transaction", "error", err)
+		return
+	}
+
+	address = ContractAddressFromNonce(w.Address, uint64(*opts.Nonce))
+	log.Debug("Contract deployment sent successfully", "address", address.Hex(), "txHash", txHash)
+	return address, txHash, nil
+}
@@ -0,0 +1,103 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConstructorABI = `[{"inputs":[{"name":"owner","type":"address"},{"name":"supply","type":"uint256"}],"stateMutability":"nonpayable","type":"constructor"}]`
+
+// mockSendRPC 注册发送交易所需的方法，广播的交易会写入 sent
+func mockSendRPC(m *mockRPC, nonce uint64, sent *[]*types.Transaction) {
+	m.Result("eth_getTransactionCount", hexutil.EncodeUint64(nonce))
+	m.Result("eth_estimateGas", "0x5208")
+	m.Result("eth_gasPrice", "0x3b9aca00")
+	m.On("eth_sendRawTransaction", func(params []json.RawMessage) (any, error) {
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+			return nil, err
+		}
+		*sent = append(*sent, tx)
+		return tx.Hash().Hex(), nil
+	})
+}
+
+func TestDeployContract(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 5, &sent)
+	w := newTestWallet(t, m)
+
+	bytecode := []byte{0x60, 0x80, 0x60, 0x40}
+	owner := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	address, txHash, err := w.DeployContract(bytecode, testConstructorABI, []interface{}{owner, big.NewInt(100)}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.CreateAddress(w.Address, 5), address)
+
+	assert.Len(t, sent, 1)
+	assert.Equal(t, txHash, sent[0].Hash().Hex())
+	assert.Nil(t, sent[0].To())
+	assert.Equal(t, bytecode, sent[0].Data()[:4])
+	assert.Len(t, sent[0].Data(), 4+64)
+
+	_, _, err = w.DeployContract(bytecode, "", []interface{}{owner}, nil)
+	assert.Error(t, err)
+	_, _, err = w.DeployContract(nil, "", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestContractAddressFromNonce(t *testing.T) {
+	deployer := common.HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0")
+	for nonce, expected := range []string{
+		"0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d",
+		"0x343c43a37d37dff08ae8c4a11544c718abb4fcf8",
+		"0xf778b86fa74e846c4f0a1fbd1335fe81c00a0c91",
+		"0xfffd933a0bc612844eaf0c6fe3e5b8e9b6c1d19c",
+	} {
+		assert.Equal(t, common.HexToAddress(expected), ContractAddressFromNonce(deployer, uint64(nonce)))
+	}
+	for _, nonce := range []uint64{0x7f, 0x80, 0xff, 0x100, 1 << 32} {
+		assert.Equal(t, crypto.CreateAddress(deployer, nonce), ContractAddressFromNonce(deployer, nonce))
+	}
+
+	m := newMockRPC(t)
+	m.On("eth_getTransactionCount", func(params []json.RawMessage) (any, error) {
+		var tag string
+		json.Unmarshal(params[1], &tag)
+		assert.Equal(t, "pending", tag)
+		return "0x7", nil
+	})
+	w := newTestWallet(t, m)
+	address, err := w.NextContractAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.CreateAddress(w.Address, 7), address)
+
+	m.On("eth_getTransactionCount", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32000, Message: "boom"}
+	})
+	_, err = w.NextContractAddress()
+	assert.Error(t, err)
+
+	// 启用 NonceManager 后使用其下一个分配的 nonce，且不占用该 nonce
+	m.Result("eth_getTransactionCount", "0x7")
+	n := w.EnableNonceManager()
+	nonce, err := n.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 7, nonce)
+	address, err = w.NextContractAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.CreateAddress(w.Address, 8), address)
+	nonce, err = n.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 8, nonce)
+}
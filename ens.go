@@ -0,0 +1,200 @@
+package goether
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/ethrpc"
+)
+
+// ENSRegistryAddresses 各链上 ENS Registry 合约的地址，按链ID索引
+var ENSRegistryAddresses = map[int64]common.Address{
+	1:        common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"), // Mainnet
+	17000:    common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"), // Holesky
+	11155111: common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"), // Sepolia
+}
+
+// ENSRegistryABI ENS Registry 合约中用到的方法
+const ENSRegistryABI = `[
+{"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// ENSResolverABI ENS Resolver 合约中用到的方法
+const ENSResolverABI = `[
+{"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"node","type":"bytes32"}],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"}
+]`
+
+// ENS 通过 Registry 与 Resolver 合约解析 ENS 名称
+type ENS struct {
+	Registry common.Address
+	Client   *ethrpc.EthRPC
+}
+
+// NewENS 根据链ID选择 Registry 地址创建 ENS 解析器
+func NewENS(client *ethrpc.EthRPC, chainID *big.Int) (*ENS, error) {
+	if chainID == nil {
+		return nil, fmt.Errorf("%w: chainID is nil", ErrInvalidChainID)
+	}
+	registry, ok := ENSRegistryAddresses[chainID.Int64()]
+	if !ok {
+		return nil, fmt.Errorf("ens is not supported on chain %s", chainID.String())
+	}
+	return &ENS{Registry: registry, Client: client}, nil
+}
+
+// IsENSName 判断 s 是否为 ENS 名称而不是十六进制地址
+func IsENSName(s string) bool {
+	return strings.Contains(s, ".") && !common.IsHexAddress(s)
+}
+
+// Resolve 解析 ENS 名称对应的地址
+func (e *ENS) Resolve(name string) (common.Address, error) {
+	return e.ResolveContext(context.Background(), name)
+}
+
+// ResolveContext 与 Resolve 相同，但查询受 ctx 控制
+func (e *ENS) ResolveContext(ctx context.Context, name string) (common.Address, error) {
+	log.Debug("Resolving ens name", "name", name)
+	node := [32]byte(Namehash(name))
+
+	resolver, err := e.resolver(ctx, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	r, err := e.contract(ENSResolverABI, resolver)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, err := callOne[common.Address](ctx, r, "addr", node)
+	if err != nil {
+		log.Error("Failed to resolve ens name", "name", name, "error", err)
+		return common.Address{}, err
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrENSNotFound, name)
+	}
+
+	log.Debug("Ens name resolved", "name", name, "address", address.Hex())
+	return address, nil
+}
+
+// LookupAddress 反向解析 address 的主名称，并校验该名称正向解析回同一地址
+func (e *ENS) LookupAddress(address common.Address) (string, error) {
+	return e.LookupAddressContext(context.Background(), address)
+}
+
+// LookupAddressContext 与 LookupAddress 相同，但查询受 ctx 控制
+func (e *ENS) LookupAddressContext(ctx context.Context, address common.Address) (string, error) {
+	log.Debug("Looking up ens name", "address", address.Hex())
+	reverse := strings.ToLower(address.Hex()[2:]) + ".addr.reverse"
+	node := [32]byte(Namehash(reverse))
+
+	resolver, err := e.resolver(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	r, err := e.contract(ENSResolverABI, resolver)
+	if err != nil {
+		return "", err
+	}
+	name, err := callOne[string](ctx, r, "name", node)
+	if err != nil {
+		log.Error("Failed to lookup ens name", "address", address.Hex(), "error", err)
+		return "", err
+	}
+	if name == "" {
+		return "", fmt.Errorf("%w: %s", ErrENSNotFound, reverse)
+	}
+
+	// 反向记录可以由任何人设置，必须确认正向解析结果一致
+	forward, err := e.ResolveContext(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if forward != address {
+		return "", fmt.Errorf("ens name %s resolves to %s, not %s", name, forward.Hex(), address.Hex())
+	}
+
+	log.Debug("Ens name found", "address", address.Hex(), "name", name)
+	return name, nil
+}
+
+// resolver 查询 node 在 Registry 中登记的 Resolver 合约地址
+func (e *ENS) resolver(ctx context.Context, node [32]byte) (common.Address, error) {
+	registry, err := e.contract(ENSRegistryABI, e.Registry)
+	if err != nil {
+		return common.Address{}, err
+	}
+	resolver, err := callOne[common.Address](ctx, registry, "resolver", node)
+	if err != nil {
+		log.Error("Failed to query ens resolver", "error", err)
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, ErrENSNotFound
+	}
+	return resolver, nil
+}
+
+func (e *ENS) contract(abiStr string, address common.Address) (*Contract, error) {
+	c, err := newContract(abiStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.Address = address
+	c.Client = e.Client
+	return c, nil
+}
+
+// Namehash 计算 EIP-137 定义的名称哈希，名称只做小写处理，不做完整的 UTS-46 规范化
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], label)
+	}
+	return node
+}
+
+// LookupName 反向解析 address 的 ENS 主名称
+func (w *Wallet) LookupName(address common.Address) (string, error) {
+	return w.LookupNameContext(context.Background(), address)
+}
+
+// LookupNameContext 与 LookupName 相同，但查询受 ctx 控制
+func (w *Wallet) LookupNameContext(ctx context.Context, address common.Address) (string, error) {
+	ens, err := NewENS(w.rpc(ctx), w.ChainID)
+	if err != nil {
+		return "", err
+	}
+	return ens.LookupAddressContext(ctx, address)
+}
+
+// ResolveAddress 解析地址参数：十六进制地址直接返回，ENS 名称通过钱包所在链的 Registry 解析
+func (w *Wallet) ResolveAddress(nameOrAddress string) (common.Address, error) {
+	return w.ResolveAddressContext(context.Background(), nameOrAddress)
+}
+
+// ResolveAddressContext 与 ResolveAddress 相同，但查询受 ctx 控制
+func (w *Wallet) ResolveAddressContext(ctx context.Context, nameOrAddress string) (common.Address, error) {
+	if !IsENSName(nameOrAddress) {
+		if !common.IsHexAddress(nameOrAddress) {
+			return common.Address{}, fmt.Errorf("%w: %s", ErrInvalidAddress, nameOrAddress)
+		}
+		return common.HexToAddress(nameOrAddress), nil
+	}
+	ens, err := NewENS(w.rpc(ctx), w.ChainID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return ens.ResolveContext(ctx, nameOrAddress)
+}
@@ -0,0 +1,82 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamehash(t *testing.T) {
+	assert.Equal(t, common.Hash{}, Namehash(""))
+	assert.Equal(t, "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae", Namehash("eth").Hex())
+	assert.Equal(t, "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f", Namehash("foo.eth").Hex())
+	assert.Equal(t, Namehash("foo.eth"), Namehash("FOO.eth"))
+}
+
+func TestENSResolve(t *testing.T) {
+	target := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	m := newMockRPC(t)
+	ensABI := strings.TrimSuffix(ENSRegistryABI, "]") + "," + strings.TrimPrefix(ENSResolverABI, "[")
+	mockContractCalls(m, ensABI, map[string][]interface{}{
+		"resolver": {common.HexToAddress("0x231b0Ee14048e9dCcD1d247744d114a4EB5E8E63")},
+		"addr":     {target},
+		"name":     {"vitalik.eth"},
+	})
+	m.On("eth_getBalance", func(params []json.RawMessage) (any, error) {
+		var account string
+		json.Unmarshal(params[0], &account)
+		assert.Equal(t, strings.ToLower(target.Hex()), strings.ToLower(account))
+		return "0x64", nil
+	})
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+
+	address, err := w.ResolveAddress("vitalik.eth")
+	assert.NoError(t, err)
+	assert.Equal(t, target, address)
+	address, err = w.ResolveAddress(target.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, target, address)
+	_, err = w.ResolveAddress("not-an-address")
+	assert.Error(t, err)
+
+	balance, err := w.GetBalanceOf("vitalik.eth")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), balance.Int64())
+
+	_, err = w.SendTxTo("vitalik.eth", big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, target, *sent[0].To())
+
+	c, err := NewContractByName("vitalik.eth", ERC20ABI, w)
+	assert.NoError(t, err)
+	assert.Equal(t, target, c.Address)
+	_, err = NewContractByName("vitalik.eth", ERC20ABI, nil)
+	assert.Error(t, err)
+
+	name, err := w.LookupName(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "vitalik.eth", name)
+	_, err = w.LookupName(w.Address)
+	assert.Error(t, err)
+
+	_, err = NewENS(w.Client, big.NewInt(56))
+	assert.Error(t, err)
+}
+
+func TestENSNotFound(t *testing.T) {
+	m := newMockRPC(t)
+	mockContractCalls(m, ENSRegistryABI, map[string][]interface{}{
+		"resolver": {common.Address{}},
+	})
+	w := newTestWallet(t, m)
+
+	_, err := w.ResolveAddress("unknown.eth")
+	assert.ErrorIs(t, err, ErrENSNotFound)
+}
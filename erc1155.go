@@ -0,0 +1,131 @@
+package goether
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC1155ABI 标准 ERC-1155 多代币合约的 ABI
+const ERC1155ABI = `[
+{"inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"accounts","type":"address[]"},{"name":"ids","type":"uint256[]"}],"name":"balanceOfBatch","outputs":[{"name":"","type":"uint256[]"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"id","type":"uint256"}],"name":"uri","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"account","type":"address"},{"name":"operator","type":"address"}],"name":"isApprovedForAll","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"operator","type":"address"},{"name":"approved","type":"bool"}],"name":"setApprovalForAll","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},{"name":"amounts","type":"uint256[]"},{"name":"data","type":"bytes"}],"name":"safeBatchTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"value","type":"uint256"}],"name":"TransferSingle","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"ids","type":"uint256[]"},{"indexed":false,"name":"values","type":"uint256[]"}],"name":"TransferBatch","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"account","type":"address"},{"indexed":true,"name":"operator","type":"address"},{"indexed":false,"name":"approved","type":"bool"}],"name":"ApprovalForAll","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"string"},{"indexed":true,"name":"id","type":"uint256"}],"name":"URI","type":"event"}
+]`
+
+// ERC1155 标准 ERC-1155 多代币合约
+type ERC1155 struct {
+	*Contract
+}
+
+// ERC1155Transfer TransferSingle 或 TransferBatch 事件解码后的转账记录，
+// TransferSingle 事件的 IDs 与 Values 只有一个元素
+type ERC1155Transfer struct {
+	Operator common.Address
+	From     common.Address
+	To       common.Address
+	IDs      []*big.Int
+	Values   []*big.Int
+}
+
+// NewERC1155 创建 ERC-1155 合约实例
+func NewERC1155(address common.Address, wallet *Wallet) (*ERC1155, error) {
+	c, err := newContract(ERC1155ABI, wallet)
+	if err != nil {
+		return nil, err
+	}
+	c.Address = address
+	return &ERC1155{Contract: c}, nil
+}
+
+// BalanceOf 查询 account 持有的 id 代币数量
+func (t *ERC1155) BalanceOf(account common.Address, id *big.Int) (*big.Int, error) {
+	return callOne[*big.Int](context.Background(), t.Contract, "balanceOf", account, id)
+}
+
+// BalanceOfBatch 批量查询余额，accounts 与 ids 按下标一一对应
+func (t *ERC1155) BalanceOfBatch(accounts []common.Address, ids []*big.Int) ([]*big.Int, error) {
+	if len(accounts) != len(ids) {
+		return nil, fmt.Errorf("accounts and ids length mismatch: %d != %d", len(accounts), len(ids))
+	}
+	return callOne[[]*big.Int](context.Background(), t.Contract, "balanceOfBatch", accounts, ids)
+}
+
+// URI 查询 id 的元数据地址
+func (t *ERC1155) URI(id *big.Int) (string, error) {
+	return callOne[string](context.Background(), t.Contract, "uri", id)
+}
+
+// IsApprovedForAll 查询 operator 是否被授权管理 account 的全部代币
+func (t *ERC1155) IsApprovedForAll(account, operator common.Address) (bool, error) {
+	return callOne[bool](context.Background(), t.Contract, "isApprovedForAll", account, operator)
+}
+
+// SetApprovalForAll 授权或取消授权 operator 管理本钱包的全部代币
+func (t *ERC1155) SetApprovalForAll(operator common.Address, approved bool, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("setApprovalForAll", opts, operator, approved)
+}
+
+// SafeTransferFrom 从 from 向 to 转移 amount 数量的 id 代币
+func (t *ERC1155) SafeTransferFrom(from, to common.Address, id, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	if data == nil {
+		data = []byte{}
+	}
+	return t.ExecMethod("safeTransferFrom", opts, from, to, id, amount, data)
+}
+
+// SafeBatchTransferFrom 批量转移代币，ids 与 amounts 按下标一一对应
+func (t *ERC1155) SafeBatchTransferFrom(from, to common.Address, ids, amounts []*big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	if len(ids) != len(amounts) {
+		return "", fmt.Errorf("ids and amounts length mismatch: %d != %d", len(ids), len(amounts))
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return t.ExecMethod("safeBatchTransferFrom", opts, from, to, ids, amounts, data)
+}
+
+// DecodeTransfer 解码 TransferSingle 或 TransferBatch 事件
+func (t *ERC1155) DecodeTransfer(topics []common.Hash, data []byte) (*ERC1155Transfer, error) {
+	name, values, err := t.DecodeEvent(topics, data)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &ERC1155Transfer{}
+	transfer.Operator, _ = values["operator"].(common.Address)
+	transfer.From, _ = values["from"].(common.Address)
+	transfer.To, _ = values["to"].(common.Address)
+	switch name {
+	case "TransferSingle":
+		id, _ := values["id"].(*big.Int)
+		value, _ := values["value"].(*big.Int)
+		transfer.IDs = []*big.Int{id}
+		transfer.Values = []*big.Int{value}
+	case "TransferBatch":
+		transfer.IDs, _ = values["ids"].([]*big.Int)
+		transfer.Values, _ = values["values"].([]*big.Int)
+	default:
+		return nil, fmt.Errorf("event %s is not a transfer event", name)
+	}
+	return transfer, nil
+}
+
+// DecodeTransferHex 与 DecodeTransfer 相同，但接受十六进制的 topics 与 data
+func (t *ERC1155) DecodeTransferHex(topicsHex []string, dataHex string) (*ERC1155Transfer, error) {
+	topics := make([]common.Hash, 0, len(topicsHex))
+	for _, topicHex := range topicsHex {
+		topics = append(topics, common.HexToHash(topicHex))
+	}
+	return t.DecodeTransfer(topics, common.FromHex(dataHex))
+}
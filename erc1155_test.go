@@ -0,0 +1,84 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestERC1155(t *testing.T) {
+	m := newMockRPC(t)
+	mockContractCalls(m, ERC1155ABI, map[string][]interface{}{
+		"balanceOf":      {big.NewInt(5)},
+		"balanceOfBatch": {[]*big.Int{big.NewInt(5), big.NewInt(7)}},
+		"uri":            {"ipfs://{id}.json"},
+	})
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+
+	token, err := NewERC1155(common.HexToAddress("0xd0a1e359811322d97991e03f863a0c30c2cf029c"), w)
+	assert.NoError(t, err)
+
+	balance, err := token.BalanceOf(w.Address, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), balance)
+	balances, err := token.BalanceOfBatch([]common.Address{w.Address, w.Address}, []*big.Int{big.NewInt(1), big.NewInt(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(5), big.NewInt(7)}, balances)
+	_, err = token.BalanceOfBatch([]common.Address{w.Address}, nil)
+	assert.Error(t, err)
+	uri, err := token.URI(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "ipfs://{id}.json", uri)
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	_, err = token.SafeBatchTransferFrom(w.Address, to, []*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(3)}, nil, nil)
+	assert.NoError(t, err)
+	method, params, err := token.DecodeData(sent[0].Data())
+	assert.NoError(t, err)
+	assert.Equal(t, "safeBatchTransferFrom", method)
+	assert.Equal(t, []*big.Int{big.NewInt(3)}, params["amounts"])
+}
+
+func TestERC1155DecodeTransfer(t *testing.T) {
+	token, err := NewERC1155(common.Address{}, nil)
+	assert.NoError(t, err)
+
+	operator := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	topics := func(event string) []common.Hash {
+		return []common.Hash{
+			token.ABI.Events[event].ID,
+			common.BytesToHash(operator.Bytes()),
+			{},
+			common.BytesToHash(to.Bytes()),
+		}
+	}
+
+	data, err := token.ABI.Events["TransferSingle"].Inputs.NonIndexed().Pack(big.NewInt(1), big.NewInt(10))
+	assert.NoError(t, err)
+	single, err := token.DecodeTransfer(topics("TransferSingle"), data)
+	assert.NoError(t, err)
+	assert.Equal(t, operator, single.Operator)
+	assert.Equal(t, common.Address{}, single.From)
+	assert.Equal(t, to, single.To)
+	assert.Equal(t, []*big.Int{big.NewInt(1)}, single.IDs)
+	assert.Equal(t, []*big.Int{big.NewInt(10)}, single.Values)
+
+	data, err = token.ABI.Events["TransferBatch"].Inputs.NonIndexed().Pack(
+		[]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	assert.NoError(t, err)
+	batch, err := token.DecodeTransfer(topics("TransferBatch"), data)
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, batch.IDs)
+	assert.Equal(t, []*big.Int{big.NewInt(10), big.NewInt(20)}, batch.Values)
+
+	data, err = token.ABI.Events["ApprovalForAll"].Inputs.NonIndexed().Pack(true)
+	assert.NoError(t, err)
+	_, err = token.DecodeTransfer(topics("ApprovalForAll")[:3], data)
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ERC20ABI 标准 ERC-20 代币合约的 ABI
+const ERC20ABI = `[
+{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+{"constant":false,"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transferFrom","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+{"constant":false,"inputs":[{"name":"spender","type":"address"},{"name":"value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"spender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Approval","type":"event"}
+]`
+
+// ERC20 标准 ERC-20 代币合约
+//
+// 数量类参数与返回值均为代币的最小单位，需要按 Decimals 换算为可读数值，
+// BalanceOfFormatted 与 AllowanceFormatted 返回已换算的十进制字符串。
+type ERC20 struct {
+	*Contract
+}
+
+// NewERC20 创建 ERC-20 代币合约实例
+func NewERC20(address common.Address, wallet *Wallet) (*ERC20, error) {
+	c, err := newContract(ERC20ABI, wallet)
+	if err != nil {
+		return nil, err
+	}
+	c.Address = address
+	return &ERC20{Contract: c}, nil
+}
+
+// Name 代币名称
+func (t *ERC20) Name() (string, error) {
+	return callOne[string](context.Background(), t.Contract, "name")
+}
+
+// Symbol 代币符号
+func (t *ERC20) Symbol() (string, error) {
+	return callOne[string](context.Background(), t.Contract, "symbol")
+}
+
+// Decimals 代币精度
+func (t *ERC20) Decimals() (uint8, error) {
+	return callOne[uint8](context.Background(), t.Contract, "decimals")
+}
+
+// TotalSupply 代币总供应量
+func (t *ERC20) TotalSupply() (*big.Int, error) {
+	return callOne[*big.Int](context.Background(), t.Contract, "totalSupply")
+}
+
+// BalanceOf 查询 owner 持有的代币数量
+func (t *ERC20) BalanceOf(owner common.Address) (*big.Int, error) {
+	return t.balanceOf(context.Background(), owner)
+}
+
+func (t *ERC20) balanceOf(ctx context.Context, owner common.Address) (*big.Int, error) {
+	return callOne[*big.Int](ctx, t.Contract, "balanceOf", owner)
+}
+
+// Allowance 查询 owner 授权给 spender 的代币数量
+func (t *ERC20) Allowance(owner, spender common.Address) (*big.Int, error) {
+	return callOne[*big.Int](context.Background(), t.Contract, "allowance", owner, spender)
+}
+
+// BalanceOfFormatted 查询 owner 持有的代币数量，按 Decimals 换算为十进制字符串，例如 USDC 的 100250000 为 "100.25"
+func (t *ERC20) BalanceOfFormatted(owner common.Address) (string, error) {
+	return t.formatted(context.Background(), "balanceOf", owner)
+}
+
+// AllowanceFormatted 查询 owner 授权给 spender 的代币数量，按 Decimals 换算为十进制字符串
+func (t *ERC20) AllowanceFormatted(owner, spender common.Address) (string, error) {
+	return t.formatted(context.Background(), "allowance", owner, spender)
+}
+
+// formatted 调用返回代币数量的只读方法，并按精度换算为十进制字符串
+func (t *ERC20) formatted(ctx context.Context, methodName string, args ...interface{}) (string, error) {
+	decimals, err := t.decimals(ctx)
+	if err != nil {
+		return "", err
+	}
+	amount, err := callOne[*big.Int](ctx, t.Contract, methodName, args...)
+	if err != nil {
+		return "", err
+	}
+	return FormatUnits(amount, decimals), nil
+}
+
+// decimals 获取代币精度，有钱包时使用 TokenInfo 的缓存
+func (t *ERC20) decimals(ctx context.Context) (uint8, error) {
+	if t.Wallet != nil {
+		return t.Wallet.tokenDecimals(ctx, t)
+	}
+	return callOne[uint8](ctx, t.Contract, "decimals")
+}
+
+// Transfer 向 to 转账 amount 数量的代币
+func (t *ERC20) Transfer(to common.Address, amount *big.Int, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("transfer", opts, to, amount)
+}
+
+// TransferFrom 使用授权额度从 from 向 to 转账
+func (t *ERC20) TransferFrom(from, to common.Address, amount *big.Int, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("transferFrom", opts, from, to, amount)
+}
+
+// Approve 授权 spender 使用 amount 数量的代币
+func (t *ERC20) Approve(spender common.Address, amount *big.Int, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("approve", opts, spender, amount)
+}
+
+// TokenInfo ERC-20 代币的元数据
+type TokenInfo struct {
+	Address     common.Address
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+}
+
+// tokenMetadata 缓存的不可变元数据，key 为 "chainID:address"
+var tokenMetadata sync.Map
+
+// TokenInfo 获取 token 的名称、符号、精度与总供应量
+//
+// 名称、符号与精度不会变化，按链ID与地址缓存在内存中，totalSupply 每次都重新查询。
+// 兼容 MKR 等将 name/symbol 定义为 bytes32 的旧合约。
+func (w *Wallet) TokenInfo(token common.Address) (*TokenInfo, error) {
+	return w.TokenInfoContext(context.Background(), token)
+}
+
+// TokenInfoContext 与 TokenInfo 相同，但查询受 ctx 控制
+func (w *Wallet) TokenInfoContext(ctx context.Context, token common.Address) (*TokenInfo, error) {
+	erc20, err := NewERC20(token, w)
+	if err != nil {
+		return nil, err
+	}
+
+	key := w.ChainID.String() + ":" + token.Hex()
+	var info TokenInfo
+	if cached, ok := tokenMetadata.Load(key); ok {
+		info = cached.(TokenInfo)
+	} else {
+		log.Debug("Fetching token metadata", "token", token.Hex(), "chainID", w.ChainID.String())
+		info.Address = token
+		if info.Name, err = callString(ctx, erc20.Contract, "name"); err != nil {
+			return nil, err
+		}
+		if info.Symbol, err = callString(ctx, erc20.Contract, "symbol"); err != nil {
+			return nil, err
+		}
+		if info.Decimals, err = callOne[uint8](ctx, erc20.Contract, "decimals"); err != nil {
+			return nil, err
+		}
+		tokenMetadata.Store(key, info)
+	}
+
+	if info.TotalSupply, err = callOne[*big.Int](ctx, erc20.Contract, "totalSupply"); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// tokenDecimals 获取代币精度，TokenInfo 已缓存时不再查询
+func (w *Wallet) tokenDecimals(ctx context.Context, token *ERC20) (uint8, error) {
+	if cached, ok := tokenMetadata.Load(w.ChainID.String() + ":" + token.Address.Hex()); ok {
+		return cached.(TokenInfo).Decimals, nil
+	}
+	decimals, err := callOne[uint8](ctx, token.Contract, "decimals")
+	if err != nil {
+		log.Error("Failed to get token decimals", "token", token.Address.Hex(), "error", err)
+	}
+	return decimals, err
+}
+
+// callString 调用返回 string 的只读方法，返回值不是 ABI string 而是 bytes32 时去掉末尾的 0 作为字符串
+func callString(ctx context.Context, c *Contract, methodName string) (string, error) {
+	res, err := c.CallMethodContext(ctx, methodName, "latest")
+	if err != nil {
+		return "", err
+	}
+	out, err := hexutil.Decode(res)
+	if err != nil {
+		return "", err
+	}
+	if values, err := c.ABI.Unpack(methodName, out); err == nil && len(values) == 1 {
+		if s, ok := values[0].(string); ok {
+			return s, nil
+		}
+	}
+	if len(out) == 32 {
+		return string(bytes.TrimRight(out, "\x00")), nil
+	}
+	return "", fmt.Errorf("method %s returned invalid string: %s", methodName, res)
+}
+
+// callOne 调用只返回一个值的只读方法，并将返回值转换为 T
+func callOne[T any](ctx context.Context, c *Contract, methodName string, args ...interface{}) (T, error) {
+	var zero T
+	results, err := c.callUnpack(ctx, methodName, args...)
+	if err != nil {
+		return zero, err
+	}
+	value, ok := results[0].(T)
+	if !ok {
+		return zero, fmt.Errorf("method %s returned %T, expected %T", methodName, results[0], zero)
+	}
+	return value, nil
+}
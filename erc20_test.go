@@ -0,0 +1,137 @@
+package goether
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockContractCalls 按方法名模拟合约的只读调用，返回值使用 ABI 编码
+func mockContractCalls(m *mockRPC, abiStr string, results map[string][]interface{}) {
+	parsed, _ := abi.JSON(strings.NewReader(abiStr))
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		method, err := parsed.MethodById(hexutil.MustDecode(tx.Data)[:4])
+		if err != nil {
+			return nil, err
+		}
+		values, ok := results[method.Name]
+		if !ok {
+			return nil, errors.New("execution reverted")
+		}
+		out, err := method.Outputs.Pack(values...)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Encode(out), nil
+	})
+}
+
+func TestERC20(t *testing.T) {
+	m := newMockRPC(t)
+	mockContractCalls(m, ERC20ABI, map[string][]interface{}{
+		"name":        {"Wrapped Ether"},
+		"symbol":      {"WETH"},
+		"decimals":    {uint8(18)},
+		"totalSupply": {big.NewInt(1000)},
+		"balanceOf":   {big.NewInt(100)},
+		"allowance":   {big.NewInt(5e17)},
+	})
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+
+	token, err := NewERC20(common.HexToAddress("0xd0a1e359811322d97991e03f863a0c30c2cf029c"), w)
+	assert.NoError(t, err)
+
+	name, err := token.Name()
+	assert.NoError(t, err)
+	assert.Equal(t, "Wrapped Ether", name)
+	symbol, err := token.Symbol()
+	assert.NoError(t, err)
+	assert.Equal(t, "WETH", symbol)
+	decimals, err := token.Decimals()
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(18), decimals)
+	supply, err := token.TotalSupply()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1000), supply)
+	allowance, err := token.Allowance(w.Address, w.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5e17), allowance)
+	formatted, err := token.AllowanceFormatted(w.Address, w.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.5", formatted)
+	formatted, err = token.BalanceOfFormatted(w.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0000000000000001", formatted)
+
+	balance, err := w.GetBalance(token.GetAddress())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), balance.Int64())
+
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	_, err = token.Transfer(to, big.NewInt(1), nil)
+	assert.NoError(t, err)
+	method, params, err := token.DecodeData(sent[0].Data())
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer", method)
+	assert.Equal(t, to, params["to"])
+	assert.Equal(t, token.Address, *sent[0].To())
+}
+
+func TestTokenInfo(t *testing.T) {
+	m := newMockRPC(t)
+	mockContractCalls(m, ERC20ABI, map[string][]interface{}{
+		"name":        {"USD Coin"},
+		"symbol":      {"USDC"},
+		"decimals":    {uint8(6)},
+		"totalSupply": {big.NewInt(1000)},
+	})
+	w := newTestWallet(t, m)
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	info, err := w.TokenInfo(token)
+	assert.NoError(t, err)
+	assert.Equal(t, TokenInfo{Address: token, Name: "USD Coin", Symbol: "USDC", Decimals: 6, TotalSupply: big.NewInt(1000)}, *info)
+	calls := m.Calls("eth_call")
+
+	// 元数据命中缓存，只重新查询 totalSupply
+	info, err = w.TokenInfo(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "USDC", info.Symbol)
+	assert.Equal(t, calls+1, m.Calls("eth_call"))
+}
+
+func TestTokenInfoBytes32(t *testing.T) {
+	m := newMockRPC(t)
+	mockContractCalls(m, `[
+{"inputs":[],"name":"name","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"symbol","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`, map[string][]interface{}{
+		"name":        {[32]byte{'M', 'a', 'k', 'e', 'r'}},
+		"symbol":      {[32]byte{'M', 'K', 'R'}},
+		"decimals":    {big.NewInt(18)},
+		"totalSupply": {big.NewInt(1000)},
+	})
+	w := newTestWallet(t, m)
+
+	info, err := w.TokenInfo(common.HexToAddress("0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Maker", info.Name)
+	assert.Equal(t, "MKR", info.Symbol)
+	assert.Equal(t, uint8(18), info.Decimals)
+}
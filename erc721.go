@@ -0,0 +1,102 @@
+package goether
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC721ABI 标准 ERC-721 NFT 合约的 ABI
+//
+// safeTransferFrom 存在重载，带 data 参数的版本在 ABI 中名为 safeTransferFrom0。
+const ERC721ABI = `[
+{"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"getApproved","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"owner","type":"address"},{"name":"operator","type":"address"}],"name":"isApprovedForAll","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"transferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"approve","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"operator","type":"address"},{"name":"approved","type":"bool"}],"name":"setApprovalForAll","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"approved","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Approval","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":true,"name":"operator","type":"address"},{"indexed":false,"name":"approved","type":"bool"}],"name":"ApprovalForAll","type":"event"}
+]`
+
+// ERC721 标准 ERC-721 NFT 合约
+type ERC721 struct {
+	*Contract
+}
+
+// NewERC721 创建 ERC-721 合约实例
+func NewERC721(address common.Address, wallet *Wallet) (*ERC721, error) {
+	c, err := newContract(ERC721ABI, wallet)
+	if err != nil {
+		return nil, err
+	}
+	c.Address = address
+	return &ERC721{Contract: c}, nil
+}
+
+// Name 合集名称
+func (t *ERC721) Name() (string, error) {
+	return callOne[string](context.Background(), t.Contract, "name")
+}
+
+// Symbol 合集符号
+func (t *ERC721) Symbol() (string, error) {
+	return callOne[string](context.Background(), t.Contract, "symbol")
+}
+
+// BalanceOf 查询 owner 持有的 NFT 数量
+func (t *ERC721) BalanceOf(owner common.Address) (*big.Int, error) {
+	return callOne[*big.Int](context.Background(), t.Contract, "balanceOf", owner)
+}
+
+// OwnerOf 查询 tokenID 的持有者
+func (t *ERC721) OwnerOf(tokenID *big.Int) (common.Address, error) {
+	return callOne[common.Address](context.Background(), t.Contract, "ownerOf", tokenID)
+}
+
+// TokenURI 查询 tokenID 的元数据地址
+func (t *ERC721) TokenURI(tokenID *big.Int) (string, error) {
+	return callOne[string](context.Background(), t.Contract, "tokenURI", tokenID)
+}
+
+// GetApproved 查询 tokenID 被授权的地址
+func (t *ERC721) GetApproved(tokenID *big.Int) (common.Address, error) {
+	return callOne[common.Address](context.Background(), t.Contract, "getApproved", tokenID)
+}
+
+// IsApprovedForAll 查询 operator 是否被授权管理 owner 的全部 NFT
+func (t *ERC721) IsApprovedForAll(owner, operator common.Address) (bool, error) {
+	return callOne[bool](context.Background(), t.Contract, "isApprovedForAll", owner, operator)
+}
+
+// SafeTransferFrom 安全转移 tokenID，data 不为空时调用带 data 参数的重载版本
+func (t *ERC721) SafeTransferFrom(from, to common.Address, tokenID *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	if data != nil {
+		return t.ExecMethod("safeTransferFrom0", opts, from, to, tokenID, data)
+	}
+	return t.ExecMethod("safeTransferFrom", opts, from, to, tokenID)
+}
+
+// TransferFrom 转移 tokenID，不检查接收方是否能够处理 NFT
+func (t *ERC721) TransferFrom(from, to common.Address, tokenID *big.Int, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("transferFrom", opts, from, to, tokenID)
+}
+
+// Approve 授权 to 转移 tokenID
+func (t *ERC721) Approve(to common.Address, tokenID *big.Int, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("approve", opts, to, tokenID)
+}
+
+// SetApprovalForAll 授权或取消授权 operator 管理本钱包的全部 NFT
+func (t *ERC721) SetApprovalForAll(operator common.Address, approved bool, opts *TxOpts) (txHash string, err error) {
+	return t.ExecMethod("setApprovalForAll", opts, operator, approved)
+}
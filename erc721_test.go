@@ -0,0 +1,47 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestERC721(t *testing.T) {
+	owner := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	m := newMockRPC(t)
+	mockContractCalls(m, ERC721ABI, map[string][]interface{}{
+		"ownerOf":   {owner},
+		"balanceOf": {big.NewInt(2)},
+		"tokenURI":  {"ipfs://token/1"},
+	})
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+
+	nft, err := NewERC721(common.HexToAddress("0xd0a1e359811322d97991e03f863a0c30c2cf029c"), w)
+	assert.NoError(t, err)
+
+	got, err := nft.OwnerOf(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, owner, got)
+	balance, err := nft.BalanceOf(owner)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), balance)
+	uri, err := nft.TokenURI(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "ipfs://token/1", uri)
+
+	_, err = nft.SafeTransferFrom(w.Address, owner, big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	_, err = nft.SafeTransferFrom(w.Address, owner, big.NewInt(1), []byte{1}, nil)
+	assert.NoError(t, err)
+	_, err = nft.SetApprovalForAll(owner, true, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "42842e0e", common.Bytes2Hex(sent[0].Data()[:4]))
+	assert.Equal(t, "b88d4fde", common.Bytes2Hex(sent[1].Data()[:4]))
+	assert.Equal(t, "a22cb465", common.Bytes2Hex(sent[2].Data()[:4]))
+}
@@ -0,0 +1,224 @@
+package goether
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// ErrorCode 稳定的错误码，不随错误信息的语言变化，可用于程序化处理
+type ErrorCode string
+
+const (
+	CodeUnknown           ErrorCode = "UNKNOWN"
+	CodeWalletNil         ErrorCode = "WALLET_NIL"
+	CodeSignerNil         ErrorCode = "SIGNER_NIL"
+	CodeInvalidChainID    ErrorCode = "INVALID_CHAIN_ID"
+	CodeInvalidAddress    ErrorCode = "INVALID_ADDRESS"
+	CodeBytecodeEmpty     ErrorCode = "BYTECODE_EMPTY"
+	CodeDataTooShort      ErrorCode = "DATA_TOO_SHORT"
+	CodeNoData            ErrorCode = "NO_DATA"
+	CodeInvalidOutputType ErrorCode = "INVALID_OUTPUT_TYPE"
+	CodeTxOptsIncomplete  ErrorCode = "TX_OPTS_INCOMPLETE"
+	CodeTxNotFound        ErrorCode = "TX_NOT_FOUND"
+	CodeTxReverted        ErrorCode = "TX_REVERTED"
+	CodeExecutionReverted ErrorCode = "EXECUTION_REVERTED"
+	CodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeNonceTooLow       ErrorCode = "NONCE_TOO_LOW"
+	CodeUnderpriced       ErrorCode = "UNDERPRICED"
+	CodeENSNotFound       ErrorCode = "ENS_NOT_FOUND"
+	CodeFeeCapExceeded    ErrorCode = "FEE_CAP_EXCEEDED"
+	CodeRPC               ErrorCode = "RPC_ERROR"
+	CodeInvalidOption     ErrorCode = "INVALID_OPTION"
+	CodeExplorerNil       ErrorCode = "EXPLORER_NIL"
+	CodeSignerClosed      ErrorCode = "SIGNER_CLOSED"
+	CodeKeyExportDisabled ErrorCode = "KEY_EXPORT_DISABLED"
+	CodeSignaturePending  ErrorCode = "SIGNATURE_PENDING"
+	CodeAccountNotFound   ErrorCode = "ACCOUNT_NOT_FOUND"
+	CodeAccountLocked     ErrorCode = "ACCOUNT_LOCKED"
+	CodeABINotFound       ErrorCode = "ABI_NOT_FOUND"
+	CodeSignatureNotFound ErrorCode = "SIGNATURE_NOT_FOUND"
+	CodeABIConflict       ErrorCode = "ABI_CONFLICT"
+)
+
+// Error 带错误码的错误，Message 为英文默认信息，通过 SetErrorMessages 可以替换为其他语言
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	if messages, ok := errorMessages.Load().(map[ErrorCode]string); ok {
+		if msg, ok := messages[e.Code]; ok {
+			return msg
+		}
+	}
+	return e.Message
+}
+
+// errorMessages 当前使用的本地化错误信息
+var errorMessages atomic.Value
+
+// SetErrorMessages 设置错误码对应的本地化信息，未包含的错误码使用英文默认信息，传入 nil 时恢复英文
+//
+//	goether.SetErrorMessages(goether.ChineseErrorMessages)
+func SetErrorMessages(messages map[ErrorCode]string) {
+	copied := make(map[ErrorCode]string, len(messages))
+	for code, msg := range messages {
+		copied[code] = msg
+	}
+	errorMessages.Store(copied)
+}
+
+// ChineseErrorMessages 内置的中文错误信息
+var ChineseErrorMessages = map[ErrorCode]string{
+	CodeWalletNil:         "钱包为空",
+	CodeSignerNil:         "签名器为空",
+	CodeInvalidChainID:    "无效的链ID",
+	CodeInvalidAddress:    "无效的地址",
+	CodeBytecodeEmpty:     "合约字节码为空",
+	CodeDataTooShort:      "数据长度不足",
+	CodeNoData:            "没有返回数据",
+	CodeInvalidOutputType: "output 无效的类型",
+	CodeTxOptsIncomplete:  "未设置基础参数",
+	CodeTxNotFound:        "交易不存在",
+	CodeTxReverted:        "交易执行失败",
+	CodeInsufficientFunds: "余额不足",
+	CodeNonceTooLow:       "nonce 过低",
+	CodeUnderpriced:       "手续费过低",
+	CodeENSNotFound:       "ENS 名称不存在",
+	CodeFeeCapExceeded:    "交易手续费超过上限",
+	CodeInvalidOption:     "不支持的配置项",
+	CodeExplorerNil:       "未配置区块浏览器",
+	CodeSignerClosed:      "签名器私钥已清除",
+	CodeKeyExportDisabled: "私钥导出已禁用",
+	CodeSignaturePending:  "签名尚未完成",
+	CodeAccountNotFound:   "keystore 目录中没有该账户",
+	CodeAccountLocked:     "账户未解锁",
+	CodeABINotFound:       "未找到已验证的合约 ABI",
+	CodeSignatureNotFound: "签名数据库中没有该函数选择器",
+	CodeABIConflict:       "合并的 ABI 中存在签名相同但定义不同的条目",
+}
+
+// ErrorCodeOf 返回 err 链中第一个可识别错误的错误码，无法识别时返回 CodeUnknown
+func ErrorCodeOf(err error) ErrorCode {
+	var codeErr *Error
+	var revertErr *RevertError
+	var contractErr *ContractError
+	var rpcErr *RPCError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &codeErr):
+		return codeErr.Code
+	case errors.As(err, &revertErr), errors.As(err, &contractErr):
+		return CodeExecutionReverted
+	case errors.As(err, &rpcErr):
+		for _, target := range []*Error{ErrInsufficientFunds, ErrNonceTooLow, ErrUnderpriced} {
+			if rpcErr.Is(target) {
+				return target.Code
+			}
+		}
+		return CodeRPC
+	}
+	return CodeUnknown
+}
+
+// 包内返回的哨兵错误，调用方可以使用 errors.Is 判断错误类型，或使用 ErrorCodeOf 取得错误码
+var (
+	// ErrWalletNil 需要钱包的操作没有设置钱包
+	ErrWalletNil = &Error{CodeWalletNil, "wallet is nil"}
+	// ErrSignerNil 创建钱包时签名器为 nil
+	ErrSignerNil = &Error{CodeSignerNil, "signer is nil"}
+	// ErrInvalidChainID 链ID为空或无法解析
+	ErrInvalidChainID = &Error{CodeInvalidChainID, "invalid chain id"}
+	// ErrInvalidAddress 既不是十六进制地址也不是 ENS 名称
+	ErrInvalidAddress = &Error{CodeInvalidAddress, "invalid address"}
+	// ErrBytecodeEmpty 部署合约时字节码为空
+	ErrBytecodeEmpty = &Error{CodeBytecodeEmpty, "bytecode is empty"}
+	// ErrDataTooShort 待解码的数据不足 4 字节选择器
+	ErrDataTooShort = &Error{CodeDataTooShort, "data is too short"}
+	// ErrNoData 合约调用没有返回数据，通常是地址上没有合约
+	ErrNoData = &Error{CodeNoData, "no data returned"}
+	// ErrInvalidOutputType DecodeFromMethod 的 output 既不是十六进制字符串也不是 []byte
+	ErrInvalidOutputType = &Error{CodeInvalidOutputType, "invalid output type, expected hex string or []byte"}
+	// ErrTxOptsIncomplete 计算手续费所需的 gasLimit 与 gas 价格没有设置
+	ErrTxOptsIncomplete = &Error{CodeTxOptsIncomplete, "transaction options are incomplete"}
+	// ErrTxNotFound 节点中找不到交易
+	ErrTxNotFound = &Error{CodeTxNotFound, "transaction not found"}
+	// ErrTxReverted 交易已上链但执行失败
+	ErrTxReverted = &Error{CodeTxReverted, "transaction reverted"}
+
+	// ErrInsufficientFunds 余额不足以支付 value + gas，节点返回的 *RPCError 也可以用它判断
+	ErrInsufficientFunds = &Error{CodeInsufficientFunds, "insufficient funds"}
+	// ErrNonceTooLow 交易的 nonce 已被使用，节点返回的 *RPCError 也可以用它判断
+	ErrNonceTooLow = &Error{CodeNonceTooLow, "nonce too low"}
+	// ErrUnderpriced 手续费过低或不足以替换交易池中的交易，节点返回的 *RPCError 也可以用它判断
+	ErrUnderpriced = &Error{CodeUnderpriced, "transaction underpriced"}
+
+	// ErrENSNotFound 名称未注册或未设置解析地址
+	ErrENSNotFound = &Error{CodeENSNotFound, "ens name not found"}
+	// ErrFeeCapExceeded 交易的手续费超过了钱包的 MaxGasPrice 或 MaxFeePerTx
+	ErrFeeCapExceeded = &Error{CodeFeeCapExceeded, "transaction fee exceeds the configured cap"}
+	// ErrInvalidOption NewWallet 收到了不支持的配置项类型
+	ErrInvalidOption = &Error{CodeInvalidOption, "unsupported wallet option"}
+	// ErrExplorerNil 查询交易历史时钱包没有设置 Explorer
+	ErrExplorerNil = &Error{CodeExplorerNil, "explorer is not configured"}
+	// ErrSignerClosed 签名器已调用 Close 或 Zero，私钥已从内存中清除
+	ErrSignerClosed = &Error{CodeSignerClosed, "signer is closed"}
+	// ErrKeyExportDisabled 签名器已调用 DisableKeyExport，不能导出私钥
+	ErrKeyExportDisabled = &Error{CodeKeyExportDisabled, "private key export is disabled"}
+	// ErrSignaturePending ThresholdSigner 的签名请求尚未完成，MPCSigner 收到后会继续轮询
+	ErrSignaturePending = &Error{CodeSignaturePending, "signature is pending"}
+	// ErrAccountNotFound Accounts 管理的 keystore 目录中没有该地址的文件
+	ErrAccountNotFound = &Error{CodeAccountNotFound, "account not found in keystore directory"}
+	// ErrAccountLocked 账户尚未解锁或已超时重新锁定
+	ErrAccountLocked = &Error{CodeAccountLocked, "account is locked"}
+	// ErrABINotFound 区块浏览器与 Sourcify 中都没有该合约已验证的 ABI
+	ErrABINotFound = &Error{CodeABINotFound, "verified contract abi not found"}
+	// ErrSignatureNotFound 签名数据库中没有该函数选择器对应的签名
+	ErrSignatureNotFound = &Error{CodeSignatureNotFound, "function signature not found"}
+	// ErrABIConflict MergeABIs 遇到签名相同但返回值、状态可变性或 indexed 不同的方法或事件
+	ErrABIConflict = &Error{CodeABIConflict, "conflicting abi definitions"}
+)
+
+// RPCError 节点返回的 JSON-RPC 错误
+//
+// 常见的节点错误可以通过 errors.Is 与 ErrInsufficientFunds、ErrNonceTooLow、ErrUnderpriced 比较。
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Data 错误中的 data 字段，回滚时为十六进制编码的回滚数据
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("Error %d (%s)", e.Code, e.Message)
+}
+
+// Is 根据节点的错误信息匹配哨兵错误
+func (e *RPCError) Is(target error) bool {
+	msg := strings.ToLower(e.Message)
+	switch target {
+	case ErrInsufficientFunds:
+		return strings.Contains(msg, "insufficient funds")
+	case ErrNonceTooLow:
+		return strings.Contains(msg, "nonce too low")
+	case ErrUnderpriced:
+		return strings.Contains(msg, "underpriced")
+	}
+	return false
+}
+
+// asRPCError 将 ethrpc 返回的错误转换为 *RPCError，其他错误原样返回
+func asRPCError(err error) error {
+	var ethErr ethrpc.EthError
+	if errors.As(err, &ethErr) {
+		return &RPCError{Code: ethErr.Code, Message: ethErr.Message}
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package goether
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPCErrorIs(t *testing.T) {
+	err := &RPCError{Code: -32000, Message: "insufficient funds for gas * price + value"}
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.NotErrorIs(t, err, ErrNonceTooLow)
+	assert.ErrorIs(t, &RPCError{Code: -32000, Message: "nonce too low"}, ErrNonceTooLow)
+	assert.ErrorIs(t, &RPCError{Code: -32000, Message: "replacement transaction underpriced"}, ErrUnderpriced)
+}
+
+func TestSentinelErrors(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	m.On("eth_sendRawTransaction", func([]json.RawMessage) (any, error) {
+		return nil, errors.New("insufficient funds for gas * price + value")
+	})
+	w := newTestWallet(t, m)
+
+	_, err := w.SendTx(common.HexToAddress("0x0000000000000000000000000000000000000001"), big.NewInt(1), nil, nil)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32000, rpcErr.Code)
+
+	_, err = NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", m.URL, "not-a-number")
+	assert.ErrorIs(t, err, ErrInvalidChainID)
+
+	_, err = NewWalletWithSigner(nil, m.URL)
+	assert.ErrorIs(t, err, ErrSignerNil)
+
+	c, err := NewContract(common.Address{}, ERC20ABI, "", nil)
+	assert.NoError(t, err)
+	_, err = c.ExecMethod("transfer", nil, common.Address{}, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrWalletNil)
+	// 没有 rpc 时只能编码与解码，发起调用时返回 ErrWalletNil
+	_, err = c.CallMethod("decimals", "latest")
+	assert.ErrorIs(t, err, ErrWalletNil)
+	_, _, err = c.DecodeData([]byte{0x01})
+	assert.ErrorIs(t, err, ErrDataTooShort)
+}
+
+func TestErrorCode(t *testing.T) {
+	t.Cleanup(func() { SetErrorMessages(nil) })
+
+	_, err := (&TxOpts{}).GetOldFee()
+	assert.ErrorIs(t, err, ErrTxOptsIncomplete)
+	assert.Equal(t, CodeTxOptsIncomplete, ErrorCodeOf(err))
+	assert.Equal(t, "transaction options are incomplete", err.Error())
+
+	assert.Equal(t, CodeInvalidChainID, ErrorCodeOf(fmt.Errorf("%w: abc", ErrInvalidChainID)))
+	assert.Equal(t, CodeInsufficientFunds, ErrorCodeOf(&RPCError{Code: -32000, Message: "insufficient funds for transfer"}))
+	assert.Equal(t, CodeRPC, ErrorCodeOf(&RPCError{Code: -32601, Message: "method not found"}))
+	assert.Equal(t, CodeExecutionReverted, ErrorCodeOf(&RevertError{Reason: "paused"}))
+	assert.Equal(t, CodeUnknown, ErrorCodeOf(errors.New("boom")))
+	assert.Equal(t, ErrorCode(""), ErrorCodeOf(nil))
+
+	SetErrorMessages(ChineseErrorMessages)
+	assert.Equal(t, "未设置基础参数", err.Error())
+	assert.Equal(t, "无效的链ID: abc", fmt.Errorf("%w: abc", ErrInvalidChainID).Error())
+	SetErrorMessages(nil)
+	assert.Equal(t, "transaction options are incomplete", err.Error())
+}
@@ -0,0 +1,331 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogPageSize FilterEvents 每次 eth_getLogs 查询的最大区块数，节点提示结果过多或范围过大时自动减半
+var LogPageSize uint64 = 2000
+
+// FilterEvents 查询区块范围内的合约事件并按 ABI 解码，toBlock 为 nil 时查询到最新区块
+//
+// filters 与 WatchEvent 相同。查询按 LogPageSize 自动分页，返回的事件按区块顺序排列，
+// Event.Log 中包含区块高度、区块哈希与交易哈希。
+func (c *Contract) FilterEvents(eventName string, fromBlock, toBlock *big.Int, filters ...[]interface{}) ([]Event, error) {
+	return c.FilterEventsContext(context.Background(), eventName, fromBlock, toBlock, filters...)
+}
+
+// FilterEventsContext 与 FilterEvents 相同，支持通过 ctx 取消
+func (c *Contract) FilterEventsContext(ctx context.Context, eventName string, fromBlock, toBlock *big.Int, filters ...[]interface{}) ([]Event, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	q, err := c.eventQuery(eventName, filters)
+	if err != nil {
+		return nil, err
+	}
+	var from, to uint64
+	if fromBlock != nil {
+		from = fromBlock.Uint64()
+	}
+	if toBlock != nil {
+		to = toBlock.Uint64()
+	} else {
+		latest, err := callContext(ctx, c.Wallet.rpc(ctx).EthBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		to = uint64(latest)
+	}
+
+	logs, err := c.Wallet.filterLogRange(ctx, q, from, to)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(logs))
+	for i := range logs {
+		l := &logs[i]
+		name, values, err := c.DecodeEvent(l.Topics, l.Data)
+		if err != nil {
+			log.Warning("Failed to decode filtered event", "event", eventName, "tx", l.TxHash, "error", err)
+			continue
+		}
+		events = append(events, Event{Name: name, Address: l.Address, Values: values, Log: l})
+	}
+	log.Debug("Filtered events", "event", eventName, "from", from, "to", to, "count", len(events))
+	return events, nil
+}
+
+// WatchEvent 监听合约事件，返回按 ABI 解码后的事件通道，通道在 ctx 结束后关闭
+//
+// filters 依次对应事件的 indexed 参数，每个参数可以给出多个候选值，nil 表示不限制。
+// 钱包设置了 Subscriber 时使用 eth_subscribe logs，重连后通过 eth_getLogs 补齐断线期间的事件；
+// 否则（或订阅失败时）跟随 SubscribeNewHeads 的新区块轮询 eth_getLogs。
+// 区块重组导致的事件撤销会以 Log.Removed 为 true 的事件推送（仅 WebSocket 订阅）。
+func (c *Contract) WatchEvent(ctx context.Context, eventName string, filters ...[]interface{}) (<-chan Event, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	w := c.Wallet
+	q, err := c.eventQuery(eventName, filters)
+	if err != nil {
+		return nil, err
+	}
+	start, err := callContext(ctx, w.rpc(ctx).EthBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	watch := &logWatch{w: w, q: q, last: uint64(start)}
+	var logs <-chan types.Log
+	if w.Subscriber != nil {
+		logs, err = subscribe[types.Log](ctx, w.Subscriber, watch.backfill, "logs", toSubscribeFilter(q))
+		if err != nil {
+			log.Warning("Failed to subscribe to logs, falling back to polling", "event", eventName, "error", err)
+			logs = nil
+		}
+	}
+	if logs == nil {
+		if logs, err = watch.poll(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		for l := range logs {
+			if !watch.fresh(l) {
+				continue
+			}
+			name, values, err := c.DecodeEvent(l.Topics, l.Data)
+			if err != nil {
+				log.Warning("Failed to decode watched event", "event", eventName, "tx", l.TxHash, "error", err)
+				continue
+			}
+			select {
+			case out <- Event{Name: name, Address: l.Address, Values: values, Log: &l}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// TopicRule 将某个 indexed 参数的候选值转换为 WatchEvent 与 FilterEvents 的过滤条件，没有候选值时返回 nil（不限制）
+func TopicRule[T any](values ...T) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	rule := make([]interface{}, len(values))
+	for i, v := range values {
+		rule[i] = v
+	}
+	return rule
+}
+
+// eventQuery 按事件名与 indexed 参数构造日志过滤条件
+func (c *Contract) eventQuery(eventName string, filters [][]interface{}) (ethereum.FilterQuery, error) {
+	event, ok := c.ABI.Events[eventName]
+	if !ok {
+		return ethereum.FilterQuery{}, fmt.Errorf("event %q not found in abi", eventName)
+	}
+	topics, err := abi.MakeTopics(filters...)
+	if err != nil {
+		return ethereum.FilterQuery{}, err
+	}
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{c.Address},
+		Topics:    append([][]common.Hash{{event.ID}}, topics...),
+	}, nil
+}
+
+// logWatch 记录已推送到的区块，用于重连补齐与去重
+type logWatch struct {
+	w *Wallet
+	q ethereum.FilterQuery
+
+	mu   sync.Mutex
+	last uint64
+	// seen last 区块中已推送的日志
+	seen map[uint]bool
+}
+
+// fresh 判断日志是否尚未推送过，撤销的日志总是推送
+func (lw *logWatch) fresh(l types.Log) bool {
+	if l.Removed {
+		return true
+	}
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	switch {
+	case l.BlockNumber < lw.last:
+		return false
+	case l.BlockNumber > lw.last:
+		lw.last = l.BlockNumber
+		lw.seen = nil
+	}
+	if lw.seen[l.Index] {
+		return false
+	}
+	if lw.seen == nil {
+		lw.seen = make(map[uint]bool)
+	}
+	lw.seen[l.Index] = true
+	return true
+}
+
+// backfill 查询从最后推送的区块到最新区块的日志，重复的日志由 fresh 过滤
+func (lw *logWatch) backfill(ctx context.Context) []types.Log {
+	lw.mu.Lock()
+	q := lw.q
+	q.FromBlock = new(big.Int).SetUint64(lw.last)
+	lw.mu.Unlock()
+
+	logs, err := lw.w.filterLogs(ctx, q)
+	if err != nil {
+		log.Warning("Failed to backfill logs after reconnect", "from", q.FromBlock, "error", err)
+		return nil
+	}
+	log.Debug("Backfilled logs after reconnect", "from", q.FromBlock, "count", len(logs))
+	return logs
+}
+
+// poll 每个新区块到来时查询上次查询之后的日志
+func (lw *logWatch) poll(ctx context.Context) (<-chan types.Log, error) {
+	heads, err := lw.w.SubscribeNewHeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan types.Log)
+	go func() {
+		defer close(out)
+		lw.mu.Lock()
+		next := lw.last + 1
+		lw.mu.Unlock()
+		for head := range heads {
+			number := head.Number.Uint64()
+			if number < next {
+				continue
+			}
+			logs, err := lw.w.filterLogRange(ctx, lw.q, next, number)
+			if err != nil {
+				log.Warning("Failed to poll logs", "from", next, "to", number, "error", err)
+				continue
+			}
+			for _, l := range logs {
+				select {
+				case out <- l:
+				case <-ctx.Done():
+					return
+				}
+			}
+			next = number + 1
+		}
+	}()
+	return out, nil
+}
+
+// filterLogRange 分页查询 [from, to] 区块范围内的日志
+func (w *Wallet) filterLogRange(ctx context.Context, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	size := LogPageSize
+	if size == 0 {
+		size = 1
+	}
+	var logs []types.Log
+	for from <= to {
+		end := to
+		if to-from >= size {
+			end = from + size - 1
+		}
+		q.FromBlock = new(big.Int).SetUint64(from)
+		q.ToBlock = new(big.Int).SetUint64(end)
+		page, err := w.filterLogs(ctx, q)
+		if err != nil {
+			if end > from && isLogLimitError(err) {
+				size = (end - from + 1) / 2
+				log.Debug("Log query too large, shrinking page", "from", from, "to", end, "size", size, "error", err)
+				continue
+			}
+			return nil, err
+		}
+		logs = append(logs, page...)
+		from = end + 1
+	}
+	return logs, nil
+}
+
+// logLimitMessages 各节点与服务商拒绝过大 eth_getLogs 查询时的错误信息，
+// 不包含限流等其他错误，它们交给重试层处理
+var logLimitMessages = []string{
+	"query returned more than",
+	"exceed maximum block range",
+	"exceeds max block range",
+	"exceeds max results",
+	"log response size exceeded",
+	"block range is too wide",
+	"block range is too large",
+	"block range too large",
+	"blocks range",
+	"requested too many blocks",
+}
+
+// isLogLimitError 判断节点是否因为结果过多或区块范围过大拒绝了 eth_getLogs 查询
+func isLogLimitError(err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, s := range logLimitMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLogs 调用 eth_getLogs 查询满足条件的日志
+func (w *Wallet) filterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_getLogs", toFilterArg(q))
+	})
+	if err != nil {
+		return nil, err
+	}
+	var logs []types.Log
+	if err = json.Unmarshal(raw, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// toFilterArg 将 FilterQuery 转换为 eth_getLogs 的参数，未指定的区块范围由节点默认为最新区块
+func toFilterArg(q ethereum.FilterQuery) interface{} {
+	arg := toSubscribeFilter(q).(map[string]interface{})
+	if q.BlockHash != nil {
+		arg["blockHash"] = *q.BlockHash
+		return arg
+	}
+	if q.FromBlock != nil {
+		arg["fromBlock"] = hexutil.EncodeBig(q.FromBlock)
+	}
+	if q.ToBlock != nil {
+		arg["toBlock"] = hexutil.EncodeBig(q.ToBlock)
+	}
+	return arg
+}
@@ -0,0 +1,228 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	eventToken = common.HexToAddress("0x3333333333333333333333333333333333333333")
+	eventFrom  = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	eventTo    = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// transferLog 构造一条 ERC-20 Transfer 日志，金额与区块高度相同
+func transferLog(block uint64, index uint) *types.Log {
+	return &types.Log{
+		Address:     eventToken,
+		Topics:      []common.Hash{transferTopic, common.BytesToHash(eventFrom.Bytes()), common.BytesToHash(eventTo.Bytes())},
+		Data:        common.BigToHash(new(big.Int).SetUint64(block)).Bytes(),
+		BlockNumber: block,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(block)),
+		TxHash:      common.BigToHash(big.NewInt(int64(block*100 + uint64(index)))),
+		Index:       index,
+	}
+}
+
+func TestWatchEventPolling(t *testing.T) {
+	HeadPollInterval = 5 * time.Millisecond
+	defer func() { HeadPollInterval = 2 * time.Second }()
+
+	m := newMockRPC(t)
+	var (
+		mu   sync.Mutex
+		head uint64 = 10
+	)
+	m.On("eth_blockNumber", func([]json.RawMessage) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		head++
+		return hexutil.EncodeUint64(head), nil
+	})
+	m.On("eth_getBlockByNumber", func(params []json.RawMessage) (any, error) {
+		var number hexutil.Uint64
+		json.Unmarshal(params[0], &number)
+		return &types.Header{Number: new(big.Int).SetUint64(uint64(number)), Difficulty: big.NewInt(0)}, nil
+	})
+	m.On("eth_getLogs", func(params []json.RawMessage) (any, error) {
+		var filter struct {
+			FromBlock hexutil.Uint64   `json:"fromBlock"`
+			ToBlock   hexutil.Uint64   `json:"toBlock"`
+			Address   []common.Address `json:"address"`
+			Topics    [][]common.Hash  `json:"topics"`
+		}
+		assert.NoError(t, json.Unmarshal(params[0], &filter))
+		assert.Equal(t, []common.Address{eventToken}, filter.Address)
+		assert.Equal(t, [][]common.Hash{{transferTopic}, nil, {common.BytesToHash(eventTo.Bytes())}}, filter.Topics)
+		logs := []*types.Log{}
+		for n := uint64(filter.FromBlock); n <= uint64(filter.ToBlock); n++ {
+			logs = append(logs, transferLog(n, 0))
+		}
+		return logs, nil
+	})
+	w := newTestWallet(t, m)
+	token, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = token.WatchEvent(ctx, "Missing")
+	assert.Error(t, err)
+	events, err := token.WatchEvent(ctx, "Transfer", nil, []interface{}{eventTo})
+	assert.NoError(t, err)
+
+	// 监听开始时的区块为 11，之后的每个区块各有一条事件
+	for want := int64(12); want < 16; want++ {
+		e := <-events
+		assert.Equal(t, "Transfer", e.Name)
+		assert.Equal(t, eventFrom, e.Values["from"])
+		assert.Equal(t, eventTo, e.Values["to"])
+		assert.Equal(t, want, e.Values["value"].(*big.Int).Int64())
+		assert.Equal(t, uint64(want), e.Log.BlockNumber)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+// testLogsAPI 第一次订阅推送区块 10 的日志，重连后推送区块 12 的日志
+type testLogsAPI struct {
+	subs int32
+}
+
+func (api *testLogsAPI) Logs(ctx context.Context, crit map[string]any) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	block := uint64(10)
+	if atomic.AddInt32(&api.subs, 1) > 1 {
+		block = 12
+	}
+	go notifier.Notify(sub.ID, transferLog(block, 0))
+	return sub, nil
+}
+
+func TestWatchEventBackfill(t *testing.T) {
+	api := &testLogsAPI{}
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", api))
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer srv.Close()
+
+	m := newMockRPC(t)
+	m.Result("eth_blockNumber", "0x9")
+	// 断线期间错过了区块 11 的日志，区块 10 的日志已经推送过
+	m.Result("eth_getLogs", []*types.Log{transferLog(10, 0), transferLog(11, 0), transferLog(11, 1)})
+	w := newTestWallet(t, m)
+	w.Subscriber = NewSubscriber("ws" + strings.TrimPrefix(srv.URL, "http"))
+	w.Subscriber.ReconnectInterval = 10 * time.Millisecond
+	defer w.Subscriber.Close()
+	token, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := token.WatchEvent(ctx, "Transfer")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), (<-events).Log.BlockNumber)
+
+	s := w.Subscriber
+	s.mu.Lock()
+	s.client.Close()
+	s.mu.Unlock()
+
+	var got [][2]uint64
+	for len(got) < 3 {
+		e := <-events
+		got = append(got, [2]uint64{e.Log.BlockNumber, uint64(e.Log.Index)})
+	}
+	assert.Equal(t, [][2]uint64{{11, 0}, {11, 1}, {12, 0}}, got)
+	assert.Equal(t, 1, m.Calls("eth_getLogs"))
+}
+
+func TestFilterEvents(t *testing.T) {
+	LogPageSize = 10
+	defer func() { LogPageSize = 2000 }()
+
+	m := newMockRPC(t)
+	m.Result("eth_blockNumber", "0x19")
+	var ranges [][2]uint64
+	m.On("eth_getLogs", func(params []json.RawMessage) (any, error) {
+		var filter struct {
+			FromBlock hexutil.Uint64 `json:"fromBlock"`
+			ToBlock   hexutil.Uint64 `json:"toBlock"`
+		}
+		json.Unmarshal(params[0], &filter)
+		from, to := uint64(filter.FromBlock), uint64(filter.ToBlock)
+		ranges = append(ranges, [2]uint64{from, to})
+		// 节点一次最多返回 6 个区块的日志
+		if to-from+1 > 6 {
+			return nil, &mockError{Code: -32602, Message: "query returned more than 10000 results"}
+		}
+		logs := []*types.Log{}
+		for n := from; n <= to; n++ {
+			if n%5 == 0 {
+				logs = append(logs, transferLog(n, 0))
+			}
+		}
+		return logs, nil
+	})
+	w := newTestWallet(t, m)
+	token, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+
+	events, err := token.FilterEvents("Transfer", big.NewInt(1), nil)
+	assert.NoError(t, err)
+	var blocks []uint64
+	for _, e := range events {
+		assert.Equal(t, "Transfer", e.Name)
+		assert.Equal(t, e.Log.BlockNumber, e.Values["value"].(*big.Int).Uint64())
+		assert.NotEqual(t, common.Hash{}, e.Log.TxHash)
+		blocks = append(blocks, e.Log.BlockNumber)
+	}
+	assert.Equal(t, []uint64{5, 10, 15, 20, 25}, blocks)
+	assert.Equal(t, [][2]uint64{{1, 10}, {1, 5}, {6, 10}, {11, 15}, {16, 20}, {21, 25}}, ranges)
+
+	// 其他错误直接返回
+	m.On("eth_getLogs", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32000, Message: "internal error"}
+	})
+	_, err = token.FilterEvents("Transfer", big.NewInt(1), big.NewInt(100))
+	assert.Error(t, err)
+}
+
+func TestIsLogLimitError(t *testing.T) {
+	for _, msg := range []string{
+		"query returned more than 10000 results",
+		"exceed maximum block range: 5000",
+		"Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range",
+		"block range is too wide",
+		"eth_getLogs and eth_newFilter are limited to a 10,000 blocks range",
+	} {
+		assert.True(t, isLogLimitError(&RPCError{Code: -32005, Message: msg}), msg)
+	}
+	// 限流等错误不应被当作查询范围过大而缩小分页
+	for _, msg := range []string{
+		"daily request count exceeded, request rate limited",
+		"rate limit exceeded",
+		"too many requests",
+		"invalid block range params",
+		"gas limit reached",
+	} {
+		assert.False(t, isLogLimitError(&RPCError{Code: -32005, Message: msg}), msg)
+	}
+	assert.False(t, isLogLimitError(errors.New("query returned more than 10000 results")))
+}
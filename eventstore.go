@@ -0,0 +1,300 @@
+package goether
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// IndexedEvent Indexer 写入存储的事件
+type IndexedEvent struct {
+	Contract    common.Address
+	Name        string
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	TxIndex     uint
+	LogIndex    uint
+	// Values 按 ABI 解码的事件参数，SQL 存储中以 JSON 保存
+	Values map[string]interface{}
+}
+
+// Checkpoint Indexer 已同步到的区块
+type Checkpoint struct {
+	Block uint64
+	Hash  common.Hash
+}
+
+// EventStore Indexer 的持久化存储
+//
+// 每个 Indexer 按名称区分，事件与检查点需要在同一个事务中写入，进程崩溃后从检查点继续同步。
+type EventStore interface {
+	// Checkpoint 读取检查点，不存在时返回 nil, nil
+	Checkpoint(ctx context.Context, name string) (*Checkpoint, error)
+	// Commit 写入事件并更新检查点，已存在的事件（相同区块与日志序号）会被忽略
+	Commit(ctx context.Context, name string, events []IndexedEvent, cp Checkpoint) error
+	// Rewind 删除 cp.Block 之后的事件并将检查点回退到 cp，用于处理区块重组
+	Rewind(ctx context.Context, name string, cp Checkpoint) error
+}
+
+// SQLDialect SQLEventStore 使用的 SQL 方言
+type SQLDialect int
+
+const (
+	// SQLite 使用 ? 占位符，参数以 TEXT 保存
+	SQLite SQLDialect = iota
+	// Postgres 使用 $n 占位符，参数以 JSONB 保存
+	Postgres
+)
+
+// sqlIdentifier 允许作为表名的标识符，支持 schema.table 形式
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLEventStore 基于 database/sql 的事件存储，支持 SQLite 与 Postgres
+//
+// 不依赖具体驱动，调用方需要自行导入驱动并打开 *sql.DB。表名可以通过 EventsTable 与
+// CheckpointsTable 配置，使用前调用 Migrate 创建表。
+type SQLEventStore struct {
+	DB      *sql.DB
+	Dialect SQLDialect
+	// EventsTable 事件表名，默认 goether_events
+	EventsTable string
+	// CheckpointsTable 检查点表名，默认 goether_checkpoints
+	CheckpointsTable string
+}
+
+// NewSQLEventStore 创建使用默认表名的 SQL 存储
+func NewSQLEventStore(db *sql.DB, dialect SQLDialect) *SQLEventStore {
+	return &SQLEventStore{
+		DB:               db,
+		Dialect:          dialect,
+		EventsTable:      "goether_events",
+		CheckpointsTable: "goether_checkpoints",
+	}
+}
+
+// Migrate 创建事件表、索引与检查点表，表已存在时不做任何修改
+func (s *SQLEventStore) Migrate(ctx context.Context) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	argsType := "TEXT"
+	if s.Dialect == Postgres {
+		argsType = "JSONB"
+	}
+	index := strings.ReplaceAll(s.EventsTable, ".", "_") + "_event_idx"
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS ` + s.EventsTable + ` (
+	indexer TEXT NOT NULL,
+	contract TEXT NOT NULL,
+	event TEXT NOT NULL,
+	block_number BIGINT NOT NULL,
+	block_hash TEXT NOT NULL,
+	tx_hash TEXT NOT NULL,
+	tx_index INTEGER NOT NULL,
+	log_index INTEGER NOT NULL,
+	args ` + argsType + ` NOT NULL,
+	PRIMARY KEY (indexer, block_number, log_index)
+)`,
+		`CREATE INDEX IF NOT EXISTS ` + index + ` ON ` + s.EventsTable + ` (indexer, contract, event, block_number)`,
+		`CREATE TABLE IF NOT EXISTS ` + s.CheckpointsTable + ` (
+	indexer TEXT PRIMARY KEY,
+	block_number BIGINT NOT NULL,
+	block_hash TEXT NOT NULL
+)`,
+	} {
+		if _, err := s.DB.ExecContext(ctx, stmt); err != nil {
+			log.Error("Failed to migrate event store", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Checkpoint 读取检查点表中 name 对应的行
+func (s *SQLEventStore) Checkpoint(ctx context.Context, name string) (*Checkpoint, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	var (
+		block int64
+		hash  string
+	)
+	err := s.DB.QueryRowContext(ctx,
+		s.bind(`SELECT block_number, block_hash FROM `+s.CheckpointsTable+` WHERE indexer = ?`), name,
+	).Scan(&block, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{Block: uint64(block), Hash: common.HexToHash(hash)}, nil
+}
+
+// Commit 在一个事务中插入事件并更新检查点
+func (s *SQLEventStore) Commit(ctx context.Context, name string, events []IndexedEvent, cp Checkpoint) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		insert := s.bind(`INSERT INTO ` + s.EventsTable + ` (indexer, contract, event, block_number, block_hash, tx_hash, tx_index, log_index, args)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`)
+		for _, e := range events {
+			args, err := json.Marshal(e.Values)
+			if err != nil {
+				return fmt.Errorf("encode %s args: %w", e.Name, err)
+			}
+			if _, err = tx.ExecContext(ctx, insert,
+				name, e.Contract.Hex(), e.Name, int64(e.BlockNumber), e.BlockHash.Hex(),
+				e.TxHash.Hex(), int64(e.TxIndex), int64(e.LogIndex), string(args),
+			); err != nil {
+				return err
+			}
+		}
+		return s.saveCheckpoint(ctx, tx, name, cp)
+	})
+}
+
+// Rewind 在一个事务中删除 cp.Block 之后的事件并回退检查点
+func (s *SQLEventStore) Rewind(ctx context.Context, name string, cp Checkpoint) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			s.bind(`DELETE FROM `+s.EventsTable+` WHERE indexer = ? AND block_number > ?`), name, int64(cp.Block),
+		); err != nil {
+			return err
+		}
+		return s.saveCheckpoint(ctx, tx, name, cp)
+	})
+}
+
+func (s *SQLEventStore) saveCheckpoint(ctx context.Context, tx *sql.Tx, name string, cp Checkpoint) error {
+	_, err := tx.ExecContext(ctx, s.bind(`INSERT INTO `+s.CheckpointsTable+` (indexer, block_number, block_hash) VALUES (?, ?, ?)
+ON CONFLICT (indexer) DO UPDATE SET block_number = excluded.block_number, block_hash = excluded.block_hash`),
+		name, int64(cp.Block), cp.Hash.Hex(),
+	)
+	return err
+}
+
+// tx 在事务中执行 fn，fn 返回错误时回滚
+func (s *SQLEventStore) tx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// validate 检查表名，表名会直接拼接到 SQL 中，不能来自不可信的输入
+func (s *SQLEventStore) validate() error {
+	for _, table := range []string{s.EventsTable, s.CheckpointsTable} {
+		if !sqlIdentifier.MatchString(table) {
+			return fmt.Errorf("invalid table name %q", table)
+		}
+	}
+	return nil
+}
+
+// bind 将 ? 占位符转换为方言对应的形式
+func (s *SQLEventStore) bind(query string) string {
+	if s.Dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MemoryEventStore 保存在内存中的事件存储，适用于测试或不需要持久化的场景
+type MemoryEventStore struct {
+	mu          sync.Mutex
+	events      map[string][]IndexedEvent
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryEventStore 创建内存存储
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{
+		events:      make(map[string][]IndexedEvent),
+		checkpoints: make(map[string]Checkpoint),
+	}
+}
+
+// Checkpoint 读取检查点
+func (s *MemoryEventStore) Checkpoint(ctx context.Context, name string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[name]
+	if !ok {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+// Commit 保存事件并更新检查点
+func (s *MemoryEventStore) Commit(ctx context.Context, name string, events []IndexedEvent, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.events[name]
+	for _, e := range events {
+		i := sort.Search(len(stored), func(i int) bool { return !eventBefore(stored[i], e) })
+		if i < len(stored) && stored[i].BlockNumber == e.BlockNumber && stored[i].LogIndex == e.LogIndex {
+			continue
+		}
+		stored = append(stored, IndexedEvent{})
+		copy(stored[i+1:], stored[i:])
+		stored[i] = e
+	}
+	s.events[name] = stored
+	s.checkpoints[name] = cp
+	return nil
+}
+
+// Rewind 删除 cp.Block 之后的事件并回退检查点
+func (s *MemoryEventStore) Rewind(ctx context.Context, name string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.events[name]
+	i := sort.Search(len(stored), func(i int) bool { return stored[i].BlockNumber > cp.Block })
+	s.events[name] = stored[:i]
+	s.checkpoints[name] = cp
+	return nil
+}
+
+// Events 返回 name 已保存的事件，按区块与日志顺序排列
+func (s *MemoryEventStore) Events(name string) []IndexedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]IndexedEvent(nil), s.events[name]...)
+}
+
+func eventBefore(a, b IndexedEvent) bool {
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber < b.BlockNumber
+	}
+	return a.LogIndex < b.LogIndex
+}
@@ -0,0 +1,147 @@
+package goether
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordDriver 记录执行的 SQL 语句，查询总是返回空结果
+type recordDriver struct {
+	mu    sync.Mutex
+	stmts []string
+	args  [][]driver.Value
+}
+
+func (d *recordDriver) record(query string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stmts = append(d.stmts, query)
+	d.args = append(d.args, args)
+}
+
+func (d *recordDriver) Connect(context.Context) (driver.Conn, error) { return recordConn{d}, nil }
+func (d *recordDriver) Driver() driver.Driver                        { return d }
+func (d *recordDriver) Open(string) (driver.Conn, error)             { return recordConn{d}, nil }
+
+type recordConn struct{ d *recordDriver }
+
+func (c recordConn) Prepare(query string) (driver.Stmt, error) { return recordStmt{c.d, query}, nil }
+func (c recordConn) Close() error                              { return nil }
+func (c recordConn) Begin() (driver.Tx, error) {
+	c.d.record("BEGIN", nil)
+	return recordTx{c.d}, nil
+}
+
+type recordTx struct{ d *recordDriver }
+
+func (tx recordTx) Commit() error   { tx.d.record("COMMIT", nil); return nil }
+func (tx recordTx) Rollback() error { tx.d.record("ROLLBACK", nil); return nil }
+
+type recordStmt struct {
+	d     *recordDriver
+	query string
+}
+
+func (s recordStmt) Close() error  { return nil }
+func (s recordStmt) NumInput() int { return -1 }
+func (s recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+func (s recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.record(s.query, args)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"block_number", "block_hash"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestSQLEventStore(t *testing.T) {
+	d := &recordDriver{}
+	db := sql.OpenDB(d)
+	defer db.Close()
+	ctx := context.Background()
+
+	s := NewSQLEventStore(db, Postgres)
+	assert.NoError(t, s.Migrate(ctx))
+	assert.Len(t, d.stmts, 3)
+	assert.Contains(t, d.stmts[0], "CREATE TABLE IF NOT EXISTS goether_events")
+	assert.Contains(t, d.stmts[0], "args JSONB NOT NULL")
+	assert.Contains(t, d.stmts[2], "CREATE TABLE IF NOT EXISTS goether_checkpoints")
+
+	cp, err := s.Checkpoint(ctx, "test")
+	assert.NoError(t, err)
+	assert.Nil(t, cp)
+	assert.Contains(t, d.stmts[3], "WHERE indexer = $1")
+
+	d.stmts, d.args = nil, nil
+	event := IndexedEvent{
+		Contract:    eventToken,
+		Name:        "Transfer",
+		BlockNumber: 10,
+		TxHash:      common.HexToHash("0x01"),
+		LogIndex:    2,
+		Values:      map[string]interface{}{"to": eventTo},
+	}
+	assert.NoError(t, s.Commit(ctx, "test", []IndexedEvent{event}, Checkpoint{Block: 10}))
+	assert.Len(t, d.stmts, 4)
+	assert.Equal(t, "BEGIN", d.stmts[0])
+	assert.Contains(t, d.stmts[1], "VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING")
+	assert.Equal(t, []driver.Value{"test", eventToken.Hex(), "Transfer", int64(10)}, d.args[1][:4])
+	assert.Equal(t, `{"to":"`+strings.ToLower(eventTo.Hex())+`"}`, d.args[1][8])
+	assert.Contains(t, d.stmts[2], "ON CONFLICT (indexer) DO UPDATE")
+	assert.Equal(t, "COMMIT", d.stmts[3])
+
+	d.stmts, d.args = nil, nil
+	assert.NoError(t, s.Rewind(ctx, "test", Checkpoint{Block: 5}))
+	assert.Contains(t, d.stmts[1], "DELETE FROM goether_events WHERE indexer = $1 AND block_number > $2")
+
+	// SQLite 使用 ? 占位符，表名可以配置
+	d.stmts = nil
+	s = NewSQLEventStore(db, SQLite)
+	s.EventsTable = "app.transfers"
+	assert.NoError(t, s.Migrate(ctx))
+	assert.Contains(t, d.stmts[0], "CREATE TABLE IF NOT EXISTS app.transfers")
+	assert.Contains(t, d.stmts[0], "args TEXT NOT NULL")
+	assert.Contains(t, d.stmts[1], "CREATE INDEX IF NOT EXISTS app_transfers_event_idx")
+	_, err = s.Checkpoint(ctx, "test")
+	assert.NoError(t, err)
+	assert.Contains(t, d.stmts[3], "WHERE indexer = ?")
+
+	s.EventsTable = "events; DROP TABLE users"
+	assert.Error(t, s.Migrate(ctx))
+}
+
+func TestMemoryEventStore(t *testing.T) {
+	s := NewMemoryEventStore()
+	ctx := context.Background()
+	cp, err := s.Checkpoint(ctx, "test")
+	assert.NoError(t, err)
+	assert.Nil(t, cp)
+
+	e := func(block uint64, index uint) IndexedEvent {
+		return IndexedEvent{BlockNumber: block, LogIndex: index}
+	}
+	assert.NoError(t, s.Commit(ctx, "test", []IndexedEvent{e(5, 1), e(3, 0), e(5, 0)}, Checkpoint{Block: 5}))
+	// 重复写入的事件被忽略
+	assert.NoError(t, s.Commit(ctx, "test", []IndexedEvent{e(5, 1), e(8, 0)}, Checkpoint{Block: 8}))
+	assert.Equal(t, []IndexedEvent{e(3, 0), e(5, 0), e(5, 1), e(8, 0)}, s.Events("test"))
+
+	assert.NoError(t, s.Rewind(ctx, "test", Checkpoint{Block: 4}))
+	assert.Equal(t, []IndexedEvent{e(3, 0)}, s.Events("test"))
+	cp, err = s.Checkpoint(ctx, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), cp.Block)
+	assert.Empty(t, s.Events("other"))
+}
@@ -0,0 +1,281 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// EtherscanV2URL Etherscan V2 多链 API 地址，需要同时设置 Explorer.ChainID
+const EtherscanV2URL = "https://api.etherscan.io/v2/api"
+
+// latestBlock Etherscan 中表示最新区块的 endblock
+const latestBlock = 99999999
+
+// Explorer Etherscan 兼容的区块浏览器 API 客户端，也适用于 Blockscout 等提供相同接口的浏览器
+//
+// 用于查询内部交易、代币转账等 JSON-RPC 无法直接提供的账户历史。
+// 浏览器单次最多返回 10000 条记录，历史较多时需要缩小区块范围分批查询。
+type Explorer struct {
+	// BaseURL API 地址，例如 https://api.etherscan.io/api 或 https://eth.blockscout.com/api
+	BaseURL string
+	APIKey  string
+	// ChainID 使用 Etherscan V2 多链 API 时的链ID，为 nil 时不发送 chainid 参数
+	ChainID *big.Int
+	// Client 发送请求的 HTTP 客户端，为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// NewExplorer 创建区块浏览器客户端
+func NewExplorer(baseURL, apiKey string) *Explorer {
+	return &Explorer{
+		BaseURL: baseURL,
+		APIKey:  apiKey,
+	}
+}
+
+// ExplorerTx 区块浏览器返回的一条交易记录，普通交易、内部交易与代币转账共用
+type ExplorerTx struct {
+	Hash        common.Hash
+	BlockNumber uint64
+	Timestamp   time.Time
+	From        common.Address
+	To          common.Address
+	// ContractAddress 创建的合约地址，代币转账时为代币合约地址
+	ContractAddress common.Address
+	Value           *big.Int
+	Gas             uint64
+	GasUsed         uint64
+	GasPrice        *big.Int
+	Input           []byte
+	// IsError 交易或内部调用执行失败
+	IsError bool
+
+	// TokenName、TokenSymbol、TokenDecimals 仅代币转账有效
+	TokenName     string
+	TokenSymbol   string
+	TokenDecimals uint8
+}
+
+// History 地址的交易历史
+type History struct {
+	Normal         []ExplorerTx
+	Internal       []ExplorerTx
+	TokenTransfers []ExplorerTx
+}
+
+// explorerTx 浏览器 API 返回的原始记录，所有数值都是十进制字符串
+type explorerTx struct {
+	Hash            string `json:"hash"`
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	ContractAddress string `json:"contractAddress"`
+	Value           string `json:"value"`
+	Gas             string `json:"gas"`
+	GasUsed         string `json:"gasUsed"`
+	GasPrice        string `json:"gasPrice"`
+	Input           string `json:"input"`
+	IsError         string `json:"isError"`
+	TokenName       string `json:"tokenName"`
+	TokenSymbol     string `json:"tokenSymbol"`
+	TokenDecimal    string `json:"tokenDecimal"`
+}
+
+func (t *explorerTx) parse() ExplorerTx {
+	blockNumber, _ := strconv.ParseUint(t.BlockNumber, 10, 64)
+	timestamp, _ := strconv.ParseInt(t.TimeStamp, 10, 64)
+	gas, _ := strconv.ParseUint(t.Gas, 10, 64)
+	gasUsed, _ := strconv.ParseUint(t.GasUsed, 10, 64)
+	decimals, _ := strconv.ParseUint(t.TokenDecimal, 10, 8)
+	input, _ := hexutil.Decode(t.Input)
+	return ExplorerTx{
+		Hash:            common.HexToHash(t.Hash),
+		BlockNumber:     blockNumber,
+		Timestamp:       time.Unix(timestamp, 0),
+		From:            common.HexToAddress(t.From),
+		To:              common.HexToAddress(t.To),
+		ContractAddress: common.HexToAddress(t.ContractAddress),
+		Value:           parseDecimal(t.Value),
+		Gas:             gas,
+		GasUsed:         gasUsed,
+		GasPrice:        parseDecimal(t.GasPrice),
+		Input:           input,
+		IsError:         t.IsError == "1",
+		TokenName:       t.TokenName,
+		TokenSymbol:     t.TokenSymbol,
+		TokenDecimals:   uint8(decimals),
+	}
+}
+
+// parseDecimal 解析十进制字符串，为空或无效时返回 nil
+func parseDecimal(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil
+	}
+	return n
+}
+
+// Transactions 查询 address 在 [fromBlock, toBlock] 区块范围内的普通交易，toBlock 为 0 时查询到最新区块
+func (e *Explorer) Transactions(address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	return e.TransactionsContext(context.Background(), address, fromBlock, toBlock)
+}
+
+// TransactionsContext 与 Transactions 相同，但请求受 ctx 控制
+func (e *Explorer) TransactionsContext(ctx context.Context, address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	return e.accountList(ctx, "txlist", address, fromBlock, toBlock)
+}
+
+// InternalTransactions 查询 address 在区块范围内的内部交易，toBlock 为 0 时查询到最新区块
+func (e *Explorer) InternalTransactions(address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	return e.InternalTransactionsContext(context.Background(), address, fromBlock, toBlock)
+}
+
+// InternalTransactionsContext 与 InternalTransactions 相同，但请求受 ctx 控制
+func (e *Explorer) InternalTransactionsContext(ctx context.Context, address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	return e.accountList(ctx, "txlistinternal", address, fromBlock, toBlock)
+}
+
+// TokenTransfers 查询 address 在区块范围内的 ERC-20 代币转账，toBlock 为 0 时查询到最新区块
+func (e *Explorer) TokenTransfers(address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	return e.TokenTransfersContext(context.Background(), address, fromBlock, toBlock)
+}
+
+// TokenTransfersContext 与 TokenTransfers 相同，但请求受 ctx 控制
+func (e *Explorer) TokenTransfersContext(ctx context.Context, address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	return e.accountList(ctx, "tokentx", address, fromBlock, toBlock)
+}
+
+// ContractABI 查询已验证合约的 ABI JSON，合约未验证时返回错误
+func (e *Explorer) ContractABI(address common.Address) (string, error) {
+	return e.ContractABIContext(context.Background(), address)
+}
+
+// ContractABIContext 与 ContractABI 相同，但请求受 ctx 控制
+func (e *Explorer) ContractABIContext(ctx context.Context, address common.Address) (string, error) {
+	params := url.Values{
+		"module":  {"contract"},
+		"action":  {"getabi"},
+		"address": {address.Hex()},
+	}
+	var abiJSON string
+	if err := e.get(ctx, params, &abiJSON); err != nil {
+		return "", err
+	}
+	if abiJSON == "" {
+		return "", fmt.Errorf("%w: %s", ErrABINotFound, address.Hex())
+	}
+	return abiJSON, nil
+}
+
+// explorerResponse 浏览器 API 的响应，出错时 Result 为错误描述字符串
+type explorerResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// accountList 调用 account 模块的列表接口，按区块升序返回
+func (e *Explorer) accountList(ctx context.Context, action string, address common.Address, fromBlock, toBlock uint64) ([]ExplorerTx, error) {
+	if toBlock == 0 {
+		toBlock = latestBlock
+	}
+	params := url.Values{
+		"module":     {"account"},
+		"action":     {action},
+		"address":    {address.Hex()},
+		"startblock": {strconv.FormatUint(fromBlock, 10)},
+		"endblock":   {strconv.FormatUint(toBlock, 10)},
+		"sort":       {"asc"},
+	}
+	log.Debug("Querying explorer", "action", action, "address", address.Hex(), "fromBlock", fromBlock, "toBlock", toBlock)
+
+	var raw []explorerTx
+	if err := e.get(ctx, params, &raw); err != nil {
+		log.Error("Failed to query explorer", "action", action, "address", address.Hex(), "error", err)
+		return nil, err
+	}
+	txs := make([]ExplorerTx, len(raw))
+	for i := range raw {
+		txs[i] = raw[i].parse()
+	}
+	log.Debug("Explorer query completed", "action", action, "count", len(txs))
+	return txs, nil
+}
+
+// get 发送 GET 请求并将 result 解码到 out，没有记录时 out 保持为空
+func (e *Explorer) get(ctx context.Context, params url.Values, out any) error {
+	if e.APIKey != "" {
+		params.Set("apikey", e.APIKey)
+	}
+	if e.ChainID != nil {
+		params.Set("chainid", e.ChainID.String())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.BaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	client := http.DefaultClient
+	if e.Client != nil {
+		client = e.Client
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("explorer returned %s", resp.Status)
+	}
+
+	var body explorerResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	if body.Status != "1" {
+		// 没有记录时 status 同样为 0，但 result 是空数组
+		var msg string
+		if json.Unmarshal(body.Result, &msg) != nil {
+			return nil
+		}
+		return fmt.Errorf("explorer error: %s: %s", body.Message, msg)
+	}
+	return json.Unmarshal(body.Result, out)
+}
+
+// History 通过 Wallet.Explorer 查询钱包地址在 [fromBlock, toBlock] 区块范围内的普通交易、
+// 内部交易与代币转账，toBlock 为 0 时查询到最新区块
+func (w *Wallet) History(fromBlock, toBlock uint64) (*History, error) {
+	return w.HistoryContext(context.Background(), fromBlock, toBlock)
+}
+
+// HistoryContext 与 History 相同，但请求受 ctx 控制
+func (w *Wallet) HistoryContext(ctx context.Context, fromBlock, toBlock uint64) (*History, error) {
+	if w.Explorer == nil {
+		return nil, ErrExplorerNil
+	}
+	var (
+		history History
+		err     error
+	)
+	if history.Normal, err = w.Explorer.TransactionsContext(ctx, w.Address, fromBlock, toBlock); err != nil {
+		return nil, err
+	}
+	if history.Internal, err = w.Explorer.InternalTransactionsContext(ctx, w.Address, fromBlock, toBlock); err != nil {
+		return nil, err
+	}
+	if history.TokenTransfers, err = w.Explorer.TokenTransfersContext(ctx, w.Address, fromBlock, toBlock); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
@@ -0,0 +1,83 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplorerHistory(t *testing.T) {
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	var queries []map[string]string
+	invalidKey := false
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if invalidKey {
+			json.NewEncoder(rw).Encode(map[string]any{"status": "0", "message": "NOTOK", "result": "Invalid API Key"})
+			return
+		}
+		q := r.URL.Query()
+		queries = append(queries, map[string]string{
+			"action":   q.Get("action"),
+			"endblock": q.Get("endblock"),
+			"apikey":   q.Get("apikey"),
+			"chainid":  q.Get("chainid"),
+		})
+		resp := map[string]any{"status": "1", "message": "OK"}
+		switch q.Get("action") {
+		case "txlist":
+			resp["result"] = []map[string]string{{
+				"hash": "0x01", "blockNumber": "100", "timeStamp": "1700000000",
+				"from": q.Get("address"), "to": "0x0000000000000000000000000000000000000002",
+				"value": "1000000000000000000", "gas": "21000", "gasUsed": "21000",
+				"gasPrice": "1000000000", "input": "0x", "isError": "0",
+			}}
+		case "txlistinternal":
+			resp = map[string]any{"status": "0", "message": "No transactions found", "result": []any{}}
+		case "tokentx":
+			resp["result"] = []map[string]string{{
+				"hash": "0x02", "blockNumber": "101", "timeStamp": "1700000012",
+				"from": "0x0000000000000000000000000000000000000002", "to": q.Get("address"),
+				"contractAddress": token.Hex(), "value": "2500000",
+				"tokenName": "Tether USD", "tokenSymbol": "USDT", "tokenDecimal": "6",
+			}}
+		}
+		json.NewEncoder(rw).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	m := newMockRPC(t)
+	explorer := NewExplorer(server.URL, "KEY")
+	explorer.ChainID = big.NewInt(1)
+	w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", m.URL, WithChainID(big.NewInt(1)), WithExplorer(explorer))
+	assert.NoError(t, err)
+
+	history, err := w.History(0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, history.Normal, 1)
+	assert.Equal(t, uint64(100), history.Normal[0].BlockNumber)
+	assert.Equal(t, w.Address, history.Normal[0].From)
+	assert.Equal(t, big.NewInt(1e18), history.Normal[0].Value)
+	assert.Equal(t, int64(1700000000), history.Normal[0].Timestamp.Unix())
+	assert.False(t, history.Normal[0].IsError)
+	assert.Empty(t, history.Internal)
+	assert.Len(t, history.TokenTransfers, 1)
+	assert.Equal(t, token, history.TokenTransfers[0].ContractAddress)
+	assert.Equal(t, "USDT", history.TokenTransfers[0].TokenSymbol)
+	assert.Equal(t, uint8(6), history.TokenTransfers[0].TokenDecimals)
+
+	assert.Len(t, queries, 3)
+	assert.Equal(t, map[string]string{"action": "txlist", "endblock": "99999999", "apikey": "KEY", "chainid": "1"}, queries[0])
+
+	invalidKey = true
+	_, err = w.History(0, 0)
+	assert.ErrorContains(t, err, "Invalid API Key")
+
+	w.Explorer = nil
+	_, err = w.History(0, 0)
+	assert.ErrorIs(t, err, ErrExplorerNil)
+}
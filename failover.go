@@ -0,0 +1,157 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// FailoverClient 在多个 RPC 节点之间自动故障转移的 HTTP 客户端
+//
+// 通过 ethrpc.WithHttpClient 注入 ethrpc.EthRPC 后，请求会依次尝试 Endpoints，
+// 节点连接失败、超时或返回 429/5xx 时切换到下一个节点。开启 RoundRobin 后只读请求
+// 会在所有节点之间轮询，发送交易等写请求始终从第一个节点开始尝试。
+type FailoverClient struct {
+	Endpoints  []string
+	RoundRobin bool
+	// Client 实际发送请求的 HTTP 客户端，为 nil 时使用 http.DefaultClient
+	Client *http.Client
+	// Timeout 单个节点的请求超时时间，超时后切换到下一个节点；为 0 时使用 DefaultFailoverTimeout，小于 0 时不限制
+	Timeout time.Duration
+
+	next atomic.Uint64
+}
+
+// DefaultFailoverTimeout FailoverClient 单个节点的默认请求超时时间，
+// http.DefaultClient 本身没有超时，不设置时无响应的节点会一直阻塞而不会触发故障转移
+var DefaultFailoverTimeout = 10 * time.Second
+
+// NewFailoverClient 创建故障转移客户端
+func NewFailoverClient(endpoints []string, roundRobin bool) *FailoverClient {
+	return &FailoverClient{
+		Endpoints:  endpoints,
+		RoundRobin: roundRobin,
+	}
+}
+
+// WithFailover 返回一个 ethrpc 配置函数，使客户端在 endpoints 之间故障转移
+//
+//	wallet, err := NewWallet(prvHex, "", WithFailover([]string{rpc1, rpc2}, true))
+func WithFailover(endpoints []string, roundRobin bool) func(rpc *ethrpc.EthRPC) {
+	return ethrpc.WithHttpClient(NewFailoverClient(endpoints, roundRobin))
+}
+
+// writeMethods 不能在节点之间轮询的写请求
+var writeMethods = map[string]bool{
+	"eth_sendRawTransaction": true,
+	"eth_sendTransaction":    true,
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口，url 参数会被忽略
+func (f *FailoverClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return f.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext 与 Post 相同，但请求受 ctx 控制
+func (f *FailoverClient) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	if len(f.Endpoints) == 0 {
+		return nil, errors.New("no rpc endpoints configured")
+	}
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	start := 0
+	if f.RoundRobin && isReadRequest(payload) {
+		start = int(f.next.Add(1)-1) % len(f.Endpoints)
+	}
+
+	timeout := f.Timeout
+	if timeout == 0 {
+		timeout = DefaultFailoverTimeout
+	}
+	var errs []error
+	for i := range f.Endpoints {
+		endpoint := f.Endpoints[(start+i)%len(f.Endpoints)]
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		resp, err := postContext(attemptCtx, client, endpoint, contentType, bytes.NewReader(payload))
+		if err != nil {
+			cancel()
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Warning("RPC endpoint failed, trying next", "endpoint", endpoint, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+			continue
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			resp.Body.Close()
+			cancel()
+			log.Warning("RPC endpoint unavailable, trying next", "endpoint", endpoint, "status", resp.StatusCode)
+			errs = append(errs, fmt.Errorf("%s: %s", endpoint, resp.Status))
+			continue
+		}
+		// 超时同样覆盖读取响应体，关闭响应体时释放
+		resp.Body = cancelOnClose{resp.Body, cancel}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("all rpc endpoints failed: %w", errors.Join(errs...))
+}
+
+// cancelOnClose 关闭响应体时取消对应请求的 context
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// isReadRequest 判断请求体（单个或批量请求）中是否只包含只读方法
+func isReadRequest(payload []byte) bool {
+	type request struct {
+		Method string `json:"method"`
+	}
+
+	var reqs []request
+	if strings.HasPrefix(strings.TrimSpace(string(payload)), "[") {
+		if err := json.Unmarshal(payload, &reqs); err != nil {
+			return false
+		}
+	} else {
+		var req request
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return false
+		}
+		reqs = append(reqs, req)
+	}
+
+	for _, req := range reqs {
+		if writeMethods[req.Method] {
+			return false
+		}
+	}
+	return true
+}
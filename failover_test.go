@@ -0,0 +1,85 @@
+package goether
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailoverClient(t *testing.T) {
+	var downCalls atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		downCalls.Add(1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	up := newMockRPC(t)
+	up.Result("eth_blockNumber", "0x10")
+	up.Result("net_version", "1")
+
+	w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", down.URL, []string{up.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", w.ChainID.String())
+
+	n, err := w.Client.EthBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, 16, n)
+	assert.Equal(t, int32(2), downCalls.Load())
+
+	// 轮询模式下只读请求在节点之间轮流发送
+	second := newMockRPC(t)
+	second.Result("eth_blockNumber", "0x11")
+	client := ethrpc.New("", WithFailover([]string{up.URL, second.URL}, true))
+	for i := 0; i < 4; i++ {
+		_, err = client.EthBlockNumber()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, up.Calls("eth_blockNumber"))
+	assert.Equal(t, 2, second.Calls("eth_blockNumber"))
+
+	// 所有节点都不可用
+	client = ethrpc.New("", WithFailover([]string{down.URL}, false))
+	_, err = client.EthBlockNumber()
+	assert.ErrorContains(t, err, "all rpc endpoints failed")
+}
+
+func TestFailoverTimeout(t *testing.T) {
+	hung := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer hung.Close()
+	up := newMockRPC(t)
+	up.Result("eth_blockNumber", "0x10")
+
+	// 无响应的节点超时后切换到下一个节点
+	f := NewFailoverClient([]string{hung.URL, up.URL}, false)
+	f.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	n, err := ethrpc.New("", ethrpc.WithHttpClient(f)).EthBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, 16, n)
+	assert.Less(t, time.Since(start), time.Second)
+
+	f.Endpoints = f.Endpoints[:1]
+	_, err = ethrpc.New("", ethrpc.WithHttpClient(f)).EthBlockNumber()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestIsReadRequest(t *testing.T) {
+	assert.True(t, isReadRequest([]byte(`{"method":"eth_call"}`)))
+	assert.False(t, isReadRequest([]byte(`{"method":"eth_sendRawTransaction"}`)))
+	assert.True(t, isReadRequest([]byte(`[{"method":"eth_call"},{"method":"eth_getBalance"}]`)))
+	assert.False(t, isReadRequest([]byte(`[{"method":"eth_call"},{"method":"eth_sendRawTransaction"}]`)))
+}
@@ -0,0 +1,163 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// FeeHistoryBlocks 计算建议手续费时参考的历史区块数量
+	FeeHistoryBlocks = 10
+	// FeeHistoryPercentile 每个区块中取小费的百分位
+	FeeHistoryPercentile = 50.0
+)
+
+// FeeSuggestion EIP-1559 交易的建议手续费
+type FeeSuggestion struct {
+	BaseFee   *big.Int // 下一个区块的 baseFee
+	GasTipCap *big.Int // 建议的矿工小费
+	GasFeeCap *big.Int // 建议的最大手续费：2 * baseFee + GasTipCap
+}
+
+type feeHistory struct {
+	OldestBlock   hexutil.Big      `json:"oldestBlock"`
+	BaseFeePerGas []hexutil.Big    `json:"baseFeePerGas"`
+	GasUsedRatio  []float64        `json:"gasUsedRatio"`
+	Reward        [][]*hexutil.Big `json:"reward"`
+}
+
+// SuggestFees 根据 eth_feeHistory 计算 EIP-1559 交易的建议手续费
+func (w *Wallet) SuggestFees() (*FeeSuggestion, error) {
+	return w.SuggestFeesContext(context.Background())
+}
+
+// SuggestFeesContext 与 SuggestFees 相同，但查询受 ctx 控制
+//
+// 小费取最近 FeeHistoryBlocks 个区块中 FeeHistoryPercentile 百分位小费的中位数，
+// 历史数据中没有小费信息时退回到 eth_maxPriorityFeePerGas。
+func (w *Wallet) SuggestFeesContext(ctx context.Context) (*FeeSuggestion, error) {
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_feeHistory",
+			hexutil.EncodeUint64(uint64(FeeHistoryBlocks)), "latest", []float64{FeeHistoryPercentile})
+	})
+	if err != nil {
+		log.Error("Failed to get fee history", "error", err)
+		return nil, err
+	}
+
+	var history feeHistory
+	if err = json.Unmarshal(raw, &history); err != nil {
+		log.Error("Failed to decode fee history", "error", err)
+		return nil, err
+	}
+	if len(history.BaseFeePerGas) == 0 {
+		return nil, errors.New("fee history has no base fee, EIP-1559 may not be supported")
+	}
+	// baseFeePerGas 的最后一项是下一个区块的 baseFee
+	baseFee := history.BaseFeePerGas[len(history.BaseFeePerGas)-1].ToInt()
+
+	var rewards []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			rewards = append(rewards, reward[0].ToInt())
+		}
+	}
+
+	var tip *big.Int
+	if len(rewards) > 0 {
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		tip = new(big.Int).Set(rewards[len(rewards)/2])
+	} else {
+		tip, err = callContext(ctx, func() (*big.Int, error) {
+			return w.rpc(ctx).SuggestGasTipCap(ctx)
+		})
+		if err != nil {
+			log.Error("Failed to get max priority fee", "error", err)
+			return nil, err
+		}
+	}
+
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+
+	log.Debug("Fee suggestion calculated",
+		"baseFee", baseFee.String(),
+		"gasTipCap", tip.String(),
+		"gasFeeCap", feeCap.String())
+	return &FeeSuggestion{
+		BaseFee:   new(big.Int).Set(baseFee),
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+	}, nil
+}
+
+// fillDynamicFees 补全 opts 中未设置的 GasTipCap 与 GasFeeCap
+//
+// 节点不支持 eth_feeHistory 时退回到旧的行为，未设置的字段使用 GasPrice。
+func (w *Wallet) fillDynamicFees(ctx context.Context, opts *TxOpts) {
+	if opts.GasTipCap != nil && opts.GasFeeCap != nil {
+		return
+	}
+
+	fees, err := w.SuggestFeesContext(ctx)
+	if err != nil {
+		log.Debug("Falling back to gas price for dynamic fees", "error", err)
+		// 只补全未设置的字段，小费不能超过最大手续费
+		switch {
+		case opts.GasTipCap == nil && opts.GasFeeCap == nil:
+			opts.GasTipCap = opts.GasPrice
+			opts.GasFeeCap = opts.GasPrice
+		case opts.GasTipCap == nil:
+			opts.GasTipCap = opts.GasPrice
+			if opts.GasTipCap.Cmp(opts.GasFeeCap) > 0 {
+				opts.GasTipCap = new(big.Int).Set(opts.GasFeeCap)
+			}
+		default:
+			opts.GasFeeCap = opts.GasPrice
+			if opts.GasFeeCap.Cmp(opts.GasTipCap) < 0 {
+				opts.GasFeeCap = new(big.Int).Set(opts.GasTipCap)
+			}
+		}
+		return
+	}
+
+	switch {
+	case opts.GasTipCap == nil && opts.GasFeeCap == nil:
+		opts.GasTipCap = fees.GasTipCap
+		opts.GasFeeCap = fees.GasFeeCap
+	case opts.GasTipCap == nil:
+		// 小费不能超过用户指定的最大手续费
+		opts.GasTipCap = fees.GasTipCap
+		if opts.GasTipCap.Cmp(opts.GasFeeCap) > 0 {
+			opts.GasTipCap = new(big.Int).Set(opts.GasFeeCap)
+		}
+	default:
+		feeCap := new(big.Int).Mul(fees.BaseFee, big.NewInt(2))
+		opts.GasFeeCap = feeCap.Add(feeCap, opts.GasTipCap)
+	}
+}
+
+// checkFeeCap 检查交易最坏情况下的手续费是否超过钱包的限制
+func (w *Wallet) checkFeeCap(tx *types.Transaction) error {
+	// 旧版交易的 GasFeeCap 即 GasPrice
+	gasPrice := tx.GasFeeCap()
+	if w.MaxGasPrice != nil && gasPrice.Cmp(w.MaxGasPrice) > 0 {
+		log.Error("Gas price exceeds cap", "gasPrice", gasPrice.String(), "max", w.MaxGasPrice.String())
+		return fmt.Errorf("%w: gas price %s > %s", ErrFeeCapExceeded, gasPrice, w.MaxGasPrice)
+	}
+	if w.MaxFeePerTx != nil {
+		fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(tx.Gas()))
+		if fee.Cmp(w.MaxFeePerTx) > 0 {
+			log.Error("Transaction fee exceeds cap", "fee", fee.String(), "max", w.MaxFeePerTx.String())
+			return fmt.Errorf("%w: fee %s > %s", ErrFeeCapExceeded, fee, w.MaxFeePerTx)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestFees(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("eth_feeHistory", map[string]any{
+		"oldestBlock":   "0x10",
+		"baseFeePerGas": []string{"0x64", "0x64", "0x6e", "0xc8"},
+		"gasUsedRatio":  []float64{0.5, 0.6, 0.9},
+		"reward":        [][]string{{"0x1"}, {"0x5"}, {"0x3"}},
+	})
+	w := newTestWallet(t, m)
+
+	fees, err := w.SuggestFees()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(200), fees.BaseFee)
+	assert.Equal(t, big.NewInt(3), fees.GasTipCap)
+	assert.Equal(t, big.NewInt(403), fees.GasFeeCap)
+
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+
+	_, err = w.SendTx(to, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(3), sent[0].GasTipCap())
+	assert.Equal(t, big.NewInt(403), sent[0].GasFeeCap())
+
+	_, err = w.SendTx(to, nil, nil, &TxOpts{GasTipCap: big.NewInt(10)})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), sent[1].GasTipCap())
+	assert.Equal(t, big.NewInt(410), sent[1].GasFeeCap())
+
+	_, err = w.SendTx(to, nil, nil, &TxOpts{GasFeeCap: big.NewInt(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), sent[2].GasTipCap())
+
+	// 不支持 eth_feeHistory 的节点退回到 gasPrice
+	m.Result("eth_feeHistory", map[string]any{"oldestBlock": "0x0", "baseFeePerGas": []string{}})
+	_, err = w.SendTx(to, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1000000000), sent[3].GasTipCap())
+	assert.Equal(t, big.NewInt(1000000000), sent[3].GasFeeCap())
+
+	// 调用方设置的字段不会被 gasPrice 覆盖
+	_, err = w.SendTx(to, nil, nil, &TxOpts{GasTipCap: big.NewInt(10)})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), sent[4].GasTipCap())
+	assert.Equal(t, big.NewInt(1000000000), sent[4].GasFeeCap())
+
+	_, err = w.SendTx(to, nil, nil, &TxOpts{GasFeeCap: big.NewInt(2000000000)})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1000000000), sent[5].GasTipCap())
+	assert.Equal(t, big.NewInt(2000000000), sent[5].GasFeeCap())
+}
+
+func TestFeeCap(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+
+	w.MaxGasPrice = big.NewInt(500_000_000)
+	_, err := w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.ErrorIs(t, err, ErrFeeCapExceeded)
+	_, err = w.SendLegacyTx(to, big.NewInt(1), nil, nil)
+	assert.ErrorIs(t, err, ErrFeeCapExceeded)
+
+	w.MaxGasPrice = nil
+	w.MaxFeePerTx = big.NewInt(21000*1_000_000_000 - 1)
+	raw, _, err := w.SignTxRaw(to, big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	_, err = w.SendRawTx(raw)
+	assert.ErrorIs(t, err, ErrFeeCapExceeded)
+	assert.Empty(t, sent)
+
+	w.MaxFeePerTx = big.NewInt(21000 * 1_000_000_000)
+	_, err = w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+}
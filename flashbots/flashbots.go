@@ -0,0 +1,382 @@
+// Package flashbots 提供 Flashbots relay 的 JSON-RPC 客户端，交易以 bundle 的形式直接提交给区块构建者，
+// 不经过公共内存池
+package flashbots
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/ethrpc"
+	"github.com/go-enols/goether"
+)
+
+const (
+	// DefaultRelay 以太坊主网的 Flashbots relay
+	DefaultRelay = "https://relay.flashbots.net"
+	// SepoliaRelay Sepolia 测试网的 Flashbots relay
+	SepoliaRelay = "https://relay-sepolia.flashbots.net"
+	// SignatureHeader relay 用于识别请求者的签名请求头
+	SignatureHeader = "X-Flashbots-Signature"
+)
+
+// Sign 计算请求体的 X-Flashbots-Signature：对 keccak256(body) 的十六进制字符串做 EIP-191 签名，
+// 格式为 "地址:签名"
+func Sign(signer goether.AccountSigner, body []byte) (string, error) {
+	sig, err := signer.SignMsg([]byte(hexutil.Encode(crypto.Keccak256(body))))
+	if err != nil {
+		return "", err
+	}
+	return signer.GetAddress().Hex() + ":" + hexutil.Encode(sig), nil
+}
+
+// SigningClient 为每个请求附加 X-Flashbots-Signature 的 HTTP 客户端
+type SigningClient struct {
+	Signer goether.AccountSigner
+	// Client 实际发送请求的客户端，为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口
+func (s *SigningClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := Sign(s.Signer, payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(SignatureHeader, signature)
+
+	client := http.DefaultClient
+	if s.Client != nil {
+		client = s.Client
+	}
+	return client.Do(req)
+}
+
+// Client Flashbots relay 客户端
+//
+// 请求使用 Wallet 的签名器签名，relay 以该地址累计的信誉决定 bundle 的优先级。
+type Client struct {
+	Client *ethrpc.EthRPC
+	Wallet *goether.Wallet
+}
+
+// NewClient 创建 relay 客户端，url 通常为 DefaultRelay，wallet 用于请求签名与签名 bundle 中的交易
+//
+// options 在签名客户端之前应用，其中替换 HTTP 客户端的配置（WithRetry、WithMiddleware 等）会被签名客户端覆盖。
+func NewClient(url string, wallet *goether.Wallet, options ...func(rpc *ethrpc.EthRPC)) *Client {
+	options = append(options, ethrpc.WithHttpClient(&SigningClient{Signer: wallet.Signer}))
+	return &Client{Client: ethrpc.New(url, options...), Wallet: wallet}
+}
+
+// Bundle 在同一区块中按顺序原子执行的一组已签名交易
+type Bundle struct {
+	// Txs RLP 编码的十六进制已签名交易
+	Txs []string
+	// BlockNumber 目标区块号，bundle 只会被打包进该区块
+	BlockNumber uint64
+	// MinTimestamp / MaxTimestamp 允许打包的区块时间范围（Unix 秒），为 0 时不限制
+	MinTimestamp uint64
+	MaxTimestamp uint64
+	// RevertingTxHashes 允许回滚的交易，其他交易回滚时整个 bundle 不会被打包
+	RevertingTxHashes []common.Hash
+	// ReplacementUUID 用于之后替换或取消该 bundle 的标识
+	ReplacementUUID string
+}
+
+// NewBundle 创建以 blockNumber 为目标区块的 bundle
+func NewBundle(blockNumber uint64, txs ...string) *Bundle {
+	return &Bundle{Txs: txs, BlockNumber: blockNumber}
+}
+
+// Add 追加已签名交易，例如 Wallet.SignTxRaw 的结果
+func (b *Bundle) Add(txs ...string) {
+	b.Txs = append(b.Txs, txs...)
+}
+
+// AddTx 编码并追加已签名交易
+func (b *Bundle) AddTx(tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	b.Txs = append(b.Txs, hexutil.Encode(raw))
+	return nil
+}
+
+func (b *Bundle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Txs               []string      `json:"txs"`
+		BlockNumber       string        `json:"blockNumber"`
+		MinTimestamp      uint64        `json:"minTimestamp,omitempty"`
+		MaxTimestamp      uint64        `json:"maxTimestamp,omitempty"`
+		RevertingTxHashes []common.Hash `json:"revertingTxHashes,omitempty"`
+		ReplacementUUID   string        `json:"replacementUuid,omitempty"`
+	}{
+		Txs:               b.Txs,
+		BlockNumber:       hexutil.EncodeUint64(b.BlockNumber),
+		MinTimestamp:      b.MinTimestamp,
+		MaxTimestamp:      b.MaxTimestamp,
+		RevertingTxHashes: b.RevertingTxHashes,
+		ReplacementUUID:   b.ReplacementUUID,
+	})
+}
+
+// SignTx 使用 Wallet 构建并签名交易后追加到 bundle，返回交易哈希
+//
+// bundle 中同一发送方的多笔交易需要在 opts 中显式设置递增的 Nonce。
+func (c *Client) SignTx(ctx context.Context, bundle *Bundle, to common.Address, amount *big.Int, data []byte, opts *goether.TxOpts) (txHash string, err error) {
+	raw, txHash, err := c.Wallet.SignTxRawContext(ctx, to, amount, data, opts)
+	if err != nil {
+		return "", err
+	}
+	bundle.Add(raw)
+	return txHash, nil
+}
+
+// SendBundle 通过 eth_sendBundle 提交 bundle，返回 bundleHash
+func (c *Client) SendBundle(bundle *Bundle) (common.Hash, error) {
+	return c.SendBundleContext(context.Background(), bundle)
+}
+
+// SendBundleContext 与 SendBundle 相同，但请求受 ctx 控制
+func (c *Client) SendBundleContext(ctx context.Context, bundle *Bundle) (common.Hash, error) {
+	if len(bundle.Txs) == 0 {
+		return common.Hash{}, errors.New("bundle has no transactions")
+	}
+	var resp struct {
+		BundleHash common.Hash `json:"bundleHash"`
+	}
+	err := call(ctx, c.Client, &resp, "eth_sendBundle", bundle)
+	return resp.BundleHash, err
+}
+
+// CallResult eth_callBundle 中单笔交易的模拟结果，金额单位均为 wei
+type CallResult struct {
+	TxHash       common.Hash
+	From         common.Address
+	To           common.Address
+	GasUsed      uint64
+	GasPrice     *big.Int
+	GasFees      *big.Int
+	CoinbaseDiff *big.Int
+	// EthSentToCoinbase 交易直接转给区块构建者的金额，不含 gas 费
+	EthSentToCoinbase *big.Int
+	// Value 交易执行的返回数据
+	Value []byte
+	// Error 交易回滚时的错误信息，Revert 为回滚数据
+	Error  string
+	Revert []byte
+}
+
+// Err 交易回滚时返回解码后的回滚原因，成功时返回 nil
+func (r *CallResult) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	if len(r.Revert) > 0 {
+		return goether.DecodeRevert(r.Revert)
+	}
+	return errors.New(r.Error)
+}
+
+// CallBundleResult eth_callBundle 的模拟结果
+type CallBundleResult struct {
+	BundleHash        common.Hash
+	BundleGasPrice    *big.Int
+	CoinbaseDiff      *big.Int
+	EthSentToCoinbase *big.Int
+	GasFees           *big.Int
+	StateBlockNumber  uint64
+	TotalGasUsed      uint64
+	Results           []*CallResult
+}
+
+// Err 返回第一笔回滚交易的错误，全部成功时返回 nil
+func (r *CallBundleResult) Err() error {
+	for i, result := range r.Results {
+		if err := result.Err(); err != nil {
+			return fmt.Errorf("bundle tx %d (%s) reverted: %w", i, result.TxHash.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// CallBundle 通过 eth_callBundle 在 stateBlock（例如 "latest"）的状态上模拟 bundle 在 bundle.BlockNumber 中的执行
+func (c *Client) CallBundle(bundle *Bundle, stateBlock string) (*CallBundleResult, error) {
+	return c.CallBundleContext(context.Background(), bundle, stateBlock)
+}
+
+// CallBundleContext 与 CallBundle 相同，但请求受 ctx 控制
+func (c *Client) CallBundleContext(ctx context.Context, bundle *Bundle, stateBlock string) (*CallBundleResult, error) {
+	if len(bundle.Txs) == 0 {
+		return nil, errors.New("bundle has no transactions")
+	}
+	if stateBlock == "" {
+		stateBlock = "latest"
+	}
+	params := map[string]any{
+		"txs":              bundle.Txs,
+		"blockNumber":      hexutil.EncodeUint64(bundle.BlockNumber),
+		"stateBlockNumber": stateBlock,
+	}
+	if bundle.MinTimestamp > 0 {
+		params["timestamp"] = bundle.MinTimestamp
+	}
+	var dec struct {
+		BundleHash        common.Hash     `json:"bundleHash"`
+		BundleGasPrice    decimal         `json:"bundleGasPrice"`
+		CoinbaseDiff      decimal         `json:"coinbaseDiff"`
+		EthSentToCoinbase decimal         `json:"ethSentToCoinbase"`
+		GasFees           decimal         `json:"gasFees"`
+		StateBlockNumber  uint64          `json:"stateBlockNumber"`
+		TotalGasUsed      uint64          `json:"totalGasUsed"`
+		Results           []callResultDec `json:"results"`
+	}
+	if err := call(ctx, c.Client, &dec, "eth_callBundle", params); err != nil {
+		return nil, err
+	}
+	result := &CallBundleResult{
+		BundleHash:        dec.BundleHash,
+		BundleGasPrice:    dec.BundleGasPrice.Int,
+		CoinbaseDiff:      dec.CoinbaseDiff.Int,
+		EthSentToCoinbase: dec.EthSentToCoinbase.Int,
+		GasFees:           dec.GasFees.Int,
+		StateBlockNumber:  dec.StateBlockNumber,
+		TotalGasUsed:      dec.TotalGasUsed,
+	}
+	for _, r := range dec.Results {
+		result.Results = append(result.Results, &CallResult{
+			TxHash:            r.TxHash,
+			From:              r.FromAddress,
+			To:                r.ToAddress,
+			GasUsed:           r.GasUsed,
+			GasPrice:          r.GasPrice.Int,
+			GasFees:           r.GasFees.Int,
+			CoinbaseDiff:      r.CoinbaseDiff.Int,
+			EthSentToCoinbase: r.EthSentToCoinbase.Int,
+			Value:             r.Value,
+			Error:             r.Error,
+			Revert:            r.Revert,
+		})
+	}
+	return result, nil
+}
+
+type callResultDec struct {
+	TxHash            common.Hash    `json:"txHash"`
+	FromAddress       common.Address `json:"fromAddress"`
+	ToAddress         common.Address `json:"toAddress"`
+	GasUsed           uint64         `json:"gasUsed"`
+	GasPrice          decimal        `json:"gasPrice"`
+	GasFees           decimal        `json:"gasFees"`
+	CoinbaseDiff      decimal        `json:"coinbaseDiff"`
+	EthSentToCoinbase decimal        `json:"ethSentToCoinbase"`
+	Value             hexutil.Bytes  `json:"value"`
+	Error             string         `json:"error"`
+	Revert            hexutil.Bytes  `json:"revert"`
+}
+
+// BuilderTimestamp 区块构建者处理 bundle 的时间
+type BuilderTimestamp struct {
+	Pubkey    string    `json:"pubkey"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BundleStats flashbots_getBundleStatsV2 的结果
+type BundleStats struct {
+	IsHighPriority         bool               `json:"isHighPriority"`
+	IsSimulated            bool               `json:"isSimulated"`
+	SimulatedAt            time.Time          `json:"simulatedAt"`
+	ReceivedAt             time.Time          `json:"receivedAt"`
+	ConsideredByBuildersAt []BuilderTimestamp `json:"consideredByBuildersAt"`
+	SealedByBuildersAt     []BuilderTimestamp `json:"sealedByBuildersAt"`
+}
+
+// GetBundleStats 查询以 blockNumber 为目标区块提交的 bundle 的处理状态
+func (c *Client) GetBundleStats(bundleHash common.Hash, blockNumber uint64) (*BundleStats, error) {
+	return c.GetBundleStatsContext(context.Background(), bundleHash, blockNumber)
+}
+
+// GetBundleStatsContext 与 GetBundleStats 相同，但请求受 ctx 控制
+func (c *Client) GetBundleStatsContext(ctx context.Context, bundleHash common.Hash, blockNumber uint64) (*BundleStats, error) {
+	var stats BundleStats
+	err := call(ctx, c.Client, &stats, "flashbots_getBundleStatsV2", map[string]any{
+		"bundleHash":  bundleHash,
+		"blockNumber": hexutil.EncodeUint64(blockNumber),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// decimal relay 返回的金额，兼容十进制字符串、十六进制字符串与 JSON 数字
+type decimal struct {
+	*big.Int
+}
+
+func (d *decimal) UnmarshalJSON(input []byte) error {
+	s := strings.Trim(string(input), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", s)
+	}
+	d.Int = n
+	return nil
+}
+
+// call 在 ctx 的约束下发送请求，relay 返回的错误转换为 *goether.RPCError
+func call(ctx context.Context, client *ethrpc.EthRPC, out any, method string, params ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if params == nil {
+		params = []any{}
+	}
+	type result struct {
+		raw json.RawMessage
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		raw, err := client.Call(method, params...)
+		ch <- result{raw, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-ch:
+		var ethErr ethrpc.EthError
+		if errors.As(r.err, &ethErr) {
+			return &goether.RPCError{Code: ethErr.Code, Message: ethErr.Message}
+		}
+		if r.err != nil {
+			return r.err
+		}
+		return json.Unmarshal(r.raw, out)
+	}
+}
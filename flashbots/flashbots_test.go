@@ -0,0 +1,188 @@
+package flashbots
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKey = "8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632"
+
+// newMockRelay 启动一个校验 X-Flashbots-Signature 并按方法名分发的 relay，返回连接到该 relay 的客户端
+func newMockRelay(t *testing.T, handlers map[string]func(params []json.RawMessage) (any, error)) *Client {
+	w, err := goether.NewOfflineWallet(testKey, big.NewInt(1))
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		signer, err := recoverHeader(r.Header.Get(SignatureHeader), body)
+		assert.NoError(t, err)
+		assert.Equal(t, w.Address, signer)
+
+		var req struct {
+			ID     int               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		h, ok := handlers[req.Method]
+		if !ok {
+			resp["error"] = &goether.RPCError{Code: -32601, Message: "method not found"}
+		} else if result, err := h(req.Params); err != nil {
+			resp["error"] = err
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(rw).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, w)
+}
+
+// recoverHeader 按 relay 的方式校验签名头并返回签名者
+func recoverHeader(header string, body []byte) (common.Address, error) {
+	address, sig, _ := strings.Cut(header, ":")
+	signer, err := goether.RecoverMsg([]byte(hexutil.Encode(crypto.Keccak256(body))), hexutil.MustDecode(sig))
+	if err == nil && signer != common.HexToAddress(address) {
+		return signer, io.ErrUnexpectedEOF
+	}
+	return signer, err
+}
+
+func TestSign(t *testing.T) {
+	signer, err := goether.NewSigner(testKey)
+	assert.NoError(t, err)
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendBundle","params":[]}`)
+	header, err := Sign(signer, body)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(header, signer.Address.Hex()+":0x"))
+	recovered, err := recoverHeader(header, body)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, recovered)
+}
+
+func TestSendBundle(t *testing.T) {
+	bundleHash := common.HexToHash("0xb0")
+	c := newMockRelay(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_sendBundle": func(params []json.RawMessage) (any, error) {
+			var bundle map[string]any
+			assert.NoError(t, json.Unmarshal(params[0], &bundle))
+			assert.Equal(t, "0x112a880", bundle["blockNumber"])
+			assert.Len(t, bundle["txs"], 2)
+			assert.Len(t, bundle["revertingTxHashes"], 1)
+			assert.NotContains(t, bundle, "minTimestamp")
+			return map[string]string{"bundleHash": bundleHash.Hex()}, nil
+		},
+	})
+
+	bundle := NewBundle(18000000)
+	nonce, gasLimit := 0, 21000
+	opts := &goether.TxOpts{Nonce: &nonce, GasLimit: &gasLimit, GasPrice: big.NewInt(1e9)}
+	txHash, err := c.SignTx(context.Background(), bundle, common.HexToAddress("0xc0"), big.NewInt(1), nil, opts)
+	assert.NoError(t, err)
+	nonce = 1
+	_, err = c.SignTx(context.Background(), bundle, common.HexToAddress("0xc0"), big.NewInt(2), nil, opts)
+	assert.NoError(t, err)
+	bundle.RevertingTxHashes = []common.Hash{common.HexToHash(txHash)}
+
+	hash, err := c.SendBundle(bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, bundleHash, hash)
+
+	_, err = c.SendBundle(NewBundle(1))
+	assert.Error(t, err)
+}
+
+func TestCallBundle(t *testing.T) {
+	reason := hexutil.Encode(append(hexutil.MustDecode("0x08c379a0"), hexutil.MustDecode(
+		"0x0000000000000000000000000000000000000000000000000000000000000020"+
+			"0000000000000000000000000000000000000000000000000000000000000004"+
+			"6f6f707300000000000000000000000000000000000000000000000000000000")...))
+	c := newMockRelay(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_callBundle": func(params []json.RawMessage) (any, error) {
+			var req map[string]any
+			assert.NoError(t, json.Unmarshal(params[0], &req))
+			assert.Equal(t, "0x2", req["blockNumber"])
+			assert.Equal(t, "latest", req["stateBlockNumber"])
+			return json.RawMessage(`{
+				"bundleGasPrice": "476190476193",
+				"bundleHash": "0x73b1e258c7a42fd0230b2fd05529c5d4b6fcb66c227783f8bece8aeacdd1db2e",
+				"coinbaseDiff": "20000000000126000",
+				"ethSentToCoinbase": "20000000000000000",
+				"gasFees": "126000",
+				"results": [
+					{"coinbaseDiff": "10000000000063000", "ethSentToCoinbase": "10000000000000000", "fromAddress": "0x02a727155aef8609c9f7f2179b2a1f560b39f5a0",
+					 "gasFees": "63000", "gasPrice": "476190476193", "gasUsed": 21000, "toAddress": "0x73625f59cadc5009cb458b751b3e7b6b48c06f2c",
+					 "txHash": "0x669b4704a7d993a946cdd6e2f95233f308ce0c4649d2e04944e8299efcaa098a", "value": "0x"},
+					{"coinbaseDiff": "63000", "ethSentToCoinbase": "0", "fromAddress": "0x02a727155aef8609c9f7f2179b2a1f560b39f5a0",
+					 "gasFees": "63000", "gasPrice": "1", "gasUsed": 30000, "toAddress": "0x73625f59cadc5009cb458b751b3e7b6b48c06f2c",
+					 "txHash": "0xa839ee83465657cac01adc1d50d96c1b586ed498120a84a64749c0034b4f19fa", "error": "execution reverted", "revert": "` + reason + `"}
+				],
+				"stateBlockNumber": 1,
+				"totalGasUsed": 51000
+			}`), nil
+		},
+	})
+
+	bundle := NewBundle(2, "0x01", "0x02")
+	result, err := c.CallBundle(bundle, "")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(51000), result.TotalGasUsed)
+	assert.Equal(t, big.NewInt(476190476193), result.BundleGasPrice)
+	assert.Equal(t, "20000000000000000", result.EthSentToCoinbase.String())
+	assert.Len(t, result.Results, 2)
+	assert.Equal(t, uint64(21000), result.Results[0].GasUsed)
+	assert.Equal(t, common.HexToAddress("0x73625f59cadc5009cb458b751b3e7b6b48c06f2c"), result.Results[0].To)
+	assert.NoError(t, result.Results[0].Err())
+
+	var revertErr *goether.RevertError
+	assert.ErrorAs(t, result.Results[1].Err(), &revertErr)
+	assert.Equal(t, "oops", revertErr.Reason)
+	assert.ErrorContains(t, result.Err(), "bundle tx 1")
+
+	_, err = c.CallBundle(NewBundle(2), "latest")
+	assert.Error(t, err)
+}
+
+func TestGetBundleStats(t *testing.T) {
+	bundleHash := common.HexToHash("0xb0")
+	c := newMockRelay(t, map[string]func(params []json.RawMessage) (any, error){
+		"flashbots_getBundleStatsV2": func(params []json.RawMessage) (any, error) {
+			var req struct {
+				BundleHash  common.Hash `json:"bundleHash"`
+				BlockNumber string      `json:"blockNumber"`
+			}
+			assert.NoError(t, json.Unmarshal(params[0], &req))
+			assert.Equal(t, bundleHash, req.BundleHash)
+			assert.Equal(t, "0xa", req.BlockNumber)
+			return json.RawMessage(`{
+				"isHighPriority": true,
+				"isSimulated": true,
+				"simulatedAt": "2022-10-06T21:36:06.317Z",
+				"receivedAt": "2022-10-06T21:36:06.250Z",
+				"consideredByBuildersAt": [{"pubkey": "0x81babeec", "timestamp": "2022-10-06T21:36:06.343Z"}],
+				"sealedByBuildersAt": []
+			}`), nil
+		},
+	})
+
+	stats, err := c.GetBundleStats(bundleHash, 10)
+	assert.NoError(t, err)
+	assert.True(t, stats.IsHighPriority)
+	assert.True(t, stats.IsSimulated)
+	assert.Len(t, stats.ConsideredByBuildersAt, 1)
+	assert.Equal(t, "0x81babeec", stats.ConsideredByBuildersAt[0].Pubkey)
+	assert.Empty(t, stats.SealedByBuildersAt)
+}
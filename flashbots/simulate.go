@@ -0,0 +1,226 @@
+package flashbots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxSimulation bundle 中单笔交易的模拟结果
+type TxSimulation struct {
+	TxHash  common.Hash
+	GasUsed uint64
+	// CoinbasePayment 交易给区块构建者带来的收益，包括优先费与直接转账（wei）
+	CoinbasePayment *big.Int
+	// Reverted 交易是否回滚，Err 为解码后的回滚原因
+	Reverted bool
+	// CanRevert 交易在 Bundle.RevertingTxHashes 中，回滚不影响 bundle 被打包
+	CanRevert bool
+	Err       error
+}
+
+// Simulation bundle 在目标区块中的模拟结果
+type Simulation struct {
+	BlockNumber      uint64
+	StateBlockNumber uint64
+	GasUsed          uint64
+	// CoinbasePayment 整个 bundle 给区块构建者带来的收益（wei）
+	CoinbasePayment *big.Int
+	// GasPrice bundle 的有效 gas 价格，即 CoinbasePayment / GasUsed，构建者按它排序 bundle
+	GasPrice *big.Int
+	Txs      []*TxSimulation
+}
+
+// Success 是否没有不允许回滚的交易回滚，bundle 只有在 Success 时才可能被打包
+func (s *Simulation) Success() bool {
+	return s.Err() == nil
+}
+
+// Err 返回第一笔不允许回滚却回滚了的交易的错误
+func (s *Simulation) Err() error {
+	for i, tx := range s.Txs {
+		if tx.Reverted && !tx.CanRevert {
+			return fmt.Errorf("bundle tx %d (%s) reverted: %w", i, tx.TxHash.Hex(), tx.Err)
+		}
+	}
+	return nil
+}
+
+// Simulate 通过 eth_callBundle 在目标区块的父区块状态上模拟 bundle，返回每笔交易的 gas、coinbase 收益与回滚状态
+func (c *Client) Simulate(bundle *Bundle) (*Simulation, error) {
+	return c.SimulateContext(context.Background(), bundle)
+}
+
+// SimulateContext 与 Simulate 相同，但请求受 ctx 控制
+func (c *Client) SimulateContext(ctx context.Context, bundle *Bundle) (*Simulation, error) {
+	if bundle.BlockNumber == 0 {
+		return nil, errors.New("bundle has no target block")
+	}
+	result, err := c.CallBundleContext(ctx, bundle, hexutil.EncodeUint64(bundle.BlockNumber-1))
+	if err != nil {
+		return nil, err
+	}
+	canRevert := make(map[common.Hash]bool, len(bundle.RevertingTxHashes))
+	for _, hash := range bundle.RevertingTxHashes {
+		canRevert[hash] = true
+	}
+
+	sim := &Simulation{
+		BlockNumber:      bundle.BlockNumber,
+		StateBlockNumber: result.StateBlockNumber,
+		GasUsed:          result.TotalGasUsed,
+		CoinbasePayment:  result.CoinbaseDiff,
+		GasPrice:         result.BundleGasPrice,
+	}
+	for _, r := range result.Results {
+		sim.Txs = append(sim.Txs, &TxSimulation{
+			TxHash:          r.TxHash,
+			GasUsed:         r.GasUsed,
+			CoinbasePayment: r.CoinbaseDiff,
+			Reverted:        r.Error != "",
+			CanRevert:       canRevert[r.TxHash],
+			Err:             r.Err(),
+		})
+	}
+	return sim, nil
+}
+
+// SimOverrides mev_simBundle 的区块参数，零值字段使用目标区块的默认值
+type SimOverrides struct {
+	// ParentBlock 模拟所基于的区块，为 nil 时使用目标区块的父区块
+	ParentBlock *uint64
+	Coinbase    *common.Address
+	Timestamp   uint64
+	GasLimit    uint64
+	BaseFee     *big.Int
+}
+
+// SimBundleResult mev_simBundle 的模拟结果，金额单位均为 wei
+type SimBundleResult struct {
+	Success    bool
+	Error      string
+	StateBlock uint64
+	GasUsed    uint64
+	// MevGasPrice 构建者从 bundle 获得的有效 gas 价格
+	MevGasPrice *big.Int
+	// Profit 构建者从 bundle 获得的收益
+	Profit *big.Int
+	// RefundableValue 可以通过 MEV-Share 返还给用户的收益
+	RefundableValue *big.Int
+	// Logs 每笔交易产生的日志，与 bundle 中的交易一一对应
+	Logs [][]SimLog
+}
+
+// SimLog mev_simBundle 返回的日志，只包含地址、主题与数据
+type SimLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// Err 模拟失败时返回错误，成功时返回 nil
+func (r *SimBundleResult) Err() error {
+	if r.Success {
+		return nil
+	}
+	if r.Error == "" {
+		return errors.New("bundle simulation failed")
+	}
+	return errors.New(r.Error)
+}
+
+// SimBundle 通过 MEV-Share 的 mev_simBundle 模拟 bundle，overrides 可以为 nil
+func (c *Client) SimBundle(bundle *Bundle, overrides *SimOverrides) (*SimBundleResult, error) {
+	return c.SimBundleContext(context.Background(), bundle, overrides)
+}
+
+// SimBundleContext 与 SimBundle 相同，但请求受 ctx 控制
+func (c *Client) SimBundleContext(ctx context.Context, bundle *Bundle, overrides *SimOverrides) (*SimBundleResult, error) {
+	if len(bundle.Txs) == 0 {
+		return nil, errors.New("bundle has no transactions")
+	}
+	canRevert := make(map[common.Hash]bool, len(bundle.RevertingTxHashes))
+	for _, hash := range bundle.RevertingTxHashes {
+		canRevert[hash] = true
+	}
+	body := make([]map[string]any, 0, len(bundle.Txs))
+	for _, raw := range bundle.Txs {
+		tx, err := decodeTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, map[string]any{"tx": raw, "canRevert": canRevert[tx.Hash()]})
+	}
+	params := map[string]any{
+		"version":   "v0.1",
+		"inclusion": map[string]string{"block": hexutil.EncodeUint64(bundle.BlockNumber)},
+		"body":      body,
+	}
+
+	simOptions := map[string]any{}
+	if overrides != nil {
+		if overrides.ParentBlock != nil {
+			simOptions["parentBlock"] = hexutil.EncodeUint64(*overrides.ParentBlock)
+		}
+		if overrides.Coinbase != nil {
+			simOptions["coinbase"] = *overrides.Coinbase
+		}
+		if overrides.Timestamp > 0 {
+			simOptions["timestamp"] = hexutil.EncodeUint64(overrides.Timestamp)
+		}
+		if overrides.GasLimit > 0 {
+			simOptions["gasLimit"] = hexutil.EncodeUint64(overrides.GasLimit)
+		}
+		if overrides.BaseFee != nil {
+			simOptions["baseFee"] = (*hexutil.Big)(overrides.BaseFee)
+		}
+	}
+	simOptions["blockNumber"] = hexutil.EncodeUint64(bundle.BlockNumber)
+
+	var dec struct {
+		Success         bool           `json:"success"`
+		Error           string         `json:"error"`
+		StateBlock      hexutil.Uint64 `json:"stateBlock"`
+		MevGasPrice     decimal        `json:"mevGasPrice"`
+		Profit          decimal        `json:"profit"`
+		RefundableValue decimal        `json:"refundableValue"`
+		GasUsed         hexutil.Uint64 `json:"gasUsed"`
+		Logs            []struct {
+			TxLogs []SimLog `json:"txLogs"`
+		} `json:"logs"`
+	}
+	if err := call(ctx, c.Client, &dec, "mev_simBundle", params, simOptions); err != nil {
+		return nil, err
+	}
+	result := &SimBundleResult{
+		Success:         dec.Success,
+		Error:           dec.Error,
+		StateBlock:      uint64(dec.StateBlock),
+		GasUsed:         uint64(dec.GasUsed),
+		MevGasPrice:     dec.MevGasPrice.Int,
+		Profit:          dec.Profit.Int,
+		RefundableValue: dec.RefundableValue.Int,
+	}
+	for _, logs := range dec.Logs {
+		result.Logs = append(result.Logs, logs.TxLogs)
+	}
+	return result, nil
+}
+
+// decodeTx 解码十六进制的已签名交易
+func decodeTx(raw string) (*types.Transaction, error) {
+	b, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle transaction: %w", err)
+	}
+	tx := new(types.Transaction)
+	if err = tx.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("invalid bundle transaction: %w", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,124 @@
+package flashbots
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate(t *testing.T) {
+	c := newMockRelay(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_callBundle": func(params []json.RawMessage) (any, error) {
+			var req map[string]any
+			assert.NoError(t, json.Unmarshal(params[0], &req))
+			assert.Equal(t, "0x64", req["blockNumber"])
+			assert.Equal(t, "0x63", req["stateBlockNumber"])
+			return json.RawMessage(`{
+				"bundleGasPrice": "2000000000",
+				"coinbaseDiff": "102000000000000",
+				"totalGasUsed": 51000,
+				"stateBlockNumber": 99,
+				"results": [
+					{"txHash": "0x0000000000000000000000000000000000000000000000000000000000000001", "gasUsed": 21000, "coinbaseDiff": "42000000000000"},
+					{"txHash": "0x0000000000000000000000000000000000000000000000000000000000000002", "gasUsed": 30000, "coinbaseDiff": "60000000000000", "error": "execution reverted", "revert": "0x"}
+				]
+			}`), nil
+		},
+	})
+
+	bundle := NewBundle(100, "0x01", "0x02")
+	sim, err := c.Simulate(bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(99), sim.StateBlockNumber)
+	assert.Equal(t, uint64(51000), sim.GasUsed)
+	assert.Equal(t, big.NewInt(102000000000000), sim.CoinbasePayment)
+	assert.Equal(t, big.NewInt(2000000000), sim.GasPrice)
+	assert.Len(t, sim.Txs, 2)
+	assert.Equal(t, uint64(21000), sim.Txs[0].GasUsed)
+	assert.Equal(t, big.NewInt(42000000000000), sim.Txs[0].CoinbasePayment)
+	assert.False(t, sim.Txs[0].Reverted)
+	assert.NoError(t, sim.Txs[0].Err)
+	assert.True(t, sim.Txs[1].Reverted)
+	assert.EqualError(t, sim.Txs[1].Err, "execution reverted")
+	assert.False(t, sim.Success())
+	assert.ErrorContains(t, sim.Err(), "bundle tx 1")
+
+	// 允许回滚的交易不影响结果
+	bundle.RevertingTxHashes = []common.Hash{common.HexToHash("0x02")}
+	sim, err = c.Simulate(bundle)
+	assert.NoError(t, err)
+	assert.True(t, sim.Txs[1].CanRevert)
+	assert.True(t, sim.Success())
+
+	_, err = c.Simulate(NewBundle(0, "0x01"))
+	assert.Error(t, err)
+}
+
+func TestSimBundle(t *testing.T) {
+	var revertible string
+	c := newMockRelay(t, map[string]func(params []json.RawMessage) (any, error){
+		"mev_simBundle": func(params []json.RawMessage) (any, error) {
+			var req struct {
+				Version   string            `json:"version"`
+				Inclusion map[string]string `json:"inclusion"`
+				Body      []struct {
+					Tx        string `json:"tx"`
+					CanRevert bool   `json:"canRevert"`
+				} `json:"body"`
+			}
+			assert.NoError(t, json.Unmarshal(params[0], &req))
+			assert.Equal(t, "v0.1", req.Version)
+			assert.Equal(t, "0x64", req.Inclusion["block"])
+			assert.Len(t, req.Body, 2)
+			assert.False(t, req.Body[0].CanRevert)
+			assert.True(t, req.Body[1].CanRevert)
+			assert.Equal(t, revertible, req.Body[1].Tx)
+
+			var options map[string]string
+			assert.NoError(t, json.Unmarshal(params[1], &options))
+			assert.Equal(t, "0x64", options["blockNumber"])
+			assert.Equal(t, "0x3b9aca00", options["baseFee"])
+			assert.NotContains(t, options, "coinbase")
+			return json.RawMessage(`{
+				"success": true,
+				"stateBlock": "0x63",
+				"mevGasPrice": "0x74c7d",
+				"profit": "0x4bc800904",
+				"refundableValue": "0x4bc800904",
+				"gasUsed": "0xa620",
+				"logs": [{"txLogs": []}, {"txLogs": [{"address": "0x00000000000000000000000000000000000000c0", "topics": ["0x0000000000000000000000000000000000000000000000000000000000000001"], "data": "0x"}]}]
+			}`), nil
+		},
+	})
+
+	bundle := NewBundle(100)
+	nonce, gasLimit := 0, 21000
+	opts := &goether.TxOpts{Nonce: &nonce, GasLimit: &gasLimit, GasPrice: big.NewInt(1e9)}
+	_, err := c.SignTx(context.Background(), bundle, common.HexToAddress("0xc0"), big.NewInt(1), nil, opts)
+	assert.NoError(t, err)
+	nonce = 1
+	txHash, err := c.SignTx(context.Background(), bundle, common.HexToAddress("0xc0"), big.NewInt(2), nil, opts)
+	assert.NoError(t, err)
+	revertible = bundle.Txs[1]
+	bundle.RevertingTxHashes = []common.Hash{common.HexToHash(txHash)}
+
+	result, err := c.SimBundle(bundle, &SimOverrides{BaseFee: big.NewInt(1e9)})
+	assert.NoError(t, err)
+	assert.NoError(t, result.Err())
+	assert.Equal(t, uint64(99), result.StateBlock)
+	assert.Equal(t, uint64(42528), result.GasUsed)
+	assert.Equal(t, big.NewInt(478333), result.MevGasPrice)
+	assert.Equal(t, big.NewInt(0x4bc800904), result.Profit)
+	assert.Len(t, result.Logs, 2)
+	assert.Empty(t, result.Logs[0])
+	assert.Equal(t, common.HexToAddress("0xc0"), result.Logs[1][0].Address)
+
+	assert.EqualError(t, (&SimBundleResult{Error: "nonce too low"}).Err(), "nonce too low")
+	_, err = c.SimBundle(NewBundle(100, "0xzz"), nil)
+	assert.Error(t, err)
+}
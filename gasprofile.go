@@ -0,0 +1,173 @@
+package goether
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasProfile 按内部调用统计的 gas 消耗报告
+type GasProfile struct {
+	// Total 整笔交易消耗的 gas，包含 21000 基础费用与 calldata 费用
+	Total uint64
+	// Entries 按调用顺序深度优先展开的所有调用
+	Entries []GasEntry
+}
+
+// GasEntry 一次内部调用的 gas 消耗
+type GasEntry struct {
+	Depth int
+	Type  string
+	From  common.Address
+	To    common.Address
+	// Method 按传入合约 ABI 解码出的方法名，无法解码时为 4 字节选择器
+	Method string
+	Gas    uint64
+	// GasUsed 调用消耗的 gas，包含所有子调用
+	GasUsed uint64
+	// SelfGas 扣除子调用后调用自身消耗的 gas
+	SelfGas uint64
+	Failed  bool
+	Frame   *CallFrame
+}
+
+// NewGasProfile 根据调用树生成 gas 报告，contracts 用于解码方法名，规则与 GetReceipt 解码日志相同
+func NewGasProfile(frame *CallFrame, contracts ...*Contract) *GasProfile {
+	profile := &GasProfile{Total: frame.GasUsed}
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		self := f.GasUsed
+		for _, call := range f.Calls {
+			if call.GasUsed > self {
+				self = 0
+				break
+			}
+			self -= call.GasUsed
+		}
+		profile.Entries = append(profile.Entries, GasEntry{
+			Depth:   depth,
+			Type:    f.Type,
+			From:    f.From,
+			To:      f.To,
+			Method:  methodName(f, contracts),
+			Gas:     f.Gas,
+			GasUsed: f.GasUsed,
+			SelfGas: self,
+			Failed:  f.Failed(),
+			Frame:   f,
+		})
+		return true
+	})
+	return profile
+}
+
+// Top 返回自身消耗最多的 n 个调用，n <= 0 时返回全部
+func (p *GasProfile) Top(n int) []GasEntry {
+	entries := append([]GasEntry(nil), p.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].SelfGas > entries[j].SelfGas
+	})
+	if n > 0 && n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// ByContract 按被调用地址汇总自身消耗的 gas
+func (p *GasProfile) ByContract() map[common.Address]uint64 {
+	result := make(map[common.Address]uint64)
+	for _, e := range p.Entries {
+		result[e.To] += e.SelfGas
+	}
+	return result
+}
+
+// String 以缩进的调用树输出报告，每行包含总消耗、自身消耗与占整笔交易的比例
+func (p *GasProfile) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "total gas: %d\n", p.Total)
+	for _, e := range p.Entries {
+		share := 0.0
+		if p.Total > 0 {
+			share = float64(e.GasUsed) * 100 / float64(p.Total)
+		}
+		status := ""
+		if e.Failed {
+			status = " [failed: " + e.Frame.Error + "]"
+		}
+		fmt.Fprintf(&b, "%s%s %s.%s gasUsed=%d self=%d (%.2f%%)%s\n",
+			strings.Repeat("  ", e.Depth), e.Type, e.To.Hex(), e.Method, e.GasUsed, e.SelfGas, share, status)
+	}
+	return b.String()
+}
+
+// ProfileTransaction 追踪已打包的交易并生成 gas 报告，需要节点开启 debug 命名空间
+func (w *Wallet) ProfileTransaction(txHash common.Hash, contracts ...*Contract) (*GasProfile, error) {
+	return w.ProfileTransactionContext(context.Background(), txHash, contracts...)
+}
+
+// ProfileTransactionContext 与 ProfileTransaction 相同，但请求受 ctx 控制
+func (w *Wallet) ProfileTransactionContext(ctx context.Context, txHash common.Hash, contracts ...*Contract) (*GasProfile, error) {
+	frame, err := w.TraceTransactionContext(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return NewGasProfile(frame, contracts...), nil
+}
+
+// ProfileCall 在 tag 区块上模拟尚未发送的交易并生成 gas 报告
+func (w *Wallet) ProfileCall(to common.Address, amount *big.Int, data []byte, tag string, contracts ...*Contract) (*GasProfile, error) {
+	return w.ProfileCallContext(context.Background(), to, amount, data, tag, contracts...)
+}
+
+// ProfileCallContext 与 ProfileCall 相同，但请求受 ctx 控制
+func (w *Wallet) ProfileCallContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, tag string, contracts ...*Contract) (*GasProfile, error) {
+	frame, err := w.TraceCallContext(ctx, to, amount, data, tag)
+	if err != nil {
+		return nil, err
+	}
+	return NewGasProfile(frame, contracts...), nil
+}
+
+// ProfileMethod 使用合约 ABI 编码调用并生成 gas 报告，合约自身的 ABI 会用于解码方法名
+func (c *Contract) ProfileMethod(method string, value *big.Int, args ...any) (*GasProfile, error) {
+	return c.ProfileMethodContext(context.Background(), method, value, args...)
+}
+
+// ProfileMethodContext 与 ProfileMethod 相同，但请求受 ctx 控制
+func (c *Contract) ProfileMethodContext(ctx context.Context, method string, value *big.Int, args ...any) (*GasProfile, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	data, err := c.EncodeData(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wallet.ProfileCallContext(ctx, c.Address, value, data, "latest", c)
+}
+
+// methodName 依次尝试地址匹配的合约与 ABI 注册表解码方法名，失败时返回选择器
+func methodName(f *CallFrame, contracts []*Contract) string {
+	switch {
+	case strings.HasPrefix(f.Type, "CREATE"):
+		return "constructor"
+	case len(f.Input) == 0:
+		return "receive"
+	case len(f.Input) < 4:
+		return "fallback"
+	}
+	for _, registry := range []bool{false, true} {
+		for _, c := range contracts {
+			if c == nil || (registry && c.Address != (common.Address{})) || (!registry && c.Address != f.To) {
+				continue
+			}
+			if method, err := c.ABI.MethodById(f.Input[:4]); err == nil {
+				return method.Name
+			}
+		}
+	}
+	return fmt.Sprintf("%#x", f.Input[:4])
+}
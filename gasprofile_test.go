@@ -0,0 +1,69 @@
+package goether
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasProfile(t *testing.T) {
+	reverted := hexutil.Encode(revertData(t, "Error(string)", "string", "insufficient balance"))
+	trace := json.RawMessage(fmt.Sprintf(failedCallTrace, reverted, reverted))
+
+	m := newMockRPC(t)
+	m.On("debug_traceTransaction", func(params []json.RawMessage) (any, error) {
+		return trace, nil
+	})
+	m.On("debug_traceCall", func(params []json.RawMessage) (any, error) {
+		var msg map[string]string
+		json.Unmarshal(params[0], &msg)
+		assert.Equal(t, eventToken, common.HexToAddress(msg["to"]))
+		assert.True(t, strings.HasPrefix(msg["data"], "0xa9059cbb"))
+		return trace, nil
+	})
+	w := newTestWallet(t, m)
+	registry, err := NewERC20(common.Address{}, w)
+	assert.NoError(t, err)
+
+	profile, err := w.ProfileTransaction(common.HexToHash("0x01"), registry.Contract)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x9000), profile.Total)
+	assert.Len(t, profile.Entries, 4)
+
+	root := profile.Entries[0]
+	assert.Equal(t, "0x38ed1739", root.Method)
+	assert.Equal(t, uint64(0x9000-0x800-0x5000), root.SelfGas)
+	pair := profile.Entries[2]
+	assert.Equal(t, 1, pair.Depth)
+	assert.Equal(t, uint64(0x5000-0x3000), pair.SelfGas)
+	token := profile.Entries[3]
+	assert.Equal(t, "transfer", token.Method)
+	assert.Equal(t, uint64(0x3000), token.SelfGas)
+	assert.True(t, token.Failed)
+
+	top := profile.Top(2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "0x38ed1739", top[0].Method)
+	assert.Equal(t, "transfer", top[1].Method)
+	assert.Len(t, profile.Top(0), 4)
+	assert.Equal(t, uint64(0x800+0x2000), profile.ByContract()[common.HexToAddress("0xa2")])
+
+	report := profile.String()
+	assert.Contains(t, report, "total gas: 36864")
+	assert.Contains(t, report, "    DELEGATECALL "+common.HexToAddress("0xa3").Hex()+".transfer gasUsed=12288 self=12288 (33.33%) [failed: execution reverted]")
+
+	token20, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+	profile, err = token20.ProfileMethod("transfer", nil, eventTo, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Len(t, profile.Entries, 4)
+
+	_, err = (&Contract{}).ProfileMethod("transfer", nil)
+	assert.ErrorIs(t, err, ErrWalletNil)
+}
@@ -0,0 +1,470 @@
+// Package gen 根据合约 ABI 生成基于 goether.Contract 的类型化 Go 绑定代码，
+// 是 abigen 之外更贴近 goether 用法的选择，命令行入口为 goether gen
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// Config 生成绑定代码的参数
+type Config struct {
+	// Package 生成代码的包名
+	Package string
+	// Type 合约类型名，例如 Token，同时作为事件结构体等名称的前缀
+	Type string
+	// ABI JSON 格式的 ABI，也可以是包含 abi 字段的 Hardhat/Foundry 编译产物
+	ABI string
+}
+
+// reservedParams 生成的方法自身使用的参数名
+var reservedParams = map[string]bool{
+	"c": true, "ctx": true, "opts": true, "err": true, "results": true, "out": true,
+	"fromBlock": true, "toBlock": true, "events": true, "event": true, "result": true, "abi": true,
+	"big": true, "common": true, "types": true, "goether": true, "context": true,
+}
+
+type genArg struct {
+	// Name 方法参数名
+	Name string
+	// Field 结构体字段名
+	Field string
+	// Raw ABI 中的参数名
+	Raw  string
+	Type string
+}
+
+type genMethod struct {
+	Name     string
+	Original string
+	Sig      string
+	Inputs   []genArg
+	Outputs  []genArg
+	Payable  bool
+}
+
+type genEvent struct {
+	Name     string
+	Original string
+	Sig      string
+	Fields   []genArg
+	Indexed  []genArg
+}
+
+type genData struct {
+	Package   string
+	Type      string
+	ABI       string
+	Calls     []genMethod
+	Transacts []genMethod
+	Events    []genEvent
+}
+
+// Generate 生成绑定代码，返回 gofmt 格式化后的源码
+//
+// 只读方法生成 X/XContext 并返回具体类型，多个返回值合并为 <Type><Method>Output 结构体；
+// 写方法通过 ExecMethod/ExecPayable 发送交易；每个事件生成 <Type><Event> 结构体以及
+// Parse、Filter、Watch 方法。匿名事件没有可以识别的 topic0，不会生成。
+// 生成的名称与嵌入的 Contract 字段或其他生成的名称冲突时在末尾追加下划线，方法优先于事件。
+func Generate(cfg Config) ([]byte, error) {
+	if !token.IsIdentifier(cfg.Package) {
+		return nil, fmt.Errorf("invalid package name %q", cfg.Package)
+	}
+	if !token.IsIdentifier(cfg.Type) || !token.IsExported(cfg.Type) {
+		return nil, fmt.Errorf("invalid type name %q, must be an exported identifier", cfg.Type)
+	}
+	abiJSON, err := extractABI(cfg.ABI)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	data := genData{Package: cfg.Package, Type: cfg.Type, ABI: abiJSON}
+	names := newNamer(cfg.Type)
+	for _, name := range sortedKeys(parsed.Methods) {
+		method := parsed.Methods[name]
+		m := genMethod{
+			Name:     names.method(abi.ToCamelCase(method.Name), len(method.Outputs) > 1),
+			Original: method.Name,
+			Sig:      method.Sig,
+			Inputs:   args(method.Inputs, "arg", method.IsPayable()),
+			Outputs:  args(method.Outputs, "out", false),
+			Payable:  method.IsPayable(),
+		}
+		if method.IsConstant() {
+			data.Calls = append(data.Calls, m)
+		} else {
+			data.Transacts = append(data.Transacts, m)
+		}
+	}
+	for _, name := range sortedKeys(parsed.Events) {
+		event := parsed.Events[name]
+		if event.Anonymous {
+			continue
+		}
+		e := genEvent{
+			Name:     names.event(abi.ToCamelCase(event.Name)),
+			Original: event.Name,
+			Sig:      event.Sig,
+			Fields:   args(event.Inputs, "arg", false),
+		}
+		for i, input := range event.Inputs {
+			if !input.Indexed {
+				continue
+			}
+			// 过滤条件使用原始类型，由 abi.MakeTopics 计算哈希
+			e.Indexed = append(e.Indexed, e.Fields[i])
+			if dynamicTopic(input.Type) {
+				e.Fields[i].Type = "common.Hash"
+			}
+		}
+		data.Events = append(data.Events, e)
+	}
+
+	var buf bytes.Buffer
+	if err = bindTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// namer 记录生成代码中已使用的包级标识符与 Type 的方法、字段名，避免名称冲突
+type namer struct {
+	typ  string
+	used map[string]bool
+}
+
+func newNamer(typ string) *namer {
+	n := &namer{typ: typ, used: map[string]bool{}}
+	// 包级标识符以 t: 开头，Type 的方法与字段以 m: 开头
+	n.take([]string{"t:" + typ, "t:" + typ + "ABI", "t:New" + typ, "m:Contract"})
+	return n
+}
+
+// method 为合约方法选择名称，生成 X、XContext 方法以及多返回值时的 <Type>XOutput 结构体
+func (n *namer) method(name string, output bool) string {
+	return n.claim(name, func(name string) []string {
+		ids := []string{"m:" + name, "m:" + name + "Context"}
+		if output {
+			ids = append(ids, "t:"+n.typ+name+"Output")
+		}
+		return ids
+	})
+}
+
+// event 为事件选择名称，生成 <Type>X 结构体以及 ParseX、FilterX、FilterXContext、WatchX 方法
+func (n *namer) event(name string) string {
+	return n.claim(name, func(name string) []string {
+		return []string{"t:" + n.typ + name, "m:Parse" + name, "m:Filter" + name, "m:Filter" + name + "Context", "m:Watch" + name}
+	})
+}
+
+// claim 在 name 末尾追加下划线直到 ids 返回的标识符都未被使用，并登记这些标识符
+func (n *namer) claim(name string, ids func(name string) []string) string {
+	for {
+		candidates := ids(name)
+		free := true
+		for _, id := range candidates {
+			if n.used[id] {
+				free = false
+				break
+			}
+		}
+		if free {
+			n.take(candidates)
+			return name
+		}
+		name += "_"
+	}
+}
+
+func (n *namer) take(ids []string) {
+	for _, id := range ids {
+		n.used[id] = true
+	}
+}
+
+// extractABI 返回压缩后的 ABI JSON，输入为编译产物时取其中的 abi 字段
+func extractABI(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "{") {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal([]byte(input), &artifact); err != nil {
+			return "", err
+		}
+		if len(artifact.ABI) == 0 {
+			return "", errors.New("artifact has no abi field")
+		}
+		input = string(artifact.ABI)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(input)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// args 为参数生成合法且互不冲突的参数名与字段名，没有名称的参数使用 prefix 加序号，
+// payable 方法额外保留 value 参数名
+func args(arguments abi.Arguments, prefix string, payable bool) []genArg {
+	result := make([]genArg, len(arguments))
+	used := map[string]bool{}
+	for i, arg := range arguments {
+		field := abi.ToCamelCase(arg.Name)
+		if arg.Name == "" {
+			field = abi.ToCamelCase(fmt.Sprintf("%s%d", prefix, i))
+		}
+		if field == "Raw" || !token.IsIdentifier(field) {
+			field += "_"
+		}
+		for used[field] {
+			field += "_"
+		}
+		used[field] = true
+
+		name := lowerFirst(field)
+		if token.IsKeyword(name) || reservedParams[name] || (payable && name == "value") {
+			name += "_"
+		}
+		result[i] = genArg{Name: name, Field: field, Raw: arg.Name, Type: goType(arg.Type)}
+	}
+	return result
+}
+
+// goType 返回参数对应的 Go 类型，字节数组写作 byte 而不是 uint8
+func goType(t abi.Type) string {
+	return strings.ReplaceAll(t.GetType().String(), "]uint8", "]byte")
+}
+
+// dynamicTopic 判断 indexed 参数在 topic 中是否只保存了哈希
+func dynamicTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var bindTemplate = template.Must(template.New("bind").Funcs(template.FuncMap{
+	"params": func(args []genArg) string {
+		var b strings.Builder
+		for _, a := range args {
+			fmt.Fprintf(&b, ", %s %s", a.Name, a.Type)
+		}
+		return b.String()
+	},
+	"names": func(args []genArg) string {
+		var b strings.Builder
+		for _, a := range args {
+			b.WriteString(", " + a.Name)
+		}
+		return b.String()
+	},
+	"rules": func(args []genArg) string {
+		var b strings.Builder
+		for _, a := range args {
+			fmt.Fprintf(&b, ", %s []%s", a.Name, a.Type)
+		}
+		return b.String()
+	},
+	"ruleNames": func(args []genArg) string {
+		var b strings.Builder
+		for _, a := range args {
+			fmt.Fprintf(&b, ", goether.TopicRule(%s...)", a.Name)
+		}
+		return b.String()
+	},
+	"trim": func(s string) string { return strings.TrimPrefix(s, ", ") },
+}).Parse(bindSource))
+
+const bindSource = `// Code generated by goether gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/goether"
+)
+
+var (
+	_ = context.Background
+	_ = big.NewInt
+	_ = abi.ConvertType
+	_ = common.Big1
+	_ = types.BloomLookup
+)
+
+// {{.Type}}ABI {{.Type}} 合约的 ABI
+const {{.Type}}ABI = {{printf "%q" .ABI}}
+
+// {{.Type}} {{.Type}} 合约的类型化绑定
+type {{.Type}} struct {
+	*goether.Contract
+}
+
+// New{{.Type}} 创建 {{.Type}} 合约实例
+func New{{.Type}}(address common.Address, wallet *goether.Wallet) (*{{.Type}}, error) {
+	c, err := goether.NewContract(address, {{.Type}}ABI, "", wallet)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Type}}{Contract: c}, nil
+}
+{{range .Calls}}{{$m := .}}
+{{- if gt (len .Outputs) 1}}
+// {{$.Type}}{{.Name}}Output {{.Original}} 的返回值
+type {{$.Type}}{{.Name}}Output struct {
+{{- range .Outputs}}
+	{{.Field}} {{.Type}}
+{{- end}}
+}
+{{end}}
+// {{.Name}} 调用只读方法 {{.Sig}}
+func (c *{{$.Type}}) {{.Name}}({{trim (params .Inputs)}}) ({{if gt (len .Outputs) 1}}{{$.Type}}{{.Name}}Output, {{else if eq (len .Outputs) 1}}{{(index .Outputs 0).Type}}, {{end}}error) {
+	return c.{{.Name}}Context(context.Background(){{names .Inputs}})
+}
+
+// {{.Name}}Context 与 {{.Name}} 相同，但 eth_call 受 ctx 控制
+func (c *{{$.Type}}) {{.Name}}Context(ctx context.Context{{params .Inputs}}) ({{if gt (len .Outputs) 1}}{{$.Type}}{{.Name}}Output, {{else if eq (len .Outputs) 1}}{{(index .Outputs 0).Type}}, {{end}}error) {
+{{- if eq (len .Outputs) 0}}
+	_, err := c.Contract.CallMethodContext(ctx, "{{.Original}}", "latest"{{names .Inputs}})
+	return err
+{{- else if eq (len .Outputs) 1}}
+	var out {{(index .Outputs 0).Type}}
+	results, err := c.Contract.CallUnpackContext(ctx, "{{.Original}}"{{names .Inputs}})
+	if err != nil {
+		return out, err
+	}
+	out = *abi.ConvertType(results[0], new({{(index .Outputs 0).Type}})).(*{{(index .Outputs 0).Type}})
+	return out, nil
+{{- else}}
+	var out {{$.Type}}{{.Name}}Output
+	results, err := c.Contract.CallUnpackContext(ctx, "{{.Original}}"{{names .Inputs}})
+	if err != nil {
+		return out, err
+	}
+{{- range $i, $o := .Outputs}}
+	out.{{$o.Field}} = *abi.ConvertType(results[{{$i}}], new({{$o.Type}})).(*{{$o.Type}})
+{{- end}}
+	return out, nil
+{{- end}}
+}
+{{end}}
+{{- range .Transacts}}
+// {{.Name}} 发送交易调用 {{.Sig}}{{if .Payable}}，value 为随交易转入的 wei{{end}}
+func (c *{{$.Type}}) {{.Name}}(opts *goether.TxOpts{{if .Payable}}, value *big.Int{{end}}{{params .Inputs}}) (string, error) {
+	return c.{{.Name}}Context(context.Background(), opts{{if .Payable}}, value{{end}}{{names .Inputs}})
+}
+
+// {{.Name}}Context 与 {{.Name}} 相同，但交易的构建与广播受 ctx 控制
+func (c *{{$.Type}}) {{.Name}}Context(ctx context.Context, opts *goether.TxOpts{{if .Payable}}, value *big.Int{{end}}{{params .Inputs}}) (string, error) {
+{{- if .Payable}}
+	return c.Contract.ExecPayableContext(ctx, "{{.Original}}", value, opts{{names .Inputs}})
+{{- else}}
+	return c.Contract.ExecMethodContext(ctx, "{{.Original}}", opts{{names .Inputs}})
+{{- end}}
+}
+{{end}}
+{{- range .Events}}
+// {{$.Type}}{{.Name}} {{.Sig}} 事件
+type {{$.Type}}{{.Name}} struct {
+{{- range .Fields}}
+	{{.Field}} {{.Type}} ` + "`" + `abi:"{{.Raw}}"` + "`" + `
+{{- end}}
+	Raw *types.Log ` + "`" + `abi:"-"` + "`" + `
+}
+
+// Parse{{.Name}} 解码 {{.Original}} 事件日志
+func (c *{{$.Type}}) Parse{{.Name}}(l types.Log) (*{{$.Type}}{{.Name}}, error) {
+	event := &{{$.Type}}{{.Name}}{Raw: &l}
+	if err := c.Contract.DecodeEventInto(l.Topics, l.Data, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Filter{{.Name}} 查询区块范围内的 {{.Original}} 事件，indexed 参数为空时不限制
+func (c *{{$.Type}}) Filter{{.Name}}(fromBlock, toBlock *big.Int{{rules .Indexed}}) ([]*{{$.Type}}{{.Name}}, error) {
+	return c.Filter{{.Name}}Context(context.Background(), fromBlock, toBlock{{names .Indexed}})
+}
+
+// Filter{{.Name}}Context 与 Filter{{.Name}} 相同，但查询受 ctx 控制
+func (c *{{$.Type}}) Filter{{.Name}}Context(ctx context.Context, fromBlock, toBlock *big.Int{{rules .Indexed}}) ([]*{{$.Type}}{{.Name}}, error) {
+	events, err := c.Contract.FilterEventsContext(ctx, "{{.Original}}", fromBlock, toBlock{{ruleNames .Indexed}})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*{{$.Type}}{{.Name}}, 0, len(events))
+	for _, e := range events {
+		event, err := c.Parse{{.Name}}(*e.Log)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+// Watch{{.Name}} 监听 {{.Original}} 事件，返回的通道在 ctx 结束后关闭
+func (c *{{$.Type}}) Watch{{.Name}}(ctx context.Context{{rules .Indexed}}) (<-chan *{{$.Type}}{{.Name}}, error) {
+	events, err := c.Contract.WatchEvent(ctx, "{{.Original}}"{{ruleNames .Indexed}})
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *{{$.Type}}{{.Name}})
+	go func() {
+		defer close(out)
+		for e := range events {
+			event, err := c.Parse{{.Name}}(*e.Log)
+			if err != nil {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+{{end}}`
@@ -0,0 +1,124 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	abiJSON, err := os.ReadFile("testdata/token.abi")
+	assert.NoError(t, err)
+	src, err := Generate(Config{Package: "token", Type: "Token", ABI: string(abiJSON)})
+	assert.NoError(t, err)
+	code := string(src)
+
+	assert.Contains(t, code, "package token")
+	assert.Contains(t, code, "func (c *Token) BalanceOf(owner common.Address) (*big.Int, error)")
+	assert.Contains(t, code, "func (c *Token) BalanceOfContext(ctx context.Context, owner common.Address) (*big.Int, error)")
+	assert.Contains(t, code, "type TokenGetReservesOutput struct")
+	assert.Contains(t, code, "func (c *Token) Ping() error")
+	// 重载方法按 abi 的命名区分
+	assert.Contains(t, code, "func (c *Token) Transfer(opts *goether.TxOpts, to common.Address, value *big.Int) (string, error)")
+	assert.Contains(t, code, "func (c *Token) Transfer0(opts *goether.TxOpts, to common.Address, value *big.Int, data []byte) (string, error)")
+	// payable 方法接收 value，关键字与未命名参数被重命名
+	assert.Contains(t, code, "func (c *Token) Deposit(opts *goether.TxOpts, value *big.Int, type_ [32]byte, arg1 string) (string, error)")
+	assert.Contains(t, code, "ExecPayableContext")
+
+	assert.Contains(t, code, "type TokenTransfer struct")
+	assert.Contains(t, code, "func (c *Token) FilterTransfer(fromBlock, toBlock *big.Int, from []common.Address, to []common.Address) ([]*TokenTransfer, error)")
+	assert.Contains(t, code, "func (c *Token) WatchTransfer(")
+	// indexed 的 string 在 topic 中只有哈希，过滤条件仍使用原始类型
+	assert.Regexp(t, `Tag\s+common.Hash\s+`+"`abi:\"tag\"`", code)
+	assert.Contains(t, code, "tag []string")
+	assert.Contains(t, code, "Raw_")
+	assert.NotContains(t, code, "TokenAnon")
+
+	// 编译产物中的 abi 字段
+	artifact := `{"contractName":"Token","abi":` + string(abiJSON) + `,"bytecode":"0x"}`
+	fromArtifact, err := Generate(Config{Package: "token", Type: "Token", ABI: artifact})
+	assert.NoError(t, err)
+	assert.Equal(t, src, fromArtifact)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := Generate(Config{Package: "my-pkg", Type: "Token", ABI: "[]"})
+	assert.Error(t, err)
+	_, err = Generate(Config{Package: "token", Type: "token", ABI: "[]"})
+	assert.Error(t, err)
+	_, err = Generate(Config{Package: "token", Type: "Token", ABI: `{"bytecode":"0x"}`})
+	assert.Error(t, err)
+	_, err = Generate(Config{Package: "token", Type: "Token", ABI: "not json"})
+	assert.Error(t, err)
+}
+
+// typeCheck 使用 go/types 检查生成的代码能否编译，依赖包的导出数据由 go list -export 提供
+func typeCheck(t *testing.T, src []byte) {
+	out, err := exec.Command("go", "list", "-export", "-deps", "-f", "{{.ImportPath}}={{.Export}}", "github.com/go-enols/goether").Output()
+	if !assert.NoError(t, err) {
+		return
+	}
+	exports := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		path, file, _ := strings.Cut(line, "=")
+		exports[path] = file
+	}
+	lookup := func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok || file == "" {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "bind.go", src, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", lookup)}
+	_, err = conf.Check("bind", fset, []*ast.File{file}, nil)
+	assert.NoError(t, err)
+}
+
+func TestGenerateTypeCheck(t *testing.T) {
+	abiJSON, err := os.ReadFile("testdata/token.abi")
+	assert.NoError(t, err)
+	src, err := Generate(Config{Package: "token", Type: "Token", ABI: string(abiJSON)})
+	assert.NoError(t, err)
+	typeCheck(t, src)
+}
+
+func TestGenerateNameCollisions(t *testing.T) {
+	abiJSON := `[
+{"type":"function","name":"contract","inputs":[],"outputs":[{"name":"","type":"address"}],"stateMutability":"view"},
+{"type":"function","name":"parseTransfer","inputs":[{"name":"data","type":"bytes"}],"outputs":[],"stateMutability":"nonpayable"},
+{"type":"function","name":"pair","inputs":[],"outputs":[{"name":"a","type":"uint256"},{"name":"b","type":"uint256"}],"stateMutability":"view"},
+{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true}],"anonymous":false},
+{"type":"event","name":"PairOutput","inputs":[],"anonymous":false},
+{"type":"event","name":"ABI","inputs":[],"anonymous":false}
+]`
+	src, err := Generate(Config{Package: "token", Type: "Token", ABI: abiJSON})
+	assert.NoError(t, err)
+	code := string(src)
+
+	// 与嵌入的 *goether.Contract 字段冲突
+	assert.Contains(t, code, "func (c *Token) Contract_() (common.Address, error)")
+	// 合约方法优先，事件的解码方法改名
+	assert.Contains(t, code, "func (c *Token) ParseTransfer(opts *goether.TxOpts, data []byte) (string, error)")
+	assert.Contains(t, code, "func (c *Token) ParseTransfer_(l types.Log) (*TokenTransfer_, error)")
+	assert.Contains(t, code, "type TokenPairOutput struct")
+	assert.Contains(t, code, "type TokenPairOutput_ struct")
+	assert.Contains(t, code, "type TokenABI_ struct")
+	typeCheck(t, src)
+}
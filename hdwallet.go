@@ -0,0 +1,187 @@
+package goether
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultHDPath MetaMask 等钱包默认使用的 BIP-44 派生路径前缀，账户下标追加在末尾
+const DefaultHDPath = "m/44'/60'/0'/0"
+
+// bip39English BIP-39 英文单词表
+//
+//go:embed bip39_english.txt
+var bip39English string
+
+// bip39Words 单词到其在单词表中下标的映射
+var bip39Words = func() map[string]int {
+	words := strings.Fields(bip39English)
+	m := make(map[string]int, len(words))
+	for i, w := range words {
+		m[w] = i
+	}
+	return m
+}()
+
+// HDWallet 基于 BIP-39 助记词与 BIP-32 派生的分层确定性钱包
+type HDWallet struct {
+	masterKey   *big.Int
+	masterChain []byte
+}
+
+// NewHDWallet 使用助记词与可选的 BIP-39 密码创建 HD 钱包
+//
+// 助记词与密码先做 NFKD 规范化，助记词必须全部来自 BIP-39 英文单词表且校验和正确。
+func NewHDWallet(mnemonic, passphrase string) (*HDWallet, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if err := validateMnemonic(words); err != nil {
+		return nil, err
+	}
+
+	seed, err := pbkdf2.Key(sha512.New, strings.Join(words, " "), []byte(norm.NFKD.String("mnemonic"+passphrase)), 2048, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errors.New("invalid master key")
+	}
+	return &HDWallet{
+		masterKey:   key,
+		masterChain: sum[32:],
+	}, nil
+}
+
+// NewSignerFromMnemonic 使用助记词按 derivationPath 派生私钥并创建签名器
+func NewSignerFromMnemonic(mnemonic, derivationPath string) (*Signer, error) {
+	hd, err := NewHDWallet(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+	return hd.Derive(derivationPath)
+}
+
+// Derive 按 BIP-32 路径派生签名器，例如 m/44'/60'/0'/0/0
+func (h *HDWallet) Derive(derivationPath string) (*Signer, error) {
+	log.Debug("Deriving HD account", "path", derivationPath)
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		log.Error("Failed to parse derivation path", "path", derivationPath, "error", err)
+		return nil, err
+	}
+
+	key, chain := h.masterKey, h.masterChain
+	for _, index := range path {
+		key, chain, err = deriveChild(key, chain, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	k, err := crypto.ToECDSA(key.FillBytes(make([]byte, 32)))
+	if err != nil {
+		return nil, err
+	}
+	address := crypto.PubkeyToAddress(k.PublicKey)
+	log.Debug("HD account derived", "path", derivationPath, "address", address.Hex())
+	return &Signer{
+		key:     k,
+		Address: address,
+	}, nil
+}
+
+// DeriveAccount 派生 DefaultHDPath 下第 index 个账户，即 m/44'/60'/0'/0/index
+func (h *HDWallet) DeriveAccount(index int) (*Signer, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("invalid account index: %d", index)
+	}
+	return h.Derive(fmt.Sprintf("%s/%d", DefaultHDPath, index))
+}
+
+// DeriveAccounts 派生 DefaultHDPath 下前 count 个账户
+func (h *HDWallet) DeriveAccounts(count int) ([]*Signer, error) {
+	signers := make([]*Signer, 0, count)
+	for i := 0; i < count; i++ {
+		s, err := h.DeriveAccount(i)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, s)
+	}
+	return signers, nil
+}
+
+// validateMnemonic 校验助记词的单词数量、单词表与校验和
+func validateMnemonic(words []string) error {
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return fmt.Errorf("invalid mnemonic word count: %d", len(words))
+	}
+
+	// 每个单词 11 位，前 ENT 位为熵，后 ENT/32 位为 sha256(熵) 的前几位
+	bits := new(big.Int)
+	for i, w := range words {
+		index, ok := bip39Words[w]
+		if !ok {
+			return fmt.Errorf("invalid mnemonic word %d: %q", i+1, w)
+		}
+		bits.Lsh(bits, 11).Or(bits, big.NewInt(int64(index)))
+	}
+	checksumBits := uint(len(words) * 11 / 33)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1)).Uint64()
+	entropy := new(big.Int).Rsh(bits, checksumBits).FillBytes(make([]byte, checksumBits*4))
+	hash := sha256.Sum256(entropy)
+	if uint64(hash[0]>>(8-checksumBits)) != checksum {
+		return errors.New("invalid mnemonic checksum")
+	}
+	return nil
+}
+
+// deriveChild BIP-32 私钥派生，index >= 2^31 时为强化派生
+func deriveChild(key *big.Int, chain []byte, index uint32) (*big.Int, []byte, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0}, key.FillBytes(make([]byte, 32))...)
+	} else {
+		k, err := crypto.ToECDSA(key.FillBytes(make([]byte, 32)))
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&k.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, chain)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	child := il.Add(il, key)
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	return child, sum[32:], nil
+}
@@ -0,0 +1,60 @@
+package goether
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestHDWallet(t *testing.T) {
+	signer, err := NewSignerFromMnemonic(testMnemonic, "m/44'/60'/0'/0/0")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", signer.Address.Hex())
+
+	hd, err := NewHDWallet(testMnemonic, "")
+	assert.NoError(t, err)
+	signers, err := hd.DeriveAccounts(2)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, signers[0].Address)
+	assert.Equal(t, "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0", signers[1].Address.Hex())
+
+	_, err = NewHDWallet("abandon about", "")
+	assert.Error(t, err)
+	_, err = hd.Derive("m/44'/60'/x")
+	assert.Error(t, err)
+}
+
+func TestHDWalletMnemonicValidation(t *testing.T) {
+	// 单词不在单词表中
+	_, err := NewHDWallet("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandonn", "")
+	assert.ErrorContains(t, err, "invalid mnemonic word 12")
+
+	// 单词正确但校验和错误
+	_, err = NewHDWallet("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "")
+	assert.ErrorContains(t, err, "invalid mnemonic checksum")
+
+	_, err = NewHDWallet("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo", "")
+	assert.ErrorContains(t, err, "invalid mnemonic checksum")
+	_, err = NewHDWallet("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", "")
+	assert.NoError(t, err)
+	_, err = NewHDWallet("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote", "")
+	assert.NoError(t, err)
+
+	// 全角字符与组合字符经 NFKD 规范化后与标准形式一致
+	hd, err := NewHDWallet("ａｂａｎｄｏｎ abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "caf\u00e9")
+	assert.NoError(t, err)
+	a, err := hd.DeriveAccount(0)
+	assert.NoError(t, err)
+	hd, err = NewHDWallet(testMnemonic, "cafe\u0301")
+	assert.NoError(t, err)
+	b, err := hd.DeriveAccount(0)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Address, b.Address)
+	hd, err = NewHDWallet(testMnemonic, "")
+	assert.NoError(t, err)
+	c, err := hd.DeriveAccount(0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a.Address, c.Address)
+}
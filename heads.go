@@ -0,0 +1,161 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// HeadPollInterval 没有 WebSocket 订阅时轮询 eth_blockNumber 的间隔
+	HeadPollInterval = 2 * time.Second
+	// HeadBufferSize 每个区块头订阅者的通道缓冲，消费方处理不及时导致缓冲已满时丢弃新的区块头
+	HeadBufferSize = 16
+	// headCatchUpLimit 轮询时一次最多补发的区块数，落后更多时只补发最近的区块
+	headCatchUpLimit = 16
+)
+
+// headFeed 一个钱包上所有区块头订阅者共享的数据源，没有订阅者时自动停止
+type headFeed struct {
+	w      *Wallet
+	mu     sync.Mutex
+	subs   map[chan *types.Header]struct{}
+	cancel context.CancelFunc
+}
+
+// SubscribeNewHeads 订阅新区块头，同一个钱包上的所有订阅共享一个数据源
+//
+// 设置了 Subscriber 时使用 WebSocket eth_subscribe，否则（或订阅失败时）按 HeadPollInterval 轮询
+// eth_blockNumber 并获取新区块的区块头。返回的通道在 ctx 结束后关闭。
+func (w *Wallet) SubscribeNewHeads(ctx context.Context) (<-chan *types.Header, error) {
+	if w.Offline {
+		return nil, errors.New("cannot subscribe to new heads in offline mode")
+	}
+	w.mu.Lock()
+	if w.heads == nil {
+		w.heads = &headFeed{w: w, subs: make(map[chan *types.Header]struct{})}
+	}
+	feed := w.heads
+	w.mu.Unlock()
+
+	ch := feed.add()
+	go func() {
+		<-ctx.Done()
+		feed.remove(ch)
+	}()
+	return ch, nil
+}
+
+// add 添加订阅者，第一个订阅者到来时启动数据源
+func (f *headFeed) add() chan *types.Header {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan *types.Header, HeadBufferSize)
+	f.subs[ch] = struct{}{}
+	if f.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		f.cancel = cancel
+		go f.run(ctx)
+	}
+	return ch
+}
+
+// remove 移除订阅者并关闭通道，最后一个订阅者离开时停止数据源
+func (f *headFeed) remove(ch chan *types.Header) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subs, ch)
+	close(ch)
+	if len(f.subs) == 0 && f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+}
+
+// broadcast 将区块头发送给所有订阅者，不会因为某个订阅者阻塞
+func (f *headFeed) broadcast(head *types.Header) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subs {
+		select {
+		case ch <- head:
+		default:
+			log.Warning("Head subscriber is too slow, dropping header", "number", head.Number)
+		}
+	}
+}
+
+func (f *headFeed) run(ctx context.Context) {
+	if f.w.Subscriber != nil {
+		heads, err := f.w.Subscriber.SubscribeNewHeads(ctx)
+		if err == nil {
+			log.Debug("Using websocket head subscription")
+			for head := range heads {
+				f.broadcast(head)
+			}
+			return
+		}
+		log.Warning("Failed to subscribe to new heads, falling back to polling", "error", err)
+	}
+	f.poll(ctx)
+}
+
+// poll 轮询区块高度，为每个新区块获取区块头
+func (f *headFeed) poll(ctx context.Context) {
+	log.Debug("Polling new heads", "interval", HeadPollInterval)
+	ticker := time.NewTicker(HeadPollInterval)
+	defer ticker.Stop()
+
+	last := -1
+	for {
+		number, err := callContext(ctx, f.w.rpc(ctx).EthBlockNumber)
+		if err != nil {
+			log.Debug("Failed to get block number", "error", err)
+		} else if number > last {
+			from := last + 1
+			if last < 0 {
+				from = number
+			} else if number-last > headCatchUpLimit {
+				from = number - headCatchUpLimit + 1
+			}
+			for n := from; n <= number; n++ {
+				head, err := f.w.headerByNumber(ctx, n)
+				if err != nil {
+					log.Debug("Failed to get block header", "number", n, "error", err)
+					break
+				}
+				f.broadcast(head)
+				last = n
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// headerByNumber 获取指定高度的区块头
+func (w *Wallet) headerByNumber(ctx context.Context, number int) (*types.Header, error) {
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_getBlockByNumber", hexutil.EncodeUint64(uint64(number)), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errors.New("block not found")
+	}
+	head := new(types.Header)
+	if err = json.Unmarshal(raw, head); err != nil {
+		return nil, err
+	}
+	return head, nil
+}
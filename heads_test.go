@@ -0,0 +1,84 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeNewHeadsPolling(t *testing.T) {
+	HeadPollInterval = 5 * time.Millisecond
+	defer func() { HeadPollInterval = 2 * time.Second }()
+
+	m := newMockRPC(t)
+	var (
+		mu   sync.Mutex
+		head = 100
+	)
+	m.On("eth_blockNumber", func([]json.RawMessage) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		// 每次轮询前进两个区块，中间的区块也需要补发
+		head += 2
+		return hexutil.EncodeUint64(uint64(head)), nil
+	})
+	m.On("eth_getBlockByNumber", func(params []json.RawMessage) (any, error) {
+		var number string
+		json.Unmarshal(params[0], &number)
+		return &types.Header{Number: hexutil.MustDecodeBig(number), Difficulty: big.NewInt(0)}, nil
+	})
+	w := newTestWallet(t, m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a, err := w.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+	b, err := w.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+
+	for _, ch := range []<-chan *types.Header{a, b} {
+		first := (<-ch).Number.Int64()
+		for i := int64(1); i <= 5; i++ {
+			assert.Equal(t, first+i, (<-ch).Number.Int64())
+		}
+	}
+	cancel()
+	for range a {
+	}
+	for range b {
+	}
+
+	// 两个订阅共享一个数据源
+	polls := m.Calls("eth_blockNumber")
+	headers := m.Calls("eth_getBlockByNumber")
+	assert.LessOrEqual(t, headers, 2*polls)
+}
+
+func TestSubscribeNewHeadsWebsocket(t *testing.T) {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", testEthAPI{}))
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer srv.Close()
+
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	w.Subscriber = NewSubscriber("ws" + strings.TrimPrefix(srv.URL, "http"))
+	defer w.Subscriber.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	heads, err := w.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), (<-heads).Number.Int64())
+	assert.Equal(t, int64(2), (<-heads).Number.Int64())
+	assert.Zero(t, m.Calls("eth_blockNumber"))
+}
@@ -0,0 +1,120 @@
+package goether
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// rpcHandler 模拟某个 JSON-RPC 方法，返回值会被序列化为 result
+type rpcHandler func(params []json.RawMessage) (any, error)
+
+// mockRPC 一个最小化的 JSON-RPC 服务端，用于离线测试
+type mockRPC struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]rpcHandler
+	calls    map[string]int
+}
+
+func newMockRPC(t *testing.T) *mockRPC {
+	m := &mockRPC{
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string]int),
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	t.Cleanup(m.Close)
+	return m
+}
+
+// On 注册方法的处理函数
+func (m *mockRPC) On(method string, h rpcHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[method] = h
+}
+
+// Result 注册一个总是返回固定结果的方法
+func (m *mockRPC) Result(method string, result any) {
+	m.On(method, func([]json.RawMessage) (any, error) { return result, nil })
+}
+
+// Calls 返回方法被调用的次数
+func (m *mockRPC) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+type mockRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type mockError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *mockError) Error() string { return e.Message }
+
+func (m *mockRPC) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	var batch []mockRequest
+	if err := json.Unmarshal(body, &batch); err == nil {
+		resps := make([]map[string]any, 0, len(batch))
+		for _, req := range batch {
+			resps = append(resps, m.handle(req))
+		}
+		json.NewEncoder(rw).Encode(resps)
+		return
+	}
+
+	var req mockRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(rw).Encode(m.handle(req))
+}
+
+func (m *mockRPC) handle(req mockRequest) map[string]any {
+	m.mu.Lock()
+	m.calls[req.Method]++
+	h, ok := m.handlers[req.Method]
+	m.mu.Unlock()
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	if !ok {
+		resp["error"] = mockError{Code: -32601, Message: "method not found: " + req.Method}
+		return resp
+	}
+	result, err := h(req.Params)
+	if err != nil {
+		if e, ok := err.(*mockError); ok {
+			resp["error"] = e
+		} else {
+			resp["error"] = mockError{Code: -32000, Message: err.Error()}
+		}
+		return resp
+	}
+	resp["result"] = result
+	return resp
+}
+
+// newTestWallet 创建一个连接到 mock 节点的钱包，链 ID 为 1
+func newTestWallet(t *testing.T, m *mockRPC) *Wallet {
+	w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", m.URL, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
@@ -0,0 +1,243 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	// DefaultIndexerConfirmations Indexer 默认只同步达到该确认数的区块
+	DefaultIndexerConfirmations uint64 = 12
+	// DefaultIndexerBatchSize Indexer 每次同步的最大区块数
+	DefaultIndexerBatchSize uint64 = 5000
+	// DefaultIndexerReorgDepth 检测到区块重组时回退的区块数
+	DefaultIndexerReorgDepth uint64 = 64
+)
+
+// indexerSource 需要索引的合约及其事件
+type indexerSource struct {
+	contract *Contract
+	events   map[string]bool
+}
+
+// Indexer 将合约事件持续同步到 EventStore
+//
+// 每轮同步从检查点之后开始，最多同步到最新区块减去 Confirmations 的位置，事件与检查点在同一个事务中写入。
+// 检查点区块的哈希与链上不一致时认为发生了区块重组，回退 ReorgDepth 个区块后重新同步。
+type Indexer struct {
+	// Name 存储中区分不同 Indexer 的名称
+	Name   string
+	Wallet *Wallet
+	Store  EventStore
+	// FromBlock 没有检查点时开始同步的区块
+	FromBlock     uint64
+	Confirmations uint64
+	BatchSize     uint64
+	ReorgDepth    uint64
+
+	sources []indexerSource
+}
+
+// NewIndexer 创建事件索引器，之后通过 Add 添加需要索引的合约事件
+func NewIndexer(name string, w *Wallet, store EventStore) *Indexer {
+	return &Indexer{
+		Name:          name,
+		Wallet:        w,
+		Store:         store,
+		Confirmations: DefaultIndexerConfirmations,
+		BatchSize:     DefaultIndexerBatchSize,
+		ReorgDepth:    DefaultIndexerReorgDepth,
+	}
+}
+
+// Add 添加需要索引的合约事件，events 为空时索引 ABI 中的所有事件
+func (ix *Indexer) Add(c *Contract, events ...string) error {
+	src := indexerSource{contract: c, events: make(map[string]bool)}
+	if len(events) == 0 {
+		for name := range c.ABI.Events {
+			src.events[name] = true
+		}
+	}
+	for _, name := range events {
+		if _, ok := c.ABI.Events[name]; !ok {
+			return fmt.Errorf("event %q not found in abi", name)
+		}
+		src.events[name] = true
+	}
+	ix.sources = append(ix.sources, src)
+	return nil
+}
+
+// Run 持续同步直到 ctx 结束，每个新区块到来时执行一轮 Sync，返回 ctx.Err()
+func (ix *Indexer) Run(ctx context.Context) error {
+	heads, err := ix.Wallet.SubscribeNewHeads(ctx)
+	if err != nil {
+		return err
+	}
+	for range heads {
+		// 落后较多时连续同步，直到追上最新区块
+		for {
+			synced, err := ix.Sync(ctx)
+			if err != nil {
+				log.Warning("Indexer sync failed", "indexer", ix.Name, "error", err)
+				break
+			}
+			if !synced {
+				break
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// Sync 执行一轮同步，最多同步 BatchSize 个区块，返回本轮是否有新的区块被同步
+func (ix *Indexer) Sync(ctx context.Context) (bool, error) {
+	if ix.Wallet == nil {
+		return false, ErrWalletNil
+	}
+	if ix.Store == nil || len(ix.sources) == 0 {
+		return false, errors.New("indexer has no store or contracts")
+	}
+	w := ix.Wallet
+
+	cp, err := ix.Store.Checkpoint(ctx, ix.Name)
+	if err != nil {
+		return false, err
+	}
+	if cp != nil {
+		if cp, err = ix.checkReorg(ctx, cp); err != nil {
+			return false, err
+		}
+	}
+	from := ix.FromBlock
+	if cp != nil {
+		from = cp.Block + 1
+	}
+
+	head, err := callContext(ctx, w.rpc(ctx).EthBlockNumber)
+	if err != nil {
+		return false, err
+	}
+	if uint64(head) < ix.Confirmations || uint64(head)-ix.Confirmations < from {
+		return false, nil
+	}
+	to := uint64(head) - ix.Confirmations
+	if ix.BatchSize > 0 && to-from >= ix.BatchSize {
+		to = from + ix.BatchSize - 1
+	}
+
+	logs, err := w.filterLogRange(ctx, ix.query(), from, to)
+	if err != nil {
+		return false, err
+	}
+	var events []IndexedEvent
+	for i := range logs {
+		l := &logs[i]
+		for _, src := range ix.sources {
+			if src.contract.Address != l.Address {
+				continue
+			}
+			event, ok := decodeLog(l, []*Contract{src.contract})
+			if !ok || !src.events[event.Name] {
+				continue
+			}
+			events = append(events, IndexedEvent{
+				Contract:    l.Address,
+				Name:        event.Name,
+				BlockNumber: l.BlockNumber,
+				BlockHash:   l.BlockHash,
+				TxHash:      l.TxHash,
+				TxIndex:     l.TxIndex,
+				LogIndex:    l.Index,
+				Values:      event.Values,
+			})
+			break
+		}
+	}
+
+	hash, err := w.blockHash(ctx, to)
+	if err != nil {
+		return false, err
+	}
+	if err = ix.Store.Commit(ctx, ix.Name, events, Checkpoint{Block: to, Hash: hash}); err != nil {
+		return false, err
+	}
+	log.Debug("Indexer synced", "indexer", ix.Name, "from", from, "to", to, "events", len(events))
+	return true, nil
+}
+
+// checkReorg 检查点区块已被重组时回退 ReorgDepth 个区块，返回新的检查点，回退到 FromBlock 之前时返回 nil
+func (ix *Indexer) checkReorg(ctx context.Context, cp *Checkpoint) (*Checkpoint, error) {
+	hash, err := ix.Wallet.blockHash(ctx, cp.Block)
+	if err != nil {
+		return nil, err
+	}
+	if hash == cp.Hash {
+		return cp, nil
+	}
+
+	if cp.Block < ix.FromBlock+ix.ReorgDepth {
+		// 回退到 FromBlock 之前，重新从头同步
+		log.Warning("Reorg detected at checkpoint, resyncing from start", "indexer", ix.Name, "block", cp.Block)
+		if ix.FromBlock == 0 {
+			// 检查点不能表示第 0 个区块之前，直接清空事件并从第 0 个区块开始
+			return nil, ix.Store.Rewind(ctx, ix.Name, Checkpoint{})
+		}
+		cp = &Checkpoint{Block: ix.FromBlock - 1}
+	} else {
+		cp = &Checkpoint{Block: cp.Block - ix.ReorgDepth}
+		log.Warning("Reorg detected at checkpoint, rewinding", "indexer", ix.Name, "to", cp.Block)
+	}
+	if cp.Hash, err = ix.Wallet.blockHash(ctx, cp.Block); err != nil {
+		return nil, err
+	}
+	if err = ix.Store.Rewind(ctx, ix.Name, *cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// query 合并所有合约与事件的过滤条件
+func (ix *Indexer) query() ethereum.FilterQuery {
+	var q ethereum.FilterQuery
+	var ids []common.Hash
+	seen := map[common.Hash]bool{}
+	for _, src := range ix.sources {
+		q.Addresses = append(q.Addresses, src.contract.Address)
+		for name := range src.events {
+			id := src.contract.ABI.Events[name].ID
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	q.Topics = [][]common.Hash{ids}
+	return q
+}
+
+// blockHash 获取指定高度的区块哈希
+func (w *Wallet) blockHash(ctx context.Context, number uint64) (common.Hash, error) {
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_getBlockByNumber", hexutil.EncodeUint64(number), false)
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var block struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	if err = json.Unmarshal(raw, &block); err != nil {
+		return common.Hash{}, err
+	}
+	if block.Hash == nil {
+		return common.Hash{}, fmt.Errorf("block %d not found", number)
+	}
+	return *block.Hash, nil
+}
@@ -0,0 +1,167 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// indexerChain 模拟节点上的区块哈希与 Transfer 日志，fork 改变区块哈希用于模拟重组
+type indexerChain struct {
+	mu   sync.Mutex
+	head uint64
+	fork byte
+	// forkFrom 从该区块开始使用 fork 作为哈希的一部分
+	forkFrom uint64
+	logs     []*types.Log
+}
+
+func (c *indexerChain) hash(n uint64) common.Hash {
+	h := common.BigToHash(new(big.Int).SetUint64(n))
+	if n >= c.forkFrom {
+		h[0] = c.fork
+	}
+	return h
+}
+
+func newIndexerChain(m *mockRPC) *indexerChain {
+	c := &indexerChain{head: 25, forkFrom: ^uint64(0)}
+	m.On("eth_blockNumber", func([]json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return hexutil.EncodeUint64(c.head), nil
+	})
+	m.On("eth_getBlockByNumber", func(params []json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var number hexutil.Uint64
+		json.Unmarshal(params[0], &number)
+		if uint64(number) > c.head {
+			return nil, nil
+		}
+		return &types.Header{
+			Number:     new(big.Int).SetUint64(uint64(number)),
+			Difficulty: big.NewInt(0),
+			Extra:      c.hash(uint64(number)).Bytes(),
+		}, nil
+	})
+	m.On("eth_getLogs", func(params []json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var filter struct {
+			FromBlock hexutil.Uint64   `json:"fromBlock"`
+			ToBlock   hexutil.Uint64   `json:"toBlock"`
+			Address   []common.Address `json:"address"`
+		}
+		json.Unmarshal(params[0], &filter)
+		result := []*types.Log{}
+		for _, l := range c.logs {
+			if l.BlockNumber >= uint64(filter.FromBlock) && l.BlockNumber <= uint64(filter.ToBlock) {
+				result = append(result, l)
+			}
+		}
+		return result, nil
+	})
+	return c
+}
+
+func indexedBlocks(events []IndexedEvent) []uint64 {
+	var blocks []uint64
+	for _, e := range events {
+		blocks = append(blocks, e.BlockNumber)
+	}
+	return blocks
+}
+
+func TestIndexer(t *testing.T) {
+	m := newMockRPC(t)
+	chain := newIndexerChain(m)
+	for _, n := range []uint64{5, 12, 20, 22, 24} {
+		chain.logs = append(chain.logs, transferLog(n, 0))
+	}
+	approval := transferLog(7, 1)
+	approval.Topics[0] = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	chain.logs = append(chain.logs, approval)
+
+	w := newTestWallet(t, m)
+	token, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+	store := NewMemoryEventStore()
+	ix := NewIndexer("test", w, store)
+	ix.FromBlock = 1
+	ix.Confirmations = 2
+	ix.BatchSize = 10
+	ix.ReorgDepth = 3
+	assert.Error(t, ix.Add(token.Contract, "Missing"))
+	assert.NoError(t, ix.Add(token.Contract, "Transfer"))
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		synced, err := ix.Sync(ctx)
+		assert.NoError(t, err)
+		assert.True(t, synced)
+	}
+	synced, err := ix.Sync(ctx)
+	assert.NoError(t, err)
+	assert.False(t, synced)
+
+	// 区块 24 尚未达到确认数，Approval 事件没有被索引
+	events := store.Events("test")
+	assert.Equal(t, []uint64{5, 12, 20, 22}, indexedBlocks(events))
+	assert.Equal(t, "Transfer", events[0].Name)
+	assert.Equal(t, eventTo, events[0].Values["to"])
+	assert.Equal(t, big.NewInt(5), events[0].Values["value"])
+	cp, err := store.Checkpoint(ctx, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(23), cp.Block)
+
+	// 区块 22 之后发生重组，区块 22 的事件被移到区块 21
+	chain.mu.Lock()
+	chain.fork, chain.forkFrom = 0xff, 21
+	chain.logs[3] = transferLog(21, 0)
+	chain.head = 26
+	chain.mu.Unlock()
+	synced, err = ix.Sync(ctx)
+	assert.NoError(t, err)
+	assert.True(t, synced)
+	assert.Equal(t, []uint64{5, 12, 20, 21, 24}, indexedBlocks(store.Events("test")))
+	cp, err = store.Checkpoint(ctx, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(24), cp.Block)
+}
+
+func TestIndexerRun(t *testing.T) {
+	HeadPollInterval = 5 * time.Millisecond
+	defer func() { HeadPollInterval = 2 * time.Second }()
+
+	m := newMockRPC(t)
+	chain := newIndexerChain(m)
+	chain.logs = []*types.Log{transferLog(3, 0), transferLog(18, 0)}
+	w := newTestWallet(t, m)
+	token, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+	store := NewMemoryEventStore()
+	ix := NewIndexer("run", w, store)
+	ix.BatchSize = 5
+	assert.NoError(t, ix.Add(token.Contract))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ix.Run(ctx) }()
+	assert.Eventually(t, func() bool {
+		cp, _ := store.Checkpoint(ctx, "run")
+		return cp != nil && cp.Block == 25-DefaultIndexerConfirmations
+	}, 5*time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, []uint64{3}, indexedBlocks(store.Events("run")))
+}
@@ -0,0 +1,59 @@
+// Package abiutil safe、userop 等子包共用的 ABI 编码辅助函数
+package abiutil
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// BigOrZero n 为 nil 时返回 0，避免 ABI 编码 nil 的 *big.Int
+func BigOrZero(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+// CheckUint 检查 values 都可以编码为 uint<bits>，nil 视为 0。
+// abi.Arguments.Pack 会把负数与超出位宽的 *big.Int 截断为补码而不返回错误
+func CheckUint(bits int, values ...*big.Int) error {
+	for _, v := range values {
+		if v != nil && (v.Sign() < 0 || v.BitLen() > bits) {
+			return fmt.Errorf("value %s is out of range for uint%d", v, bits)
+		}
+	}
+	return nil
+}
+
+// BytesOrEmpty b 为 nil 时返回空切片
+func BytesOrEmpty(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
+// MustABI 解析 JSON 格式的 ABI，出错时 panic，只用于包级常量
+func MustABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// MustArguments 按类型名创建匿名参数列表，类型无效时 panic，只用于包级常量
+func MustArguments(types ...string) abi.Arguments {
+	var args abi.Arguments
+	for _, t := range types {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}
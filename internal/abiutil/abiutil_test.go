@@ -0,0 +1,25 @@
+package abiutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelpers(t *testing.T) {
+	assert.Equal(t, 0, BigOrZero(nil).Sign())
+	assert.Equal(t, big.NewInt(5), BigOrZero(big.NewInt(5)))
+	assert.Equal(t, []byte{}, BytesOrEmpty(nil))
+
+	assert.NoError(t, CheckUint(256, nil, big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), 255)))
+	assert.Error(t, CheckUint(256, big.NewInt(1), big.NewInt(-1)))
+	assert.Error(t, CheckUint(256, new(big.Int).Lsh(big.NewInt(1), 256)))
+	assert.Error(t, CheckUint(48, new(big.Int).Lsh(big.NewInt(1), 48)))
+
+	args := MustArguments("address", "uint256")
+	assert.Len(t, args, 2)
+	assert.Equal(t, "uint256", args[1].Type.String())
+	assert.Panics(t, func() { MustArguments("notatype") })
+	assert.Panics(t, func() { MustABI("not json") })
+}
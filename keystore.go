@@ -0,0 +1,84 @@
+package goether
+
+import (
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/google/uuid"
+)
+
+// ScryptParams 导出 keystore 时使用的 scrypt 参数
+type ScryptParams struct {
+	N int
+	P int
+}
+
+var (
+	// StandardScryptParams 与 geth 默认一致的参数，解密约需 1 秒
+	StandardScryptParams = ScryptParams{N: keystore.StandardScryptN, P: keystore.StandardScryptP}
+	// LightScryptParams 较弱但更快的参数，适合测试或低性能设备
+	LightScryptParams = ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+)
+
+// NewSignerFromKeystore 使用密码解密 Keystore V3 格式的 JSON 并创建签名器
+func NewSignerFromKeystore(keyJSON []byte, password string) (*Signer, error) {
+	log.Debug("Creating signer from keystore")
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		log.Error("Failed to decrypt keystore", "error", err)
+		return nil, err
+	}
+
+	log.Debug("Signer created from keystore successfully", "address", key.Address.Hex())
+	return &Signer{
+		key:     key.PrivateKey,
+		Address: key.Address,
+	}, nil
+}
+
+// NewSignerFromKeystorePath 读取 keystore 文件并使用密码解密
+func NewSignerFromKeystorePath(keystorePath, password string) (*Signer, error) {
+	log.Debug("Creating signer from keystore file", "path", keystorePath)
+	b, err := os.ReadFile(keystorePath)
+	if err != nil {
+		log.Error("Failed to read keystore file", "path", keystorePath, "error", err)
+		return nil, err
+	}
+	return NewSignerFromKeystore(b, password)
+}
+
+// ExportKeystore 使用密码将私钥加密导出为 Keystore V3 格式的 JSON
+func (s Signer) ExportKeystore(password string, params ScryptParams) ([]byte, error) {
+	log.Debug("Exporting keystore", "address", s.Address.Hex(), "scryptN", params.N, "scryptP", params.P)
+	if s.noExport {
+		return nil, ErrKeyExportDisabled
+	}
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    s.Address,
+		PrivateKey: key,
+	}, password, params.N, params.P)
+	if err != nil {
+		log.Error("Failed to encrypt keystore", "error", err)
+		return nil, err
+	}
+	return keyJSON, nil
+}
+
+// NewWalletFromKeystore 与 NewWalletFromPath 相同，但私钥文件为加密的 keystore
+func NewWalletFromKeystore(keystorePath, password, rpc string, options ...any) (*Wallet, error) {
+	log.Debug("Creating wallet from keystore file", "path", keystorePath, "rpc", rpc)
+	signer, err := NewSignerFromKeystorePath(keystorePath, password)
+	if err != nil {
+		return nil, err
+	}
+	return NewWalletWithSigner(signer, rpc, options...)
+}
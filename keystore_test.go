@@ -0,0 +1,29 @@
+package goether
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeystore(t *testing.T) {
+	keyJSON, err := TestSigner.ExportKeystore("secret", LightScryptParams)
+	assert.NoError(t, err)
+
+	signer, err := NewSignerFromKeystore(keyJSON, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, signer.Address)
+	assert.Equal(t, TestSigner.GetPublicKeyHex(), signer.GetPublicKeyHex())
+
+	_, err = NewSignerFromKeystore(keyJSON, "wrong")
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	assert.NoError(t, os.WriteFile(path, keyJSON, 0o600))
+	w, err := NewWalletFromKeystore(path, "secret", "", big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, w.Address)
+}
@@ -0,0 +1,145 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KMSClient AWS KMS 的最小接口，避免直接依赖 aws-sdk-go-v2。
+// 使用 kms.Client 实现时：
+//
+//	GetPublicKey: 调用 GetPublicKey 并返回 PublicKey 字段（DER 编码的 SubjectPublicKeyInfo）
+//	Sign: 调用 Sign，MessageType 为 DIGEST，SigningAlgorithm 为 ECDSA_SHA_256，返回 Signature 字段（DER 编码）
+type KMSClient interface {
+	GetPublicKey(ctx context.Context, keyID string) ([]byte, error)
+	Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+}
+
+// KMSSigner 将签名委托给 AWS KMS 中 ECC_SECG_P256K1 类型的密钥，私钥不会离开 KMS
+type KMSSigner struct {
+	digestSigner
+	KeyID string
+
+	client KMSClient
+	pub    *ecdsa.PublicKey
+}
+
+// NewKMSSigner 读取 KMS 密钥的公钥并计算地址
+func NewKMSSigner(ctx context.Context, client KMSClient, keyID string) (*KMSSigner, error) {
+	log.Debug("Creating KMS signer", "keyID", keyID)
+	der, err := client.GetPublicKey(ctx, keyID)
+	if err != nil {
+		log.Error("Failed to get KMS public key", "keyID", keyID, "error", err)
+		return nil, err
+	}
+	pub, err := parseSecp256k1PublicKey(der)
+	if err != nil {
+		log.Error("Failed to parse KMS public key", "keyID", keyID, "error", err)
+		return nil, err
+	}
+
+	address := crypto.PubkeyToAddress(*pub)
+	log.Debug("KMS signer created successfully", "keyID", keyID, "address", address.Hex())
+	s := &KMSSigner{
+		KeyID:  keyID,
+		client: client,
+		pub:    pub,
+	}
+	s.digestSigner = digestSigner{Address: address, sign: s.SignDigest}
+	return s, nil
+}
+
+// GetPublicKey 获取公钥字节数组
+func (s *KMSSigner) GetPublicKey() []byte {
+	return crypto.FromECDSAPub(s.pub)
+}
+
+// SignDigest 对 32 字节摘要签名，返回 [R || S || V] 格式且 V 为 0 或 1 的签名
+func (s *KMSSigner) SignDigest(digest []byte) ([]byte, error) {
+	return s.SignDigestContext(context.Background(), digest)
+}
+
+// SignDigestContext 与 SignDigest 相同，但 KMS 调用受 ctx 控制
+func (s *KMSSigner) SignDigestContext(ctx context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("digest must be 32 bytes, got %d", len(digest))
+	}
+	der, err := s.client.Sign(ctx, s.KeyID, digest)
+	if err != nil {
+		log.Error("Failed to sign with KMS", "keyID", s.KeyID, "error", err)
+		return nil, err
+	}
+	return derToEthSignature(der, digest, s.pub)
+}
+
+var (
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1   = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parseSecp256k1PublicKey 解析 DER 编码的 secp256k1 SubjectPublicKeyInfo，
+// x509.ParsePKIXPublicKey 不支持该曲线
+func parseSecp256k1PublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var info subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+	if !info.Algorithm.Algorithm.Equal(oidECPublicKey) {
+		return nil, errors.New("public key is not an ec key")
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil {
+		return nil, err
+	}
+	if !curve.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("unsupported curve %s, expected secp256k1", curve)
+	}
+	return crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+}
+
+// derToEthSignature 将 DER 编码的 ECDSA 签名转换为以太坊的 [R || S || V] 格式：
+// S 按 EIP-2 规范化到曲线阶的一半以下，V 通过恢复公钥比对得到
+func derToEthSignature(der, digest []byte, pub *ecdsa.PublicKey) ([]byte, error) {
+	var rs struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &rs); err != nil {
+		return nil, fmt.Errorf("invalid der signature: %w", err)
+	}
+
+	n := crypto.S256().Params().N
+	if rs.R.Sign() <= 0 || rs.R.Cmp(n) >= 0 || rs.S.Sign() <= 0 || rs.S.Cmp(n) >= 0 {
+		return nil, errors.New("signature values out of range")
+	}
+	halfN := new(big.Int).Rsh(n, 1)
+	if rs.S.Cmp(halfN) > 0 {
+		rs.S = new(big.Int).Sub(n, rs.S)
+	}
+
+	sig := make([]byte, 65)
+	rs.R.FillBytes(sig[:32])
+	rs.S.FillBytes(sig[32:64])
+
+	want := crypto.FromECDSAPub(pub)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		recovered, err := crypto.Ecrecover(digest, sig)
+		if err == nil && bytes.Equal(recovered, want) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("signature does not match public key")
+}
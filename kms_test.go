@@ -0,0 +1,99 @@
+package goether
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKMS 使用本地私钥模拟 KMS，highS 为 true 时返回未规范化的 S
+type fakeKMS struct {
+	key   *ecdsa.PrivateKey
+	highS bool
+}
+
+func (f *fakeKMS) GetPublicKey(ctx context.Context, keyID string) ([]byte, error) {
+	params, _ := asn1.Marshal(oidSecp256k1)
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidECPublicKey,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PublicKey: asn1.BitString{Bytes: crypto.FromECDSAPub(&f.key.PublicKey), BitLength: 65 * 8},
+	})
+}
+
+func (f *fakeKMS) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	sig, err := crypto.Sign(digest, f.key)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if f.highS {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+func TestKMSSigner(t *testing.T) {
+	kms := &fakeKMS{key: TestSigner.GetPrivateKey()}
+	signer, err := NewKMSSigner(context.Background(), kms, "alias/test")
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, signer.Address)
+	assert.Equal(t, TestSigner.GetPublicKey(), signer.GetPublicKey())
+
+	for _, highS := range []bool{false, true} {
+		kms.highS = highS
+		sig, err := signer.SignMsg([]byte("123"))
+		assert.NoError(t, err)
+		expected, _ := TestSigner.SignMsg([]byte("123"))
+		assert.Equal(t, expected, sig)
+	}
+
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	tx, err := signer.SignTx(1, to, big.NewInt(0), 21000, big.NewInt(1), big.NewInt(2), nil, big.NewInt(42))
+	assert.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(42)), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, from)
+
+	tx, err = signer.SignLegacyTx(1, to, big.NewInt(0), 21000, big.NewInt(1), nil, big.NewInt(42))
+	assert.NoError(t, err)
+	from, err = types.Sender(types.NewEIP155Signer(big.NewInt(42)), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, from)
+}
+
+func TestWalletWithKMSSigner(t *testing.T) {
+	signer, err := NewKMSSigner(context.Background(), &fakeKMS{key: TestSigner.GetPrivateKey()}, "alias/test")
+	assert.NoError(t, err)
+
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 3, &sent)
+	w, err := NewWalletWithSigner(signer, m.URL, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, w.Address)
+
+	_, err = w.SendTx(common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA"), big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	address, _, err := w.DeployContract([]byte{0x60, 0x80}, "", nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 2)
+	for _, tx := range sent {
+		from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+		assert.NoError(t, err)
+		assert.Equal(t, signer.Address, from)
+	}
+	assert.Nil(t, sent[1].To())
+	assert.NotEqual(t, common.Address{}, address)
+}
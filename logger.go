@@ -0,0 +1,105 @@
+package goether
+
+import (
+	"sync/atomic"
+
+	golog "github.com/go-enols/go-log"
+)
+
+// Logger 包内使用的日志接口，keyvals 为交替出现的键值对
+type Logger interface {
+	Debug(msg string, keyvals ...any)
+	Info(msg string, keyvals ...any)
+	Warning(msg string, keyvals ...any)
+	Error(msg string, keyvals ...any)
+}
+
+// NopLogger 丢弃所有日志，是包的默认 Logger
+type NopLogger struct{}
+
+func (NopLogger) Debug(string, ...any)   {}
+func (NopLogger) Info(string, ...any)    {}
+func (NopLogger) Warning(string, ...any) {}
+func (NopLogger) Error(string, ...any)   {}
+
+// GoLogger 将日志输出到 github.com/go-enols/go-log
+type GoLogger struct{}
+
+func (GoLogger) Debug(msg string, keyvals ...any)   { golog.Debug(append([]any{msg}, keyvals...)...) }
+func (GoLogger) Info(msg string, keyvals ...any)    { golog.Info(append([]any{msg}, keyvals...)...) }
+func (GoLogger) Warning(msg string, keyvals ...any) { golog.Warning(append([]any{msg}, keyvals...)...) }
+func (GoLogger) Error(msg string, keyvals ...any)   { golog.Error(append([]any{msg}, keyvals...)...) }
+
+// RedactLogger 将 keys 对应的值替换为 "[REDACTED]" 后再交给 Logger 输出，
+// 用于隐藏签名、calldata 等敏感数据
+type RedactLogger struct {
+	Logger Logger
+	keys   map[string]bool
+}
+
+// NewRedactLogger 创建隐藏 keys 对应值的 Logger
+//
+//	SetLogger(NewRedactLogger(GoLogger{}, "signature", "data", "result"))
+func NewRedactLogger(logger Logger, keys ...string) *RedactLogger {
+	r := &RedactLogger{Logger: logger, keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		r.keys[key] = true
+	}
+	return r
+}
+
+func (r *RedactLogger) redact(keyvals []any) []any {
+	out := make([]any, len(keyvals))
+	copy(out, keyvals)
+	for i := 0; i+1 < len(out); i += 2 {
+		if key, ok := out[i].(string); ok && r.keys[key] {
+			out[i+1] = "[REDACTED]"
+		}
+	}
+	return out
+}
+
+func (r *RedactLogger) Debug(msg string, keyvals ...any) { r.Logger.Debug(msg, r.redact(keyvals)...) }
+func (r *RedactLogger) Info(msg string, keyvals ...any)  { r.Logger.Info(msg, r.redact(keyvals)...) }
+func (r *RedactLogger) Warning(msg string, keyvals ...any) {
+	r.Logger.Warning(msg, r.redact(keyvals)...)
+}
+func (r *RedactLogger) Error(msg string, keyvals ...any) { r.Logger.Error(msg, r.redact(keyvals)...) }
+
+// loggerHolder atomic.Value 要求每次存入的具体类型相同
+type loggerHolder struct {
+	Logger
+}
+
+// logProxy 转发到当前 Logger，使 SetLogger 可以与日志输出并发调用
+type logProxy struct {
+	current atomic.Value
+}
+
+func (p *logProxy) get() Logger {
+	return p.current.Load().(loggerHolder).Logger
+}
+
+func (p *logProxy) Debug(msg string, keyvals ...any)   { p.get().Debug(msg, keyvals...) }
+func (p *logProxy) Info(msg string, keyvals ...any)    { p.get().Info(msg, keyvals...) }
+func (p *logProxy) Warning(msg string, keyvals ...any) { p.get().Warning(msg, keyvals...) }
+func (p *logProxy) Error(msg string, keyvals ...any)   { p.get().Error(msg, keyvals...) }
+
+// log 包内所有日志都通过它输出
+var log = newLogProxy()
+
+func newLogProxy() *logProxy {
+	p := &logProxy{}
+	p.current.Store(loggerHolder{NopLogger{}})
+	return p
+}
+
+// SetLogger 设置包使用的 Logger，传入 nil 时恢复为 NopLogger
+//
+//	goether.SetLogger(goether.GoLogger{}) // 恢复输出到 go-log
+func SetLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger{}
+	}
+	log.current.Store(loggerHolder{logger})
+}
@@ -0,0 +1,52 @@
+package goether
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordLogger 记录所有日志，用于测试
+type recordLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (r *recordLogger) record(level, msg string, keyvals ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, fmt.Sprint(append([]any{level, msg}, keyvals...)...))
+}
+
+func (r *recordLogger) Debug(msg string, keyvals ...any)   { r.record("DEBUG", msg, keyvals...) }
+func (r *recordLogger) Info(msg string, keyvals ...any)    { r.record("INFO", msg, keyvals...) }
+func (r *recordLogger) Warning(msg string, keyvals ...any) { r.record("WARNING", msg, keyvals...) }
+func (r *recordLogger) Error(msg string, keyvals ...any)   { r.record("ERROR", msg, keyvals...) }
+
+func TestSetLogger(t *testing.T) {
+	t.Cleanup(func() { SetLogger(nil) })
+	signer, err := NewSigner("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632")
+	assert.NoError(t, err)
+
+	rec := &recordLogger{}
+	SetLogger(rec)
+	sig, err := signer.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rec.entries)
+	assert.Contains(t, rec.entries[len(rec.entries)-1], hexutil.Encode(sig))
+
+	rec.entries = nil
+	SetLogger(NewRedactLogger(rec, "signature"))
+	_, err = signer.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Contains(t, rec.entries[len(rec.entries)-1], "[REDACTED]")
+
+	rec.entries = nil
+	SetLogger(nil)
+	_, err = signer.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Empty(t, rec.entries)
+}
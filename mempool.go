@@ -0,0 +1,134 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// PendingTxFilter 交易池订阅的客户端过滤条件
+//
+// 设置了 To 或 Selectors 时需要完整交易才能判断，会自动获取交易详情；
+// 两个条件同时设置时需要都满足。
+type PendingTxFilter struct {
+	// Full 获取完整交易，为 false 且没有过滤条件时只推送交易哈希
+	Full bool
+	// To 只保留发往这些地址的交易，合约创建交易不会匹配
+	To []common.Address
+	// Selectors 只保留 calldata 以这些方法选择器开头的交易
+	Selectors [][4]byte
+}
+
+// hydrate 是否需要获取完整交易
+func (f PendingTxFilter) hydrate() bool {
+	return f.Full || len(f.To) > 0 || len(f.Selectors) > 0
+}
+
+// match 判断完整交易是否满足过滤条件
+func (f PendingTxFilter) match(tx *types.Transaction) bool {
+	if len(f.To) > 0 {
+		to := tx.To()
+		if to == nil {
+			return false
+		}
+		found := false
+		for _, addr := range f.To {
+			if addr == *to {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Selectors) > 0 {
+		data := tx.Data()
+		if len(data) < 4 {
+			return false
+		}
+		found := false
+		for _, sel := range f.Selectors {
+			if bytes.Equal(data[:4], sel[:]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// PendingTx 交易池中的交易，未获取完整交易时 Tx 为 nil、From 为零地址
+type PendingTx struct {
+	Hash common.Hash
+	Tx   *types.Transaction
+	From common.Address
+}
+
+// SubscribePendingTxs 订阅进入交易池的交易，并按 filter 在客户端过滤
+//
+// 需要完整交易时通过同一条连接调用 eth_getTransactionByHash 获取详情，
+// 获取时已经离开交易池（被打包或丢弃）的交易会被跳过。返回的通道在 ctx 结束后关闭。
+func (s *Subscriber) SubscribePendingTxs(ctx context.Context, filter PendingTxFilter) (<-chan *PendingTx, error) {
+	hashes, err := s.SubscribePendingTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *PendingTx)
+	go func() {
+		defer close(out)
+		for hash := range hashes {
+			ptx := &PendingTx{Hash: hash}
+			if filter.hydrate() {
+				ptx.Tx, ptx.From, err = s.pendingTransaction(ctx, hash)
+				if err != nil {
+					log.Debug("Failed to get pending transaction", "hash", hash, "error", err)
+					continue
+				}
+				if ptx.Tx == nil || !filter.match(ptx.Tx) {
+					continue
+				}
+			}
+			select {
+			case out <- ptx:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// pendingTransaction 获取交易详情及发送方，交易不存在时返回 nil
+func (s *Subscriber) pendingTransaction(ctx context.Context, hash common.Hash) (*types.Transaction, common.Address, error) {
+	client, err := s.conn(ctx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	var raw json.RawMessage
+	if err = client.CallContext(ctx, &raw, "eth_getTransactionByHash", hash); err != nil {
+		return nil, common.Address{}, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, common.Address{}, nil
+	}
+
+	tx := new(types.Transaction)
+	if err = json.Unmarshal(raw, tx); err != nil {
+		return nil, common.Address{}, err
+	}
+	var sender struct {
+		From common.Address `json:"from"`
+	}
+	if err = json.Unmarshal(raw, &sender); err != nil {
+		return nil, common.Address{}, err
+	}
+	return tx, sender.From, nil
+}
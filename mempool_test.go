@@ -0,0 +1,118 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMempoolAPI 依次推送交易哈希，最后一个哈希在交易池中不存在
+type testMempoolAPI struct {
+	txs []*types.Transaction
+}
+
+func (api *testMempoolAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		hashes := []common.Hash{}
+		for _, tx := range api.txs {
+			hashes = append(hashes, tx.Hash())
+		}
+		hashes = append(hashes, common.HexToHash("0xdead"))
+		for _, hash := range hashes {
+			select {
+			case <-sub.Err():
+				return
+			case <-time.After(5 * time.Millisecond):
+				notifier.Notify(sub.ID, hash)
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func (api *testMempoolAPI) GetTransactionByHash(hash common.Hash) (json.RawMessage, error) {
+	for _, tx := range api.txs {
+		if tx.Hash() != hash {
+			continue
+		}
+		raw, err := tx.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		fields := map[string]any{}
+		json.Unmarshal(raw, &fields)
+		fields["from"] = TestSigner.Address
+		return json.Marshal(fields)
+	}
+	return json.RawMessage("null"), nil
+}
+
+func TestSubscribePendingTxs(t *testing.T) {
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	other := common.HexToAddress("0x01")
+	swap := [4]byte{0x38, 0xed, 0x17, 0x39}
+	sign := func(nonce int, to common.Address, data []byte) *types.Transaction {
+		tx, err := TestSigner.SignTx(nonce, to, big.NewInt(0), 100000, big.NewInt(1), big.NewInt(1), data, big.NewInt(1))
+		assert.NoError(t, err)
+		return tx
+	}
+	api := &testMempoolAPI{txs: []*types.Transaction{
+		sign(0, router, append(swap[:], 1, 2, 3)),
+		sign(1, other, append(swap[:], 1, 2, 3)),
+		sign(2, router, []byte{0xa9, 0x05, 0x9c, 0xbb}),
+		sign(3, router, nil),
+	}}
+
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", api))
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer srv.Close()
+	s := NewSubscriber("ws" + strings.TrimPrefix(srv.URL, "http"))
+	defer s.Close()
+
+	collect := func(filter PendingTxFilter, n int) []*PendingTx {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ch, err := s.SubscribePendingTxs(ctx, filter)
+		assert.NoError(t, err)
+		var txs []*PendingTx
+		for ptx := range ch {
+			txs = append(txs, ptx)
+			if len(txs) == n {
+				break
+			}
+		}
+		return txs
+	}
+
+	// 不需要完整交易时推送所有哈希
+	txs := collect(PendingTxFilter{}, 5)
+	assert.Len(t, txs, 5)
+	assert.Nil(t, txs[0].Tx)
+	assert.Equal(t, api.txs[0].Hash(), txs[0].Hash)
+
+	// 获取完整交易，不存在的交易被跳过
+	txs = collect(PendingTxFilter{Full: true}, 4)
+	assert.Len(t, txs, 4)
+	assert.Equal(t, api.txs[3].Hash(), txs[3].Tx.Hash())
+	assert.Equal(t, TestSigner.Address, txs[3].From)
+
+	txs = collect(PendingTxFilter{To: []common.Address{router}, Selectors: [][4]byte{swap}}, 1)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, api.txs[0].Hash(), txs[0].Hash)
+
+	txs = collect(PendingTxFilter{To: []common.Address{router}}, 3)
+	assert.Len(t, txs, 3)
+	assert.Equal(t, api.txs[3].Hash(), txs[2].Hash)
+}
@@ -0,0 +1,165 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// RPCRequest 一次 JSON-RPC 调用，中间件可以修改 Method 与 Params 来改写请求
+type RPCRequest struct {
+	Method string
+	Params []json.RawMessage
+}
+
+// RPCHandler 执行一次 JSON-RPC 调用并返回 result
+type RPCHandler func(req *RPCRequest) (json.RawMessage, error)
+
+// Middleware 包装 RPCHandler，可以在调用前后插入日志、指标、缓存等逻辑，
+// 不调用 next 直接返回即可短路请求
+type Middleware func(next RPCHandler) RPCHandler
+
+// Observe 返回一个在每次调用结束后回调 fn 的中间件，用于日志与指标
+func Observe(fn func(method string, params []json.RawMessage, duration time.Duration, err error)) Middleware {
+	return func(next RPCHandler) RPCHandler {
+		return func(req *RPCRequest) (json.RawMessage, error) {
+			start := time.Now()
+			result, err := next(req)
+			fn(req.Method, req.Params, time.Since(start), err)
+			return result, err
+		}
+	}
+}
+
+// MiddlewareClient 让每个 JSON-RPC 请求依次经过 Middlewares 的 HTTP 客户端
+//
+// Middlewares 中靠前的中间件在外层，批量请求不经过中间件直接发送。
+type MiddlewareClient struct {
+	Middlewares []Middleware
+	// Client 实际发送请求的客户端，为 nil 时使用 http.DefaultClient
+	Client HTTPClient
+}
+
+// NewMiddlewareClient 创建中间件客户端，client 为 nil 时使用 http.DefaultClient
+func NewMiddlewareClient(client HTTPClient, middlewares ...Middleware) *MiddlewareClient {
+	return &MiddlewareClient{
+		Middlewares: middlewares,
+		Client:      client,
+	}
+}
+
+// WithMiddleware 返回一个 ethrpc 配置函数，使客户端的请求经过 middlewares
+//
+//	wallet, err := NewWallet(prvHex, rpc, WithMiddleware(Observe(func(method string, params []json.RawMessage, d time.Duration, err error) {
+//		log.Debug("rpc", "method", method, "duration", d, "error", err)
+//	})))
+func WithMiddleware(middlewares ...Middleware) func(rpc *ethrpc.EthRPC) {
+	return ethrpc.WithHttpClient(NewMiddlewareClient(nil, middlewares...))
+}
+
+type rpcMessage struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id,omitempty"`
+	Method  string            `json:"method,omitempty"`
+	Params  []json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage   `json:"result,omitempty"`
+	Error   *RPCError         `json:"error,omitempty"`
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口
+func (m *MiddlewareClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return m.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext 与 Post 相同，但请求受 ctx 控制
+func (m *MiddlewareClient) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var client HTTPClient = http.DefaultClient
+	if m.Client != nil {
+		client = m.Client
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(payload)), "[") {
+		return postContext(ctx, client, url, contentType, bytes.NewReader(payload))
+	}
+
+	var msg rpcMessage
+	if err = json.Unmarshal(payload, &msg); err != nil {
+		return nil, err
+	}
+
+	handler := func(req *RPCRequest) (json.RawMessage, error) {
+		return postRPC(ctx, client, url, contentType, msg.ID, req)
+	}
+	for i := len(m.Middlewares) - 1; i >= 0; i-- {
+		handler = m.Middlewares[i](handler)
+	}
+
+	result, err := handler(&RPCRequest{Method: msg.Method, Params: msg.Params})
+	resp := rpcMessage{JSONRPC: "2.0", ID: msg.ID, Result: result}
+	if err != nil {
+		rpcErr, ok := err.(*RPCError)
+		if !ok {
+			return nil, err
+		}
+		resp.Result, resp.Error = nil, rpcErr
+	} else if resp.Result == nil {
+		resp.Result = json.RawMessage("null")
+	}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(b)),
+	}, nil
+}
+
+// postRPC 发送单个 JSON-RPC 请求，节点返回错误时返回 *RPCError
+func postRPC(ctx context.Context, client HTTPClient, url, contentType string, id json.RawMessage, req *RPCRequest) (json.RawMessage, error) {
+	params := req.Params
+	if params == nil {
+		params = []json.RawMessage{}
+	}
+	body, err := json.Marshal(struct {
+		JSONRPC string            `json:"jsonrpc"`
+		ID      json.RawMessage   `json:"id"`
+		Method  string            `json:"method"`
+		Params  []json.RawMessage `json:"params"`
+	}{"2.0", id, req.Method, params})
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := postContext(ctx, client, url, contentType, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp rpcMessage
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("invalid rpc response (%s): %w", httpResp.Status, err)
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
@@ -0,0 +1,94 @@
+package goether
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareClient(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("eth_blockNumber", "0x10")
+	m.Result("eth_getBalance", "0x64")
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted"}
+	})
+
+	var methods []string
+	var errs []error
+	observe := Observe(func(method string, params []json.RawMessage, duration time.Duration, err error) {
+		methods = append(methods, method)
+		errs = append(errs, err)
+		assert.GreaterOrEqual(t, duration, time.Duration(0))
+	})
+	// 缓存 eth_blockNumber，第二次调用不再请求节点
+	var cached json.RawMessage
+	cache := func(next RPCHandler) RPCHandler {
+		return func(req *RPCRequest) (json.RawMessage, error) {
+			if req.Method != "eth_blockNumber" {
+				return next(req)
+			}
+			if cached == nil {
+				result, err := next(req)
+				if err != nil {
+					return nil, err
+				}
+				cached = result
+			}
+			return cached, nil
+		}
+	}
+
+	w, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", m.URL, big.NewInt(1), observe, Middleware(cache))
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		n, err := w.Client.EthBlockNumber()
+		assert.NoError(t, err)
+		assert.Equal(t, 16, n)
+	}
+	assert.Equal(t, 1, m.Calls("eth_blockNumber"))
+
+	balance, err := w.GetBalance()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), balance.Int64())
+
+	_, err = w.Client.EthCall(ethrpc.T{To: w.Address.Hex()}, "latest")
+	var rpcErr ethrpc.EthError
+	assert.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, 3, rpcErr.Code)
+
+	assert.Equal(t, []string{"eth_blockNumber", "eth_blockNumber", "eth_getBalance", "eth_call"}, methods)
+	var observed *RPCError
+	assert.ErrorAs(t, errs[3], &observed)
+	assert.Equal(t, "execution reverted", observed.Message)
+}
+
+func TestMiddlewareRewrite(t *testing.T) {
+	m := newMockRPC(t)
+	m.On("eth_getBalance", func(params []json.RawMessage) (any, error) {
+		var tag string
+		json.Unmarshal(params[1], &tag)
+		if tag != "pending" {
+			return "0x0", nil
+		}
+		return "0x1", nil
+	})
+	rewrite := func(next RPCHandler) RPCHandler {
+		return func(req *RPCRequest) (json.RawMessage, error) {
+			if req.Method == "eth_getBalance" {
+				req.Params[1] = json.RawMessage(`"pending"`)
+			}
+			return next(req)
+		}
+	}
+	client := ethrpc.New(m.URL, WithMiddleware(rewrite))
+	balance, err := client.EthGetBalance("0xab6c371B6c466BcF14d4003601951e5873dF2AcA", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), balance.Int64())
+}
@@ -0,0 +1,109 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ThresholdSigner 门限签名（threshold ECDSA）或 MPC 签名服务的异步接口，
+// 提交签名请求后需要参与方经过多轮交互才能得到签名
+type ThresholdSigner interface {
+	// GetAddress 门限密钥对应的地址
+	GetAddress() common.Address
+	// RequestSignature 提交 32 字节摘要的签名请求，返回用于查询结果的请求ID
+	RequestSignature(ctx context.Context, digest []byte) (requestID string, err error)
+	// SignatureResult 查询签名请求的结果，签名尚未完成时返回 ErrSignaturePending，
+	// 完成时返回 65 字节 [R || S || V] 签名，V 可以是 0/1 或 27/28
+	SignatureResult(ctx context.Context, requestID string) ([]byte, error)
+}
+
+// MPCSigner 将 ThresholdSigner 适配为 AccountSigner：提交请求后轮询结果直到签名完成，
+// 可以直接用于 NewWalletWithSigner，发送交易时的等待受 ctx 控制
+type MPCSigner struct {
+	digestSigner
+	// PollInterval 查询签名结果的间隔，默认 1 秒
+	PollInterval time.Duration
+	// Timeout 单次签名的最长等待时间，默认 2 分钟，为负数时只受 ctx 控制
+	Timeout time.Duration
+
+	backend ThresholdSigner
+}
+
+// NewMPCSigner 创建使用门限签名服务的签名器
+func NewMPCSigner(backend ThresholdSigner) *MPCSigner {
+	s := &MPCSigner{
+		PollInterval: time.Second,
+		Timeout:      2 * time.Minute,
+		backend:      backend,
+	}
+	s.digestSigner = digestSigner{Address: backend.GetAddress(), sign: s.SignDigest}
+	return s
+}
+
+// SignDigest 对 32 字节摘要签名，返回 [R || S || V] 格式且 V 为 0 或 1 的签名
+func (s *MPCSigner) SignDigest(digest []byte) ([]byte, error) {
+	return s.SignDigestContext(context.Background(), digest)
+}
+
+// SignDigestContext 与 SignDigest 相同，但等待签名完成受 ctx 控制
+func (s *MPCSigner) SignDigestContext(ctx context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("digest must be 32 bytes, got %d", len(digest))
+	}
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	requestID, err := s.backend.RequestSignature(ctx, digest)
+	if err != nil {
+		log.Error("Failed to request threshold signature", "address", s.Address.Hex(), "error", err)
+		return nil, err
+	}
+	log.Debug("Threshold signature requested", "address", s.Address.Hex(), "requestID", requestID)
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sig, err := s.backend.SignatureResult(ctx, requestID)
+		if err == nil {
+			return s.checkSignature(digest, sig)
+		}
+		if !errors.Is(err, ErrSignaturePending) {
+			log.Error("Threshold signature failed", "requestID", requestID, "error", err)
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			log.Error("Threshold signature timed out", "requestID", requestID, "error", ctx.Err())
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSignature 将 V 转换为 0 或 1，并确认签名由门限密钥产生
+func (s *MPCSigner) checkSignature(digest, sig []byte) ([]byte, error) {
+	sig, err := SignatureToV0(sig)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(*pub) != s.Address {
+		return nil, errors.New("threshold signature does not match signer address")
+	}
+	return sig, nil
+}
@@ -0,0 +1,104 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeThreshold 使用本地签名器模拟门限签名服务，每个请求需要查询 rounds 次才会完成
+type fakeThreshold struct {
+	signer *Signer
+	rounds int
+
+	mu       sync.Mutex
+	requests map[string][]byte
+	polls    map[string]int
+}
+
+func newFakeThreshold(rounds int) *fakeThreshold {
+	return &fakeThreshold{
+		signer:   TestSigner,
+		rounds:   rounds,
+		requests: make(map[string][]byte),
+		polls:    make(map[string]int),
+	}
+}
+
+func (f *fakeThreshold) GetAddress() common.Address {
+	return f.signer.Address
+}
+
+func (f *fakeThreshold) RequestSignature(ctx context.Context, digest []byte) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := fmt.Sprintf("req-%d", len(f.requests))
+	f.requests[id] = digest
+	return id, nil
+}
+
+func (f *fakeThreshold) SignatureResult(ctx context.Context, requestID string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	digest, ok := f.requests[requestID]
+	if !ok {
+		return nil, errors.New("unknown request")
+	}
+	f.polls[requestID]++
+	if f.polls[requestID] < f.rounds {
+		return nil, ErrSignaturePending
+	}
+	sig, err := f.signer.SignDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func TestMPCSigner(t *testing.T) {
+	backend := newFakeThreshold(3)
+	signer := NewMPCSigner(backend)
+	signer.PollInterval = time.Millisecond
+
+	sig, err := signer.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	expected, err := TestSigner.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sig)
+	assert.Equal(t, 3, backend.polls["req-0"])
+
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 3, &sent)
+	w, err := NewWalletWithSigner(signer, m.URL, big.NewInt(1))
+	assert.NoError(t, err)
+	_, err = w.SendTx(common.HexToAddress("0x01"), big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	_, err = w.SendLegacyTx(common.HexToAddress("0x01"), big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 2)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), sent[0])
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, from)
+	from, err = types.Sender(types.NewEIP155Signer(big.NewInt(1)), sent[1])
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, from)
+
+	// 签名迟迟不完成时受 ctx 控制
+	signer.PollInterval = 10 * time.Millisecond
+	backend.rounds = 1000
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = w.SendTxContext(ctx, common.HexToAddress("0x01"), big.NewInt(1), nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, sent, 2)
+}
@@ -0,0 +1,249 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-enols/ethrpc"
+)
+
+// Multicall3Address Multicall3 在绝大多数 EVM 链上的部署地址
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// Multicall3ABI Multicall3 aggregate3 与 getEthBalance 方法的 ABI
+const Multicall3ABI = `[
+{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"},
+{"inputs":[{"name":"addr","type":"address"}],"name":"getEthBalance","outputs":[{"name":"balance","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+var multicall3ABI, _ = abi.JSON(strings.NewReader(Multicall3ABI))
+
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type multicallCall struct {
+	contract *Contract
+	method   string
+	call     multicall3Call
+}
+
+// MulticallResult 单个调用的结果，Err 不为空时 Values 无效
+type MulticallResult struct {
+	Method string
+	Values []interface{}
+	Err    error
+}
+
+// Multicall 将多个合约只读调用合并为一次 Multicall3 aggregate3 调用
+type Multicall struct {
+	Address common.Address
+	Client  *ethrpc.EthRPC
+
+	calls []multicallCall
+}
+
+// NewMulticall 使用默认的 Multicall3 地址创建 Multicall
+func NewMulticall(client *ethrpc.EthRPC) *Multicall {
+	return &Multicall{
+		Address: Multicall3Address,
+		Client:  client,
+	}
+}
+
+// Add 添加一个允许失败的调用，失败只会体现在对应的 MulticallResult.Err 中，
+// 返回该调用在结果中的下标
+func (m *Multicall) Add(c *Contract, methodName string, args ...interface{}) (int, error) {
+	return m.add(c, methodName, true, args...)
+}
+
+// AddRequired 添加一个不允许失败的调用，该调用失败时整个 aggregate3 会回滚
+func (m *Multicall) AddRequired(c *Contract, methodName string, args ...interface{}) (int, error) {
+	return m.add(c, methodName, false, args...)
+}
+
+func (m *Multicall) add(c *Contract, methodName string, allowFailure bool, args ...interface{}) (int, error) {
+	data, err := c.EncodeData(methodName, args...)
+	if err != nil {
+		return -1, err
+	}
+	m.calls = append(m.calls, multicallCall{
+		contract: c,
+		method:   methodName,
+		call: multicall3Call{
+			Target:       c.Address,
+			AllowFailure: allowFailure,
+			CallData:     data,
+		},
+	})
+	return len(m.calls) - 1, nil
+}
+
+// AddRaw 添加一个已编码 calldata 的调用，成功时 MulticallResult.Values 只包含原始返回数据 []byte，
+// allowFailure 为 false 时该调用失败会使整个 aggregate3 回滚
+func (m *Multicall) AddRaw(target common.Address, data []byte, allowFailure bool) int {
+	m.calls = append(m.calls, multicallCall{
+		call: multicall3Call{
+			Target:       target,
+			AllowFailure: allowFailure,
+			CallData:     data,
+		},
+	})
+	return len(m.calls) - 1
+}
+
+// Len 已添加的调用数量
+func (m *Multicall) Len() int {
+	return len(m.calls)
+}
+
+// Reset 清空已添加的调用
+func (m *Multicall) Reset() {
+	m.calls = nil
+}
+
+// Call 执行所有已添加的调用，结果顺序与添加顺序一致
+func (m *Multicall) Call(tag string) ([]MulticallResult, error) {
+	return m.CallContext(context.Background(), tag)
+}
+
+// CallContext 与 Call 相同，但 eth_call 受 ctx 控制
+func (m *Multicall) CallContext(ctx context.Context, tag string) ([]MulticallResult, error) {
+	log.Debug("Executing multicall", "address", m.Address.Hex(), "calls", len(m.calls), "tag", tag)
+	if len(m.calls) == 0 {
+		return nil, nil
+	}
+	if m.Client == nil {
+		return nil, errors.New("client is nil")
+	}
+
+	calls := make([]multicall3Call, len(m.calls))
+	for i, c := range m.calls {
+		calls[i] = c.call
+	}
+	data, err := multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		log.Error("Failed to encode multicall data", "error", err)
+		return nil, err
+	}
+
+	res, err := callContext(ctx, func() (string, error) {
+		return m.Client.EthCall(ethrpc.T{
+			To:   m.Address.String(),
+			Data: hexutil.Encode(data),
+		}, tag)
+	})
+	if err != nil {
+		log.Error("Failed to call multicall", "error", err)
+		return nil, err
+	}
+
+	out, err := hexutil.Decode(res)
+	if err != nil {
+		return nil, err
+	}
+	unpacked, err := multicall3ABI.Unpack("aggregate3", out)
+	if err != nil {
+		log.Error("Failed to unpack multicall result", "error", err)
+		return nil, err
+	}
+	returnData := *abi.ConvertType(unpacked[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(returnData) != len(m.calls) {
+		return nil, fmt.Errorf("multicall returned %d results, expected %d", len(returnData), len(m.calls))
+	}
+
+	results := make([]MulticallResult, len(m.calls))
+	for i, c := range m.calls {
+		results[i].Method = c.method
+		if !returnData[i].Success {
+			results[i].Err = fmt.Errorf("call %s on %s failed", c.method, c.call.Target.Hex())
+			continue
+		}
+		if c.contract == nil {
+			results[i].Values = []interface{}{returnData[i].ReturnData}
+			continue
+		}
+		results[i].Values, results[i].Err = c.contract.ABI.Unpack(c.method, returnData[i].ReturnData)
+	}
+
+	log.Debug("Multicall executed successfully", "calls", len(results))
+	return results, nil
+}
+
+// scanBatchSize ScanBalances 每次 aggregate3 调用包含的最大调用数量
+const scanBatchSize = 500
+
+// NativeToken ScanBalances 结果中表示原生币（ETH）的地址
+var NativeToken = common.Address{}
+
+// TokenBalance 单个代币的余额，Err 不为空时 Balance 为 nil
+type TokenBalance struct {
+	Token   common.Address
+	Balance *big.Int
+	Err     error
+}
+
+// ScanBalances 通过 Multicall3 批量查询钱包的原生币与 tokens 的余额
+//
+// 结果的第一项为原生币余额（Token 为 NativeToken），之后与 tokens 的顺序一致，
+// 每 500 个调用合并为一次 eth_call，单个代币查询失败只会体现在对应的 Err 中。
+func (w *Wallet) ScanBalances(tokens []common.Address) ([]TokenBalance, error) {
+	return w.ScanBalancesContext(context.Background(), tokens)
+}
+
+// ScanBalancesContext 与 ScanBalances 相同，但查询受 ctx 控制
+func (w *Wallet) ScanBalancesContext(ctx context.Context, tokens []common.Address) ([]TokenBalance, error) {
+	log.Debug("Scanning balances", "address", w.Address.Hex(), "tokens", len(tokens))
+	mc3 := &Contract{Address: Multicall3Address, ABI: multicall3ABI}
+	erc20, err := NewERC20(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]TokenBalance, 0, len(tokens)+1)
+	mc := NewMulticall(w.rpc(ctx))
+	if _, err = mc.Add(mc3, "getEthBalance", w.Address); err != nil {
+		return nil, err
+	}
+	balances = append(balances, TokenBalance{Token: NativeToken})
+	for _, token := range tokens {
+		c := *erc20.Contract
+		c.Address = token
+		if _, err = mc.Add(&c, "balanceOf", w.Address); err != nil {
+			return nil, err
+		}
+		balances = append(balances, TokenBalance{Token: token})
+	}
+
+	for start := 0; start < len(balances); start += scanBatchSize {
+		end := min(start+scanBatchSize, len(balances))
+		batch := &Multicall{Address: mc.Address, Client: mc.Client, calls: mc.calls[start:end]}
+		results, err := batch.CallContext(ctx, "latest")
+		if err != nil {
+			return nil, err
+		}
+		for i, result := range results {
+			b := &balances[start+i]
+			if b.Err = result.Err; b.Err != nil {
+				continue
+			}
+			b.Balance, _ = result.Values[0].(*big.Int)
+		}
+	}
+
+	log.Debug("Balances scanned successfully", "address", w.Address.Hex(), "tokens", len(tokens))
+	return balances, nil
+}
@@ -0,0 +1,138 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMulticall(t *testing.T) {
+	token, err := NewERC20(common.HexToAddress("0xd0a1e359811322d97991e03f863a0c30c2cf029c"), nil)
+	assert.NoError(t, err)
+
+	m := newMockRPC(t)
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		assert.Equal(t, Multicall3Address, common.HexToAddress(tx.To))
+
+		method := multicall3ABI.Methods["aggregate3"]
+		args, err := method.Inputs.Unpack(hexutil.MustDecode(tx.Data)[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[0], new([]multicall3Call)).(*[]multicall3Call)
+
+		results := make([]multicall3Result, len(calls))
+		for i, call := range calls {
+			called, _ := token.ABI.MethodById(call.CallData[:4])
+			switch called.Name {
+			case "symbol":
+				out, _ := called.Outputs.Pack("WETH")
+				results[i] = multicall3Result{Success: true, ReturnData: out}
+			case "balanceOf":
+				out, _ := called.Outputs.Pack(big.NewInt(100))
+				results[i] = multicall3Result{Success: true, ReturnData: out}
+			}
+		}
+		out, err := method.Outputs.Pack(results)
+		return hexutil.Encode(out), err
+	})
+
+	w := newTestWallet(t, m)
+	mc := NewMulticall(w.Client)
+	_, err = mc.Add(token.Contract, "symbol")
+	assert.NoError(t, err)
+	_, err = mc.Add(token.Contract, "balanceOf", w.Address)
+	assert.NoError(t, err)
+	idx, err := mc.Add(token.Contract, "allowance", w.Address, w.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, idx)
+	_, err = mc.Add(token.Contract, "missing")
+	assert.Error(t, err)
+
+	results, err := mc.Call("latest")
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "WETH", results[0].Values[0])
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, big.NewInt(100), results[1].Values[0])
+	assert.Equal(t, "allowance", results[2].Method)
+	assert.Error(t, results[2].Err)
+	assert.Equal(t, 1, m.Calls("eth_call"))
+}
+
+func TestScanBalances(t *testing.T) {
+	failing := common.HexToAddress("0x0000000000000000000000000000000000000bad")
+	erc20, _ := abi.JSON(strings.NewReader(ERC20ABI))
+
+	m := newMockRPC(t)
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		method := multicall3ABI.Methods["aggregate3"]
+		args, err := method.Inputs.Unpack(hexutil.MustDecode(tx.Data)[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[0], new([]multicall3Call)).(*[]multicall3Call)
+
+		results := make([]multicall3Result, len(calls))
+		for i, call := range calls {
+			if call.Target == failing {
+				continue
+			}
+			if call.Target == Multicall3Address {
+				called, _ := multicall3ABI.MethodById(call.CallData[:4])
+				out, _ := called.Outputs.Pack(big.NewInt(1e18))
+				results[i] = multicall3Result{Success: true, ReturnData: out}
+				continue
+			}
+			called, _ := erc20.MethodById(call.CallData[:4])
+			out, _ := called.Outputs.Pack(new(big.Int).SetBytes(call.Target.Bytes()))
+			results[i] = multicall3Result{Success: true, ReturnData: out}
+		}
+		out, err := method.Outputs.Pack(results)
+		return hexutil.Encode(out), err
+	})
+	w := newTestWallet(t, m)
+
+	tokens := []common.Address{
+		common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		failing,
+		common.HexToAddress("0x0000000000000000000000000000000000000002"),
+	}
+	balances, err := w.ScanBalances(tokens)
+	assert.NoError(t, err)
+	assert.Len(t, balances, 4)
+	assert.Equal(t, NativeToken, balances[0].Token)
+	assert.Equal(t, big.NewInt(1e18), balances[0].Balance)
+	assert.Equal(t, big.NewInt(1), balances[1].Balance)
+	assert.Equal(t, failing, balances[2].Token)
+	assert.Error(t, balances[2].Err)
+	assert.Nil(t, balances[2].Balance)
+	assert.Equal(t, big.NewInt(2), balances[3].Balance)
+	assert.Equal(t, 1, m.Calls("eth_call"))
+
+	many := make([]common.Address, scanBatchSize)
+	for i := range many {
+		many[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	balances, err = w.ScanBalances(many)
+	assert.NoError(t, err)
+	assert.Len(t, balances, scanBatchSize+1)
+	assert.Equal(t, big.NewInt(scanBatchSize), balances[scanBatchSize].Balance)
+	assert.Equal(t, 3, m.Calls("eth_call"))
+}
@@ -0,0 +1,219 @@
+package goether
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// NonceManager 在本地为并发发送的交易分配连续的 nonce
+//
+// 首次分配时从链上同步 pending nonce，之后在本地递增；发送失败的 nonce
+// 通过 Release 归还并被优先复用。出现 nonce 错乱时可以调用 Sync 重新从链上同步。
+//
+// 通过 NewPersistentNonceManager 设置 NonceStore 后，每次分配与归还都会持久化状态；
+// 进程重启后首次同步取链上 pending nonce 与保存的 nonce 中较大的一个，避免与尚未被节点看到的交易冲突。
+type NonceManager struct {
+	mu       sync.Mutex
+	next     int
+	synced   bool
+	released []int
+
+	fetch func(ctx context.Context) (int, error)
+	store NonceStore
+	key   string
+}
+
+// NewNonceManager 创建 nonce 管理器，fetch 用于从链上获取 pending nonce
+func NewNonceManager(fetch func(ctx context.Context) (int, error)) *NonceManager {
+	return &NonceManager{fetch: fetch}
+}
+
+// NewPersistentNonceManager 创建状态保存在 store 中的 nonce 管理器，key 区分不同的账户与链
+func NewPersistentNonceManager(fetch func(ctx context.Context) (int, error), store NonceStore, key string) *NonceManager {
+	return &NonceManager{fetch: fetch, store: store, key: key}
+}
+
+// Next 分配下一个可用的 nonce
+func (n *NonceManager) Next(ctx context.Context) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.synced {
+		if err := n.restore(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(n.released) > 0 {
+		nonce := n.released[0]
+		if err := n.save(ctx, n.next, n.released[1:]); err != nil {
+			return 0, err
+		}
+		n.released = n.released[1:]
+		log.Debug("Reusing released nonce", "nonce", nonce)
+		return nonce, nil
+	}
+
+	nonce := n.next
+	if err := n.save(ctx, n.next+1, n.released); err != nil {
+		return 0, err
+	}
+	n.next++
+	return nonce, nil
+}
+
+// Peek 返回 Next 将要分配的 nonce，但不分配
+func (n *NonceManager) Peek(ctx context.Context) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.synced {
+		if err := n.restore(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if len(n.released) > 0 {
+		return n.released[0], nil
+	}
+	return n.next, nil
+}
+
+// Release 归还一个已分配但未成功发送的 nonce
+func (n *NonceManager) Release(nonce int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.synced || nonce >= n.next {
+		return
+	}
+	log.Debug("Releasing nonce", "nonce", nonce)
+	defer func() {
+		// 保存失败时本地状态仍然有效，只是重启后这个 nonce 会被跳过，需要通过 Sync 或补齐空缺恢复
+		if err := n.save(context.Background(), n.next, n.released); err != nil {
+			log.Warning("Failed to persist released nonce", "nonce", nonce, "error", err)
+		}
+	}()
+
+	if nonce == n.next-1 {
+		n.next--
+		// 归还的 nonce 与尾部相连时一并收回
+		for len(n.released) > 0 && n.released[len(n.released)-1] == n.next-1 {
+			n.released = n.released[:len(n.released)-1]
+			n.next--
+		}
+		return
+	}
+
+	i := sort.SearchInts(n.released, nonce)
+	if i < len(n.released) && n.released[i] == nonce {
+		return
+	}
+	n.released = append(n.released, 0)
+	copy(n.released[i+1:], n.released[i:])
+	n.released[i] = nonce
+}
+
+// Sync 从链上重新同步 nonce，并丢弃所有已归还的 nonce，持久化的状态也会被链上的值覆盖
+func (n *NonceManager) Sync(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sync(ctx)
+}
+
+func (n *NonceManager) sync(ctx context.Context) error {
+	nonce, err := n.fetch(ctx)
+	if err != nil {
+		log.Error("Failed to sync nonce", "error", err)
+		return err
+	}
+	log.Debug("Nonce synced from chain", "nonce", nonce)
+
+	if err = n.save(ctx, nonce, nil); err != nil {
+		return err
+	}
+	n.next = nonce
+	n.released = nil
+	n.synced = true
+	return nil
+}
+
+// restore 首次分配前同步状态，保存的 nonce 高于链上时说明仍有交易未被节点看到，继续使用保存的状态
+func (n *NonceManager) restore(ctx context.Context) error {
+	if n.store == nil {
+		return n.sync(ctx)
+	}
+	state, err := n.store.Load(ctx, n.key)
+	if err != nil {
+		log.Error("Failed to load nonce state", "key", n.key, "error", err)
+		return err
+	}
+	if state == nil {
+		return n.sync(ctx)
+	}
+	nonce, err := n.fetch(ctx)
+	if err != nil {
+		log.Error("Failed to sync nonce", "error", err)
+		return err
+	}
+	if state.Next <= nonce {
+		log.Debug("Stored nonce is behind chain, syncing", "key", n.key, "stored", state.Next, "chain", nonce)
+		return n.sync(ctx)
+	}
+
+	// 链上已经使用的 nonce 不能再复用
+	released := make([]int, 0, len(state.Released))
+	for _, r := range state.Released {
+		if r >= nonce {
+			released = append(released, r)
+		}
+	}
+	sort.Ints(released)
+	log.Info("Nonce state restored", "key", n.key, "next", state.Next, "chain", nonce, "released", len(released))
+	n.next = state.Next
+	n.released = released
+	n.synced = true
+	return nil
+}
+
+// save 将状态写入 NonceStore，未设置 NonceStore 时不做处理
+func (n *NonceManager) save(ctx context.Context, next int, released []int) error {
+	if n.store == nil {
+		return nil
+	}
+	err := n.store.Save(ctx, n.key, NonceState{
+		Next:     next,
+		Released: append([]int(nil), released...),
+	})
+	if err != nil {
+		log.Error("Failed to persist nonce state", "key", n.key, "error", err)
+	}
+	return err
+}
+
+// EnableNonceManager 为钱包启用本地 nonce 管理，启用后未指定 Nonce 的交易
+// 都从管理器分配 nonce，适用于多个 goroutine 同时使用一个钱包发送交易的场景
+func (w *Wallet) EnableNonceManager() *NonceManager {
+	if w.NonceManager == nil {
+		w.NonceManager = NewNonceManager(w.GetPendingNonceContext)
+	}
+	return w.NonceManager
+}
+
+// EnablePersistentNonceManager 与 EnableNonceManager 相同，但 nonce 状态保存在 store 中，
+// 以链ID与地址区分不同的钱包，进程重启后可以继续分配而不会与尚未确认的交易冲突
+func (w *Wallet) EnablePersistentNonceManager(store NonceStore) *NonceManager {
+	if w.NonceManager == nil {
+		key := fmt.Sprintf("%s-%s", w.ChainID.String(), w.Address.Hex())
+		w.NonceManager = NewPersistentNonceManager(w.GetPendingNonceContext, store, key)
+	}
+	return w.NonceManager
+}
+
+// releaseNonceOnError 在 *err 不为空时归还 NonceManager 分配的 nonce
+func (w *Wallet) releaseNonceOnError(nonce int, err *error) {
+	if *err != nil && w.NonceManager != nil {
+		w.NonceManager.Release(nonce)
+	}
+}
@@ -0,0 +1,153 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonceManager(t *testing.T) {
+	chainNonce := 10
+	n := NewNonceManager(func(context.Context) (int, error) { return chainNonce, nil })
+	ctx := context.Background()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces []int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := n.Next(ctx)
+			assert.NoError(t, err)
+			mu.Lock()
+			nonces = append(nonces, nonce)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Ints(nonces)
+	for i, nonce := range nonces {
+		assert.Equal(t, 10+i, nonce)
+	}
+
+	// 归还中间的 nonce 会被优先复用
+	n.Release(20)
+	n.Release(15)
+	nonce, _ := n.Peek(ctx)
+	assert.Equal(t, 15, nonce)
+	nonce, _ = n.Next(ctx)
+	assert.Equal(t, 15, nonce)
+	nonce, _ = n.Next(ctx)
+	assert.Equal(t, 20, nonce)
+	nonce, _ = n.Peek(ctx)
+	assert.Equal(t, 60, nonce)
+	nonce, _ = n.Next(ctx)
+	assert.Equal(t, 60, nonce)
+
+	// 归还尾部的 nonce 会直接回退
+	n.Release(58)
+	n.Release(60)
+	n.Release(59)
+	nonce, _ = n.Next(ctx)
+	assert.Equal(t, 58, nonce)
+
+	chainNonce = 100
+	assert.NoError(t, n.Sync(ctx))
+	nonce, _ = n.Next(ctx)
+	assert.Equal(t, 100, nonce)
+
+	failing := NewNonceManager(func(context.Context) (int, error) { return 0, errors.New("rpc down") })
+	_, err := failing.Next(ctx)
+	assert.Error(t, err)
+}
+
+func TestWalletNonceManager(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 3, &sent)
+	w := newTestWallet(t, m)
+	w.EnableNonceManager()
+
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	for i := 0; i < 3; i++ {
+		_, err := w.SendTx(to, nil, nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, m.Calls("eth_getTransactionCount"))
+	for i, tx := range sent {
+		assert.Equal(t, uint64(3+i), tx.Nonce())
+	}
+
+	// 广播失败时 nonce 被归还
+	m.On("eth_sendRawTransaction", func([]json.RawMessage) (any, error) {
+		return nil, errors.New("rpc down")
+	})
+	_, err := w.SendTx(to, nil, nil, nil)
+	assert.Error(t, err)
+	nonce, _ := w.NonceManager.Next(context.Background())
+	assert.Equal(t, 6, nonce)
+}
+
+func TestWalletNonceManagerSharedOpts(t *testing.T) {
+	m := newMockRPC(t)
+	mockSendRPC(m, 3, new([]*types.Transaction))
+	var (
+		mu     sync.Mutex
+		nonces []int
+	)
+	m.On("eth_sendRawTransaction", func(params []json.RawMessage) (any, error) {
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		nonces = append(nonces, int(tx.Nonce()))
+		mu.Unlock()
+		return tx.Hash().Hex(), nil
+	})
+	w := newTestWallet(t, m)
+	w.EnableNonceManager()
+
+	// 多个 goroutine 共享同一个 opts，每笔交易使用各自分配的 nonce
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	opts := &TxOpts{GasPrice: big.NewInt(1e9)}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := w.SendTx(to, nil, nil, opts)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	sort.Ints(nonces)
+	assert.Len(t, nonces, 10)
+	for i, nonce := range nonces {
+		assert.Equal(t, 3+i, nonce)
+	}
+	assert.Nil(t, opts.Nonce)
+	assert.Nil(t, opts.GasLimit)
+
+	// 归还的 nonce 不会留在 opts 中，重试时重新分配
+	m.On("eth_sendRawTransaction", func([]json.RawMessage) (any, error) {
+		return nil, errors.New("rpc down")
+	})
+	_, err := w.SendTx(to, nil, nil, opts)
+	assert.Error(t, err)
+	assert.Nil(t, opts.Nonce)
+}
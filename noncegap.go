@@ -0,0 +1,147 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceGapReport CheckNonceGaps 的检查结果
+type NonceGapReport struct {
+	// Latest 已上链的交易数量，即下一笔待打包交易的 nonce
+	Latest int
+	// Pending 节点返回的 pending nonce，包含交易池中可执行的交易
+	Pending int
+	// PendingTxs 交易池中可执行、等待打包的交易，Latest 之后一直存在说明手续费可能过低
+	PendingTxs map[int]common.Hash
+	// QueuedTxs 交易池中因前面的 nonce 缺失而无法执行的交易
+	QueuedTxs map[int]common.Hash
+	// Gaps 缺失的 nonce，补齐后 QueuedTxs 中的交易才能被打包
+	Gaps []int
+	// PoolAvailable 节点是否支持 txpool_contentFrom，不支持时只能比较 Latest 与 Pending，无法发现空缺
+	PoolAvailable bool
+}
+
+// HasGaps 是否存在缺失的 nonce
+func (r *NonceGapReport) HasGaps() bool {
+	return len(r.Gaps) > 0
+}
+
+// Stuck 已发送但尚未上链的 nonce，按从小到大排序
+func (r *NonceGapReport) Stuck() []int {
+	nonces := make([]int, 0, len(r.PendingTxs)+len(r.QueuedTxs))
+	for nonce := range r.PendingTxs {
+		nonces = append(nonces, nonce)
+	}
+	for nonce := range r.QueuedTxs {
+		nonces = append(nonces, nonce)
+	}
+	sort.Ints(nonces)
+	return nonces
+}
+
+// CheckNonceGaps 比较 latest 与 pending nonce 以及交易池内容，找出缺失的 nonce 与卡住的交易
+func (w *Wallet) CheckNonceGaps() (*NonceGapReport, error) {
+	return w.CheckNonceGapsContext(context.Background())
+}
+
+// CheckNonceGapsContext 与 CheckNonceGaps 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) CheckNonceGapsContext(ctx context.Context) (*NonceGapReport, error) {
+	latest, err := w.GetNonceContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := w.GetPendingNonceContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &NonceGapReport{
+		Latest:     latest,
+		Pending:    pending,
+		PendingTxs: map[int]common.Hash{},
+		QueuedTxs:  map[int]common.Hash{},
+	}
+
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("txpool_contentFrom", w.Address.Hex())
+	})
+	if err != nil {
+		// 很多公共节点不开放 txpool 命名空间，此时只返回 nonce 的比较结果
+		log.Warning("Failed to get txpool content, gaps cannot be detected", "address", w.Address.Hex(), "error", err)
+		return report, nil
+	}
+	var content struct {
+		Pending map[string]struct{ Hash common.Hash } `json:"pending"`
+		Queued  map[string]struct{ Hash common.Hash } `json:"queued"`
+	}
+	if err = json.Unmarshal(raw, &content); err != nil {
+		log.Error("Failed to decode txpool content", "error", err)
+		return nil, err
+	}
+	report.PoolAvailable = true
+
+	highest := pending - 1
+	for key, tx := range content.Pending {
+		if nonce, err := strconv.Atoi(key); err == nil && nonce >= latest {
+			report.PendingTxs[nonce] = tx.Hash
+		}
+	}
+	for key, tx := range content.Queued {
+		if nonce, err := strconv.Atoi(key); err == nil && nonce >= latest {
+			report.QueuedTxs[nonce] = tx.Hash
+			highest = max(highest, nonce)
+		}
+	}
+	for nonce := latest; nonce < highest; nonce++ {
+		_, isPending := report.PendingTxs[nonce]
+		_, isQueued := report.QueuedTxs[nonce]
+		if !isPending && !isQueued {
+			report.Gaps = append(report.Gaps, nonce)
+		}
+	}
+
+	if report.HasGaps() {
+		log.Warning("Nonce gaps detected", "address", w.Address.Hex(), "latest", latest, "pending", pending, "gaps", report.Gaps)
+	}
+	return report, nil
+}
+
+// FillNonceGaps 检查 nonce 空缺，并为每个缺失的 nonce 向自己发送一笔 0 值交易，使后面排队的交易可以被打包
+//
+// opts 中的手续费用于所有补位交易，未设置时使用建议手续费。返回检查结果与补位交易的哈希。
+func (w *Wallet) FillNonceGaps(opts *TxOpts) (*NonceGapReport, []string, error) {
+	return w.FillNonceGapsContext(context.Background(), opts)
+}
+
+// FillNonceGapsContext 与 FillNonceGaps 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) FillNonceGapsContext(ctx context.Context, opts *TxOpts) (*NonceGapReport, []string, error) {
+	report, err := w.CheckNonceGapsContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hashes []string
+	for _, nonce := range report.Gaps {
+		filler := TxOpts{}
+		if opts != nil {
+			filler = *opts
+		}
+		filler.Nonce = &nonce
+		if filler.GasLimit == nil {
+			gasLimit := 21000
+			filler.GasLimit = &gasLimit
+		}
+		log.Info("Filling nonce gap", "address", w.Address.Hex(), "nonce", nonce)
+		txHash, err := w.SendTxContext(ctx, w.Address, big.NewInt(0), nil, &filler)
+		if err != nil {
+			log.Error("Failed to fill nonce gap", "nonce", nonce, "error", err)
+			return report, hashes, err
+		}
+		hashes = append(hashes, txHash)
+	}
+	return report, hashes, nil
+}
@@ -0,0 +1,70 @@
+package goether
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckNonceGaps(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	m.On("eth_getTransactionCount", func(params []json.RawMessage) (any, error) {
+		var tag string
+		json.Unmarshal(params[1], &tag)
+		if tag == "pending" {
+			return "0x7", nil
+		}
+		return "0x5", nil
+	})
+	m.Result("txpool_contentFrom", map[string]any{
+		"pending": map[string]any{
+			"4": map[string]any{"hash": common.HexToHash("0x04")},
+			"5": map[string]any{"hash": common.HexToHash("0x05")},
+			"6": map[string]any{"hash": common.HexToHash("0x06")},
+		},
+		"queued": map[string]any{
+			"8":  map[string]any{"hash": common.HexToHash("0x08")},
+			"10": map[string]any{"hash": common.HexToHash("0x0a")},
+		},
+	})
+	w := newTestWallet(t, m)
+
+	report, err := w.CheckNonceGaps()
+	assert.NoError(t, err)
+	assert.True(t, report.PoolAvailable)
+	assert.Equal(t, 5, report.Latest)
+	assert.Equal(t, 7, report.Pending)
+	assert.Equal(t, []int{7, 9}, report.Gaps)
+	assert.Equal(t, []int{5, 6, 8, 10}, report.Stuck())
+	assert.Equal(t, common.HexToHash("0x0a"), report.QueuedTxs[10])
+
+	_, hashes, err := w.FillNonceGaps(nil)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 2)
+	assert.Len(t, sent, 2)
+	for i, nonce := range []uint64{7, 9} {
+		assert.Equal(t, nonce, sent[i].Nonce())
+		assert.Equal(t, w.Address, *sent[i].To())
+		assert.Equal(t, uint64(21000), sent[i].Gas())
+		assert.Zero(t, sent[i].Value().Sign())
+	}
+}
+
+func TestCheckNonceGapsWithoutTxpool(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 3, &sent)
+	w := newTestWallet(t, m)
+
+	report, err := w.CheckNonceGaps()
+	assert.NoError(t, err)
+	assert.False(t, report.PoolAvailable)
+	assert.False(t, report.HasGaps())
+	assert.Equal(t, 3, report.Latest)
+	assert.Equal(t, 3, report.Pending)
+}
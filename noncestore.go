@@ -0,0 +1,125 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// NonceState NonceManager 持久化的状态
+type NonceState struct {
+	// Next 下一个新分配的 nonce
+	Next int `json:"next"`
+	// Released 已归还、等待复用的 nonce
+	Released []int `json:"released,omitempty"`
+}
+
+// NonceStore NonceManager 的持久化存储，可以基于文件、Redis、SQL 等实现
+//
+// 同一个 key 只会被一个 NonceManager 使用，多个进程共享同一个账户时需要存储自身提供互斥。
+type NonceStore interface {
+	// Load 读取状态，不存在时返回 nil, nil
+	Load(ctx context.Context, key string) (*NonceState, error)
+	// Save 保存状态，返回前应确保已经写入持久化介质
+	Save(ctx context.Context, key string, state NonceState) error
+}
+
+// FileNonceStore 将每个 key 的状态以 JSON 保存在目录中的单独文件里
+type FileNonceStore struct {
+	Dir string
+}
+
+// NewFileNonceStore 创建文件存储，目录不存在时会创建
+func NewFileNonceStore(dir string) (*FileNonceStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		log.Error("Failed to create nonce store directory", "dir", dir, "error", err)
+		return nil, err
+	}
+	return &FileNonceStore{Dir: dir}, nil
+}
+
+// Load 读取 key 对应的文件
+func (s *FileNonceStore) Load(ctx context.Context, key string) (*NonceState, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := new(NonceState)
+	if err = json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Save 先写入临时文件并同步到磁盘，再重命名覆盖，进程在写入途中崩溃也不会留下损坏的文件
+func (s *FileNonceStore) Save(ctx context.Context, key string, state NonceState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.Dir, ".nonce-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(key))
+}
+
+// path key 中不能用于文件名的字符替换为下划线
+func (s *FileNonceStore) path(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == '.' {
+			return '_'
+		}
+		return r
+	}, key)
+	return filepath.Join(s.Dir, name+".json")
+}
+
+// MemoryNonceStore 保存在内存中的 NonceStore，进程退出后状态丢失，适合测试或作为其他实现的参考
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	states map[string]NonceState
+}
+
+// NewMemoryNonceStore 创建内存存储
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{states: make(map[string]NonceState)}
+}
+
+// Load 读取状态
+func (s *MemoryNonceStore) Load(ctx context.Context, key string) (*NonceState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	if !ok {
+		return nil, nil
+	}
+	state.Released = append([]int(nil), state.Released...)
+	return &state, nil
+}
+
+// Save 保存状态
+func (s *MemoryNonceStore) Save(ctx context.Context, key string, state NonceState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state.Released = append([]int(nil), state.Released...)
+	s.states[key] = state
+	return nil
+}
@@ -0,0 +1,104 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileNonceStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileNonceStore(t.TempDir())
+	assert.NoError(t, err)
+
+	state, err := store.Load(ctx, "1-0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	assert.NoError(t, store.Save(ctx, "1-0xab6c371B6c466BcF14d4003601951e5873dF2AcA", NonceState{Next: 12, Released: []int{10}}))
+	state, err = store.Load(ctx, "1-0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	assert.NoError(t, err)
+	assert.Equal(t, &NonceState{Next: 12, Released: []int{10}}, state)
+}
+
+func TestPersistentNonceManager(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileNonceStore(t.TempDir())
+	assert.NoError(t, err)
+	chainNonce := 10
+	fetch := func(context.Context) (int, error) { return chainNonce, nil }
+
+	n := NewPersistentNonceManager(fetch, store, "relayer")
+	for i := 0; i < 5; i++ {
+		nonce, err := n.Next(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 10+i, nonce)
+	}
+	n.Release(12)
+
+	// 模拟进程重启：节点只看到了一部分交易，新的管理器从保存的状态继续分配
+	chainNonce = 11
+	n = NewPersistentNonceManager(fetch, store, "relayer")
+	nonce, err := n.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, nonce)
+	nonce, err = n.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, nonce)
+
+	// 链上已经超过保存的状态时以链上为准
+	chainNonce = 20
+	n = NewPersistentNonceManager(fetch, store, "relayer")
+	nonce, err = n.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, nonce)
+
+	// Sync 以链上为准并覆盖保存的状态
+	chainNonce = 18
+	assert.NoError(t, n.Sync(ctx))
+	n = NewPersistentNonceManager(fetch, store, "relayer")
+	nonce, err = n.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 18, nonce)
+}
+
+type failingNonceStore struct {
+	*MemoryNonceStore
+}
+
+func (s failingNonceStore) Save(context.Context, string, NonceState) error {
+	return errors.New("disk full")
+}
+
+func TestPersistentNonceManagerSaveError(t *testing.T) {
+	n := NewPersistentNonceManager(func(context.Context) (int, error) { return 3, nil }, failingNonceStore{NewMemoryNonceStore()}, "relayer")
+	_, err := n.Next(context.Background())
+	assert.Error(t, err)
+}
+
+func TestWalletPersistentNonceManager(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 3, &sent)
+	store := NewMemoryNonceStore()
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+
+	w := newTestWallet(t, m)
+	w.EnablePersistentNonceManager(store)
+	for i := 0; i < 2; i++ {
+		_, err := w.SendTx(to, nil, nil, nil)
+		assert.NoError(t, err)
+	}
+
+	// 重启后节点仍返回 3，新钱包从保存的 5 继续
+	w = newTestWallet(t, m)
+	w.EnablePersistentNonceManager(store)
+	_, err := w.SendTx(to, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 3)
+	assert.Equal(t, []uint64{3, 4, 5}, []uint64{sent[0].Nonce(), sent[1].Nonce(), sent[2].Nonce()})
+}
@@ -0,0 +1,205 @@
+package goether
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// walletConfig NewWallet 的配置
+type walletConfig struct {
+	clientOptions []func(rpc *ethrpc.EthRPC)
+	client        *ethrpc.EthRPC
+	http          *http.Client
+	endpoints     []string
+	retry         *RetryPolicy
+	limiter       *RateLimiter
+	middlewares   []Middleware
+	subscriber    *Subscriber
+	explorer      *Explorer
+	version       string
+	chainID       *big.Int
+	privateRelays []*PrivateRelay
+	// transport client 实际使用的 HTTP 客户端，只有 FromWallet 时才知道
+	transport HTTPClient
+}
+
+// WalletOption NewWallet 的类型化配置项
+//
+//	wallet, err := NewWallet(prvHex, rpc, WithChainID(big.NewInt(1)), WithRetryPolicy(DefaultRetryPolicy))
+type WalletOption func(c *walletConfig)
+
+// WithChainID 直接指定链ID，不再通过 net_version 查询
+func WithChainID(chainID *big.Int) WalletOption {
+	return func(c *walletConfig) {
+		c.chainID = chainID
+		c.version = chainID.String()
+	}
+}
+
+// WithNetworkVersion 指定网络版本，链ID从中解析
+func WithNetworkVersion(version string) WalletOption {
+	return func(c *walletConfig) {
+		c.version = version
+	}
+}
+
+// WithClient 使用预先配置的 RPC 客户端，rpc 参数与其他客户端配置将被忽略
+func WithClient(client *ethrpc.EthRPC) WalletOption {
+	return func(c *walletConfig) {
+		c.client = client
+	}
+}
+
+// WithRPCOptions 创建 RPC 客户端时使用的 ethrpc 配置函数
+//
+// ethrpc 的配置函数都会替换实际发送请求的 HTTP 客户端，因此不能与 WithHTTPClient、WithEndpoints、
+// WithRetryPolicy、WithRateLimiter、WithRPCMiddleware 同时使用，否则 NewWallet 返回 ErrInvalidOption。
+// 需要代理等自定义传输时请使用 WithHTTPClient。
+func WithRPCOptions(options ...func(rpc *ethrpc.EthRPC)) WalletOption {
+	return func(c *walletConfig) {
+		c.clientOptions = append(c.clientOptions, options...)
+	}
+}
+
+// WithHTTPClient 实际发送请求的 HTTP 客户端，例如配置了代理或超时的客户端，
+// 故障转移、限流、重试与中间件都基于该客户端发送请求
+func WithHTTPClient(client *http.Client) WalletOption {
+	return func(c *walletConfig) {
+		c.http = client
+	}
+}
+
+// WithEndpoints 备用 RPC 节点，rpc 不可用时依次故障转移
+func WithEndpoints(endpoints ...string) WalletOption {
+	return func(c *walletConfig) {
+		c.endpoints = append(c.endpoints, endpoints...)
+	}
+}
+
+// WithSubscriber WebSocket 订阅客户端，用于 eth_subscribe
+func WithSubscriber(subscriber *Subscriber) WalletOption {
+	return func(c *walletConfig) {
+		c.subscriber = subscriber
+	}
+}
+
+// WithExplorer 区块浏览器客户端，用于 Wallet.History 查询交易历史
+func WithExplorer(explorer *Explorer) WalletOption {
+	return func(c *walletConfig) {
+		c.explorer = explorer
+	}
+}
+
+// WithRetryPolicy RPC 请求失败时的重试策略
+func WithRetryPolicy(policy RetryPolicy) WalletOption {
+	return func(c *walletConfig) {
+		c.retry = &policy
+	}
+}
+
+// WithRateLimiter 请求限流器，多个钱包可以共享同一个限流器
+func WithRateLimiter(limiter *RateLimiter) WalletOption {
+	return func(c *walletConfig) {
+		c.limiter = limiter
+	}
+}
+
+// WithRPCMiddleware RPC 中间件，按传入顺序由外到内包装每个请求
+func WithRPCMiddleware(middlewares ...Middleware) WalletOption {
+	return func(c *walletConfig) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithPrivateRelays 通过私有 relay 发送交易，例如 WithPrivateRelays(FlashbotsProtect(), MEVBlocker())
+func WithPrivateRelays(relays ...*PrivateRelay) WalletOption {
+	return func(c *walletConfig) {
+		c.privateRelays = append(c.privateRelays, relays...)
+	}
+}
+
+// FromWallet 从现有钱包复制链ID、客户端、订阅客户端与区块浏览器
+func FromWallet(w *Wallet) WalletOption {
+	return func(c *walletConfig) {
+		c.chainID = w.ChainID
+		c.client = w.Client
+		if w.transportOf == w.Client {
+			c.transport = w.transport
+		}
+		c.subscriber = w.Subscriber
+		c.explorer = w.Explorer
+		c.version = w.ChainID.String()
+	}
+}
+
+// walletOption 将 NewWallet 的 any 类型参数转换为 WalletOption，不支持的类型返回 ErrInvalidOption
+func walletOption(opt any) (WalletOption, error) {
+	switch data := opt.(type) {
+	case WalletOption:
+		return data, nil
+	case func(rpc *ethrpc.EthRPC):
+		return WithRPCOptions(data), nil
+	case *ethrpc.EthRPC:
+		return WithClient(data), nil
+	case *http.Client:
+		return WithHTTPClient(data), nil
+	case *Subscriber:
+		return WithSubscriber(data), nil
+	case *Explorer:
+		return WithExplorer(data), nil
+	case []string:
+		return WithEndpoints(data...), nil
+	case RetryPolicy:
+		return WithRetryPolicy(data), nil
+	case *RateLimiter:
+		return WithRateLimiter(data), nil
+	case Middleware:
+		return WithRPCMiddleware(data), nil
+	case string:
+		return WithNetworkVersion(data), nil
+	case *big.Int:
+		return WithChainID(data), nil
+	case *Wallet:
+		return FromWallet(data), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrInvalidOption, opt)
+}
+
+// validate 检查互相冲突的配置项
+func (c *walletConfig) validate() error {
+	if len(c.clientOptions) == 0 || c.client != nil {
+		return nil
+	}
+	if c.http != nil || len(c.endpoints) > 0 || c.retry != nil || c.limiter != nil || len(c.middlewares) > 0 {
+		return fmt.Errorf("%w: WithRPCOptions cannot be combined with WithHTTPClient, WithEndpoints, WithRetryPolicy, WithRateLimiter or WithRPCMiddleware", ErrInvalidOption)
+	}
+	return nil
+}
+
+// httpClient 按配置组合故障转移、限流、重试与中间件客户端，WithHTTPClient 的客户端在最内层，
+// 都没有配置时返回 nil
+func (c *walletConfig) httpClient() HTTPClient {
+	var client HTTPClient
+	if len(c.endpoints) > 0 {
+		failover := NewFailoverClient(c.endpoints, false)
+		failover.Client = c.http
+		client = failover
+	} else if c.http != nil {
+		client = c.http
+	}
+	if c.limiter != nil {
+		client = NewRateLimitClient(client, c.limiter)
+	}
+	// 每次重试都重新取得令牌
+	if c.retry != nil {
+		client = NewRetryClient(client, *c.retry)
+	}
+	// 中间件在最外层，记录的耗时包含重试与限流等待
+	if len(c.middlewares) > 0 {
+		client = NewMiddlewareClient(client, c.middlewares...)
+	}
+	return client
+}
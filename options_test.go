@@ -0,0 +1,109 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPrvHex = "8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632"
+
+func TestWalletOptions(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("net_version", "5")
+	m.Result("eth_blockNumber", "0x10")
+
+	var methods []string
+	w, err := NewWallet(testPrvHex, m.URL, WithChainID(big.NewInt(10)), WithRPCMiddleware(Observe(func(method string, _ []json.RawMessage, _ time.Duration, _ error) {
+		methods = append(methods, method)
+	})))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), w.ChainID.Int64())
+	assert.Equal(t, 0, m.Calls("net_version"))
+	_, err = w.Client.EthBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eth_blockNumber"}, methods)
+
+	w2, err := NewWallet(testPrvHex, "", FromWallet(w))
+	assert.NoError(t, err)
+	assert.Same(t, w.Client, w2.Client)
+	assert.Equal(t, w.ChainID, w2.ChainID)
+
+	client := ethrpc.New(m.URL)
+	w3, err := NewWallet(testPrvHex, "", WithClient(client))
+	assert.NoError(t, err)
+	assert.Same(t, client, w3.Client)
+	assert.Equal(t, int64(5), w3.ChainID.Int64())
+
+	// 旧的 any 参数依然可用，可以与 WalletOption 混合使用
+	w4, err := NewWallet(testPrvHex, m.URL, "7", WithRetryPolicy(DefaultRetryPolicy))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), w4.ChainID.Int64())
+}
+
+func TestWalletOptionUnsupported(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("net_version", "1")
+
+	_, err := NewWallet(testPrvHex, m.URL, 42)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "int")
+	assert.Equal(t, 0, m.Calls("net_version"))
+}
+
+// countingTransport 统计经过的请求数
+type countingTransport struct {
+	n atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.n.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("net_version", "1")
+	m.Result("eth_blockNumber", "0x10")
+
+	transport := &countingTransport{}
+	w, err := NewWallet(testPrvHex, m.URL, WithHTTPClient(&http.Client{Transport: transport}))
+	assert.NoError(t, err)
+	_, err = w.Client.EthBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), transport.n.Load())
+
+	// 故障转移与重试同样通过该客户端发送请求
+	transport = &countingTransport{}
+	w, err = NewWallet(testPrvHex, "http://127.0.0.1:1", WithEndpoints(m.URL), WithRetryPolicy(DefaultRetryPolicy), WithHTTPClient(&http.Client{Transport: transport}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), w.ChainID.Int64())
+	assert.Equal(t, int32(2), transport.n.Load())
+}
+
+func TestWithRPCOptionsConflict(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("net_version", "1")
+
+	for _, opt := range []WalletOption{
+		WithHTTPClient(http.DefaultClient),
+		WithEndpoints(m.URL),
+		WithRetryPolicy(DefaultRetryPolicy),
+		WithRateLimiter(NewRateLimiter(10, 10)),
+		WithRPCMiddleware(Observe(func(string, []json.RawMessage, time.Duration, error) {})),
+	} {
+		_, err := NewWallet(testPrvHex, m.URL, WithRPCOptions(ethrpc.WithHttpProxy("http://127.0.0.1:7890")), opt)
+		assert.ErrorIs(t, err, ErrInvalidOption)
+	}
+	assert.Equal(t, 0, m.Calls("net_version"))
+
+	// 使用 WithClient 时其他客户端配置本来就会被忽略
+	_, err := NewWallet(testPrvHex, "", WithRPCOptions(ethrpc.WithHttpProxy("http://127.0.0.1:7890")), WithRetryPolicy(DefaultRetryPolicy), WithClient(ethrpc.New(m.URL)))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WalletPool 将交易轮流分配给多个工作钱包发送，适用于空投、中继等需要高吞吐量的场景
+//
+// 每个工作钱包都启用 NonceManager 在本地分配 nonce，同一个钱包上的并发发送不会冲突；
+// Treasury 为资金钱包，通过 Rebalance 为余额不足的工作钱包补充原生币。
+type WalletPool struct {
+	Treasury *Wallet
+
+	mu      sync.Mutex
+	wallets []*Wallet
+	next    int
+}
+
+// NewWalletPool 创建钱包池，treasury 可以为 nil（此时不能调用 Rebalance）
+func NewWalletPool(treasury *Wallet, wallets ...*Wallet) *WalletPool {
+	p := &WalletPool{Treasury: treasury}
+	if treasury != nil {
+		treasury.EnableNonceManager()
+	}
+	for _, w := range wallets {
+		p.Add(w)
+	}
+	return p
+}
+
+// Add 添加工作钱包并为其启用 NonceManager
+func (p *WalletPool) Add(w *Wallet) {
+	w.EnableNonceManager()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.wallets = append(p.wallets, w)
+	log.Debug("Wallet added to pool", "address", w.Address.Hex(), "size", len(p.wallets))
+}
+
+// Wallets 池中的所有工作钱包
+func (p *WalletPool) Wallets() []*Wallet {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Wallet(nil), p.wallets...)
+}
+
+// Len 工作钱包数量
+func (p *WalletPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.wallets)
+}
+
+// Next 按轮询顺序返回下一个工作钱包，池为空时返回 nil
+func (p *WalletPool) Next() *Wallet {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.wallets) == 0 {
+		return nil
+	}
+	w := p.wallets[p.next%len(p.wallets)]
+	p.next++
+	return w
+}
+
+// SendTx 使用下一个工作钱包发送 EIP-1559 交易
+//
+// opts 会被复制后使用，可以在多次调用间共享；其中的 Nonce 会被忽略，由工作钱包的 NonceManager 分配。
+func (p *WalletPool) SendTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	return p.SendTxContext(context.Background(), to, amount, data, opts)
+}
+
+// SendTxContext 与 SendTx 相同，但所有 RPC 调用都受 ctx 控制
+func (p *WalletPool) SendTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	w := p.Next()
+	if w == nil {
+		return "", ErrWalletNil
+	}
+	if opts != nil {
+		copied := *opts
+		copied.Nonce = nil
+		opts = &copied
+	}
+	log.Debug("Sending transaction from pool", "from", w.Address.Hex(), "to", to.Hex())
+	return w.SendTxContext(ctx, to, amount, data, opts)
+}
+
+// Rebalance 检查所有工作钱包的原生币余额，低于 min 的钱包由 Treasury 补足到 target，返回补充交易的哈希
+//
+// 某个钱包补充失败时继续处理其余钱包，返回的错误包含所有失败原因。
+func (p *WalletPool) Rebalance(min, target *big.Int) ([]string, error) {
+	return p.RebalanceContext(context.Background(), min, target)
+}
+
+// RebalanceContext 与 Rebalance 相同，但查询与交易发送都受 ctx 控制
+func (p *WalletPool) RebalanceContext(ctx context.Context, min, target *big.Int) ([]string, error) {
+	if p.Treasury == nil {
+		return nil, ErrWalletNil
+	}
+	var (
+		hashes []string
+		errs   []error
+	)
+	for _, w := range p.Wallets() {
+		balance, err := w.GetBalanceContext(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if balance.Cmp(min) >= 0 {
+			continue
+		}
+		amount := new(big.Int).Sub(target, &balance)
+		if amount.Sign() <= 0 {
+			continue
+		}
+		log.Info("Funding pool wallet", "address", w.Address.Hex(), "balance", balance.String(), "amount", amount.String())
+		txHash, err := p.Treasury.SendTxContext(ctx, w.Address, amount, nil, nil)
+		if err != nil {
+			log.Error("Failed to fund pool wallet", "address", w.Address.Hex(), "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		hashes = append(hashes, txHash)
+	}
+	return hashes, errors.Join(errs...)
+}
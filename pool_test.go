@@ -0,0 +1,75 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletPool(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 7, &sent)
+	treasury := newTestWallet(t, m)
+
+	var workers []*Wallet
+	for i := 0; i < 3; i++ {
+		w, _, err := NewRandomWallet(m.URL, big.NewInt(1))
+		assert.NoError(t, err)
+		workers = append(workers, w)
+	}
+	pool := NewWalletPool(treasury, workers...)
+	assert.Equal(t, 3, pool.Len())
+
+	opts := &TxOpts{}
+	for i := 0; i < 6; i++ {
+		_, err := pool.SendTx(common.HexToAddress("0x01"), big.NewInt(1), nil, opts)
+		assert.NoError(t, err)
+	}
+	assert.Nil(t, opts.Nonce)
+	assert.Len(t, sent, 6)
+
+	// 轮流使用工作钱包，每个钱包的 nonce 在本地递增
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	nonces := map[common.Address][]uint64{}
+	for i, tx := range sent {
+		from, err := types.Sender(signer, tx)
+		assert.NoError(t, err)
+		assert.Equal(t, workers[i%3].Address, from)
+		nonces[from] = append(nonces[from], tx.Nonce())
+	}
+	for _, w := range workers {
+		assert.Equal(t, []uint64{7, 8}, nonces[w.Address])
+	}
+
+	m.On("eth_getBalance", func(params []json.RawMessage) (any, error) {
+		var account string
+		json.Unmarshal(params[0], &account)
+		if common.HexToAddress(account) == workers[0].Address {
+			return "0x3e8", nil
+		}
+		return "0x64", nil
+	})
+	sent = nil
+	hashes, err := pool.Rebalance(big.NewInt(500), big.NewInt(1000))
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 2)
+	assert.Len(t, sent, 2)
+	for i, tx := range sent {
+		from, err := types.Sender(signer, tx)
+		assert.NoError(t, err)
+		assert.Equal(t, treasury.Address, from)
+		assert.Equal(t, workers[i+1].Address, *tx.To())
+		assert.Equal(t, big.NewInt(900), tx.Value())
+		assert.Equal(t, uint64(7+i), tx.Nonce())
+	}
+
+	_, err = NewWalletPool(nil).SendTx(common.HexToAddress("0x01"), big.NewInt(1), nil, nil)
+	assert.ErrorIs(t, err, ErrWalletNil)
+	_, err = NewWalletPool(nil, workers...).Rebalance(big.NewInt(1), big.NewInt(2))
+	assert.ErrorIs(t, err, ErrWalletNil)
+}
@@ -0,0 +1,129 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxPreview 交易发送前的模拟结果
+type TxPreview struct {
+	Success    bool
+	ReturnData []byte
+	GasUsed    uint64
+	Logs       []types.Log
+	// Err 执行失败的原因，回滚时为 *RevertError
+	Err error
+	// AssetChanges 发送方与所有交易对手的原生币与 ERC-20 余额净变化，不包含手续费
+	AssetChanges []AssetChange
+}
+
+// Changes 返回 account 的资产净变化，键为代币地址，原生币为 NativeTokenAddress
+func (p *TxPreview) Changes(account common.Address) map[common.Address]*big.Int {
+	return (&SimulationResult{AssetChanges: p.AssetChanges}).Changes(account)
+}
+
+// Preview 在发送前模拟交易，报告发送方与交易对手的原生币和 ERC-20 余额变化
+//
+// 优先使用 eth_simulateV1，节点不支持时退回 debug_traceCall 的 callTracer，
+// 两者都不支持时返回错误。交易失败不会返回错误，而是记录在 TxPreview.Err 中。
+func (w *Wallet) Preview(to common.Address, amount *big.Int, data []byte) (*TxPreview, error) {
+	return w.PreviewContext(context.Background(), to, amount, data)
+}
+
+// PreviewContext 与 Preview 相同，但请求受 ctx 控制
+func (w *Wallet) PreviewContext(ctx context.Context, to common.Address, amount *big.Int, data []byte) (*TxPreview, error) {
+	sim := NewSimulator(w)
+	sim.Blocks = []SimBlock{{Calls: []SimCall{{To: &to, Value: amount, Data: data}}}}
+	result, err := sim.RunContext(ctx)
+	if err == nil && len(result.Blocks) == 1 && len(result.Blocks[0].Calls) == 1 {
+		call := result.Blocks[0].Calls[0]
+		return &TxPreview{
+			Success:      call.Success,
+			ReturnData:   call.ReturnData,
+			GasUsed:      call.GasUsed,
+			Logs:         call.Logs,
+			Err:          call.Err,
+			AssetChanges: result.AssetChanges,
+		}, nil
+	}
+	if err != nil && !isMethodNotFound(err) {
+		return nil, err
+	}
+
+	log.Debug("eth_simulateV1 unavailable, previewing with debug_traceCall", "to", to.Hex())
+	frame, err := w.TraceCallContext(ctx, to, amount, data, "latest")
+	if err != nil {
+		return nil, err
+	}
+	return previewFromTrace(frame), nil
+}
+
+// PreviewMethod 使用合约 ABI 编码调用并模拟，value 为附带的转账金额，可以为 nil
+func (c *Contract) PreviewMethod(method string, value *big.Int, args ...any) (*TxPreview, error) {
+	return c.PreviewMethodContext(context.Background(), method, value, args...)
+}
+
+// PreviewMethodContext 与 PreviewMethod 相同，但请求受 ctx 控制
+func (c *Contract) PreviewMethodContext(ctx context.Context, method string, value *big.Int, args ...any) (*TxPreview, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	data, err := c.EncodeData(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wallet.PreviewContext(ctx, c.Address, value, data)
+}
+
+// previewFromTrace 根据调用树生成预览，失败调用及其子调用的转账与日志都已回滚，不计入
+func previewFromTrace(frame *CallFrame) *TxPreview {
+	call := SimulatedCall{Success: !frame.Failed(), ReturnData: frame.Output, GasUsed: frame.GasUsed, Err: frame.Err()}
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		if f.Failed() {
+			return false
+		}
+		// DELEGATECALL 与 STATICCALL 的 value 不是实际转账
+		if f.Value != nil && f.Value.Sign() > 0 && f.Type != "DELEGATECALL" && f.Type != "STATICCALL" {
+			call.Logs = append(call.Logs, types.Log{
+				Address: NativeTokenAddress,
+				Topics:  []common.Hash{transferTopic, common.BytesToHash(f.From.Bytes()), common.BytesToHash(f.To.Bytes())},
+				Data:    common.BigToHash(f.Value).Bytes(),
+			})
+		}
+		for _, l := range f.Logs {
+			call.Logs = append(call.Logs, types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+		}
+		return true
+	})
+	return &TxPreview{
+		Success:      call.Success,
+		ReturnData:   call.ReturnData,
+		GasUsed:      call.GasUsed,
+		Logs:         call.Logs,
+		Err:          call.Err,
+		AssetChanges: assetChanges([]SimulatedBlock{{Calls: []SimulatedCall{call}}}),
+	}
+}
+
+// isMethodNotFound 判断节点是否不支持请求的方法
+func isMethodNotFound(err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.Code == -32601 {
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, s := range []string{"method not found", "does not exist", "not supported", "not available"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
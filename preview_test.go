@@ -0,0 +1,96 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreview(t *testing.T) {
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	router := common.HexToAddress("0xa1")
+	native := &types.Log{
+		Address: NativeTokenAddress,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(w.Address.Bytes()), common.BytesToHash(router.Bytes())},
+		Data:    common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+	received := &types.Log{
+		Address: eventToken,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(router.Bytes()), common.BytesToHash(w.Address.Bytes())},
+		Data:    common.BigToHash(big.NewInt(7)).Bytes(),
+	}
+	m.On("eth_simulateV1", func(params []json.RawMessage) (any, error) {
+		var payload struct {
+			BlockStateCalls []struct {
+				Calls []map[string]string `json:"calls"`
+			} `json:"blockStateCalls"`
+			TraceTransfers bool `json:"traceTransfers"`
+		}
+		assert.NoError(t, json.Unmarshal(params[0], &payload))
+		assert.True(t, payload.TraceTransfers)
+		call := payload.BlockStateCalls[0].Calls[0]
+		assert.Equal(t, router, common.HexToAddress(call["to"]))
+		assert.Equal(t, "0x64", call["value"])
+		return []map[string]any{{
+			"number": "0x10", "hash": common.HexToHash("0x10"), "timestamp": "0x64", "gasUsed": "0x9000",
+			"calls": []map[string]any{{"status": "0x1", "returnData": "0x", "gasUsed": "0x9000", "logs": []*types.Log{native, received}}},
+		}}, nil
+	})
+
+	preview, err := w.Preview(router, big.NewInt(100), []byte{0x7f, 0xf3, 0x6a, 0xb5})
+	assert.NoError(t, err)
+	assert.True(t, preview.Success)
+	assert.Equal(t, uint64(0x9000), preview.GasUsed)
+	assert.Len(t, preview.Logs, 2)
+	assert.Equal(t, map[common.Address]*big.Int{NativeTokenAddress: big.NewInt(-100), eventToken: big.NewInt(7)}, preview.Changes(w.Address))
+	assert.Equal(t, map[common.Address]*big.Int{NativeTokenAddress: big.NewInt(100), eventToken: big.NewInt(-7)}, preview.Changes(router))
+}
+
+func TestPreviewTraceFallback(t *testing.T) {
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	router := common.HexToAddress("0xa1")
+	pair := common.HexToAddress("0xa2")
+	transfer := func(from, to common.Address, amount int64) map[string]any {
+		return map[string]any{
+			"address": eventToken,
+			"topics":  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			"data":    hexutil.Encode(common.BigToHash(big.NewInt(amount)).Bytes()),
+		}
+	}
+	// router 收到 100 wei 后转给 pair，pair 转出代币；被 try/catch 捕获的失败调用不计入
+	m.On("debug_traceCall", func(params []json.RawMessage) (any, error) {
+		return map[string]any{
+			"type": "CALL", "from": w.Address, "to": router, "value": "0x64", "gas": "0x30000", "gasUsed": "0x9000", "input": "0x7ff36ab5", "output": "0x",
+			"calls": []map[string]any{
+				{"type": "CALL", "from": router, "to": pair, "value": "0x64", "gas": "0x20000", "gasUsed": "0x5000", "input": "0x022c0d9f",
+					"logs": []map[string]any{transfer(pair, w.Address, 7)}},
+				{"type": "DELEGATECALL", "from": router, "to": pair, "value": "0x64", "gas": "0x1000", "gasUsed": "0x100", "input": "0x"},
+				{"type": "CALL", "from": router, "to": pair, "value": "0x1", "gas": "0x1000", "gasUsed": "0x1000", "input": "0x", "error": "out of gas",
+					"logs": []map[string]any{transfer(pair, router, 1)}},
+			},
+		}, nil
+	})
+
+	preview, err := w.Preview(router, big.NewInt(100), []byte{0x7f, 0xf3, 0x6a, 0xb5})
+	assert.NoError(t, err)
+	assert.True(t, preview.Success)
+	assert.Nil(t, preview.Err)
+	assert.Len(t, preview.Logs, 3)
+	assert.Equal(t, map[common.Address]*big.Int{NativeTokenAddress: big.NewInt(-100), eventToken: big.NewInt(7)}, preview.Changes(w.Address))
+	assert.Equal(t, map[common.Address]*big.Int{}, preview.Changes(router))
+	assert.Equal(t, map[common.Address]*big.Int{NativeTokenAddress: big.NewInt(100), eventToken: big.NewInt(-7)}, preview.Changes(pair))
+
+	_, err = (&Contract{}).PreviewMethod("transfer", nil)
+	assert.ErrorIs(t, err, ErrWalletNil)
+
+	// 两种方式都不支持时返回错误
+	_, err = newTestWallet(t, newMockRPC(t)).Preview(router, nil, nil)
+	assert.Error(t, err)
+}
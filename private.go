@@ -0,0 +1,303 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/ethrpc"
+)
+
+// ErrPrivateTxDropped 私有 relay 报告交易已失败或被取消，交易不会再被打包
+var ErrPrivateTxDropped = errors.New("private transaction dropped by relay")
+
+// PrivateRelay 私有交易 relay，交易直接发送给区块构建者而不进入公共交易池，避免被抢跑或夹子攻击
+type PrivateRelay struct {
+	Name string
+	URL  string
+	// Method 提交交易的 JSON-RPC 方法，为空时使用 eth_sendRawTransaction
+	Method string
+	// Params 根据已签名交易的十六进制编码构造请求参数，为 nil 时参数为 [raw]
+	Params func(raw string) []any
+	// Headers 附加的请求头，例如 bloXroute 的 Authorization
+	Headers map[string]string
+	// StatusURL 查询交易状态的地址，%s 替换为交易哈希；为空时只能通过回执判断交易是否被打包
+	StatusURL string
+	// Client 发送请求的客户端，为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// FlashbotsProtect Flashbots Protect RPC，交易会共享给所有注册的构建者
+func FlashbotsProtect() *PrivateRelay {
+	return &PrivateRelay{
+		Name:      "flashbots",
+		URL:       "https://rpc.flashbots.net/fast",
+		StatusURL: "https://protect.flashbots.net/tx/%s",
+	}
+}
+
+// MEVBlocker MEV Blocker RPC，交易被用于回跑时返还部分收益
+func MEVBlocker() *PrivateRelay {
+	return &PrivateRelay{
+		Name: "mevblocker",
+		URL:  "https://rpc.mevblocker.io",
+	}
+}
+
+// BloXroute bloXroute 私有交易接口，authHeader 为账户的 Authorization 请求头
+func BloXroute(authHeader string) *PrivateRelay {
+	return &PrivateRelay{
+		Name:   "bloxroute",
+		URL:    "https://api.blxrbdn.com",
+		Method: "blxr_private_tx",
+		Params: func(raw string) []any {
+			return []any{map[string]any{"transaction": strings.TrimPrefix(raw, "0x")}}
+		},
+		Headers: map[string]string{"Authorization": authHeader},
+	}
+}
+
+// Post 实现 HTTPClient 接口，为请求附加 Headers
+func (r *PrivateRelay) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return r.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext 与 Post 相同，但请求受 ctx 控制
+func (r *PrivateRelay) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range r.Headers {
+		req.Header.Set(key, value)
+	}
+	client := http.DefaultClient
+	if r.Client != nil {
+		client = r.Client
+	}
+	return client.Do(req)
+}
+
+// Send 将已签名交易提交给 relay，返回 relay 确认的交易哈希
+//
+// 各 relay 的返回格式不一致：交易哈希字符串、包含 txHash 的对象或 null，哈希可能缺少 0x 前缀，
+// 无法解析或与本地计算的哈希不一致时以本地哈希为准。
+func (r *PrivateRelay) Send(ctx context.Context, tx *types.Transaction) (string, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	method := r.Method
+	if method == "" {
+		method = "eth_sendRawTransaction"
+	}
+	var params []any
+	if r.Params != nil {
+		params = r.Params(hexutil.Encode(raw))
+	} else {
+		params = []any{hexutil.Encode(raw)}
+	}
+	req := &RPCRequest{Method: method}
+	for _, param := range params {
+		b, err := json.Marshal(param)
+		if err != nil {
+			return "", err
+		}
+		req.Params = append(req.Params, b)
+	}
+
+	result, err := callContext(ctx, func() (json.RawMessage, error) {
+		return postRPC(ctx, r, r.URL, "application/json", json.RawMessage("1"), req)
+	})
+	txHash := tx.Hash().Hex()
+	if err != nil {
+		// 重复提交时 relay 可能返回 already known
+		if strings.Contains(strings.ToLower(err.Error()), "already known") {
+			return txHash, nil
+		}
+		return "", err
+	}
+	if returned := parseRelayTxHash(result); returned != "" && !strings.EqualFold(returned, txHash) {
+		log.Warning("Private relay returned unexpected transaction hash", "relay", r.Name, "expected", txHash, "returned", returned)
+	}
+	return txHash, nil
+}
+
+// parseRelayTxHash 从 relay 的返回中取出交易哈希，无法识别时返回空字符串
+func parseRelayTxHash(result json.RawMessage) string {
+	var hash string
+	if err := json.Unmarshal(result, &hash); err != nil {
+		var obj struct {
+			TxHash  string `json:"txHash"`
+			TxHash2 string `json:"tx_hash"`
+			Hash    string `json:"hash"`
+		}
+		if json.Unmarshal(result, &obj) != nil {
+			return ""
+		}
+		hash = obj.TxHash
+		if hash == "" {
+			hash = obj.TxHash2
+		}
+		if hash == "" {
+			hash = obj.Hash
+		}
+	}
+	if hash == "" {
+		return ""
+	}
+	if !strings.HasPrefix(hash, "0x") {
+		hash = "0x" + hash
+	}
+	if b, err := hexutil.Decode(hash); err != nil || len(b) != 32 {
+		return ""
+	}
+	return hash
+}
+
+// PrivateTxStatus relay 报告的私有交易状态
+type PrivateTxStatus string
+
+const (
+	PrivateTxPending   PrivateTxStatus = "PENDING"
+	PrivateTxIncluded  PrivateTxStatus = "INCLUDED"
+	PrivateTxFailed    PrivateTxStatus = "FAILED"
+	PrivateTxCancelled PrivateTxStatus = "CANCELLED"
+	PrivateTxUnknown   PrivateTxStatus = "UNKNOWN"
+)
+
+// Dropped 交易是否已被 relay 放弃
+func (s PrivateTxStatus) Dropped() bool {
+	return s == PrivateTxFailed || s == PrivateTxCancelled
+}
+
+// Status 通过 StatusURL 查询交易状态，未配置 StatusURL 时返回 PrivateTxUnknown
+func (r *PrivateRelay) Status(ctx context.Context, txHash string) (PrivateTxStatus, error) {
+	if r.StatusURL == "" {
+		return PrivateTxUnknown, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(r.StatusURL, txHash), nil)
+	if err != nil {
+		return "", err
+	}
+	client := http.DefaultClient
+	if r.Client != nil {
+		client = r.Client
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s status returned %s", r.Name, resp.Status)
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return "", err
+	}
+	if status.Status == "" {
+		return PrivateTxUnknown, nil
+	}
+	return PrivateTxStatus(strings.ToUpper(status.Status)), nil
+}
+
+// sendPrivate 依次将交易提交给所有私有 relay，至少一个 relay 接收即视为成功
+func (w *Wallet) sendPrivate(ctx context.Context, raw []byte) (string, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return "", err
+	}
+	var (
+		txHash string
+		errs   []error
+	)
+	for _, relay := range w.PrivateRelays {
+		hash, err := relay.Send(ctx, tx)
+		if err != nil {
+			log.Warning("Private relay rejected transaction", "relay", relay.Name, "txHash", tx.Hash().Hex(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", relay.Name, err))
+			continue
+		}
+		log.Debug("Transaction sent to private relay", "relay", relay.Name, "txHash", hash)
+		txHash = hash
+	}
+	if txHash == "" {
+		return "", errors.Join(errs...)
+	}
+	return txHash, nil
+}
+
+// WaitForPrivateTx 等待通过私有 relay 发送的交易被打包并达到 confirmations 个确认，最多等待 timeout
+//
+// 私有交易不会出现在公共交易池中，除轮询回执外还会查询 relay 的交易状态，
+// 所有 relay 都提供状态并报告交易失败或被取消时返回 ErrPrivateTxDropped，
+// 任一 relay 没有 StatusURL 时只能等待回执直到超时。
+func (w *Wallet) WaitForPrivateTx(txHash string, confirmations int, timeout time.Duration) (*ethrpc.TransactionReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return w.WaitForPrivateTxContext(ctx, txHash, confirmations)
+}
+
+// WaitForPrivateTxContext 与 WaitForPrivateTx 相同，超时由 ctx 控制
+func (w *Wallet) WaitForPrivateTxContext(ctx context.Context, txHash string, confirmations int) (*ethrpc.TransactionReceipt, error) {
+	log.Debug("Waiting for private transaction", "txHash", txHash, "confirmations", confirmations)
+
+	ticker := time.NewTicker(ReceiptPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		receipt, err := w.checkReceipt(ctx, txHash, confirmations)
+		if receipt == nil && isTransientError(err) {
+			if ctx.Err() == nil {
+				log.Debug("Failed to check transaction receipt, retrying", "txHash", txHash, "error", err)
+				lastErr = err
+			}
+		} else if receipt != nil || err != nil {
+			return receipt, err
+		}
+		if status, dropped := w.privateTxDropped(ctx, txHash); dropped {
+			log.Warning("Private transaction dropped", "txHash", txHash, "status", status)
+			return nil, fmt.Errorf("%w: %s is %s", ErrPrivateTxDropped, txHash, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Error("Timeout waiting for private transaction", "txHash", txHash, "error", ctx.Err(), "lastError", lastErr)
+			return nil, waitTimeoutError(txHash, ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// privateTxDropped 所有 relay 都报告交易被放弃时返回 true。
+// 没有 StatusURL 的 relay 无法确认交易状态，可能仍会打包交易，此时返回 false
+func (w *Wallet) privateTxDropped(ctx context.Context, txHash string) (PrivateTxStatus, bool) {
+	var last PrivateTxStatus
+	for _, relay := range w.PrivateRelays {
+		if relay.StatusURL == "" {
+			return "", false
+		}
+		status, err := relay.Status(ctx, txHash)
+		if err != nil {
+			log.Debug("Failed to get private transaction status", "relay", relay.Name, "txHash", txHash, "error", err)
+			return "", false
+		}
+		if !status.Dropped() {
+			return status, false
+		}
+		last = status
+	}
+	return last, last != ""
+}
@@ -0,0 +1,155 @@
+package goether
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendTxPrivate(t *testing.T) {
+	m := newMockRPC(t)
+	var public []*types.Transaction
+	mockSendRPC(m, 3, &public)
+	w := newTestWallet(t, m)
+
+	// Flashbots Protect 风格：返回交易哈希字符串
+	protect := newMockRPC(t)
+	var private []string
+	protect.On("eth_sendRawTransaction", func(params []json.RawMessage) (any, error) {
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		private = append(private, raw)
+		tx := new(types.Transaction)
+		tx.UnmarshalBinary(hexutil.MustDecode(raw))
+		return tx.Hash().Hex(), nil
+	})
+
+	// bloXroute 风格：自定义方法、不带 0x 的参数、对象形式且不带 0x 的哈希
+	var bloxrouteCalls int
+	bloxroute := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		bloxrouteCalls++
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		var req mockRequest
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &req))
+		assert.Equal(t, "blxr_private_tx", req.Method)
+		var params struct {
+			Transaction string `json:"transaction"`
+		}
+		assert.NoError(t, json.Unmarshal(req.Params[0], &params))
+		assert.False(t, strings.HasPrefix(params.Transaction, "0x"))
+		tx := new(types.Transaction)
+		assert.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode("0x"+params.Transaction)))
+		json.NewEncoder(rw).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]string{"txHash": tx.Hash().Hex()[2:]}})
+	}))
+	defer bloxroute.Close()
+
+	relay := BloXroute("secret")
+	relay.URL = bloxroute.URL
+	w.PrivateRelays = []*PrivateRelay{{Name: "protect", URL: protect.URL}, relay}
+
+	to := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	txHash, err := w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, public)
+	assert.Len(t, private, 1)
+	assert.Equal(t, 1, bloxrouteCalls)
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode(private[0])))
+	assert.Equal(t, tx.Hash().Hex(), txHash)
+
+	// 单笔交易关闭私有发送
+	txHash, err = w.SendTx(to, big.NewInt(1), nil, &TxOpts{Public: true})
+	assert.NoError(t, err)
+	assert.Len(t, public, 1)
+	assert.Equal(t, public[0].Hash().Hex(), txHash)
+	assert.Len(t, private, 1)
+
+	// 一个 relay 拒绝时其他 relay 接收即成功
+	protect.On("eth_sendRawTransaction", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32000, Message: "nonce too low"}
+	})
+	_, err = w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bloxrouteCalls)
+
+	w.PrivateRelays = w.PrivateRelays[:1]
+	_, err = w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.ErrorContains(t, err, "protect")
+	assert.ErrorContains(t, err, "nonce too low")
+
+	// 重复提交视为成功
+	protect.On("eth_sendRawTransaction", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32000, Message: "already known"}
+	})
+	_, err = w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestParseRelayTxHash(t *testing.T) {
+	hash := common.HexToHash("0x01").Hex()
+	assert.Equal(t, hash, parseRelayTxHash(json.RawMessage(`"`+hash+`"`)))
+	assert.Equal(t, hash, parseRelayTxHash(json.RawMessage(`"`+hash[2:]+`"`)))
+	assert.Equal(t, hash, parseRelayTxHash(json.RawMessage(`{"txHash":"`+hash+`"}`)))
+	assert.Equal(t, hash, parseRelayTxHash(json.RawMessage(`{"tx_hash":"`+hash[2:]+`"}`)))
+	assert.Equal(t, "", parseRelayTxHash(json.RawMessage(`null`)))
+	assert.Equal(t, "", parseRelayTxHash(json.RawMessage(`true`)))
+	assert.Equal(t, "", parseRelayTxHash(json.RawMessage(`"ok"`)))
+}
+
+func TestWaitForPrivateTx(t *testing.T) {
+	ReceiptPollInterval = 10 * time.Millisecond
+
+	status := "PENDING"
+	statusServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tx/0x01", r.URL.Path)
+		json.NewEncoder(rw).Encode(map[string]any{"status": status, "hash": "0x01"})
+	}))
+	defer statusServer.Close()
+
+	m := newMockRPC(t)
+	polls := 0
+	m.On("eth_getTransactionReceipt", func([]json.RawMessage) (any, error) {
+		polls++
+		if polls < 3 {
+			return nil, nil
+		}
+		return testReceipt("0x1"), nil
+	})
+	w := newTestWallet(t, m)
+	relay := FlashbotsProtect()
+	relay.StatusURL = statusServer.URL + "/tx/%s"
+	w.PrivateRelays = []*PrivateRelay{MEVBlocker(), relay}
+
+	receipt, err := w.WaitForPrivateTx("0x01", 1, time.Second)
+	assert.NoError(t, err)
+	assert.NotNil(t, receipt)
+
+	// MEV Blocker 没有状态接口，仍可能打包交易，只能等待回执
+	m.Result("eth_getTransactionReceipt", nil)
+	status = "failed"
+	_, err = w.WaitForPrivateTx("0x01", 1, 50*time.Millisecond)
+	assert.False(t, errors.Is(err, ErrPrivateTxDropped))
+	assert.ErrorContains(t, err, "timeout")
+
+	w.PrivateRelays = []*PrivateRelay{relay}
+	_, err = w.WaitForPrivateTx("0x01", 1, time.Second)
+	assert.True(t, errors.Is(err, ErrPrivateTxDropped))
+	assert.ErrorContains(t, err, "FAILED")
+
+	// 没有 relay 提供状态时只能等待回执
+	w.PrivateRelays = []*PrivateRelay{MEVBlocker()}
+	_, err = w.WaitForPrivateTx("0x01", 1, 50*time.Millisecond)
+	assert.ErrorContains(t, err, "timeout")
+}
@@ -0,0 +1,142 @@
+package goether
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ProtectedSigner 将私钥保存在受保护内存中的签名器
+//
+// 私钥与随机掩码异或后保存在 mlock 锁定的匿名内存中，空闲时该内存设置为不可访问，
+// 只在签名时短暂解密，签名结束后立即清除明文。可以防止私钥被交换到磁盘，
+// 并降低 core dump 与内存扫描泄露私钥的风险。Linux 与 macOS 之外的平台退化为普通内存。
+// 使用完毕后需要调用 Close 释放内存。
+type ProtectedSigner struct {
+	digestSigner
+
+	pub ecdsa.PublicKey
+	mu  sync.Mutex
+	// mem 依次为掩码后的私钥、掩码与解密时使用的临时空间，各 32 字节
+	mem []byte
+}
+
+// NewProtectedSigner 从十六进制私钥创建 ProtectedSigner
+func NewProtectedSigner(prvHex string) (*ProtectedSigner, error) {
+	signer, err := NewSigner(prvHex)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Protect()
+}
+
+// Protect 将私钥移入受保护内存并清除 Signer 中的私钥，之后需要使用返回的 ProtectedSigner 签名
+func (s *Signer) Protect() (*ProtectedSigner, error) {
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	mem, err := allocLocked(96)
+	if err != nil {
+		log.Error("Failed to allocate locked memory", "error", err)
+		return nil, err
+	}
+	sealed, pad := mem[:32], mem[32:64]
+	if _, err = rand.Read(pad); err != nil {
+		freeLocked(mem)
+		return nil, err
+	}
+	seckey := crypto.FromECDSA(key)
+	for i := range sealed {
+		sealed[i] = seckey[i] ^ pad[i]
+	}
+	wipeBytes(seckey)
+	if err = protectLocked(mem, false); err != nil {
+		wipeBytes(mem)
+		freeLocked(mem)
+		return nil, err
+	}
+
+	p := &ProtectedSigner{
+		pub: key.PublicKey,
+		mem: mem,
+	}
+	p.digestSigner = digestSigner{Address: s.Address, sign: p.SignDigest}
+	s.Zero()
+	log.Debug("Private key moved to protected memory", "address", p.Address.Hex())
+	return p, nil
+}
+
+// withKey 解密私钥并调用 fn，fn 返回后清除明文私钥
+func (s *ProtectedSigner) withKey(fn func(key *ecdsa.PrivateKey) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mem == nil {
+		return ErrSignerClosed
+	}
+	if err := protectLocked(s.mem, true); err != nil {
+		return err
+	}
+	defer protectLocked(s.mem, false)
+
+	sealed, pad, plain := s.mem[:32], s.mem[32:64], s.mem[64:96]
+	for i := range plain {
+		plain[i] = sealed[i] ^ pad[i]
+	}
+	key, err := crypto.ToECDSA(plain)
+	wipeBytes(plain)
+	if err != nil {
+		return err
+	}
+	defer wipeBigInt(key.D)
+	return fn(key)
+}
+
+// Close 清除并释放受保护内存，之后的签名返回 ErrSignerClosed
+func (s *ProtectedSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mem == nil {
+		return nil
+	}
+	if err := protectLocked(s.mem, true); err != nil {
+		return err
+	}
+	wipeBytes(s.mem)
+	err := freeLocked(s.mem)
+	s.mem = nil
+	return err
+}
+
+// GetPublicKey 获取公钥字节数组
+func (s *ProtectedSigner) GetPublicKey() []byte {
+	return crypto.FromECDSAPub(&s.pub)
+}
+
+// SignDigest 对 32 字节摘要签名，返回 [R || S || V] 格式且 V 为 0 或 1 的签名
+func (s *ProtectedSigner) SignDigest(digest []byte) (sig []byte, err error) {
+	err = s.withKey(func(key *ecdsa.PrivateKey) error {
+		sig, err = crypto.Sign(digest, key)
+		return err
+	})
+	return sig, err
+}
+
+// wipeBytes 将 b 清零
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// wipeBigInt 将 d 的底层存储清零
+func wipeBigInt(d *big.Int) {
+	words := d.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	d.SetInt64(0)
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package goether
+
+// allocLocked 当前平台不支持锁定内存，退化为普通的堆内存
+func allocLocked(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+func protectLocked([]byte, bool) error {
+	return nil
+}
+
+func freeLocked([]byte) error {
+	return nil
+}
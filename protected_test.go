@@ -0,0 +1,42 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectedSigner(t *testing.T) {
+	signer, err := NewSigner(testPrvHex)
+	assert.NoError(t, err)
+	protected, err := signer.Protect()
+	assert.NoError(t, err)
+	defer protected.Close()
+
+	// 原签名器的私钥已被清除
+	_, err = signer.SignMsg([]byte("hello"))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+
+	assert.Equal(t, TestSigner.Address, protected.GetAddress())
+	assert.Equal(t, TestSigner.GetPublicKey(), protected.GetPublicKey())
+	sig, err := protected.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	expected, err := TestSigner.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sig)
+
+	chainID := big.NewInt(1)
+	tx, err := protected.SignTx(1, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1), big.NewInt(2), nil, chainID)
+	assert.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, from)
+
+	assert.NoError(t, protected.Close())
+	assert.NoError(t, protected.Close())
+	_, err = protected.SignDigest(make([]byte, 32))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+}
@@ -0,0 +1,33 @@
+//go:build linux || darwin
+
+package goether
+
+import "syscall"
+
+// allocLocked 在 Go 堆之外映射一段匿名内存并锁定，避免被交换到磁盘
+func allocLocked(size int) ([]byte, error) {
+	b, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Mlock(b); err != nil {
+		syscall.Munmap(b)
+		return nil, err
+	}
+	return b, nil
+}
+
+// protectLocked 设置内存的访问权限，不可访问时任何读写都会触发段错误
+func protectLocked(b []byte, accessible bool) error {
+	prot := syscall.PROT_NONE
+	if accessible {
+		prot = syscall.PROT_READ | syscall.PROT_WRITE
+	}
+	return syscall.Mprotect(b, prot)
+}
+
+// freeLocked 解锁并释放内存
+func freeLocked(b []byte) error {
+	syscall.Munlock(b)
+	return syscall.Munmap(b)
+}
@@ -0,0 +1,158 @@
+package goether
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-enols/ethrpc"
+)
+
+// EIP1822ProxiableSlot EIP-1822 (UUPS) 逻辑合约地址的存储槽，keccak256("PROXIABLE")
+var EIP1822ProxiableSlot = common.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7")
+
+// implementationSelector beacon 合约 implementation() 的选择器
+var implementationSelector = hexutil.MustDecode("0x5c60da1b")
+
+// ProxyKind 代理合约的类型
+type ProxyKind int
+
+const (
+	// ProxyNone 不是可识别的代理合约
+	ProxyNone ProxyKind = iota
+	// ProxyEIP1967 逻辑合约地址保存在 EIP-1967 implementation 槽，包括透明代理与 UUPS 代理
+	ProxyEIP1967
+	// ProxyBeacon EIP-1967 beacon 代理，逻辑合约地址由 beacon 合约的 implementation() 返回
+	ProxyBeacon
+	// ProxyEIP1822 逻辑合约地址保存在 EIP-1822 PROXIABLE 槽
+	ProxyEIP1822
+)
+
+func (k ProxyKind) String() string {
+	switch k {
+	case ProxyEIP1967:
+		return "eip1967"
+	case ProxyBeacon:
+		return "beacon"
+	case ProxyEIP1822:
+		return "eip1822"
+	}
+	return "none"
+}
+
+// ProxyInfo 代理合约的检测结果
+type ProxyInfo struct {
+	Kind           ProxyKind
+	Implementation common.Address
+	// Beacon beacon 代理的 beacon 合约地址
+	Beacon common.Address
+	// Admin EIP-1967 admin 槽中的管理员地址，未设置时为零地址
+	Admin common.Address
+}
+
+// DetectProxy 通过 eth_getStorageAt 读取 EIP-1967、beacon 与 EIP-1822 存储槽，识别 address 是否为代理合约，
+// 不是代理合约时返回 Kind 为 ProxyNone 的结果
+func (w *Wallet) DetectProxy(address common.Address) (*ProxyInfo, error) {
+	return w.DetectProxyContext(context.Background(), address)
+}
+
+// DetectProxyContext 与 DetectProxy 相同，但查询受 ctx 控制
+func (w *Wallet) DetectProxyContext(ctx context.Context, address common.Address) (*ProxyInfo, error) {
+	slotAddress := func(slot common.Hash) (common.Address, error) {
+		value, err := w.GetStorageAtContext(ctx, address, slot, "latest")
+		return common.BytesToAddress(value.Bytes()), err
+	}
+
+	info := &ProxyInfo{}
+	impl, err := slotAddress(EIP1967ImplementationSlot)
+	if err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		info.Kind, info.Implementation = ProxyEIP1967, impl
+	} else {
+		beacon, err := slotAddress(EIP1967BeaconSlot)
+		if err != nil {
+			return nil, err
+		}
+		if beacon != (common.Address{}) {
+			if impl, err = w.beaconImplementation(ctx, beacon); err != nil {
+				return nil, err
+			}
+			info.Kind, info.Implementation, info.Beacon = ProxyBeacon, impl, beacon
+		}
+	}
+	if info.Kind != ProxyNone {
+		if info.Admin, err = slotAddress(EIP1967AdminSlot); err != nil {
+			return nil, err
+		}
+	} else {
+		if impl, err = slotAddress(EIP1822ProxiableSlot); err != nil {
+			return nil, err
+		}
+		if impl != (common.Address{}) {
+			info.Kind, info.Implementation = ProxyEIP1822, impl
+		}
+	}
+	log.Debug("Proxy detection completed", "address", address.Hex(), "kind", info.Kind, "implementation", info.Implementation.Hex())
+	return info, nil
+}
+
+// beaconImplementation 调用 beacon 合约的 implementation() 获取逻辑合约地址
+func (w *Wallet) beaconImplementation(ctx context.Context, beacon common.Address) (common.Address, error) {
+	res, err := callContext(ctx, func() (string, error) {
+		return w.rpc(ctx).EthCall(ethrpc.T{
+			From: w.Address.Hex(),
+			To:   beacon.Hex(),
+			Data: hexutil.Encode(implementationSelector),
+		}, "latest")
+	})
+	if err != nil {
+		log.Error("Failed to call beacon implementation", "beacon", beacon.Hex(), "error", err)
+		return common.Address{}, err
+	}
+	data, err := hexutil.Decode(res)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(data) < 32 {
+		return common.Address{}, fmt.Errorf("%w: beacon %s implementation()", ErrNoData, beacon.Hex())
+	}
+	return common.BytesToAddress(data[:32]), nil
+}
+
+// ResolveProxy 检测合约是否为代理合约并返回逻辑合约地址
+//
+// fetchABI 为 true 且合约是代理时，通过 FetchABI 获取逻辑合约的 ABI 并与当前 ABI 合并，
+// 之后调用方法与解码事件都按逻辑合约进行，代理自身的管理方法依然可用，交易依然发送到代理地址。
+func (c *Contract) ResolveProxy(fetchABI bool) (*ProxyInfo, error) {
+	return c.ResolveProxyContext(context.Background(), fetchABI)
+}
+
+// ResolveProxyContext 与 ResolveProxy 相同，但请求受 ctx 控制
+func (c *Contract) ResolveProxyContext(ctx context.Context, fetchABI bool) (*ProxyInfo, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	info, err := c.Wallet.DetectProxyContext(ctx, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	if !fetchABI || info.Kind == ProxyNone {
+		return info, nil
+	}
+
+	impl, err := NewContractFromAddressContext(ctx, info.Implementation, c.Wallet)
+	if err != nil {
+		log.Error("Failed to fetch implementation abi", "proxy", c.Address.Hex(), "implementation", info.Implementation.Hex(), "error", err)
+		return info, err
+	}
+	merged, err := MergeABIs(c.ABI, impl.ABI)
+	if err != nil {
+		return info, err
+	}
+	c.ABI = merged
+	log.Debug("Contract abi merged with implementation abi", "proxy", c.Address.Hex(), "implementation", info.Implementation.Hex())
+	return info, nil
+}
@@ -0,0 +1,88 @@
+package goether
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectProxy(t *testing.T) {
+	var (
+		transparent = common.HexToAddress("0x0000000000000000000000000000000000000001")
+		beaconProxy = common.HexToAddress("0x0000000000000000000000000000000000000002")
+		uups        = common.HexToAddress("0x0000000000000000000000000000000000000003")
+		plain       = common.HexToAddress("0x0000000000000000000000000000000000000004")
+		beacon      = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		admin       = common.HexToAddress("0x00000000000000000000000000000000000000ad")
+		impl        = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	)
+	storage := map[common.Address]map[common.Hash]common.Address{
+		transparent: {EIP1967ImplementationSlot: impl, EIP1967AdminSlot: admin},
+		beaconProxy: {EIP1967BeaconSlot: beacon},
+		uups:        {EIP1822ProxiableSlot: impl},
+	}
+	m := newMockRPC(t)
+	m.On("eth_getStorageAt", func(params []json.RawMessage) (any, error) {
+		var address, slot string
+		json.Unmarshal(params[0], &address)
+		json.Unmarshal(params[1], &slot)
+		value := storage[common.HexToAddress(address)][common.HexToHash(slot)]
+		return common.BytesToHash(value.Bytes()).Hex(), nil
+	})
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		assert.Equal(t, beacon, common.HexToAddress(tx.To))
+		assert.Equal(t, "0x5c60da1b", tx.Data)
+		return common.BytesToHash(impl.Bytes()).Hex(), nil
+	})
+	w := newTestWallet(t, m)
+
+	info, err := w.DetectProxy(transparent)
+	assert.NoError(t, err)
+	assert.Equal(t, &ProxyInfo{Kind: ProxyEIP1967, Implementation: impl, Admin: admin}, info)
+
+	info, err = w.DetectProxy(beaconProxy)
+	assert.NoError(t, err)
+	assert.Equal(t, &ProxyInfo{Kind: ProxyBeacon, Implementation: impl, Beacon: beacon}, info)
+
+	info, err = w.DetectProxy(uups)
+	assert.NoError(t, err)
+	assert.Equal(t, &ProxyInfo{Kind: ProxyEIP1822, Implementation: impl}, info)
+	assert.Equal(t, "eip1822", info.Kind.String())
+
+	info, err = w.DetectProxy(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, ProxyNone, info.Kind)
+
+	// 获取逻辑合约 ABI 后按 ERC-20 调用代理合约
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/contract/1/"+impl.Hex(), r.URL.Path)
+		rw.Write([]byte(`{"abi":` + ERC20ABI + `}`))
+	}))
+	t.Cleanup(server.Close)
+	defer func(url, dir string) { SourcifyURL, ABICacheDir = url, dir }(SourcifyURL, ABICacheDir)
+	SourcifyURL = server.URL
+	ABICacheDir = t.TempDir()
+
+	c, err := NewContract(transparent, `[{"type":"function","name":"upgradeTo","inputs":[{"name":"impl","type":"address"}],"outputs":[]}]`, "", w)
+	assert.NoError(t, err)
+	info, err = c.ResolveProxy(false)
+	assert.NoError(t, err)
+	assert.Equal(t, impl, info.Implementation)
+	assert.NotContains(t, c.ABI.Methods, "balanceOf")
+
+	_, err = c.ResolveProxy(true)
+	assert.NoError(t, err)
+	assert.Contains(t, c.ABI.Methods, "balanceOf")
+	assert.Contains(t, c.ABI.Events, "Transfer")
+	assert.Contains(t, c.ABI.Methods, "upgradeTo")
+	assert.Equal(t, transparent, c.Address)
+}
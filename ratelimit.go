@@ -0,0 +1,122 @@
+package goether
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// RateLimiter 令牌桶限流器，并发安全
+//
+// 同一个 RateLimiter 可以传给多个钱包或客户端，使它们共享同一个节点的请求配额。
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	now func() time.Time
+	// sleep 测试中替换等待函数，为 nil 时按 ctx 等待
+	sleep func(time.Duration)
+}
+
+// NewRateLimiter 创建每秒最多 rps 个请求、最多突发 burst 个请求的限流器，burst 小于 1 时视为 1
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait 阻塞直到取得一个令牌
+func (l *RateLimiter) Wait() {
+	l.WaitContext(context.Background())
+}
+
+// WaitContext 与 Wait 相同，但 ctx 取消时立即返回 ctx.Err()
+func (l *RateLimiter) WaitContext(ctx context.Context) error {
+	wait := l.reserve()
+	if wait <= 0 {
+		return nil
+	}
+	if l.sleep != nil {
+		l.sleep(wait)
+		return ctx.Err()
+	}
+	return sleepContext(ctx, wait)
+}
+
+// reserve 取走一个令牌，令牌不足时允许透支并返回需要等待的时间
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// RateLimitClient 发送请求前从 Limiter 取得令牌的 HTTP 客户端，批量请求只消耗一个令牌
+type RateLimitClient struct {
+	Limiter *RateLimiter
+	// Client 实际发送请求的客户端，为 nil 时使用 http.DefaultClient
+	Client HTTPClient
+}
+
+// NewRateLimitClient 创建限流客户端，client 为 nil 时使用 http.DefaultClient
+func NewRateLimitClient(client HTTPClient, limiter *RateLimiter) *RateLimitClient {
+	return &RateLimitClient{
+		Limiter: limiter,
+		Client:  client,
+	}
+}
+
+// WithRateLimit 返回一个 ethrpc 配置函数，使客户端的请求受 limiter 限制
+//
+//	limiter := NewRateLimiter(10, 20)
+//	w1, err := NewWallet(prv1, rpc, WithRateLimit(limiter))
+//	w2, err := NewWallet(prv2, rpc, WithRateLimit(limiter))
+func WithRateLimit(limiter *RateLimiter) func(rpc *ethrpc.EthRPC) {
+	return ethrpc.WithHttpClient(NewRateLimitClient(nil, limiter))
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口
+func (r *RateLimitClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return r.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext 与 Post 相同，但等待令牌与请求都受 ctx 控制
+func (r *RateLimitClient) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	if r.Limiter != nil {
+		if err := r.Limiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var client HTTPClient = http.DefaultClient
+	if r.Client != nil {
+		client = r.Client
+	}
+	return postContext(ctx, client, url, contentType, body)
+}
@@ -0,0 +1,47 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewRateLimiter(10, 2)
+	l.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, 100*time.Millisecond, l.reserve())
+	assert.Equal(t, 200*time.Millisecond, l.reserve())
+
+	// 令牌最多恢复到 burst
+	now = now.Add(time.Minute)
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, 100*time.Millisecond, l.reserve())
+}
+
+func TestRateLimiterSharedByWallets(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("eth_getBalance", "0x1")
+	now := time.Unix(1700000000, 0)
+	var waits []time.Duration
+	l := NewRateLimiter(1, 1)
+	l.now = func() time.Time { return now }
+	l.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	w1, err := NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", m.URL, big.NewInt(1), l)
+	assert.NoError(t, err)
+	w2, err := NewWallet("b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2", m.URL, big.NewInt(1), l)
+	assert.NoError(t, err)
+
+	_, err = w1.GetBalance()
+	assert.NoError(t, err)
+	_, err = w2.GetBalance()
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second}, waits)
+}
@@ -0,0 +1,233 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/ethrpc"
+)
+
+// Receipt 交易回执，包含 status、gasUsed、effectiveGasPrice 与日志，
+// Events 为按传入的合约 ABI 解码成功的事件
+type Receipt struct {
+	*types.Receipt
+	Events []Event
+}
+
+// Event 按 ABI 解码后的事件日志
+type Event struct {
+	Name    string
+	Address common.Address
+	Values  map[string]interface{}
+	Log     *types.Log
+}
+
+// Succeeded 交易是否执行成功
+func (r *Receipt) Succeeded() bool {
+	return r.Status == types.ReceiptStatusSuccessful
+}
+
+// GetReceipt 获取交易回执，并使用 contracts 的 ABI 解码日志
+//
+// 日志优先使用地址相同的合约解码，Address 为零地址的合约作为 ABI 注册表，
+// 可以解码任意地址的日志，例如用一个 ERC20 实例解码所有代币的 Transfer 事件。
+// 交易不存在或尚未打包时返回 ErrTxNotFound。
+func (w *Wallet) GetReceipt(txHash string, contracts ...*Contract) (*Receipt, error) {
+	return w.GetReceiptContext(context.Background(), txHash, contracts...)
+}
+
+// GetReceiptContext 与 GetReceipt 相同，但查询受 ctx 控制
+func (w *Wallet) GetReceiptContext(ctx context.Context, txHash string, contracts ...*Contract) (*Receipt, error) {
+	log.Debug("Getting transaction receipt", "txHash", txHash, "contracts", len(contracts))
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_getTransactionReceipt", txHash)
+	})
+	if err != nil {
+		log.Error("Failed to get transaction receipt", "txHash", txHash, "error", err)
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, txHash)
+	}
+
+	receipt := &Receipt{Receipt: new(types.Receipt)}
+	if err = json.Unmarshal(raw, receipt.Receipt); err != nil {
+		log.Error("Failed to decode transaction receipt", "txHash", txHash, "error", err)
+		return nil, err
+	}
+	for _, l := range receipt.Logs {
+		if event, ok := decodeLog(l, contracts); ok {
+			receipt.Events = append(receipt.Events, event)
+		}
+	}
+
+	log.Debug("Transaction receipt retrieved",
+		"txHash", txHash,
+		"status", receipt.Status,
+		"gasUsed", receipt.GasUsed,
+		"logs", len(receipt.Logs),
+		"events", len(receipt.Events))
+	return receipt, nil
+}
+
+// DecodeReceiptLogs 按 ABI 解码回执中由本合约地址产生的日志，ABI 中没有的事件会被跳过
+func (c *Contract) DecodeReceiptLogs(receipt *types.Receipt) []Event {
+	var events []Event
+	for _, l := range receipt.Logs {
+		if l.Address != c.Address {
+			continue
+		}
+		if event, ok := decodeLog(l, []*Contract{c}); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// DecodeTxLogs 获取交易回执并解码其中由本合约地址产生的日志，交易不存在或尚未打包时返回 ErrTxNotFound
+func (c *Contract) DecodeTxLogs(txHash string) ([]Event, error) {
+	return c.DecodeTxLogsContext(context.Background(), txHash)
+}
+
+// DecodeTxLogsContext 与 DecodeTxLogs 相同，但查询受 ctx 控制
+func (c *Contract) DecodeTxLogsContext(ctx context.Context, txHash string) ([]Event, error) {
+	if c.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	receipt, err := c.Wallet.GetReceiptContext(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return c.DecodeReceiptLogs(receipt.Receipt), nil
+}
+
+// decodeLog 依次尝试地址匹配的合约与 ABI 注册表解码日志
+func decodeLog(l *types.Log, contracts []*Contract) (Event, bool) {
+	if len(l.Topics) == 0 {
+		return Event{}, false
+	}
+	for _, registry := range []bool{false, true} {
+		for _, c := range contracts {
+			if c == nil || (registry && c.Address != (common.Address{})) || (!registry && c.Address != l.Address) {
+				continue
+			}
+			if _, err := c.ABI.EventByID(l.Topics[0]); err != nil {
+				continue
+			}
+			name, values, err := c.DecodeEvent(l.Topics, l.Data)
+			if err != nil {
+				continue
+			}
+			return Event{Name: name, Address: l.Address, Values: values, Log: l}, true
+		}
+	}
+	return Event{}, false
+}
+
+// TxStatus 交易状态
+type TxStatus int
+
+const (
+	// TxStatusNotFound 节点中找不到交易，可能尚未广播或已被丢弃
+	TxStatusNotFound TxStatus = iota
+	// TxStatusPending 交易在交易池中等待打包
+	TxStatusPending
+	// TxStatusSuccess 交易已打包并执行成功
+	TxStatusSuccess
+	// TxStatusReverted 交易已打包但执行失败
+	TxStatusReverted
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusNotFound:
+		return "not found"
+	case TxStatusPending:
+		return "pending"
+	case TxStatusSuccess:
+		return "success"
+	case TxStatusReverted:
+		return "reverted"
+	}
+	return fmt.Sprintf("TxStatus(%d)", int(s))
+}
+
+// TxState GetTxStatus 的查询结果
+type TxState struct {
+	Status TxStatus
+	// Receipt 交易已打包时的回执
+	Receipt *Receipt
+	// Revert 交易回滚时在上一个区块的状态上重放得到的回滚原因，无法取得时为 nil
+	Revert *RevertError
+}
+
+// GetTxStatus 查询交易处于未找到、等待打包、成功或回滚中的哪种状态
+func (w *Wallet) GetTxStatus(txHash string) (*TxState, error) {
+	return w.GetTxStatusContext(context.Background(), txHash)
+}
+
+// GetTxStatusContext 与 GetTxStatus 相同，但查询受 ctx 控制
+func (w *Wallet) GetTxStatusContext(ctx context.Context, txHash string) (*TxState, error) {
+	receipt, err := w.GetReceiptContext(ctx, txHash)
+	if errors.Is(err, ErrTxNotFound) {
+		tx, err := callContext(ctx, func() (*ethrpc.Transaction, error) {
+			return w.rpc(ctx).EthGetTransactionByHash(txHash)
+		})
+		if err != nil {
+			log.Error("Failed to get transaction", "txHash", txHash, "error", err)
+			return nil, err
+		}
+		// 已打包但节点还没有索引回执时同样视为等待中
+		if tx.Hash == "" {
+			return &TxState{Status: TxStatusNotFound}, nil
+		}
+		return &TxState{Status: TxStatusPending}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if receipt.Succeeded() {
+		return &TxState{Status: TxStatusSuccess, Receipt: receipt}, nil
+	}
+	state := &TxState{Status: TxStatusReverted, Receipt: receipt}
+	state.Revert = w.replayRevert(ctx, txHash, receipt.BlockNumber)
+	log.Debug("Transaction reverted", "txHash", txHash, "revert", state.Revert)
+	return state, nil
+}
+
+// replayRevert 在交易所在区块的上一个区块状态上重放交易，返回回滚原因，
+// 节点不支持历史状态或重放没有回滚时返回 nil
+func (w *Wallet) replayRevert(ctx context.Context, txHash string, blockNumber *big.Int) *RevertError {
+	tx, err := callContext(ctx, func() (*ethrpc.Transaction, error) {
+		return w.rpc(ctx).EthGetTransactionByHash(txHash)
+	})
+	if err != nil || tx.Hash == "" || blockNumber == nil || blockNumber.Sign() == 0 {
+		log.Debug("Cannot replay transaction", "txHash", txHash, "error", err)
+		return nil
+	}
+
+	msg := ethrpc.T{
+		From:  tx.From,
+		To:    tx.To,
+		Gas:   tx.Gas,
+		Value: &tx.Value,
+		Data:  tx.Input,
+	}
+	parent := hexutil.EncodeBig(new(big.Int).Sub(blockNumber, big.NewInt(1)))
+	_, err = callData(ctx, w.Client, w.knownTransport(), func(rpc *ethrpc.EthRPC) (string, error) {
+		return rpc.EthCall(msg, parent)
+	})
+	var revert *RevertError
+	if errors.As(asRevertError(err), &revert) {
+		return revert
+	}
+	log.Debug("Replay did not revert", "txHash", txHash, "error", err)
+	return nil
+}
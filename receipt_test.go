@@ -0,0 +1,152 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReceipt(t *testing.T) {
+	tokenA := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tokenB := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	from := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	txHash := common.HexToHash("0x01")
+
+	transfer := func(token common.Address, amount int64) *types.Log {
+		return &types.Log{
+			Address: token,
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(to.Bytes()),
+			},
+			Data:   common.BigToHash(big.NewInt(amount)).Bytes(),
+			TxHash: txHash,
+		}
+	}
+	unknown := &types.Log{Address: tokenA, Topics: []common.Hash{common.HexToHash("0xff")}, TxHash: txHash}
+
+	m := newMockRPC(t)
+	m.Result("eth_getTransactionReceipt", &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            txHash,
+		GasUsed:           50000,
+		CumulativeGasUsed: 50000,
+		EffectiveGasPrice: big.NewInt(1e9),
+		BlockNumber:       big.NewInt(10),
+		Logs:              []*types.Log{transfer(tokenA, 5), unknown, transfer(tokenB, 7)},
+	})
+	w := newTestWallet(t, m)
+
+	a, err := NewERC20(tokenA, w)
+	assert.NoError(t, err)
+	receipt, err := w.GetReceipt(txHash.Hex(), a.Contract)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Succeeded())
+	assert.Equal(t, uint64(50000), receipt.GasUsed)
+	assert.Equal(t, big.NewInt(1e9), receipt.EffectiveGasPrice)
+	assert.Len(t, receipt.Logs, 3)
+	assert.Len(t, receipt.Events, 1)
+	assert.Equal(t, "Transfer", receipt.Events[0].Name)
+	assert.Equal(t, tokenA, receipt.Events[0].Address)
+	assert.Equal(t, big.NewInt(5), receipt.Events[0].Values["value"])
+	assert.Equal(t, to, receipt.Events[0].Values["to"])
+
+	// 零地址的合约作为 ABI 注册表解码任意代币的事件
+	registry, err := NewERC20(common.Address{}, w)
+	assert.NoError(t, err)
+	receipt, err = w.GetReceipt(txHash.Hex(), registry.Contract)
+	assert.NoError(t, err)
+	assert.Len(t, receipt.Events, 2)
+	assert.Equal(t, tokenB, receipt.Events[1].Address)
+	assert.Equal(t, big.NewInt(7), receipt.Events[1].Values["value"])
+
+	// 只解码合约自身地址产生的日志
+	events, err := a.DecodeTxLogs(txHash.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, big.NewInt(5), events[0].Values["value"])
+	b, err := NewERC20(tokenB, w)
+	assert.NoError(t, err)
+	events = b.DecodeReceiptLogs(receipt.Receipt)
+	assert.Len(t, events, 1)
+	assert.Equal(t, tokenB, events[0].Address)
+	assert.Equal(t, from, events[0].Values["from"])
+	assert.Empty(t, registry.DecodeReceiptLogs(receipt.Receipt))
+
+	m.Result("eth_getTransactionReceipt", nil)
+	_, err = w.GetReceipt(txHash.Hex())
+	assert.ErrorIs(t, err, ErrTxNotFound)
+	_, err = a.DecodeTxLogs(txHash.Hex())
+	assert.ErrorIs(t, err, ErrTxNotFound)
+}
+
+func TestGetTxStatus(t *testing.T) {
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	txHash := "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+	m.Result("eth_getTransactionReceipt", nil)
+	m.Result("eth_getTransactionByHash", nil)
+	state, err := w.GetTxStatus(txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusNotFound, state.Status)
+
+	tx := map[string]any{
+		"hash":     txHash,
+		"from":     w.Address.Hex(),
+		"to":       "0x0000000000000000000000000000000000000001",
+		"nonce":    "0x1",
+		"gas":      "0x7530",
+		"gasPrice": "0x3b9aca00",
+		"value":    "0x0",
+		"input":    "0xabcd",
+	}
+	m.Result("eth_getTransactionByHash", tx)
+	state, err = w.GetTxStatus(txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusPending, state.Status)
+	assert.Equal(t, "pending", state.Status.String())
+
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      common.HexToHash(txHash),
+		GasUsed:     21000,
+		BlockNumber: big.NewInt(16),
+		Logs:        []*types.Log{},
+	}
+	m.Result("eth_getTransactionReceipt", receipt)
+	state, err = w.GetTxStatus(txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusSuccess, state.Status)
+	assert.Equal(t, uint64(21000), state.Receipt.GasUsed)
+
+	var tag string
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		json.Unmarshal(params[1], &tag)
+		return nil, &mockError{Code: 3, Message: "execution reverted: insufficient allowance"}
+	})
+	receipt.Status = types.ReceiptStatusFailed
+	state, err = w.GetTxStatus(txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusReverted, state.Status)
+	assert.Equal(t, "0xf", tag)
+	if assert.NotNil(t, state.Revert) {
+		assert.Equal(t, "insufficient allowance", state.Revert.Reason)
+	}
+
+	// 节点无法重放时只返回回滚状态
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32000, Message: "missing trie node"}
+	})
+	state, err = w.GetTxStatus(txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, TxStatusReverted, state.Status)
+	assert.Nil(t, state.Revert)
+}
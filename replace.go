@@ -0,0 +1,145 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-enols/ethrpc"
+)
+
+var (
+	// ReplacementBumpPercent 替换交易时手续费的最小涨幅，与 geth 交易池默认的 price bump 一致
+	ReplacementBumpPercent = 10
+	// MaxReplacementAttempts 节点提示手续费不足时继续加价重试的最大次数
+	MaxReplacementAttempts = 5
+)
+
+// CancelTx 以相同 nonce 向自己发送一笔 0 值交易来取消卡住的交易
+//
+// opts 中的手续费作为起始报价，未设置时使用建议手续费；节点返回
+// replacement transaction underpriced 时按 ReplacementBumpPercent 加价后重试。
+func (w *Wallet) CancelTx(nonce int, opts *TxOpts) (txHash string, err error) {
+	return w.CancelTxContext(context.Background(), nonce, opts)
+}
+
+// CancelTxContext 与 CancelTx 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) CancelTxContext(ctx context.Context, nonce int, opts *TxOpts) (txHash string, err error) {
+	log.Debug("Cancelling transaction", "from", w.Address.Hex(), "nonce", nonce)
+
+	replacement := TxOpts{}
+	if opts != nil {
+		replacement = *opts
+	}
+	replacement.Nonce = &nonce
+	if replacement.GasLimit == nil {
+		gasLimit := 21000
+		replacement.GasLimit = &gasLimit
+	}
+	return w.sendReplacement(ctx, w.SendTxContext, w.Address, big.NewInt(0), nil, &replacement)
+}
+
+// SpeedUpTx 以相同的 nonce、to、value 与 data 重新发送 pending 交易，
+// 手续费至少提高 ReplacementBumpPercent 且不低于当前建议手续费，返回新交易的哈希
+func (w *Wallet) SpeedUpTx(txHash string) (newTxHash string, err error) {
+	return w.SpeedUpTxContext(context.Background(), txHash)
+}
+
+// SpeedUpTxContext 与 SpeedUpTx 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) SpeedUpTxContext(ctx context.Context, txHash string) (newTxHash string, err error) {
+	log.Debug("Speeding up transaction", "txHash", txHash)
+	tx, err := callContext(ctx, func() (*ethrpc.Transaction, error) {
+		return w.rpc(ctx).EthGetTransactionByHash(txHash)
+	})
+	if err != nil {
+		log.Error("Failed to get transaction", "txHash", txHash, "error", err)
+		return
+	}
+	switch {
+	case tx.Hash == "":
+		return "", fmt.Errorf("%w: %s", ErrTxNotFound, txHash)
+	case tx.BlockNumber != nil:
+		return "", fmt.Errorf("transaction %s is already mined", txHash)
+	case !strings.EqualFold(tx.From, w.Address.Hex()):
+		return "", fmt.Errorf("transaction %s is not sent by %s", txHash, w.Address.Hex())
+	case tx.To == "":
+		return "", fmt.Errorf("transaction %s is a contract creation and cannot be sped up", txHash)
+	}
+
+	nonce, gasLimit := tx.Nonce, tx.Gas
+	opts := &TxOpts{Nonce: &nonce, GasLimit: &gasLimit}
+	send := w.SendTxContext
+	if tx.MaxFeePerGas != nil && tx.MaxPriorityFeePerGas != nil {
+		opts.GasTipCap = bumpFee(tx.MaxPriorityFeePerGas, ReplacementBumpPercent)
+		opts.GasFeeCap = bumpFee(tx.MaxFeePerGas, ReplacementBumpPercent)
+		if fees, err := w.SuggestFeesContext(ctx); err == nil {
+			opts.GasTipCap = maxBig(opts.GasTipCap, fees.GasTipCap)
+			opts.GasFeeCap = maxBig(opts.GasFeeCap, fees.GasFeeCap)
+		}
+		if opts.GasTipCap.Cmp(opts.GasFeeCap) > 0 {
+			opts.GasFeeCap = new(big.Int).Set(opts.GasTipCap)
+		}
+	} else {
+		opts.GasPrice = bumpFee(&tx.GasPrice, ReplacementBumpPercent)
+		if gasPrice, err := callContext(ctx, w.rpc(ctx).EthGasPrice); err == nil {
+			opts.GasPrice = maxBig(opts.GasPrice, &gasPrice)
+		}
+		send = w.SendLegacyTxContext
+	}
+
+	return w.sendReplacement(ctx, send, common.HexToAddress(tx.To), new(big.Int).Set(&tx.Value), common.FromHex(tx.Input), opts)
+}
+
+// sendFunc SendTxContext 或 SendLegacyTxContext
+type sendFunc func(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (string, error)
+
+// sendReplacement 发送替换交易，手续费不足以替换时加价重试
+func (w *Wallet) sendReplacement(ctx context.Context, send sendFunc, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	// send 只在副本上补全手续费，这里先补全，加价时才有起始报价
+	if opts, err = w.InitTxOptsContext(ctx, to, amount, data, opts); err != nil {
+		return "", err
+	}
+	for attempt := 0; ; attempt++ {
+		txHash, err = send(ctx, to, amount, data, opts)
+		if err == nil || !isUnderpriced(err) || attempt >= MaxReplacementAttempts {
+			return
+		}
+		log.Debug("Replacement transaction underpriced, bumping fees",
+			"nonce", *opts.Nonce,
+			"attempt", attempt+1,
+			"gasTipCap", opts.GasTipCap.String(),
+			"gasFeeCap", opts.GasFeeCap.String())
+		opts.GasTipCap = bumpFee(opts.GasTipCap, ReplacementBumpPercent)
+		opts.GasFeeCap = bumpFee(opts.GasFeeCap, ReplacementBumpPercent)
+		opts.GasPrice = bumpFee(opts.GasPrice, ReplacementBumpPercent)
+	}
+}
+
+// bumpFee 将 fee 提高 percent%，结果至少比原值大 1
+func bumpFee(fee *big.Int, percent int) *big.Int {
+	if fee == nil {
+		return nil
+	}
+	bumped := new(big.Int).Mul(fee, big.NewInt(int64(100+percent)))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// isUnderpriced 判断节点是否因为手续费不足拒绝了交易
+func isUnderpriced(err error) bool {
+	return errors.Is(err, ErrUnderpriced) || strings.Contains(strings.ToLower(err.Error()), "underpriced")
+}
@@ -0,0 +1,85 @@
+package goether
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBumpFee(t *testing.T) {
+	assert.Equal(t, big.NewInt(110), bumpFee(big.NewInt(100), 10))
+	assert.Equal(t, big.NewInt(13), bumpFee(big.NewInt(11), 10))
+	assert.Equal(t, big.NewInt(2), bumpFee(big.NewInt(1), 10))
+	assert.Nil(t, bumpFee(nil, 10))
+}
+
+func TestCancelTx(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	// 模拟交易池中 nonce 为 3 的交易，最大手续费为 1.2 gwei
+	m.On("eth_sendRawTransaction", func(params []json.RawMessage) (any, error) {
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+			return nil, err
+		}
+		if tx.GasFeeCap().Cmp(big.NewInt(1_320_000_000)) < 0 {
+			return nil, errors.New("replacement transaction underpriced")
+		}
+		sent = append(sent, tx)
+		return tx.Hash().Hex(), nil
+	})
+	w := newTestWallet(t, m)
+
+	_, err := w.CancelTx(3, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Equal(t, uint64(3), sent[0].Nonce())
+	assert.Equal(t, w.Address, *sent[0].To())
+	assert.Equal(t, int64(0), sent[0].Value().Int64())
+	assert.Equal(t, uint64(21000), sent[0].Gas())
+	assert.Equal(t, 4, m.Calls("eth_sendRawTransaction"))
+}
+
+func TestSpeedUpTx(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+	pending := map[string]any{
+		"hash":                 "0x01",
+		"from":                 w.Address.Hex(),
+		"to":                   "0x0000000000000000000000000000000000000001",
+		"nonce":                "0x9",
+		"gas":                  "0x7530",
+		"gasPrice":             "0x77359400",
+		"maxFeePerGas":         "0x77359400",
+		"maxPriorityFeePerGas": "0x3b9aca00",
+		"value":                "0x64",
+		"input":                "0xabcd",
+		"type":                 "0x2",
+	}
+	m.Result("eth_getTransactionByHash", pending)
+
+	_, err := w.SpeedUpTx("0x01")
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	tx := sent[0]
+	assert.Equal(t, uint64(9), tx.Nonce())
+	assert.Equal(t, uint64(30000), tx.Gas())
+	assert.Equal(t, int64(100), tx.Value().Int64())
+	assert.Equal(t, []byte{0xab, 0xcd}, tx.Data())
+	assert.Equal(t, big.NewInt(2_200_000_000), tx.GasFeeCap())
+	assert.Equal(t, big.NewInt(1_100_000_000), tx.GasTipCap())
+
+	pending["blockNumber"] = "0x10"
+	_, err = w.SpeedUpTx("0x01")
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+)
+
+// HTTPClient ethrpc 发送请求所需的最小接口，*http.Client 与本包中的各种客户端都实现了该接口，
+// 可以互相嵌套组合
+type HTTPClient interface {
+	Post(url string, contentType string, body io.Reader) (*http.Response, error)
+}
+
+// RetryPolicy RPC 请求的重试策略，第 n 次重试前等待 min(InitialBackoff * Multiplier^(n-1), MaxBackoff)
+type RetryPolicy struct {
+	// MaxAttempts 包括第一次请求在内的最大尝试次数，小于等于 1 时不重试
+	MaxAttempts int
+	// InitialBackoff 第一次重试前的等待时间
+	InitialBackoff time.Duration
+	// MaxBackoff 单次等待时间的上限，为 0 时不限制
+	MaxBackoff time.Duration
+	// Multiplier 每次重试等待时间的倍数，小于 1 时视为 2
+	Multiplier float64
+	// Retryable 判断请求是否需要重试，为 nil 时使用 IsRetryable
+	Retryable func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy 默认重试策略：最多尝试 4 次，等待 200ms、400ms、800ms
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+// IsRetryable 默认的重试判断：网络错误、超时以及 429/502/503/504 响应
+func IsRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff 第 attempt 次重试前的等待时间，attempt 从 1 开始
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && time.Duration(d) > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// RetryClient 按 RetryPolicy 以指数退避重试失败请求的 HTTP 客户端
+//
+// 通过 ethrpc.WithHttpClient 注入后 Wallet 与 Contract 发出的所有 RPC 请求都会重试，
+// 429 响应携带 Retry-After 时优先使用节点给出的等待时间。
+type RetryClient struct {
+	Policy RetryPolicy
+	// Client 实际发送请求的客户端，为 nil 时使用 http.DefaultClient
+	Client HTTPClient
+
+	sleep func(time.Duration)
+}
+
+// NewRetryClient 创建重试客户端，client 为 nil 时使用 http.DefaultClient
+func NewRetryClient(client HTTPClient, policy RetryPolicy) *RetryClient {
+	return &RetryClient{
+		Policy: policy,
+		Client: client,
+	}
+}
+
+// WithRetry 返回一个 ethrpc 配置函数，使客户端按 policy 重试失败的请求
+//
+//	wallet, err := NewWallet(prvHex, rpc, WithRetry(DefaultRetryPolicy))
+func WithRetry(policy RetryPolicy) func(rpc *ethrpc.EthRPC) {
+	return ethrpc.WithHttpClient(NewRetryClient(nil, policy))
+}
+
+// Post 实现 ethrpc 所需的 httpClient 接口
+func (r *RetryClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	return r.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext 与 Post 相同，但请求与重试等待都受 ctx 控制，ctx 取消后不再重试
+func (r *RetryClient) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var client HTTPClient = http.DefaultClient
+	if r.Client != nil {
+		client = r.Client
+	}
+	retryable := r.Policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	sleep := func(d time.Duration) error {
+		if r.sleep != nil {
+			r.sleep(d)
+			return ctx.Err()
+		}
+		return sleepContext(ctx, d)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := postContext(ctx, client, url, contentType, bytes.NewReader(payload))
+		if attempt >= r.Policy.MaxAttempts || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+
+		wait := r.Policy.backoff(attempt)
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+			resp.Body.Close()
+			log.Warning("RPC request failed, retrying", "status", resp.StatusCode, "attempt", attempt, "backoff", wait)
+		} else {
+			log.Warning("RPC request failed, retrying", "error", err, "attempt", attempt, "backoff", wait)
+		}
+		if err = sleep(wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter 解析以秒为单位的 Retry-After 响应头
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
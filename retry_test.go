@@ -0,0 +1,58 @@
+package goether
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, p.backoff(4))
+	assert.Equal(t, time.Second, p.backoff(5))
+	assert.Equal(t, time.Second, p.backoff(100))
+}
+
+func TestRetryClient(t *testing.T) {
+	up := newMockRPC(t)
+	up.Result("eth_blockNumber", "0x10")
+
+	var calls atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			rw.Header().Set("Retry-After", "3")
+			rw.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			up.serveHTTP(rw, r)
+		}
+	}))
+	defer flaky.Close()
+
+	var waits []time.Duration
+	retry := NewRetryClient(nil, RetryPolicy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond})
+	retry.sleep = func(d time.Duration) { waits = append(waits, d) }
+	client := ethrpc.New(flaky.URL, ethrpc.WithHttpClient(retry))
+
+	n, err := client.EthBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, 16, n)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []time.Duration{3 * time.Second, 200 * time.Millisecond}, waits)
+
+	// 超过最大尝试次数后返回最后一次的结果
+	calls.Store(0)
+	retry.Policy.MaxAttempts = 2
+	_, err = client.EthBlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
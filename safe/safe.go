@@ -0,0 +1,166 @@
+// Package safe 提供 Safe（原 Gnosis Safe）多签钱包的合约绑定、SafeTx 哈希计算，
+// 以及所有者签名的收集、排序与执行
+package safe
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/go-enols/goether/internal/abiutil"
+)
+
+// SafeABI Safe v1.3+ 中签名收集与执行所需的方法
+const SafeABI = `[
+{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"_nonce","type":"uint256"}],"name":"getTransactionHash","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"stateMutability":"payable","type":"function"},
+{"inputs":[{"name":"hashToApprove","type":"bytes32"}],"name":"approveHash","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+var (
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+	domainArgs     = abiutil.MustArguments("bytes32", "uint256", "address")
+	safeTxArgs     = abiutil.MustArguments("bytes32", "address", "uint256", "bytes32", "uint8", "uint256", "uint256", "uint256", "address", "address", "uint256")
+)
+
+// Operation Safe 交易的调用方式
+type Operation uint8
+
+const (
+	Call         Operation = 0
+	DelegateCall Operation = 1
+)
+
+// Transaction Safe 多签交易，数值字段为 nil 时按 0 处理
+type Transaction struct {
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      Operation
+	SafeTxGas      *big.Int
+	BaseGas        *big.Int
+	GasPrice       *big.Int
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Nonce          *big.Int
+}
+
+// Hash 计算 Safe v1.3+ 的 EIP-712 safeTxHash，所有者对该哈希签名。数值字段为负数或超过 uint256 时返回错误
+func (tx *Transaction) Hash(safe common.Address, chainID *big.Int) (common.Hash, error) {
+	if err := abiutil.CheckUint(256, chainID, tx.Value, tx.SafeTxGas, tx.BaseGas, tx.GasPrice, tx.Nonce); err != nil {
+		return common.Hash{}, err
+	}
+	domain, err := domainArgs.Pack(domainTypeHash, abiutil.BigOrZero(chainID), safe)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	message, err := safeTxArgs.Pack(
+		safeTxTypeHash,
+		tx.To,
+		abiutil.BigOrZero(tx.Value),
+		crypto.Keccak256Hash(tx.Data),
+		uint8(tx.Operation),
+		abiutil.BigOrZero(tx.SafeTxGas),
+		abiutil.BigOrZero(tx.BaseGas),
+		abiutil.BigOrZero(tx.GasPrice),
+		tx.GasToken,
+		tx.RefundReceiver,
+		abiutil.BigOrZero(tx.Nonce),
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, crypto.Keccak256(domain), crypto.Keccak256(message)), nil
+}
+
+// Safe Safe 多签钱包合约绑定
+type Safe struct {
+	*goether.Contract
+}
+
+// NewSafe 创建 Safe 合约实例
+func NewSafe(address common.Address, wallet *goether.Wallet) (*Safe, error) {
+	c, err := goether.NewContractFromABIs(address, wallet, SafeABI)
+	if err != nil {
+		return nil, err
+	}
+	return &Safe{Contract: c}, nil
+}
+
+// Threshold 执行交易所需的签名数量
+func (s *Safe) Threshold() (*big.Int, error) {
+	return s.ThresholdContext(context.Background())
+}
+
+// ThresholdContext 与 Threshold 相同，但请求受 ctx 控制
+func (s *Safe) ThresholdContext(ctx context.Context) (*big.Int, error) {
+	var threshold *big.Int
+	err := s.CallMethodIntoContext(ctx, "getThreshold", "latest", &threshold)
+	return threshold, err
+}
+
+// Owners 所有者列表
+func (s *Safe) Owners() ([]common.Address, error) {
+	return s.OwnersContext(context.Background())
+}
+
+// OwnersContext 与 Owners 相同，但请求受 ctx 控制
+func (s *Safe) OwnersContext(ctx context.Context) ([]common.Address, error) {
+	var owners []common.Address
+	err := s.CallMethodIntoContext(ctx, "getOwners", "latest", &owners)
+	return owners, err
+}
+
+// Nonce 下一笔多签交易的 nonce
+func (s *Safe) Nonce() (*big.Int, error) {
+	return s.NonceContext(context.Background())
+}
+
+// NonceContext 与 Nonce 相同，但请求受 ctx 控制
+func (s *Safe) NonceContext(ctx context.Context) (*big.Int, error) {
+	var nonce *big.Int
+	err := s.CallMethodIntoContext(ctx, "nonce", "latest", &nonce)
+	return nonce, err
+}
+
+// NewTransaction 创建使用当前 nonce 的 Call 交易
+func (s *Safe) NewTransaction(to common.Address, value *big.Int, data []byte) (*Transaction, error) {
+	return s.NewTransactionContext(context.Background(), to, value, data)
+}
+
+// NewTransactionContext 与 NewTransaction 相同，但请求受 ctx 控制
+func (s *Safe) NewTransactionContext(ctx context.Context, to common.Address, value *big.Int, data []byte) (*Transaction, error) {
+	nonce, err := s.NonceContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{To: to, Value: value, Data: data, Nonce: nonce}, nil
+}
+
+// TransactionHash 由链上合约计算 safeTxHash，可用于核对 Transaction.Hash
+func (s *Safe) TransactionHash(tx *Transaction) (common.Hash, error) {
+	return s.TransactionHashContext(context.Background(), tx)
+}
+
+// TransactionHashContext 与 TransactionHash 相同，但请求受 ctx 控制
+func (s *Safe) TransactionHashContext(ctx context.Context, tx *Transaction) (common.Hash, error) {
+	var hash [32]byte
+	err := s.CallMethodIntoContext(ctx, "getTransactionHash", "latest", &hash,
+		tx.To, abiutil.BigOrZero(tx.Value), abiutil.BytesOrEmpty(tx.Data), uint8(tx.Operation), abiutil.BigOrZero(tx.SafeTxGas),
+		abiutil.BigOrZero(tx.BaseGas), abiutil.BigOrZero(tx.GasPrice), tx.GasToken, tx.RefundReceiver, abiutil.BigOrZero(tx.Nonce))
+	return hash, err
+}
+
+// Hash 计算 tx 在本 Safe 上的 safeTxHash，链ID取自钱包，没有钱包时返回 ErrWalletNil
+func (s *Safe) Hash(tx *Transaction) (common.Hash, error) {
+	if s.Wallet == nil {
+		return common.Hash{}, goether.ErrWalletNil
+	}
+	return tx.Hash(s.Address, s.Wallet.ChainID)
+}
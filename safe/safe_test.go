@@ -0,0 +1,164 @@
+package safe
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKey = "8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632"
+
+var safeABI, _ = abi.JSON(strings.NewReader(SafeABI))
+
+// newMockWallet 启动一个按方法名分发的 JSON-RPC 服务并返回连接到该服务的钱包
+func newMockWallet(t *testing.T, handlers map[string]func(params []json.RawMessage) (any, error)) *goether.Wallet {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		h, ok := handlers[req.Method]
+		if !ok {
+			resp["error"] = &goether.RPCError{Code: -32601, Message: "method not found"}
+		} else if result, err := h(req.Params); err != nil {
+			resp["error"] = err
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(rw).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	w, err := goether.NewWallet(testKey, server.URL, big.NewInt(1))
+	assert.NoError(t, err)
+	return w
+}
+
+func TestTransactionHash(t *testing.T) {
+	safeAddress := common.HexToAddress("0x00000000000000000000000000000000000005af")
+	tx := &Transaction{
+		To:             common.HexToAddress("0x00000000000000000000000000000000000000b0"),
+		Value:          big.NewInt(1e18),
+		Data:           []byte{0xa9, 0x05, 0x9c, 0xbb},
+		Operation:      DelegateCall,
+		SafeTxGas:      big.NewInt(50000),
+		RefundReceiver: common.HexToAddress("0x00000000000000000000000000000000000000c0"),
+		Nonce:          big.NewInt(7),
+	}
+
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}},
+			"SafeTx": {
+				{Name: "to", Type: "address"}, {Name: "value", Type: "uint256"}, {Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"}, {Name: "safeTxGas", Type: "uint256"}, {Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"}, {Name: "gasToken", Type: "address"}, {Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain:      apitypes.TypedDataDomain{ChainId: math.NewHexOrDecimal256(5), VerifyingContract: safeAddress.Hex()},
+		Message: apitypes.TypedDataMessage{
+			"to":             tx.To.Hex(),
+			"value":          "1000000000000000000",
+			"data":           hexutil.Encode(tx.Data),
+			"operation":      "1",
+			"safeTxGas":      "50000",
+			"baseGas":        "0",
+			"gasPrice":       "0",
+			"gasToken":       common.Address{}.Hex(),
+			"refundReceiver": tx.RefundReceiver.Hex(),
+			"nonce":          "7",
+		},
+	}
+	expected, _, err := apitypes.TypedDataAndHash(typed)
+	assert.NoError(t, err)
+	hash, err := tx.Hash(safeAddress, big.NewInt(5))
+	assert.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(expected), hash)
+	other, err := tx.Hash(safeAddress, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	// 负数与超过 uint256 的值会被 ABI 编码截断，必须返回错误而不是错误的哈希
+	tx.Value = big.NewInt(-1)
+	_, err = tx.Hash(safeAddress, big.NewInt(5))
+	assert.Error(t, err)
+	tx.Value = new(big.Int).Lsh(big.NewInt(1), 256)
+	_, err = tx.Hash(safeAddress, big.NewInt(5))
+	assert.Error(t, err)
+}
+
+func TestSafeViews(t *testing.T) {
+	safeAddress := common.HexToAddress("0x00000000000000000000000000000000000005af")
+	owners := []common.Address{common.HexToAddress("0xa1"), common.HexToAddress("0xa2")}
+	tx := &Transaction{To: common.HexToAddress("0xb0"), Value: big.NewInt(1), Nonce: big.NewInt(4)}
+	w := newMockWallet(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_call": func(params []json.RawMessage) (any, error) {
+			var msg map[string]string
+			json.Unmarshal(params[0], &msg)
+			assert.Equal(t, safeAddress, common.HexToAddress(msg["to"]))
+			data := hexutil.MustDecode(msg["data"])
+			method, err := safeABI.MethodById(data)
+			assert.NoError(t, err)
+			var out []byte
+			switch method.Name {
+			case "getThreshold":
+				out, _ = method.Outputs.Pack(big.NewInt(2))
+			case "getOwners":
+				out, _ = method.Outputs.Pack(owners)
+			case "nonce":
+				out, _ = method.Outputs.Pack(big.NewInt(4))
+			case "getTransactionHash":
+				hash, _ := tx.Hash(safeAddress, big.NewInt(1))
+				out, _ = method.Outputs.Pack(hash)
+			}
+			return hexutil.Encode(out), nil
+		},
+	})
+	s, err := NewSafe(safeAddress, w)
+	assert.NoError(t, err)
+
+	threshold, err := s.Threshold()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), threshold)
+	got, err := s.Owners()
+	assert.NoError(t, err)
+	assert.Equal(t, owners, got)
+
+	next, err := s.NewTransaction(tx.To, tx.Value, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(4), next.Nonce)
+	hash, err := s.Hash(tx)
+	assert.NoError(t, err)
+	nextHash, err := s.Hash(next)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, nextHash)
+
+	onchain, err := s.TransactionHash(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, onchain)
+	onchain, err = s.TransactionHashContext(context.Background(), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, onchain)
+
+	// 没有钱包时不知道链ID
+	offline, err := NewSafe(safeAddress, nil)
+	assert.NoError(t, err)
+	_, err = offline.Hash(tx)
+	assert.ErrorIs(t, err, goether.ErrWalletNil)
+}
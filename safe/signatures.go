@@ -0,0 +1,233 @@
+package safe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/go-enols/goether/internal/abiutil"
+)
+
+var (
+	// ErrThresholdNotMet 收集到的所有者签名少于 Safe 的阈值
+	ErrThresholdNotMet = errors.New("safe signature threshold not met")
+	// ErrInvalidSignature 签名格式错误或不支持的签名类型
+	ErrInvalidSignature = errors.New("invalid safe signature")
+)
+
+// Collector 收集同一个 safeTxHash 的所有者签名，按所有者地址升序拼接为 execTransaction 的 signatures
+//
+// 支持直接对哈希签名（v 为 27/28）、eth_sign 签名（v 为 31/32）以及 approveHash 预批准（v 为 1），
+// 同一所有者重复添加时保留最后一次。
+type Collector struct {
+	Hash       common.Hash
+	signatures map[common.Address][]byte
+}
+
+// NewCollector 创建 hash 的签名收集器，hash 通常为 Safe.Hash(tx)
+func NewCollector(hash common.Hash) *Collector {
+	return &Collector{Hash: hash, signatures: make(map[common.Address][]byte)}
+}
+
+// Sign 使用本地签名器对 safeTxHash 签名并加入收集器
+func (c *Collector) Sign(signer goether.AccountSigner) error {
+	sig, err := signer.SignDigest(c.Hash.Bytes())
+	if err != nil {
+		return err
+	}
+	sig, err = goether.SignatureToV27(sig)
+	if err != nil {
+		return err
+	}
+	_, err = c.Add(sig)
+	return err
+}
+
+// Add 加入一个 65 字节的签名并返回恢复出的所有者地址
+func (c *Collector) Add(sig []byte) (common.Address, error) {
+	owner, err := c.recover(sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	c.signatures[owner] = common.CopyBytes(sig)
+	return owner, nil
+}
+
+// AddHex 加入十六进制格式的签名，例如其他所有者通过钱包导出的签名
+func (c *Collector) AddHex(sig string) (common.Address, error) {
+	b, err := hexutil.Decode(sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return c.Add(b)
+}
+
+// Approved 加入已通过 approveHash 或作为交易发送方批准的所有者
+func (c *Collector) Approved(owner common.Address) {
+	sig := make([]byte, 65)
+	copy(sig[12:32], owner.Bytes())
+	sig[64] = 1
+	c.signatures[owner] = sig
+}
+
+// Fetch 从 Safe Transaction Service 拉取已提交的确认签名，serviceURL 例如 https://safe-transaction-mainnet.safe.global，
+// 返回新加入的签名数量，无法识别的签名会被跳过
+func (c *Collector) Fetch(ctx context.Context, serviceURL string) (int, error) {
+	url := fmt.Sprintf("%s/api/v1/multisig-transactions/%s/confirmations/", strings.TrimSuffix(serviceURL, "/"), c.Hash.Hex())
+	added := 0
+	for url != "" {
+		var page struct {
+			Next    *string `json:"next"`
+			Results []struct {
+				Owner     common.Address `json:"owner"`
+				Signature string         `json:"signature"`
+			} `json:"results"`
+		}
+		if err := getJSON(ctx, url, &page); err != nil {
+			return added, err
+		}
+		for _, confirmation := range page.Results {
+			if _, ok := c.signatures[confirmation.Owner]; ok {
+				continue
+			}
+			sig, err := hexutil.Decode(confirmation.Signature)
+			if err != nil {
+				continue
+			}
+			if owner, err := c.recover(sig); err != nil || owner != confirmation.Owner {
+				continue
+			}
+			c.signatures[confirmation.Owner] = sig
+			added++
+		}
+		url = ""
+		if page.Next != nil {
+			url = *page.Next
+		}
+	}
+	return added, nil
+}
+
+// Owners 已签名的所有者，按地址升序排列
+func (c *Collector) Owners() []common.Address {
+	owners := make([]common.Address, 0, len(c.signatures))
+	for owner := range c.signatures {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		return bytes.Compare(owners[i].Bytes(), owners[j].Bytes()) < 0
+	})
+	return owners
+}
+
+// Len 已收集的签名数量
+func (c *Collector) Len() int {
+	return len(c.signatures)
+}
+
+// Encode 按所有者地址升序拼接全部签名，Safe 要求签名者地址严格递增
+func (c *Collector) Encode() []byte {
+	var out []byte
+	for _, owner := range c.Owners() {
+		out = append(out, c.signatures[owner]...)
+	}
+	return out
+}
+
+// recover 根据 v 的取值恢复签名者
+func (c *Collector) recover(sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+	v := sig[64]
+	switch {
+	case v == 1:
+		return common.BytesToAddress(sig[:32]), nil
+	case v == 27 || v == 28:
+		return ecrecover(c.Hash.Bytes(), sig, v-27)
+	case v == 31 || v == 32:
+		return ecrecover(accounts.TextHash(c.Hash.Bytes()), sig, v-31)
+	}
+	return common.Address{}, fmt.Errorf("%w: unsupported v %d", ErrInvalidSignature, v)
+}
+
+func ecrecover(hash, sig []byte, v byte) (common.Address, error) {
+	normalized := common.CopyBytes(sig)
+	normalized[64] = v
+	pub, err := crypto.SigToPub(hash, normalized)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Execute 检查签名者均为所有者且数量达到阈值后发送 execTransaction，返回交易哈希
+//
+// 非所有者的签名会被忽略，签名数量超过阈值时全部提交，合约只校验前 threshold 个。
+func (s *Safe) Execute(tx *Transaction, signatures *Collector, opts *goether.TxOpts) (txHash string, err error) {
+	return s.ExecuteContext(context.Background(), tx, signatures, opts)
+}
+
+// ExecuteContext 与 Execute 相同，但查询与交易的发送受 ctx 控制
+func (s *Safe) ExecuteContext(ctx context.Context, tx *Transaction, signatures *Collector, opts *goether.TxOpts) (txHash string, err error) {
+	hash, err := s.Hash(tx)
+	if err != nil {
+		return "", err
+	}
+	if hash != signatures.Hash {
+		return "", fmt.Errorf("signatures are for %s, transaction hash is %s", signatures.Hash.Hex(), hash.Hex())
+	}
+	threshold, err := s.ThresholdContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	owners, err := s.OwnersContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	isOwner := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		isOwner[owner] = true
+	}
+	valid := NewCollector(signatures.Hash)
+	for owner, sig := range signatures.signatures {
+		if isOwner[owner] {
+			valid.signatures[owner] = sig
+		}
+	}
+	if big.NewInt(int64(valid.Len())).Cmp(threshold) < 0 {
+		return "", fmt.Errorf("%w: %d of %s", ErrThresholdNotMet, valid.Len(), threshold)
+	}
+	return s.ExecMethodContext(ctx, "execTransaction", opts,
+		tx.To, abiutil.BigOrZero(tx.Value), abiutil.BytesOrEmpty(tx.Data), uint8(tx.Operation), abiutil.BigOrZero(tx.SafeTxGas),
+		abiutil.BigOrZero(tx.BaseGas), abiutil.BigOrZero(tx.GasPrice), tx.GasToken, tx.RefundReceiver, valid.Encode())
+}
+
+// getJSON 发送 GET 请求并将 JSON 响应解码到 out
+func getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,203 @@
+package safe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+var testOwnerKeys = []string{
+	"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcac78d8a6e0a8f2f9",
+	"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+	"5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a",
+}
+
+func testOwners(t *testing.T) []*goether.Signer {
+	var signers []*goether.Signer
+	for _, key := range testOwnerKeys {
+		s, err := goether.NewSigner(key)
+		assert.NoError(t, err)
+		signers = append(signers, s)
+	}
+	return signers
+}
+
+// ethSign 以 eth_sign 方式签名 hash，v 加 4 标记为 Safe 的 eth_sign 签名
+func ethSign(t *testing.T, key string, hash common.Hash) []byte {
+	priv, err := crypto.HexToECDSA(key)
+	assert.NoError(t, err)
+	sig, err := crypto.Sign(accounts.TextHash(hash.Bytes()), priv)
+	assert.NoError(t, err)
+	sig[64] += 31
+	return sig
+}
+
+func TestCollector(t *testing.T) {
+	owners := testOwners(t)
+	hash := crypto.Keccak256Hash([]byte("safe tx"))
+	c := NewCollector(hash)
+
+	assert.NoError(t, c.Sign(owners[0]))
+	signer, err := c.AddHex(hexutil.Encode(ethSign(t, testOwnerKeys[1], hash)))
+	assert.NoError(t, err)
+	assert.Equal(t, owners[1].Address, signer)
+	c.Approved(owners[2].Address)
+	// 重复签名只保留一份
+	assert.NoError(t, c.Sign(owners[0]))
+	assert.Equal(t, 3, c.Len())
+
+	sorted := c.Owners()
+	assert.Len(t, sorted, 3)
+	for i := 1; i < len(sorted); i++ {
+		assert.Equal(t, -1, bytes.Compare(sorted[i-1].Bytes(), sorted[i].Bytes()))
+	}
+	encoded := c.Encode()
+	assert.Len(t, encoded, 3*65)
+	for i, owner := range sorted {
+		recovered, err := c.recover(encoded[i*65 : (i+1)*65])
+		assert.NoError(t, err)
+		assert.Equal(t, owner, recovered)
+	}
+
+	_, err = c.Add(make([]byte, 64))
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+	bad := make([]byte, 65)
+	bad[64] = 5
+	_, err = c.Add(bad)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+	_, err = c.AddHex("0xzz")
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+}
+
+func TestCollectorFetch(t *testing.T) {
+	owners := testOwners(t)
+	hash := crypto.Keccak256Hash([]byte("safe tx"))
+	direct, err := owners[0].SignDigest(hash.Bytes())
+	assert.NoError(t, err)
+	direct, _ = goether.SignatureToV27(direct)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/multisig-transactions/"+hash.Hex()+"/confirmations/" {
+			http.NotFound(rw, r)
+			return
+		}
+		if r.URL.Query().Get("offset") == "" {
+			next := server.URL + r.URL.Path + "?offset=2"
+			json.NewEncoder(rw).Encode(map[string]any{"next": next, "results": []map[string]string{
+				{"owner": owners[0].Address.Hex(), "signature": hexutil.Encode(direct)},
+				// 签名与声明的所有者不一致，应被跳过
+				{"owner": owners[2].Address.Hex(), "signature": hexutil.Encode(direct)},
+			}})
+			return
+		}
+		json.NewEncoder(rw).Encode(map[string]any{"next": nil, "results": []map[string]string{
+			{"owner": owners[1].Address.Hex(), "signature": hexutil.Encode(ethSign(t, testOwnerKeys[1], hash))},
+		}})
+	}))
+	defer server.Close()
+
+	c := NewCollector(hash)
+	added, err := c.Fetch(context.Background(), server.URL+"/")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.ElementsMatch(t, []common.Address{owners[0].Address, owners[1].Address}, c.Owners())
+
+	// 已有的签名不会重复计数
+	added, err = c.Fetch(context.Background(), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	_, err = NewCollector(hash).Fetch(context.Background(), server.URL+"/missing")
+	assert.Error(t, err)
+}
+
+func TestExecute(t *testing.T) {
+	owners := testOwners(t)
+	safeAddress := common.HexToAddress("0x00000000000000000000000000000000000005af")
+	var sent []*types.Transaction
+	w := newMockWallet(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_call": func(params []json.RawMessage) (any, error) {
+			var msg map[string]string
+			json.Unmarshal(params[0], &msg)
+			data := hexutil.MustDecode(msg["data"])
+			method, err := safeABI.MethodById(data)
+			assert.NoError(t, err)
+			var out []byte
+			switch method.Name {
+			case "getThreshold":
+				out, _ = method.Outputs.Pack(big.NewInt(2))
+			case "getOwners":
+				out, _ = method.Outputs.Pack([]common.Address{owners[0].Address, owners[1].Address})
+			default:
+				return nil, fmt.Errorf("unexpected call %s", method.Name)
+			}
+			return hexutil.Encode(out), nil
+		},
+		"eth_getTransactionCount": func(params []json.RawMessage) (any, error) { return "0x0", nil },
+		"eth_estimateGas":         func(params []json.RawMessage) (any, error) { return "0x30d40", nil },
+		"eth_gasPrice":            func(params []json.RawMessage) (any, error) { return "0x3b9aca00", nil },
+		"eth_sendRawTransaction": func(params []json.RawMessage) (any, error) {
+			var raw string
+			json.Unmarshal(params[0], &raw)
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+				return nil, err
+			}
+			sent = append(sent, tx)
+			return tx.Hash().Hex(), nil
+		},
+	})
+	s, err := NewSafe(safeAddress, w)
+	assert.NoError(t, err)
+
+	tx := &Transaction{To: common.HexToAddress("0xb0"), Value: big.NewInt(1), Nonce: big.NewInt(0)}
+	hash, err := s.Hash(tx)
+	assert.NoError(t, err)
+	c := NewCollector(hash)
+	assert.NoError(t, c.Sign(owners[0]))
+	// 非所有者的签名不计入阈值
+	assert.NoError(t, c.Sign(owners[2]))
+	_, err = s.Execute(tx, c, nil)
+	assert.True(t, errors.Is(err, ErrThresholdNotMet))
+	assert.Empty(t, sent)
+
+	_, err = s.Execute(&Transaction{To: tx.To, Nonce: big.NewInt(1)}, c, nil)
+	assert.Error(t, err)
+
+	assert.NoError(t, c.Sign(owners[1]))
+	txHash, err := s.Execute(tx, c, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Equal(t, txHash, sent[0].Hash().Hex())
+	assert.Equal(t, safeAddress, *sent[0].To())
+
+	method, err := safeABI.MethodById(sent[0].Data())
+	assert.NoError(t, err)
+	assert.Equal(t, "execTransaction", method.Name)
+	args, err := method.Inputs.Unpack(sent[0].Data()[4:])
+	assert.NoError(t, err)
+	signatures := args[9].([]byte)
+	assert.Len(t, signatures, 2*65)
+	valid := NewCollector(c.Hash)
+	for i := 0; i < 2; i++ {
+		_, err := valid.Add(signatures[i*65 : (i+1)*65])
+		assert.NoError(t, err)
+	}
+	assert.ElementsMatch(t, []common.Address{owners[0].Address, owners[1].Address}, valid.Owners())
+	assert.Equal(t, valid.Encode(), signatures)
+}
@@ -0,0 +1,262 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	// OpenchainURL openchain 签名数据库查询接口，优先使用
+	OpenchainURL = "https://api.openchain.xyz/signature-database/v1/lookup"
+	// FourByteURL 4byte.directory 函数签名查询接口，openchain 没有结果时使用
+	FourByteURL = "https://www.4byte.directory/api/v1/signatures/"
+	// SignatureCacheDir 函数签名的本地缓存目录，每个选择器保存为一个文件，为空时只缓存在内存中
+	SignatureCacheDir = defaultCacheDir("signatures")
+)
+
+// signatureCache 进程内的选择器签名缓存，只缓存查询到结果的选择器
+var signatureCache sync.Map
+
+// DecodedCall 根据签名数据库解码的调用数据
+type DecodedCall struct {
+	// Signature 匹配的函数签名，例如 transfer(address,uint256)
+	Signature string
+	Method    abi.Method
+	Args      []interface{}
+	// Candidates 该选择器对应的全部候选签名，不同函数可能具有相同的选择器
+	Candidates []string
+}
+
+// LookupSelector 查询 4 字节函数选择器对应的候选签名，先查缓存，再依次查询 openchain 与 4byte.directory
+func LookupSelector(selector [4]byte) ([]string, error) {
+	return LookupSelectorContext(context.Background(), selector)
+}
+
+// LookupSelectorContext 与 LookupSelector 相同，但请求受 ctx 控制
+func LookupSelectorContext(ctx context.Context, selector [4]byte) ([]string, error) {
+	hex := hexutil.Encode(selector[:])
+	if cached, ok := signatureCache.Load(hex); ok {
+		return cached.([]string), nil
+	}
+	cacheFile := ""
+	if SignatureCacheDir != "" {
+		cacheFile = filepath.Join(SignatureCacheDir, hex+".json")
+		var signatures []string
+		if data, err := os.ReadFile(cacheFile); err == nil && json.Unmarshal(data, &signatures) == nil && len(signatures) > 0 {
+			signatureCache.Store(hex, signatures)
+			return signatures, nil
+		}
+	}
+
+	signatures, err := openchainSignatures(ctx, hex)
+	if err != nil {
+		log.Warning("Failed to query openchain", "selector", hex, "error", err)
+	}
+	if len(signatures) == 0 {
+		if signatures, err = fourByteSignatures(ctx, hex); err != nil {
+			log.Warning("Failed to query 4byte.directory", "selector", hex, "error", err)
+			return nil, err
+		}
+	}
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSignatureNotFound, hex)
+	}
+
+	signatureCache.Store(hex, signatures)
+	if cacheFile != "" {
+		data, _ := json.Marshal(signatures)
+		err = os.MkdirAll(SignatureCacheDir, 0o755)
+		if err == nil {
+			err = os.WriteFile(cacheFile, data, 0o644)
+		}
+		if err != nil {
+			log.Warning("Failed to cache signatures", "file", cacheFile, "error", err)
+		}
+	}
+	log.Debug("Selector resolved", "selector", hex, "signatures", signatures)
+	return signatures, nil
+}
+
+// openchainSignatures 查询 openchain，忽略被标记为垃圾数据的签名
+func openchainSignatures(ctx context.Context, hex string) ([]string, error) {
+	var body struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			Function map[string][]struct {
+				Name     string `json:"name"`
+				Filtered bool   `json:"filtered"`
+			} `json:"function"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, OpenchainURL+"?"+url.Values{"function": {hex}}.Encode(), &body); err != nil {
+		return nil, err
+	}
+	if !body.OK {
+		return nil, errors.New("openchain returned not ok")
+	}
+	var signatures []string
+	for _, s := range body.Result.Function[hex] {
+		if !s.Filtered {
+			signatures = append(signatures, s.Name)
+		}
+	}
+	return signatures, nil
+}
+
+// fourByteSignatures 查询 4byte.directory，按提交时间排序，较早提交的签名更可能是真实函数
+func fourByteSignatures(ctx context.Context, hex string) ([]string, error) {
+	var body struct {
+		Results []struct {
+			ID            int    `json:"id"`
+			TextSignature string `json:"text_signature"`
+		} `json:"results"`
+	}
+	if err := getJSON(ctx, FourByteURL+"?"+url.Values{"hex_signature": {hex}}.Encode(), &body); err != nil {
+		return nil, err
+	}
+	sort.Slice(body.Results, func(i, j int) bool { return body.Results[i].ID < body.Results[j].ID })
+	signatures := make([]string, len(body.Results))
+	for i, r := range body.Results {
+		signatures[i] = r.TextSignature
+	}
+	return signatures, nil
+}
+
+// DecodeCalldata 在没有 ABI 的情况下通过签名数据库识别并解码调用数据
+//
+// 依次尝试每个候选签名，优先选择解码后重新编码与原数据完全一致的签名，
+// 否则使用第一个能够解码的签名。参数没有名称，结果仅供分析参考。
+func DecodeCalldata(data []byte) (*DecodedCall, error) {
+	return DecodeCalldataContext(context.Background(), data)
+}
+
+// DecodeCalldataContext 与 DecodeCalldata 相同，但请求受 ctx 控制
+func DecodeCalldataContext(ctx context.Context, data []byte) (*DecodedCall, error) {
+	if len(data) < 4 {
+		return nil, ErrDataTooShort
+	}
+	signatures, err := LookupSelectorContext(ctx, [4]byte(data[:4]))
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback *DecodedCall
+	for _, signature := range signatures {
+		method, err := methodFromSignature(signature)
+		if err != nil {
+			log.Debug("Skipping unparsable signature", "signature", signature, "error", err)
+			continue
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			continue
+		}
+		call := &DecodedCall{Signature: signature, Method: method, Args: args, Candidates: signatures}
+		if packed, err := method.Inputs.Pack(args...); err == nil && bytes.Equal(packed, data[4:]) {
+			return call, nil
+		}
+		if fallback == nil {
+			fallback = call
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("calldata does not match any of %d known signatures for %s", len(signatures), hexutil.Encode(data[:4]))
+	}
+	return fallback, nil
+}
+
+// methodFromSignature 将 name(type1,type2) 形式的签名解析为 abi.Method，参数依次命名为 arg0、arg1
+func methodFromSignature(signature string) (abi.Method, error) {
+	open := strings.IndexByte(signature, '(')
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return abi.Method{}, fmt.Errorf("invalid signature %q", signature)
+	}
+	name := signature[:open]
+	types, err := splitTypes(signature[open+1 : len(signature)-1])
+	if err != nil {
+		return abi.Method{}, err
+	}
+	inputs := make(abi.Arguments, len(types))
+	for i, t := range types {
+		marshaling, err := typeMarshaling(t)
+		if err != nil {
+			return abi.Method{}, err
+		}
+		typ, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+		if err != nil {
+			return abi.Method{}, err
+		}
+		inputs[i] = abi.Argument{Name: fmt.Sprintf("arg%d", i), Type: typ}
+	}
+	method := abi.NewMethod(name, name, abi.Function, "", false, false, inputs, nil)
+	if method.Sig != signature {
+		return abi.Method{}, fmt.Errorf("invalid signature %q", signature)
+	}
+	return method, nil
+}
+
+// typeMarshaling 将签名中的类型转换为 abi.ArgumentMarshaling，(a,b)[] 这样的元组转换为 tuple[]
+func typeMarshaling(t string) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(t, "(") {
+		return abi.ArgumentMarshaling{Type: t}, nil
+	}
+	end := strings.LastIndexByte(t, ')')
+	types, err := splitTypes(t[1:end])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	m := abi.ArgumentMarshaling{Type: "tuple" + t[end+1:]}
+	for i, component := range types {
+		c, err := typeMarshaling(component)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		c.Name = fmt.Sprintf("field%d", i)
+		m.Components = append(m.Components, c)
+	}
+	return m, nil
+}
+
+// splitTypes 按顶层逗号拆分参数类型列表
+func splitTypes(list string) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var (
+		types []string
+		depth int
+		start int
+	)
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in %q", list)
+			}
+		case ',':
+			if depth == 0 {
+				types = append(types, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %q", list)
+	}
+	return append(types, list[start:]), nil
+}
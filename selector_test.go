@@ -0,0 +1,100 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCalldata(t *testing.T) {
+	swap, err := methodFromSignature("swap((address,uint256)[],bool)")
+	assert.NoError(t, err)
+	assert.Equal(t, "swap((address,uint256)[],bool)", swap.Sig)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/openchain":
+			selector := r.URL.Query().Get("function")
+			functions := map[string]any{}
+			if selector == "0xa9059cbb" {
+				// 错误的签名排在前面，bytes 的偏移量无效所以无法解码
+				functions[selector] = []map[string]any{
+					{"name": "fake(bytes)", "filtered": false},
+					{"name": "spam()", "filtered": true},
+					{"name": "transfer(address,uint256)", "filtered": false},
+				}
+			}
+			json.NewEncoder(rw).Encode(map[string]any{"ok": true, "result": map[string]any{"function": functions}})
+		case "/4byte/":
+			results := []map[string]any{}
+			if r.URL.Query().Get("hex_signature") == hexutil.Encode(swap.ID) {
+				results = append(results,
+					map[string]any{"id": 9, "text_signature": "collision(uint256)"},
+					map[string]any{"id": 3, "text_signature": swap.Sig},
+				)
+			}
+			json.NewEncoder(rw).Encode(map[string]any{"results": results})
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	defer func(openchain, fourByte, dir string) {
+		OpenchainURL, FourByteURL, SignatureCacheDir = openchain, fourByte, dir
+	}(OpenchainURL, FourByteURL, SignatureCacheDir)
+	OpenchainURL = server.URL + "/openchain"
+	FourByteURL = server.URL + "/4byte/"
+	SignatureCacheDir = t.TempDir()
+	signatureCache.Clear()
+	defer signatureCache.Clear()
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	data := append(hexutil.MustDecode("0xa9059cbb"), common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)...)
+	call, err := DecodeCalldata(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", call.Signature)
+	assert.Equal(t, []interface{}{to, big.NewInt(1000)}, call.Args)
+	assert.Equal(t, []string{"fake(bytes)", "transfer(address,uint256)"}, call.Candidates)
+	assert.FileExists(t, filepath.Join(SignatureCacheDir, "0xa9059cbb.json"))
+
+	// 内存缓存与文件缓存都不再请求签名数据库
+	n := requests.Load()
+	_, err = DecodeCalldata(data)
+	assert.NoError(t, err)
+	signatureCache.Clear()
+	_, err = DecodeCalldata(data)
+	assert.NoError(t, err)
+	assert.Equal(t, n, requests.Load())
+
+	// openchain 没有结果时查询 4byte.directory，元组参数也可以解码
+	type leg struct {
+		Field0 common.Address
+		Field1 *big.Int
+	}
+	packed, err := swap.Inputs.Pack([]leg{{to, big.NewInt(7)}}, true)
+	assert.NoError(t, err)
+	call, err = DecodeCalldata(append(swap.ID, packed...))
+	assert.NoError(t, err)
+	assert.Equal(t, swap.Sig, call.Signature)
+	assert.Equal(t, []string{swap.Sig, "collision(uint256)"}, call.Candidates)
+	assert.Equal(t, true, call.Args[1])
+
+	_, err = DecodeCalldata(hexutil.MustDecode("0xdeadbeef"))
+	assert.ErrorIs(t, err, ErrSignatureNotFound)
+	_, err = DecodeCalldata([]byte{1, 2})
+	assert.ErrorIs(t, err, ErrDataTooShort)
+	_, err = methodFromSignature("broken(uint256")
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+// AuthorizationGas EIP-7702 中每个授权元组额外消耗的 gas（PER_EMPTY_ACCOUNT_COST），
+// eth_estimateGas 无法携带授权列表，自动估算 gasLimit 时按授权数量追加
+const AuthorizationGas = 25000
+
+// SignAuthorization 使用 signer 签名 EIP-7702 授权元组，将 signer 账户的代码委托给 delegate
+//
+// chainID 为 0 时授权在所有链上有效；nonce 为授权账户在交易执行时的 nonce，
+// 授权账户同时也是交易发送者时需要使用交易 nonce + 1。
+func SignAuthorization(signer AccountSigner, chainID *big.Int, delegate common.Address, nonce uint64) (types.SetCodeAuthorization, error) {
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	auth := types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(chainID),
+		Address: delegate,
+		Nonce:   nonce,
+	}
+
+	payload, err := rlp.EncodeToBytes([]any{&auth.ChainID, auth.Address, auth.Nonce})
+	if err != nil {
+		return auth, err
+	}
+	sig, err := signer.SignDigest(crypto.Keccak256(append([]byte{0x05}, payload...)))
+	if err != nil {
+		log.Error("Failed to sign authorization", "delegate", delegate.Hex(), "error", err)
+		return auth, err
+	}
+	auth.R.SetBytes(sig[:32])
+	auth.S.SetBytes(sig[32:64])
+	auth.V = sig[64]
+	return auth, nil
+}
+
+// SignAuthorization 使用钱包的签名器与链ID签名 EIP-7702 授权元组
+func (w *Wallet) SignAuthorization(delegate common.Address, nonce uint64) (types.SetCodeAuthorization, error) {
+	return SignAuthorization(w.Signer, w.ChainID, delegate, nonce)
+}
+
+// SendSetCodeTx 发送 EIP-7702 类型 4 交易，authList 中的每个授权都会把授权账户的代码委托给对应地址
+func (w *Wallet) SendSetCodeTx(to common.Address, amount *big.Int, data []byte, authList []types.SetCodeAuthorization, opts *TxOpts) (txHash string, err error) {
+	return w.SendSetCodeTxContext(context.Background(), to, amount, data, authList, opts)
+}
+
+// SendSetCodeTxContext 与 SendSetCodeTx 相同，但所有 RPC 调用都受 ctx 控制
+func (w *Wallet) SendSetCodeTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, authList []types.SetCodeAuthorization, opts *TxOpts) (txHash string, err error) {
+	log.Debug("Sending set code transaction",
+		"from", w.Address.Hex(),
+		"to", to.Hex(),
+		"authorizations", len(authList))
+	if len(authList) == 0 {
+		return "", errors.New("authorization list is empty")
+	}
+
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	estimate := opts == nil || opts.GasLimit == nil
+	opts, err = w.InitTxOptsContext(ctx, to, amount, data, opts)
+	if err != nil {
+		log.Error("Failed to initialize set code transaction options", "error", err)
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(*opts.Nonce, &err)
+	}
+	if estimate {
+		gasLimit := *opts.GasLimit + AuthorizationGas*len(authList)
+		opts.GasLimit = &gasLimit
+	}
+
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	tx, err := signTxWith(w.signDigest(ctx), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(w.ChainID),
+		Nonce:     uint64(*opts.Nonce),
+		GasTipCap: uint256.MustFromBig(opts.GasTipCap),
+		GasFeeCap: uint256.MustFromBig(opts.GasFeeCap),
+		Gas:       uint64(*opts.GasLimit),
+		To:        to,
+		Value:     uint256.MustFromBig(amount),
+		Data:      data,
+		AuthList:  authList,
+	}, types.LatestSignerForChainID(w.ChainID))
+	if err != nil {
+		log.Error("Failed to sign set code transaction", "error", err)
+		return
+	}
+
+	txHash, err = w.sendTx(ctx, tx, opts)
+	if err != nil {
+		log.Error("Failed to send set code transaction", "error", err)
+		return
+	}
+
+	log.Debug("Set code transaction sent successfully", "txHash", txHash)
+	return txHash, nil
+}
@@ -0,0 +1,45 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendSetCodeTx(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 2, &sent)
+	w := newTestWallet(t, m)
+
+	delegate := common.HexToAddress("0x63c0c19a282a1B52b07dD5a65b58948A07DAE32B")
+	auth, err := w.SignAuthorization(delegate, 3)
+	assert.NoError(t, err)
+	authority, err := auth.Authority()
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, authority)
+
+	expected, err := types.SignSetCode(TestSigner.GetPrivateKey(), types.SetCodeAuthorization{
+		ChainID: auth.ChainID,
+		Address: delegate,
+		Nonce:   3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, auth)
+
+	_, err = w.SendSetCodeTx(w.Address, nil, nil, []types.SetCodeAuthorization{auth}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Equal(t, uint8(types.SetCodeTxType), sent[0].Type())
+	assert.Equal(t, uint64(21000+AuthorizationGas), sent[0].Gas())
+	assert.Len(t, sent[0].SetCodeAuthorizations(), 1)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), sent[0])
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, from)
+
+	_, err = w.SendSetCodeTx(w.Address, nil, nil, nil, nil)
+	assert.Error(t, err)
+}
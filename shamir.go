@@ -0,0 +1,138 @@
+package goether
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SplitKey 使用 Shamir 秘密共享将私钥拆分为 n 份，任意 k 份即可恢复，少于 k 份不会泄露私钥的任何信息
+//
+// 每份为 33 字节：32 字节的份额值加 1 字节的横坐标，可以使用 hexutil.Encode 保存。
+// 签名器调用 DisableKeyExport 或 Close 之后不能拆分。
+func (s Signer) SplitKey(n, k int) ([][]byte, error) {
+	if s.noExport {
+		return nil, ErrKeyExportDisabled
+	}
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	seckey := crypto.FromECDSA(key)
+	defer wipeBytes(seckey)
+
+	log.Debug("Splitting private key", "address", s.Address.Hex(), "shares", n, "threshold", k)
+	return shamirSplit(seckey, n, k)
+}
+
+// RecoverKey 使用 SplitKey 生成的至少 k 份份额恢复签名器
+//
+// 份额不足 k 份时无法发现错误，会得到另一个私钥，恢复后应当核对 Address。
+func RecoverKey(shares [][]byte) (*Signer, error) {
+	secret, err := shamirCombine(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(secret)
+	key, err := crypto.ToECDSA(secret)
+	if err != nil {
+		return nil, err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	log.Debug("Private key recovered from shares", "address", address.Hex(), "shares", len(shares))
+	return &Signer{
+		key:     key,
+		Address: address,
+	}, nil
+}
+
+// shamirSplit 在 GF(2^8) 上对 secret 的每个字节分别生成 k-1 次随机多项式，
+// 份额的横坐标为 1 到 n
+func shamirSplit(secret []byte, n, k int) ([][]byte, error) {
+	if k < 2 || n < k || n > 255 {
+		return nil, fmt.Errorf("invalid shamir parameters: n=%d, k=%d, require 2 <= k <= n <= 255", n, k)
+	}
+	coefficients := make([]byte, k-1)
+	defer wipeBytes(coefficients)
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+	for b, value := range secret {
+		if _, err := rand.Read(coefficients); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			x := share[len(secret)]
+			// 秦九韶算法计算多项式在 x 处的值
+			var y byte
+			for c := len(coefficients) - 1; c >= 0; c-- {
+				y = gfMul(y, x) ^ coefficients[c]
+			}
+			share[b] = gfMul(y, x) ^ value
+		}
+	}
+	return shares, nil
+}
+
+// shamirCombine 使用拉格朗日插值计算多项式在 0 处的值
+func shamirCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least two shares are required")
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, errors.New("invalid share length")
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, errors.New("shares have different lengths")
+		}
+		xs[i] = share[size-1]
+		if xs[i] == 0 || seen[xs[i]] {
+			return nil, errors.New("duplicate or invalid share")
+		}
+		seen[xs[i]] = true
+	}
+
+	secret := make([]byte, size-1)
+	for i, share := range shares {
+		// 基函数 l_i(0) = Π x_j / (x_j - x_i)，GF(2^8) 中减法即异或
+		basis := byte(1)
+		for j := range shares {
+			if i != j {
+				basis = gfMul(basis, gfDiv(xs[j], xs[j]^xs[i]))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= gfMul(share[b], basis)
+		}
+	}
+	return secret, nil
+}
+
+// gfMul GF(2^8) 乘法，使用 AES 的不可约多项式 x^8 + x^4 + x^3 + x + 1，不依赖输入分支
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		a = a<<1 ^ (0x1b & -(a >> 7))
+		b >>= 1
+	}
+	return p
+}
+
+// gfDiv GF(2^8) 除法，b 的逆元为 b^254
+func gfDiv(a, b byte) byte {
+	inv := b
+	for i := 0; i < 6; i++ {
+		inv = gfMul(gfMul(inv, inv), b)
+	}
+	return gfMul(a, gfMul(inv, inv))
+}
@@ -0,0 +1,53 @@
+package goether
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitKey(t *testing.T) {
+	shares, err := TestSigner.SplitKey(5, 3)
+	assert.NoError(t, err)
+	assert.Len(t, shares, 5)
+	for _, share := range shares {
+		assert.Len(t, share, 33)
+	}
+
+	for _, subset := range [][][]byte{
+		{shares[0], shares[1], shares[2]},
+		{shares[4], shares[2], shares[0]},
+		{shares[1], shares[3], shares[4], shares[0]},
+		shares,
+	} {
+		signer, err := RecoverKey(subset)
+		assert.NoError(t, err)
+		assert.Equal(t, TestSigner.Address, signer.Address)
+	}
+
+	// 份额不足时得到错误的私钥
+	signer, err := RecoverKey(shares[:2])
+	if err == nil {
+		assert.NotEqual(t, TestSigner.Address, signer.Address)
+	}
+
+	_, err = RecoverKey([][]byte{shares[0], shares[0]})
+	assert.Error(t, err)
+	_, err = TestSigner.SplitKey(2, 3)
+	assert.Error(t, err)
+
+	locked, err := NewSigner(testPrvHex)
+	assert.NoError(t, err)
+	locked.DisableKeyExport()
+	_, err = locked.SplitKey(3, 2)
+	assert.ErrorIs(t, err, ErrKeyExportDisabled)
+}
+
+func TestGFArithmetic(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), gfDiv(byte(a), byte(a)))
+		assert.Equal(t, byte(a), gfMul(byte(a), 1))
+	}
+	// AES 规范中的示例 {57} • {83} = {c1}
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+}
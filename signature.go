@@ -0,0 +1,230 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SplitSignature 将 65 字节 [R || S || V] 签名拆分为 r、s、v，v 保持签名中的原值，
+// 64 字节的 EIP-2098 紧凑签名会先展开，v 为 27 或 28
+func SplitSignature(sig []byte) (r, s, v *big.Int, err error) {
+	if len(sig) == 64 {
+		sig, _ = ExpandSignature(sig)
+	}
+	if len(sig) != 65 {
+		return nil, nil, nil, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:64])
+	v = new(big.Int).SetUint64(uint64(sig[64]))
+	return r, s, v, nil
+}
+
+// JoinSignature 将 r、s、v 组合为 65 字节 [R || S || V] 签名
+func JoinSignature(r, s, v *big.Int) ([]byte, error) {
+	if r == nil || s == nil || v == nil {
+		return nil, errors.New("signature component is nil")
+	}
+	if r.Sign() < 0 || r.BitLen() > 256 || s.Sign() < 0 || s.BitLen() > 256 || !v.IsUint64() || v.Uint64() > 255 {
+		return nil, errors.New("signature component out of range")
+	}
+	sig := make([]byte, 65)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[64] = byte(v.Uint64())
+	return sig, nil
+}
+
+// SignatureToV27 返回 v 为 27 或 28 的签名副本，合约中的 ecrecover 与 SignMsg 使用该格式
+func SignatureToV27(sig []byte) ([]byte, error) {
+	return setSignatureV(sig, 27)
+}
+
+// SignatureToV0 返回 v 为 0 或 1 的签名副本，crypto.Ecrecover 与 SignDigest 使用该格式
+func SignatureToV0(sig []byte) ([]byte, error) {
+	return setSignatureV(sig, 0)
+}
+
+// setSignatureV 将 v 转换为 base + yParity
+func setSignatureV(sig []byte, base byte) ([]byte, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	out := make([]byte, 65)
+	copy(out, sig)
+	switch out[64] {
+	case 0, 1:
+		out[64] += base
+	case 27, 28:
+		out[64] = out[64] - 27 + base
+	default:
+		return nil, fmt.Errorf("invalid signature v: %d", out[64])
+	}
+	return out, nil
+}
+
+// CompactSignature 将 65 字节签名转换为 EIP-2098 的 64 字节紧凑签名 [R || yParity<<255 | S]
+func CompactSignature(sig []byte) ([]byte, error) {
+	sig, err := SignatureToV0(sig)
+	if err != nil {
+		return nil, err
+	}
+	if sig[32]&0x80 != 0 {
+		return nil, errors.New("signature s is not canonical")
+	}
+	compact := sig[:64]
+	compact[32] |= sig[64] << 7
+	return compact, nil
+}
+
+// ExpandSignature 将 EIP-2098 的 64 字节紧凑签名转换为 v 为 27 或 28 的 65 字节签名
+func ExpandSignature(compact []byte) ([]byte, error) {
+	if len(compact) != 64 {
+		return nil, fmt.Errorf("invalid compact signature length: %d", len(compact))
+	}
+	sig := make([]byte, 65)
+	copy(sig, compact)
+	sig[64] = 27 + sig[32]>>7
+	sig[32] &= 0x7f
+	return sig, nil
+}
+
+// ERC1271ABI EIP-1271 合约签名验证接口
+const ERC1271ABI = `[
+{"inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}
+]`
+
+// erc1271MagicValue isValidSignature 验证通过时返回的 bytes4(keccak256("isValidSignature(bytes32,bytes)"))
+var erc1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// ERC6492MagicSuffix EIP-6492 包装签名末尾的 32 字节标记
+var ERC6492MagicSuffix = common.FromHex("0x6492649264926492649264926492649264926492649264926492649264926492")
+
+// erc6492Arguments EIP-6492 包装签名的内容 abi.encode(factory, factoryCalldata, signature)
+var erc6492Arguments = func() abi.Arguments {
+	addressType, _ := abi.NewType("address", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	return abi.Arguments{{Type: addressType}, {Type: bytesType}, {Type: bytesType}}
+}()
+
+// ERC6492Signature 解包后的 EIP-6492 签名
+type ERC6492Signature struct {
+	// Factory 部署智能账户的工厂合约
+	Factory common.Address
+	// FactoryCalldata 调用工厂部署账户的 calldata
+	FactoryCalldata []byte
+	// Signature 账户部署后 isValidSignature 使用的原始签名
+	Signature []byte
+}
+
+// IsERC6492Signature sig 是否为 EIP-6492 包装签名
+func IsERC6492Signature(sig []byte) bool {
+	return len(sig) > len(ERC6492MagicSuffix) && bytes.HasSuffix(sig, ERC6492MagicSuffix)
+}
+
+// ParseERC6492Signature 解包 EIP-6492 签名
+func ParseERC6492Signature(sig []byte) (*ERC6492Signature, error) {
+	if !IsERC6492Signature(sig) {
+		return nil, errors.New("not an ERC-6492 signature")
+	}
+	values, err := erc6492Arguments.Unpack(sig[:len(sig)-len(ERC6492MagicSuffix)])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ERC-6492 signature: %w", err)
+	}
+	return &ERC6492Signature{
+		Factory:         values[0].(common.Address),
+		FactoryCalldata: values[1].([]byte),
+		Signature:       values[2].([]byte),
+	}, nil
+}
+
+// VerifySignature 验证 sig 是否为 signer 对 hash 的有效签名
+//
+// signer 是合约账户（Safe、智能合约钱包等）时调用其 EIP-1271 isValidSignature，
+// 否则使用 ecrecover 验证。hash 为签名的摘要，消息签名使用 accounts.TextHash(msg)，
+// EIP-712 签名使用 EIP712Hash(typedData)。
+//
+// sig 为 EIP-6492 包装签名时支持尚未部署的智能账户：通过 Multicall3 在同一个 eth_call 中
+// 先调用工厂部署账户，再调用 isValidSignature，不会产生链上交易。
+func (w *Wallet) VerifySignature(signer common.Address, hash common.Hash, sig []byte) (bool, error) {
+	return w.VerifySignatureContext(context.Background(), signer, hash, sig)
+}
+
+// VerifySignatureContext 与 VerifySignature 相同，但查询受 ctx 控制
+func (w *Wallet) VerifySignatureContext(ctx context.Context, signer common.Address, hash common.Hash, sig []byte) (bool, error) {
+	isContract, err := w.IsContractContext(ctx, signer)
+	if err != nil {
+		return false, err
+	}
+	if IsERC6492Signature(sig) {
+		wrapped, err := ParseERC6492Signature(sig)
+		if err != nil {
+			return false, err
+		}
+		if isContract {
+			return w.isValidSignature(ctx, signer, hash, wrapped.Signature)
+		}
+		return w.isValidCounterfactualSignature(ctx, signer, hash, wrapped)
+	}
+	if !isContract {
+		_, address, err := Ecrecover(hash.Bytes(), sig)
+		if err != nil {
+			return false, err
+		}
+		return address == signer, nil
+	}
+	return w.isValidSignature(ctx, signer, hash, sig)
+}
+
+// isValidSignature 调用合约的 EIP-1271 isValidSignature，合约回滚视为签名无效
+func (w *Wallet) isValidSignature(ctx context.Context, signer common.Address, hash common.Hash, sig []byte) (bool, error) {
+	c, err := NewContract(signer, ERC1271ABI, "", w)
+	if err != nil {
+		return false, err
+	}
+	magic, err := callOne[[4]byte](ctx, c, "isValidSignature", hash, sig)
+	var revert *RevertError
+	var contractErr *ContractError
+	if errors.As(err, &revert) || errors.As(err, &contractErr) || errors.Is(err, ErrNoData) {
+		log.Debug("Contract rejected signature", "signer", signer.Hex(), "error", err)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	log.Debug("Contract signature checked", "signer", signer.Hex(), "magic", common.Bytes2Hex(magic[:]))
+	return bytes.Equal(magic[:], erc1271MagicValue[:]), nil
+}
+
+// isValidCounterfactualSignature 在 eth_call 中部署账户后验证签名，部署失败或合约回滚视为签名无效
+func (w *Wallet) isValidCounterfactualSignature(ctx context.Context, signer common.Address, hash common.Hash, wrapped *ERC6492Signature) (bool, error) {
+	c, err := NewContract(signer, ERC1271ABI, "", w)
+	if err != nil {
+		return false, err
+	}
+	data, err := c.ABI.Pack("isValidSignature", hash, wrapped.Signature)
+	if err != nil {
+		return false, err
+	}
+
+	mc := NewMulticall(w.rpc(ctx))
+	mc.AddRaw(wrapped.Factory, wrapped.FactoryCalldata, true)
+	mc.AddRaw(signer, data, true)
+	results, err := mc.CallContext(ctx, "latest")
+	if err != nil {
+		return false, err
+	}
+	if results[1].Err != nil {
+		log.Debug("Counterfactual account rejected signature", "signer", signer.Hex(), "factory", wrapped.Factory.Hex(), "error", results[1].Err)
+		return false, nil
+	}
+	out := results[1].Values[0].([]byte)
+	log.Debug("Counterfactual signature checked", "signer", signer.Hex(), "factory", wrapped.Factory.Hex(), "result", common.Bytes2Hex(out))
+	return len(out) >= 4 && bytes.Equal(out[:4], erc1271MagicValue[:]), nil
+}
@@ -0,0 +1,191 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	safe := common.HexToAddress("0x00000000000000000000000000000000000005af")
+	validHash := common.BytesToHash(accounts.TextHash([]byte("approved")))
+	parsed, _ := abi.JSON(strings.NewReader(ERC1271ABI))
+
+	m := newMockRPC(t)
+	m.On("eth_getCode", func(params []json.RawMessage) (any, error) {
+		var address string
+		json.Unmarshal(params[0], &address)
+		if common.HexToAddress(address) == safe {
+			return "0x6080604052", nil
+		}
+		return "0x", nil
+	})
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		assert.Equal(t, safe, common.HexToAddress(tx.To))
+		args, err := parsed.Methods["isValidSignature"].Inputs.Unpack(hexutil.MustDecode(tx.Data)[4:])
+		if err != nil {
+			return nil, err
+		}
+		if args[0].([32]byte) != validHash {
+			return nil, &mockError{Code: 3, Message: "execution reverted: invalid signature"}
+		}
+		out, _ := parsed.Methods["isValidSignature"].Outputs.Pack(erc1271MagicValue)
+		return hexutil.Encode(out), nil
+	})
+	w := newTestWallet(t, m)
+
+	ok, err := w.VerifySignature(safe, validHash, []byte{0x01})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = w.VerifySignature(safe, common.HexToHash("0x01"), []byte{0x01})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// EOA 使用 ecrecover
+	sig, err := w.Signer.SignMsg([]byte("approved"))
+	assert.NoError(t, err)
+	ok, err = w.VerifySignature(w.Address, validHash, sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = w.VerifySignature(common.HexToAddress("0x01"), validHash, sig)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifySignatureERC6492(t *testing.T) {
+	account := common.HexToAddress("0x0000000000000000000000000000000000006492")
+	factory := common.HexToAddress("0x000000000000000000000000000000000000fac7")
+	factoryCalldata := []byte{0xde, 0xad}
+	validHash := common.HexToHash("0x01")
+	parsed, _ := abi.JSON(strings.NewReader(ERC1271ABI))
+
+	sig, err := erc6492Arguments.Pack(factory, factoryCalldata, []byte{0x01})
+	assert.NoError(t, err)
+	sig = append(sig, ERC6492MagicSuffix...)
+	assert.True(t, IsERC6492Signature(sig))
+	wrapped, err := ParseERC6492Signature(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, factory, wrapped.Factory)
+	assert.Equal(t, factoryCalldata, wrapped.FactoryCalldata)
+	assert.Equal(t, []byte{0x01}, wrapped.Signature)
+
+	m := newMockRPC(t)
+	m.Result("eth_getCode", "0x")
+	m.On("eth_call", func(params []json.RawMessage) (any, error) {
+		var tx struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		json.Unmarshal(params[0], &tx)
+		assert.Equal(t, Multicall3Address, common.HexToAddress(tx.To))
+
+		method := multicall3ABI.Methods["aggregate3"]
+		args, err := method.Inputs.Unpack(hexutil.MustDecode(tx.Data)[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[0], new([]multicall3Call)).(*[]multicall3Call)
+		assert.Len(t, calls, 2)
+		assert.Equal(t, factory, calls[0].Target)
+		assert.Equal(t, factoryCalldata, calls[0].CallData)
+		assert.Equal(t, account, calls[1].Target)
+
+		results := []multicall3Result{{Success: true}, {Success: false}}
+		checked, err := parsed.Methods["isValidSignature"].Inputs.Unpack(calls[1].CallData[4:])
+		if err != nil {
+			return nil, err
+		}
+		if checked[0].([32]byte) == validHash {
+			out, _ := parsed.Methods["isValidSignature"].Outputs.Pack(erc1271MagicValue)
+			results[1] = multicall3Result{Success: true, ReturnData: out}
+		}
+		out, err := method.Outputs.Pack(results)
+		return hexutil.Encode(out), err
+	})
+	w := newTestWallet(t, m)
+
+	ok, err := w.VerifySignature(account, validHash, sig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = w.VerifySignature(account, common.HexToHash("0x02"), sig)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, IsERC6492Signature([]byte{0x01}))
+	_, err = ParseERC6492Signature(append([]byte{0x01}, ERC6492MagicSuffix...))
+	assert.Error(t, err)
+}
+
+func TestSplitSignature(t *testing.T) {
+	sig, err := TestSigner.SignMsg([]byte("123"))
+	assert.NoError(t, err)
+
+	r, s, v, err := SplitSignature(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, sig[:32], common.LeftPadBytes(r.Bytes(), 32))
+	assert.Equal(t, sig[32:64], common.LeftPadBytes(s.Bytes(), 32))
+	assert.Equal(t, uint64(sig[64]), v.Uint64())
+
+	joined, err := JoinSignature(r, s, v)
+	assert.NoError(t, err)
+	assert.Equal(t, sig, joined)
+
+	v0, err := SignatureToV0(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, sig[64]-27, v0[64])
+	assert.Equal(t, sig[:64], v0[:64])
+	v27, err := SignatureToV27(v0)
+	assert.NoError(t, err)
+	assert.Equal(t, sig, v27)
+	v27, err = SignatureToV27(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, sig, v27)
+
+	_, _, _, err = SplitSignature(sig[:63])
+	assert.Error(t, err)
+	_, err = JoinSignature(new(big.Int).Lsh(big.NewInt(1), 256), s, v)
+	assert.Error(t, err)
+	sig[64] = 5
+	_, err = SignatureToV0(sig)
+	assert.Error(t, err)
+}
+
+func TestCompactSignature(t *testing.T) {
+	msg := []byte("compact")
+	for i := 0; i < 4; i++ {
+		msg = append(msg, byte(i))
+		sig, err := TestSigner.SignMsg(msg)
+		assert.NoError(t, err)
+
+		compact, err := CompactSignature(sig)
+		assert.NoError(t, err)
+		assert.Len(t, compact, 64)
+		assert.Equal(t, sig[64]-27, compact[32]>>7)
+		expanded, err := ExpandSignature(compact)
+		assert.NoError(t, err)
+		assert.Equal(t, sig, expanded)
+
+		// 验证函数同时接受 64 与 65 字节签名
+		ok, err := VerifyMsg(msg, compact, TestSigner.Address)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		_, _, v, err := SplitSignature(compact)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(sig[64]), v.Uint64())
+	}
+
+	_, err := ExpandSignature(make([]byte, 65))
+	assert.Error(t, err)
+}
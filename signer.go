@@ -1,14 +1,15 @@
 package goether
 
 import (
+	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
 	"math/big"
 	"os"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
-	"github.com/go-enols/go-log"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -17,9 +18,52 @@ import (
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
+// AccountSigner 签名器接口，Wallet 通过它完成所有签名，
+// 本地私钥、KMS、Vault、硬件钱包或多签等实现可以互相替换
+type AccountSigner interface {
+	// GetAddress 签名账户的地址
+	GetAddress() common.Address
+	// SignDigest 对 32 字节摘要签名，返回 [R || S || V] 格式且 V 为 0 或 1 的签名
+	SignDigest(digest []byte) ([]byte, error)
+	SignTx(
+		nonce int, to common.Address, amount *big.Int,
+		gasLimit int, gasTipCap *big.Int, gasFeeCap *big.Int,
+		data []byte, chainID *big.Int,
+	) (*types.Transaction, error)
+	SignLegacyTx(
+		nonce int, to common.Address, amount *big.Int,
+		gasLimit int, gasPrice *big.Int,
+		data []byte, chainID *big.Int,
+	) (*types.Transaction, error)
+	SignMsg(msg []byte) ([]byte, error)
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
+
+// ContextSigner 签名需要网络请求或多轮交互的签名器可以实现该接口，
+// Wallet 构建交易时会通过它传入 ctx，使签名的等待时间受调用方控制
+type ContextSigner interface {
+	SignDigestContext(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+var (
+	_ ContextSigner = (*KMSSigner)(nil)
+	_ ContextSigner = (*VaultSigner)(nil)
+	_ ContextSigner = (*MPCSigner)(nil)
+)
+
+var (
+	_ AccountSigner = (*Signer)(nil)
+	_ AccountSigner = (*KMSSigner)(nil)
+	_ AccountSigner = (*VaultSigner)(nil)
+	_ AccountSigner = (*ProtectedSigner)(nil)
+	_ AccountSigner = (*MPCSigner)(nil)
+)
+
 type Signer struct {
 	Address common.Address
 	key     *ecdsa.PrivateKey
+	// noExport 为 true 时 GetPrivateKey 与 ExportKeystore 不再返回私钥
+	noExport bool
 }
 
 func NewSigner(prvHex string) (*Signer, error) {
@@ -54,10 +98,68 @@ func NewSignerFromPath(prvPath string) (*Signer, error) {
 	return NewSigner(strings.TrimSpace(string(b)))
 }
 
+// NewRandomSigner 使用 crypto/rand 生成新的私钥，返回签名器与十六进制私钥
+func NewRandomSigner() (*Signer, string, error) {
+	k, err := crypto.GenerateKey()
+	if err != nil {
+		log.Error("Failed to generate private key", "error", err)
+		return nil, "", err
+	}
+
+	address := crypto.PubkeyToAddress(k.PublicKey)
+	log.Debug("Random signer generated", "address", address.Hex())
+	return &Signer{
+		key:     k,
+		Address: address,
+	}, hexutil.Encode(crypto.FromECDSA(k)), nil
+}
+
+func (s Signer) GetAddress() common.Address {
+	return s.Address
+}
+
+// GetPrivateKey 获取私钥对象，调用 DisableKeyExport 或 Close 之后返回 nil
 func (s Signer) GetPrivateKey() *ecdsa.PrivateKey {
+	if s.noExport || s.closed() {
+		return nil
+	}
 	return s.key
 }
 
+// DisableKeyExport 禁止通过 GetPrivateKey 与 ExportKeystore 取出私钥，签名不受影响，禁用后无法恢复
+func (s *Signer) DisableKeyExport() {
+	s.noExport = true
+}
+
+// Zero 将内存中的私钥清零，之后的签名、解密与 ECDH 都会返回 ErrSignerClosed。
+// 私钥对象在所有 Signer 副本之间共享，清零对它们同时生效。
+func (s *Signer) Zero() {
+	if s.key == nil || s.key.D == nil {
+		return
+	}
+	wipeBigInt(s.key.D)
+	log.Debug("Signer private key zeroed", "address", s.Address.Hex())
+}
+
+// Close 与 Zero 相同，实现 io.Closer
+func (s *Signer) Close() error {
+	s.Zero()
+	return nil
+}
+
+// closed 私钥是否已被清零
+func (s Signer) closed() bool {
+	return s.key == nil || s.key.D == nil || s.key.D.Sign() == 0
+}
+
+// privateKey 返回用于签名的私钥，已清零时返回 ErrSignerClosed
+func (s Signer) privateKey() (*ecdsa.PrivateKey, error) {
+	if s.closed() {
+		return nil, ErrSignerClosed
+	}
+	return s.key, nil
+}
+
 func (s Signer) GetPublicKey() []byte {
 	return crypto.FromECDSAPub(&s.key.PublicKey)
 }
@@ -66,15 +168,46 @@ func (s Signer) GetPublicKeyHex() string {
 	return hexutil.Encode(s.GetPublicKey())
 }
 
+// GetPublicKeyCompressed 获取 33 字节的压缩公钥
+func (s Signer) GetPublicKeyCompressed() []byte {
+	return crypto.CompressPubkey(&s.key.PublicKey)
+}
+
+// GetPublicKeyCompressedHex 获取压缩公钥的十六进制字符串
+func (s Signer) GetPublicKeyCompressedHex() string {
+	return hexutil.Encode(s.GetPublicKeyCompressed())
+}
+
 // SignTx DynamicFeeTx
 func (s *Signer) SignTx(
 	nonce int, to common.Address, amount *big.Int,
 	gasLimit int, gasTipCap *big.Int, gasFeeCap *big.Int,
 	data []byte, chainID *big.Int,
 ) (tx *types.Transaction, err error) {
+	return s.signDynamicFeeTx(nonce, &to, amount, gasLimit, gasTipCap, gasFeeCap, data, chainID)
+}
+
+// SignDeployTx 签名一笔创建合约的 DynamicFeeTx，交易的 To 为空，data 为合约字节码
+func (s *Signer) SignDeployTx(
+	nonce int, amount *big.Int,
+	gasLimit int, gasTipCap *big.Int, gasFeeCap *big.Int,
+	data []byte, chainID *big.Int,
+) (tx *types.Transaction, err error) {
+	return s.signDynamicFeeTx(nonce, nil, amount, gasLimit, gasTipCap, gasFeeCap, data, chainID)
+}
+
+func (s *Signer) signDynamicFeeTx(
+	nonce int, to *common.Address, amount *big.Int,
+	gasLimit int, gasTipCap *big.Int, gasFeeCap *big.Int,
+	data []byte, chainID *big.Int,
+) (tx *types.Transaction, err error) {
+	toHex := "<contract creation>"
+	if to != nil {
+		toHex = to.Hex()
+	}
 	log.Debug("Signing dynamic fee transaction",
 		"from", s.Address.Hex(),
-		"to", to.Hex(),
+		"to", toHex,
 		"nonce", nonce,
 		"amount", amount.String(),
 		"gasLimit", gasLimit,
@@ -87,12 +220,16 @@ func (s *Signer) SignTx(
 		GasTipCap: gasTipCap,
 		GasFeeCap: gasFeeCap,
 		Gas:       uint64(gasLimit),
-		To:        &to,
+		To:        to,
 		Value:     amount,
 		Data:      data,
 	}
 
-	tx, err = types.SignNewTx(s.key, types.LatestSignerForChainID(chainID), baseTx)
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	tx, err = types.SignNewTx(key, types.LatestSignerForChainID(chainID), baseTx)
 	if err != nil {
 		log.Error("Failed to sign dynamic fee transaction", "error", err)
 		return nil, err
@@ -116,12 +253,16 @@ func (s *Signer) SignLegacyTx(
 		"gasPrice", gasPrice.String(),
 		"chainID", chainID.String())
 
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
 	tx, err = types.SignTx(
 		types.NewTransaction(
 			uint64(nonce), to, amount,
 			uint64(gasLimit), gasPrice, data),
 		types.NewEIP155Signer(chainID),
-		s.key,
+		key,
 	)
 	if err != nil {
 		log.Error("Failed to sign legacy transaction", "error", err)
@@ -132,10 +273,62 @@ func (s *Signer) SignLegacyTx(
 	return tx, nil
 }
 
+// SignAccessListTx 签名 EIP-2930 交易，使用 gasPrice 计费并携带 accessList
+func (s *Signer) SignAccessListTx(
+	nonce int, to common.Address, amount *big.Int,
+	gasLimit int, gasPrice *big.Int,
+	data []byte, accessList types.AccessList, chainID *big.Int,
+) (tx *types.Transaction, err error) {
+	log.Debug("Signing access list transaction",
+		"from", s.Address.Hex(),
+		"to", to.Hex(),
+		"nonce", nonce,
+		"amount", amount.String(),
+		"gasLimit", gasLimit,
+		"gasPrice", gasPrice.String(),
+		"accessList", len(accessList),
+		"chainID", chainID.String())
+
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	tx, err = types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.AccessListTx{
+		ChainID:    chainID,
+		Nonce:      uint64(nonce),
+		GasPrice:   gasPrice,
+		Gas:        uint64(gasLimit),
+		To:         &to,
+		Value:      amount,
+		Data:       data,
+		AccessList: accessList,
+	})
+	if err != nil {
+		log.Error("Failed to sign access list transaction", "error", err)
+		return nil, err
+	}
+
+	log.Debug("Access list transaction signed successfully", "txHash", tx.Hash().Hex())
+	return tx, nil
+}
+
+// SignDigest 对 32 字节摘要签名，V 为 0 或 1
+func (s Signer) SignDigest(digest []byte) ([]byte, error) {
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(digest, key)
+}
+
 func (s Signer) SignMsg(msg []byte) (sig []byte, err error) {
 	log.Debug("Signing message", "signer", s.Address.Hex(), "msgLength", len(msg))
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
 	hash := accounts.TextHash(msg)
-	sig, err = crypto.Sign(hash, s.key)
+	sig, err = crypto.Sign(hash, key)
 	if err != nil {
 		log.Error("Failed to sign message", "error", err)
 		return
@@ -148,13 +341,17 @@ func (s Signer) SignMsg(msg []byte) (sig []byte, err error) {
 
 func (s Signer) SignTypedData(typedData apitypes.TypedData) (sig []byte, err error) {
 	log.Debug("Signing typed data", "signer", s.Address.Hex(), "domain", typedData.Domain.Name)
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
 	hash, err := EIP712Hash(typedData)
 	if err != nil {
 		log.Error("Failed to generate EIP712 hash", "error", err)
 		return
 	}
 
-	sig, err = crypto.Sign(hash, s.key)
+	sig, err = crypto.Sign(hash, key)
 	if err != nil {
 		log.Error("Failed to sign typed data", "error", err)
 		return
@@ -167,6 +364,159 @@ func (s Signer) SignTypedData(typedData apitypes.TypedData) (sig []byte, err err
 
 // Decrypt decrypt
 func (s Signer) Decrypt(ct []byte) ([]byte, error) {
-	eciesPriv := ecies.ImportECDSA(s.key)
-	return eciesPriv.Decrypt(ct, nil, nil)
+	return s.DecryptWithOptions(ct, nil)
+}
+
+// DecryptWithOptions 使用与加密时相同的共享信息与 ECIES 参数解密
+func (s Signer) DecryptWithOptions(ct []byte, opts *ECIESOptions) ([]byte, error) {
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	s1, s2, params := opts.sharedInfo()
+	eciesPriv := ecies.ImportECDSA(key)
+	if params != nil {
+		eciesPriv.PublicKey.Params = params
+	}
+	return eciesPriv.Decrypt(ct, s1, s2)
+}
+
+// DecryptHex 与 DecryptWithOptions 相同，但密文为十六进制字符串，0x 前缀可选
+func (s Signer) DecryptHex(ctHex string, opts *ECIESOptions) ([]byte, error) {
+	ct, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(ctHex, "0x"), "0X"))
+	if err != nil {
+		return nil, err
+	}
+	return s.DecryptWithOptions(ct, opts)
+}
+
+// SharedSecret 使用 secp256k1 ECDH 计算与 peerPublicKey 的 32 字节共享密钥（共享点的 x 坐标），
+// peerPublicKey 可以是 65 字节非压缩公钥或 33 字节压缩公钥。
+// 双方计算结果相同，用作对称密钥前应先经过 HKDF 等密钥派生函数处理。
+func (s Signer) SharedSecret(peerPublicKey []byte) ([]byte, error) {
+	pub, err := parsePublicKey(peerPublicKey)
+	if err != nil {
+		log.Error("Failed to parse peer public key", "error", err)
+		return nil, err
+	}
+	key, err := s.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	return ecies.ImportECDSA(key).GenerateShared(ecies.ImportECDSAPublic(pub), 32, 0)
+}
+
+// parsePublicKey 解析 65 字节非压缩公钥或 33 字节压缩公钥
+func parsePublicKey(pub []byte) (*ecdsa.PublicKey, error) {
+	if len(pub) == 33 {
+		return crypto.DecompressPubkey(pub)
+	}
+	return crypto.UnmarshalPubkey(pub)
+}
+
+// digestSignFunc 对 32 字节摘要签名，返回 [R || S || V] 格式且 V 为 0 或 1 的签名，
+// 用于私钥不在本地内存中的远程签名器
+type digestSignFunc func(digest []byte) ([]byte, error)
+
+// digestSigner 基于摘要签名函数实现 AccountSigner 中除 SignDigest 以外的方法，
+// 远程签名器嵌入它后只需要实现 SignDigest
+type digestSigner struct {
+	Address common.Address
+
+	sign digestSignFunc
+}
+
+// GetAddress 签名账户的地址
+func (s *digestSigner) GetAddress() common.Address {
+	return s.Address
+}
+
+// SignTx DynamicFeeTx
+func (s *digestSigner) SignTx(
+	nonce int, to common.Address, amount *big.Int,
+	gasLimit int, gasTipCap *big.Int, gasFeeCap *big.Int,
+	data []byte, chainID *big.Int,
+) (*types.Transaction, error) {
+	log.Debug("Signing dynamic fee transaction", "from", s.Address.Hex(), "to", to.Hex(), "nonce", nonce)
+	return signTxWith(s.sign, &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     uint64(nonce),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       uint64(gasLimit),
+		To:        &to,
+		Value:     amount,
+		Data:      data,
+	}, types.LatestSignerForChainID(chainID))
+}
+
+// SignLegacyTx 签名 EIP-155 旧版交易
+func (s *digestSigner) SignLegacyTx(
+	nonce int, to common.Address, amount *big.Int,
+	gasLimit int, gasPrice *big.Int,
+	data []byte, chainID *big.Int,
+) (*types.Transaction, error) {
+	log.Debug("Signing legacy transaction", "from", s.Address.Hex(), "to", to.Hex(), "nonce", nonce)
+	return signTxWith(s.sign, &types.LegacyTx{
+		Nonce:    uint64(nonce),
+		GasPrice: gasPrice,
+		Gas:      uint64(gasLimit),
+		To:       &to,
+		Value:    amount,
+		Data:     data,
+	}, types.NewEIP155Signer(chainID))
+}
+
+// SignMsg 对 EIP-191 消息签名
+func (s *digestSigner) SignMsg(msg []byte) ([]byte, error) {
+	return signMsgWith(s.sign, msg)
+}
+
+// SignTypedData 对 EIP-712 结构化数据签名
+func (s *digestSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	return signTypedDataWith(s.sign, typedData)
+}
+
+// signDigest 返回钱包签名器的摘要签名函数，签名器实现 ContextSigner 时签名受 ctx 控制
+func (w *Wallet) signDigest(ctx context.Context) digestSignFunc {
+	if signer, ok := w.Signer.(ContextSigner); ok {
+		return func(digest []byte) ([]byte, error) {
+			return signer.SignDigestContext(ctx, digest)
+		}
+	}
+	return w.Signer.SignDigest
+}
+
+// signTxWith 使用 sign 对交易签名
+func signTxWith(sign digestSignFunc, txData types.TxData, signer types.Signer) (*types.Transaction, error) {
+	tx := types.NewTx(txData)
+	sig, err := sign(signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// signMsgWith 使用 sign 对 EIP-191 消息签名，V 为 27 或 28
+func signMsgWith(sign digestSignFunc, msg []byte) ([]byte, error) {
+	sig, err := sign(accounts.TextHash(msg))
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// signTypedDataWith 使用 sign 对 EIP-712 结构化数据签名，V 为 27 或 28
+func signTypedDataWith(sign digestSignFunc, typedData apitypes.TypedData) ([]byte, error) {
+	hash, err := EIP712Hash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
 }
@@ -1,12 +1,16 @@
 package goether
 
 import (
+	"crypto/aes"
+	"crypto/sha512"
 	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/stretchr/testify/assert"
 )
@@ -74,3 +78,98 @@ func TestSigner_Decrypt_Encrypt(t *testing.T) {
 
 	assert.Equal(t, msg02, string(decMsg))
 }
+
+func TestNewRandomSigner(t *testing.T) {
+	s, prvHex, err := NewRandomSigner()
+	assert.NoError(t, err)
+	imported, err := NewSigner(prvHex)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Address, imported.Address)
+
+	other, _, err := NewRandomSigner()
+	assert.NoError(t, err)
+	assert.NotEqual(t, s.Address, other.Address)
+
+	w, walletHex, err := NewRandomWallet("", big.NewInt(1))
+	assert.NoError(t, err)
+	imported, err = NewSigner(walletHex)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, imported.Address)
+}
+
+func TestGetPublicKeyCompressed(t *testing.T) {
+	compressed := TestSigner.GetPublicKeyCompressed()
+	assert.Len(t, compressed, 33)
+	assert.Equal(t, hexutil.Encode(compressed), TestSigner.GetPublicKeyCompressedHex())
+
+	pub, err := crypto.DecompressPubkey(compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.GetPublicKey(), crypto.FromECDSAPub(pub))
+}
+
+func TestSharedSecret(t *testing.T) {
+	peer, err := NewSigner("dde30fa25128addf45656a39c0570fd06fce3e48056457b9f1f9fda603cc4be1")
+	assert.NoError(t, err)
+
+	secret, err := TestSigner.SharedSecret(peer.GetPublicKey())
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+	peerSecret, err := peer.SharedSecret(TestSigner.GetPublicKeyCompressed())
+	assert.NoError(t, err)
+	assert.Equal(t, secret, peerSecret)
+
+	_, err = TestSigner.SharedSecret([]byte{0x04, 0x01})
+	assert.Error(t, err)
+}
+
+func TestEncryptWithOptions(t *testing.T) {
+	opts := &ECIESOptions{
+		SharedInfo1: []byte("kdf"),
+		SharedInfo2: []byte("mac"),
+		Params: &ecies.ECIESParams{
+			Hash:      sha512.New,
+			Cipher:    aes.NewCipher,
+			BlockSize: aes.BlockSize,
+			KeyLen:    16,
+		},
+	}
+	ct, err := EncryptHex(TestSigner.GetPublicKeyCompressedHex(), []byte("hello"), opts)
+	assert.NoError(t, err)
+
+	msg, err := TestSigner.DecryptHex(ct, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(msg))
+
+	// 共享信息或参数不一致时无法解密
+	_, err = TestSigner.DecryptHex(ct, nil)
+	assert.Error(t, err)
+	_, err = TestSigner.DecryptHex(ct, &ECIESOptions{SharedInfo1: []byte("kdf"), SharedInfo2: []byte("mac")})
+	assert.Error(t, err)
+}
+
+func TestSignerZero(t *testing.T) {
+	signer, _, err := NewRandomSigner()
+	assert.NoError(t, err)
+	signer.DisableKeyExport()
+	assert.Nil(t, signer.GetPrivateKey())
+	_, err = signer.ExportKeystore("secret", LightScryptParams)
+	assert.ErrorIs(t, err, ErrKeyExportDisabled)
+
+	// 禁止导出不影响签名
+	_, err = signer.SignMsg([]byte("hello"))
+	assert.NoError(t, err)
+
+	pub := signer.GetPublicKey()
+	copied := *signer
+	assert.NoError(t, signer.Close())
+	assert.Equal(t, pub, signer.GetPublicKey())
+
+	_, err = signer.SignMsg([]byte("hello"))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+	_, err = copied.SignDigest(make([]byte, 32))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+	_, err = signer.SignTx(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), big.NewInt(1), nil, big.NewInt(1))
+	assert.ErrorIs(t, err, ErrSignerClosed)
+	_, err = signer.SharedSecret(TestSigner.GetPublicKey())
+	assert.ErrorIs(t, err, ErrSignerClosed)
+}
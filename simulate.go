@@ -0,0 +1,121 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/ethrpc"
+)
+
+var panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+// RevertError 交易执行回滚时的错误
+type RevertError struct {
+	// Reason 解码后的回滚原因，Panic 以 "panic: " 开头，无法解码时为空
+	Reason string
+	// Data 原始回滚数据，节点没有返回时为空
+	Data []byte
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.Reason != "":
+		return "execution reverted: " + e.Reason
+	case len(e.Data) > 0:
+		return "execution reverted: " + hexutil.Encode(e.Data)
+	default:
+		return "execution reverted"
+	}
+}
+
+// DecodeRevert 解码 Error(string) 或 Panic(uint256) 回滚数据，其他数据只保留原始字节
+func DecodeRevert(data []byte) *RevertError {
+	revert := &RevertError{Data: data}
+	reason, err := abi.UnpackRevert(data)
+	if err != nil {
+		return revert
+	}
+	if bytes.Equal(data[:4], panicSelector) {
+		reason = "panic: " + reason
+	}
+	revert.Reason = reason
+	return revert
+}
+
+// asRevertError 将节点返回的回滚错误转换为 *RevertError，其他错误原样返回
+//
+// 回滚数据来自 JSON-RPC 错误的 data 字段，需要使用 callData 发送请求才能取得；
+// 没有 data 时只解码紧跟在 "execution reverted:" 之后的十六进制数据，否则从错误信息中提取回滚原因。
+func asRevertError(err error) error {
+	var rpcErr *RPCError
+	if !errors.As(asRPCError(err), &rpcErr) {
+		return err
+	}
+	msg := rpcErr.Message
+	if rpcErr.Code != 3 && !strings.Contains(strings.ToLower(msg), "revert") {
+		return err
+	}
+
+	var hexData string
+	if json.Unmarshal(rpcErr.Data, &hexData) == nil {
+		if data, err := hexutil.Decode(hexData); err == nil && len(data) > 0 {
+			return DecodeRevert(data)
+		}
+	}
+	if i := strings.Index(strings.ToLower(msg), "execution reverted"); i >= 0 {
+		msg = msg[i+len("execution reverted"):]
+		// 使用 WithClient 等无法读取 data 的客户端时，部分节点把回滚数据附加在错误信息中
+		if hexData, ok := strings.CutPrefix(msg, ":"); ok && len(rpcErr.Data) == 0 {
+			if data, err := hexutil.Decode(strings.TrimSpace(hexData)); err == nil && len(data) > 0 {
+				return DecodeRevert(data)
+			}
+		}
+	}
+	return &RevertError{Reason: strings.TrimSpace(strings.TrimPrefix(msg, ":"))}
+}
+
+// Simulate 使用与交易相同的参数执行 eth_call，回滚时返回 *RevertError，成功时返回调用结果
+func (w *Wallet) Simulate(to common.Address, amount *big.Int, data []byte, opts *TxOpts) ([]byte, error) {
+	return w.SimulateContext(context.Background(), to, amount, data, opts)
+}
+
+// SimulateContext 与 Simulate 相同，但 eth_call 受 ctx 控制
+func (w *Wallet) SimulateContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) ([]byte, error) {
+	return w.simulate(ctx, &to, amount, data, opts)
+}
+
+// simulate to 为 nil 时模拟创建合约的交易
+func (w *Wallet) simulate(ctx context.Context, to *common.Address, amount *big.Int, data []byte, opts *TxOpts) ([]byte, error) {
+	msg := ethrpc.T{
+		From:  w.Address.String(),
+		Value: amount,
+		Data:  hexutil.Encode(data),
+	}
+	if to != nil {
+		msg.To = to.String()
+	}
+	if opts != nil {
+		if opts.GasLimit != nil {
+			msg.Gas = *opts.GasLimit
+		}
+		msg.GasPrice = opts.GasPrice
+	}
+
+	res, err := callData(ctx, w.Client, w.knownTransport(), func(rpc *ethrpc.EthRPC) (string, error) {
+		return rpc.EthCall(msg, "pending")
+	})
+	if err != nil {
+		err = asRevertError(err)
+		log.Error("Transaction simulation failed", "from", w.Address.Hex(), "error", err)
+		return nil, err
+	}
+	return common.FromHex(res), nil
+}
@@ -0,0 +1,105 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// revertData 按 signature 编码回滚数据，例如 Error(string)
+func revertData(t *testing.T, signature, typ string, value any) []byte {
+	abiType, err := abi.NewType(typ, "", nil)
+	assert.NoError(t, err)
+	packed, err := abi.Arguments{{Type: abiType}}.Pack(value)
+	assert.NoError(t, err)
+	return append(crypto.Keccak256([]byte(signature))[:4], packed...)
+}
+
+func TestDecodeRevert(t *testing.T) {
+	revert := DecodeRevert(revertData(t, "Error(string)", "string", "insufficient balance"))
+	assert.Equal(t, "insufficient balance", revert.Reason)
+	assert.Equal(t, "execution reverted: insufficient balance", revert.Error())
+
+	revert = DecodeRevert(revertData(t, "Panic(uint256)", "uint256", big.NewInt(0x11)))
+	assert.Equal(t, "panic: arithmetic underflow or overflow", revert.Reason)
+
+	revert = DecodeRevert([]byte{0x01, 0x02, 0x03, 0x04})
+	assert.Empty(t, revert.Reason)
+	assert.Equal(t, "execution reverted: 0x01020304", revert.Error())
+}
+
+func TestSimulateBeforeSend(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	data := revertData(t, "Error(string)", "string", "not owner")
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted", Data: hexutil.Encode(data)}
+	})
+	w := newTestWallet(t, m)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	_, err := w.SendTx(to, nil, nil, &TxOpts{Simulate: true})
+	var revert *RevertError
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, "not owner", revert.Reason)
+	assert.Empty(t, sent)
+
+	m.Result("eth_call", "0x")
+	w.SimulateBeforeSend = true
+	_, err = w.SendTx(to, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Equal(t, 2, m.Calls("eth_call"))
+
+	m.On("eth_estimateGas", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32000, Message: "execution reverted: paused"}
+	})
+	_, err = w.SendTx(to, nil, nil, nil)
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, "paused", revert.Reason)
+
+	// Panic 与自定义错误只有原始数据，节点的错误信息中没有原因
+	panicData := revertData(t, "Panic(uint256)", "uint256", big.NewInt(0x12))
+	m.On("eth_estimateGas", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted", Data: hexutil.Encode(panicData)}
+	})
+	_, err = w.SendTx(to, nil, nil, nil)
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, "panic: division or modulo by zero", revert.Reason)
+	assert.Equal(t, panicData, revert.Data)
+
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted", Data: "0x01020304"}
+	})
+	_, err = w.Simulate(to, nil, nil, nil)
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, []byte{1, 2, 3, 4}, revert.Data)
+	assert.Empty(t, revert.Reason)
+
+	// 替换了 Client 时读不到 data，只解码紧跟在 "execution reverted:" 之后的十六进制数据
+	w.Client = ethrpc.New(m.URL)
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted: " + hexutil.Encode(data), Data: hexutil.Encode(panicData)}
+	})
+	_, err = w.Simulate(to, nil, nil, nil)
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, "not owner", revert.Reason)
+	assert.Equal(t, data, revert.Data)
+
+	m.On("eth_call", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: 3, Message: "execution reverted: bad input 0x01020304"}
+	})
+	_, err = w.Simulate(to, nil, nil, nil)
+	assert.ErrorAs(t, err, &revert)
+	assert.Equal(t, "bad input 0x01020304", revert.Reason)
+	assert.Empty(t, revert.Data)
+}
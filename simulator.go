@@ -0,0 +1,327 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// NativeTokenAddress eth_simulateV1 开启 traceTransfers 时原生币转账日志使用的地址，
+// 也用于在 AssetChange 中表示原生币
+var NativeTokenAddress = common.HexToAddress("0xEeeeeEeeeEeEeEeEeEeEEEeeeeEeeeeeeeEEeE")
+
+// StateOverride 模拟时覆盖账户的状态，State 替换全部存储，StateDiff 只修改指定的存储槽
+type StateOverride struct {
+	Balance   *big.Int
+	Nonce     *uint64
+	Code      []byte
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+func (o StateOverride) MarshalJSON() ([]byte, error) {
+	var enc struct {
+		Balance   *hexutil.Big                `json:"balance,omitempty"`
+		Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+		Code      hexutil.Bytes               `json:"code,omitempty"`
+		State     map[common.Hash]common.Hash `json:"state,omitempty"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+	}
+	enc.Balance = (*hexutil.Big)(o.Balance)
+	enc.Nonce = (*hexutil.Uint64)(o.Nonce)
+	enc.Code, enc.State, enc.StateDiff = o.Code, o.State, o.StateDiff
+	return json.Marshal(enc)
+}
+
+// BlockOverride 模拟区块的区块头参数，未设置的字段由节点在上一个区块的基础上推算
+type BlockOverride struct {
+	Number       *big.Int
+	Time         *uint64
+	GasLimit     *uint64
+	FeeRecipient *common.Address
+	BaseFee      *big.Int
+	PrevRandao   *common.Hash
+}
+
+func (o BlockOverride) MarshalJSON() ([]byte, error) {
+	var enc struct {
+		Number       *hexutil.Big    `json:"number,omitempty"`
+		Time         *hexutil.Uint64 `json:"time,omitempty"`
+		GasLimit     *hexutil.Uint64 `json:"gasLimit,omitempty"`
+		FeeRecipient *common.Address `json:"feeRecipient,omitempty"`
+		BaseFee      *hexutil.Big    `json:"baseFeePerGas,omitempty"`
+		PrevRandao   *common.Hash    `json:"prevRandao,omitempty"`
+	}
+	enc.Number = (*hexutil.Big)(o.Number)
+	enc.Time = (*hexutil.Uint64)(o.Time)
+	enc.GasLimit = (*hexutil.Uint64)(o.GasLimit)
+	enc.FeeRecipient, enc.PrevRandao = o.FeeRecipient, o.PrevRandao
+	enc.BaseFee = (*hexutil.Big)(o.BaseFee)
+	return json.Marshal(enc)
+}
+
+// SimCall 模拟执行的一笔交易，From 为 nil 时使用钱包地址，To 为 nil 时创建合约
+type SimCall struct {
+	From  *common.Address
+	To    *common.Address
+	Value *big.Int
+	Data  []byte
+	Gas   *uint64
+}
+
+// SimBlock 模拟的一个区块，其中的交易按顺序执行，后面的区块基于前面区块执行后的状态
+type SimBlock struct {
+	BlockOverride  *BlockOverride
+	StateOverrides map[common.Address]StateOverride
+	Calls          []SimCall
+}
+
+// Simulator 基于 eth_simulateV1 在多个区块中按顺序模拟一组交易，用于在花费 gas 之前验证
+// 授权 + 兑换等多步操作
+//
+//	sim := goether.NewSimulator(wallet)
+//	approve, _ := token.SimCall("approve", nil, router, amount)
+//	swap, _ := pair.SimCall("swap", nil, ...)
+//	sim.Blocks = []goether.SimBlock{{Calls: []goether.SimCall{approve, swap}}}
+//	result, err := sim.Run()
+type Simulator struct {
+	Wallet *Wallet
+	Blocks []SimBlock
+	// TraceTransfers 为 true 时原生币转账也作为日志返回，并计入 AssetChanges
+	TraceTransfers bool
+	// Validation 为 true 时按真实交易校验 nonce、余额与手续费
+	Validation bool
+	// BlockTag 模拟的起始区块，为空时使用 latest
+	BlockTag string
+}
+
+// NewSimulator 创建模拟器，默认开启 TraceTransfers
+func NewSimulator(w *Wallet) *Simulator {
+	return &Simulator{Wallet: w, TraceTransfers: true}
+}
+
+// SimCall 将方法调用编码为模拟交易，value 为附带的转账金额，可以为 nil
+func (c *Contract) SimCall(methodName string, value *big.Int, args ...interface{}) (SimCall, error) {
+	data, err := c.EncodeData(methodName, args...)
+	if err != nil {
+		return SimCall{}, err
+	}
+	to := c.Address
+	return SimCall{To: &to, Value: value, Data: data}, nil
+}
+
+// SimulatedCall 单笔交易的模拟结果
+type SimulatedCall struct {
+	Success    bool
+	ReturnData []byte
+	GasUsed    uint64
+	Logs       []types.Log
+	// Err 执行失败的原因，回滚时为 *RevertError
+	Err error
+}
+
+// SimulatedBlock 单个区块的模拟结果
+type SimulatedBlock struct {
+	Number    uint64
+	Hash      common.Hash
+	Timestamp uint64
+	GasUsed   uint64
+	BaseFee   *big.Int
+	Calls     []SimulatedCall
+}
+
+// AssetChange 模拟过程中账户某种资产的净变化，Token 为 NativeTokenAddress 时表示原生币
+type AssetChange struct {
+	Account common.Address
+	Token   common.Address
+	Delta   *big.Int
+}
+
+// SimulationResult 模拟结果
+type SimulationResult struct {
+	Blocks []SimulatedBlock
+	// AssetChanges 根据 ERC-20 Transfer 日志与原生币转账日志汇总的资产净变化，按账户与代币排序
+	AssetChanges []AssetChange
+}
+
+// Success 所有交易都执行成功
+func (r *SimulationResult) Success() bool {
+	for _, block := range r.Blocks {
+		for _, call := range block.Calls {
+			if !call.Success {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Changes 返回 account 的资产净变化，键为代币地址
+func (r *SimulationResult) Changes(account common.Address) map[common.Address]*big.Int {
+	changes := make(map[common.Address]*big.Int)
+	for _, c := range r.AssetChanges {
+		if c.Account == account {
+			changes[c.Token] = c.Delta
+		}
+	}
+	return changes
+}
+
+// Run 执行模拟，单笔交易失败不会返回错误，而是记录在 SimulatedCall.Err 中
+func (s *Simulator) Run() (*SimulationResult, error) {
+	return s.RunContext(context.Background())
+}
+
+// RunContext 与 Run 相同，但请求受 ctx 控制
+func (s *Simulator) RunContext(ctx context.Context) (*SimulationResult, error) {
+	if s.Wallet == nil {
+		return nil, ErrWalletNil
+	}
+	if len(s.Blocks) == 0 {
+		return nil, errors.New("simulator has no blocks")
+	}
+	tag := s.BlockTag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	type simCall struct {
+		From  common.Address  `json:"from"`
+		To    *common.Address `json:"to,omitempty"`
+		Value *hexutil.Big    `json:"value,omitempty"`
+		Data  hexutil.Bytes   `json:"input,omitempty"`
+		Gas   *hexutil.Uint64 `json:"gas,omitempty"`
+	}
+	type simBlock struct {
+		BlockOverrides *BlockOverride                   `json:"blockOverrides,omitempty"`
+		StateOverrides map[common.Address]StateOverride `json:"stateOverrides,omitempty"`
+		Calls          []simCall                        `json:"calls"`
+	}
+	payload := struct {
+		BlockStateCalls []simBlock `json:"blockStateCalls"`
+		TraceTransfers  bool       `json:"traceTransfers"`
+		Validation      bool       `json:"validation"`
+	}{TraceTransfers: s.TraceTransfers, Validation: s.Validation}
+	for _, block := range s.Blocks {
+		b := simBlock{BlockOverrides: block.BlockOverride, StateOverrides: block.StateOverrides, Calls: []simCall{}}
+		for _, call := range block.Calls {
+			c := simCall{From: s.Wallet.Address, To: call.To, Data: call.Data, Value: (*hexutil.Big)(call.Value), Gas: (*hexutil.Uint64)(call.Gas)}
+			if call.From != nil {
+				c.From = *call.From
+			}
+			b.Calls = append(b.Calls, c)
+		}
+		payload.BlockStateCalls = append(payload.BlockStateCalls, b)
+	}
+
+	log.Debug("Simulating transactions", "blocks", len(s.Blocks), "tag", tag)
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return s.Wallet.rpc(ctx).Call("eth_simulateV1", payload, tag)
+	})
+	if err != nil {
+		log.Error("Failed to simulate transactions", "error", err)
+		return nil, err
+	}
+
+	var blocks []struct {
+		Number    hexutil.Uint64 `json:"number"`
+		Hash      common.Hash    `json:"hash"`
+		Timestamp hexutil.Uint64 `json:"timestamp"`
+		GasUsed   hexutil.Uint64 `json:"gasUsed"`
+		BaseFee   *hexutil.Big   `json:"baseFeePerGas"`
+		Calls     []struct {
+			Status     hexutil.Uint64 `json:"status"`
+			ReturnData hexutil.Bytes  `json:"returnData"`
+			GasUsed    hexutil.Uint64 `json:"gasUsed"`
+			Logs       []types.Log    `json:"logs"`
+			Error      *struct {
+				Code    int           `json:"code"`
+				Message string        `json:"message"`
+				Data    hexutil.Bytes `json:"data"`
+			} `json:"error"`
+		} `json:"calls"`
+	}
+	if err = json.Unmarshal(raw, &blocks); err != nil {
+		return nil, err
+	}
+
+	result := &SimulationResult{}
+	for _, b := range blocks {
+		block := SimulatedBlock{
+			Number:    uint64(b.Number),
+			Hash:      b.Hash,
+			Timestamp: uint64(b.Timestamp),
+			GasUsed:   uint64(b.GasUsed),
+			BaseFee:   (*big.Int)(b.BaseFee),
+		}
+		for _, c := range b.Calls {
+			call := SimulatedCall{
+				Success:    c.Status == 1,
+				ReturnData: c.ReturnData,
+				GasUsed:    uint64(c.GasUsed),
+				Logs:       c.Logs,
+			}
+			if c.Error != nil {
+				if len(c.Error.Data) > 0 {
+					call.Err = DecodeRevert(c.Error.Data)
+				} else {
+					call.Err = asRevertError(&RPCError{Code: c.Error.Code, Message: c.Error.Message})
+				}
+			}
+			block.Calls = append(block.Calls, call)
+		}
+		result.Blocks = append(result.Blocks, block)
+	}
+	result.AssetChanges = assetChanges(result.Blocks)
+	log.Debug("Simulation completed", "blocks", len(result.Blocks), "success", result.Success())
+	return result, nil
+}
+
+// assetChanges 汇总成功交易中 Transfer 日志表示的资产净变化，ERC-721 的 Transfer 有 4 个 topic，不计入
+func assetChanges(blocks []SimulatedBlock) []AssetChange {
+	type key struct{ account, token common.Address }
+	deltas := make(map[key]*big.Int)
+	add := func(account, token common.Address, amount *big.Int) {
+		k := key{account, token}
+		if deltas[k] == nil {
+			deltas[k] = new(big.Int)
+		}
+		deltas[k].Add(deltas[k], amount)
+	}
+	for _, block := range blocks {
+		for _, call := range block.Calls {
+			if !call.Success {
+				continue
+			}
+			for _, l := range call.Logs {
+				if len(l.Topics) != 3 || l.Topics[0] != transferTopic || len(l.Data) != 32 {
+					continue
+				}
+				amount := new(big.Int).SetBytes(l.Data)
+				add(common.BytesToAddress(l.Topics[1].Bytes()), l.Address, new(big.Int).Neg(amount))
+				add(common.BytesToAddress(l.Topics[2].Bytes()), l.Address, amount)
+			}
+		}
+	}
+
+	var changes []AssetChange
+	for k, delta := range deltas {
+		if delta.Sign() != 0 {
+			changes = append(changes, AssetChange{Account: k.account, Token: k.token, Delta: delta})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if c := bytes.Compare(changes[i].Account.Bytes(), changes[j].Account.Bytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(changes[i].Token.Bytes(), changes[j].Token.Bytes()) < 0
+	})
+	return changes
+}
@@ -0,0 +1,106 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulator(t *testing.T) {
+	m := newMockRPC(t)
+	w := newTestWallet(t, m)
+	token, err := NewERC20(eventToken, w)
+	assert.NoError(t, err)
+
+	native := &types.Log{
+		Address: NativeTokenAddress,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(eventTo.Bytes()), common.BytesToHash(eventFrom.Bytes())},
+		Data:    common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+	reverted := revertData(t, "Error(string)", "string", "insufficient allowance")
+	m.On("eth_simulateV1", func(params []json.RawMessage) (any, error) {
+		var payload struct {
+			BlockStateCalls []struct {
+				BlockOverrides map[string]string            `json:"blockOverrides"`
+				StateOverrides map[string]map[string]string `json:"stateOverrides"`
+				Calls          []map[string]string          `json:"calls"`
+			} `json:"blockStateCalls"`
+			TraceTransfers bool `json:"traceTransfers"`
+		}
+		assert.NoError(t, json.Unmarshal(params[0], &payload))
+		assert.True(t, payload.TraceTransfers)
+		assert.Len(t, payload.BlockStateCalls, 2)
+		first := payload.BlockStateCalls[0]
+		assert.Equal(t, "0xde0b6b3a7640000", first.StateOverrides[strings.ToLower(w.Address.Hex())]["balance"])
+		assert.Equal(t, w.Address.Hex(), common.HexToAddress(first.Calls[0]["from"]).Hex())
+		assert.Equal(t, eventFrom, common.HexToAddress(first.Calls[1]["from"]))
+		assert.Equal(t, "0x64", first.Calls[1]["value"])
+		assert.Equal(t, "0x65", payload.BlockStateCalls[1].BlockOverrides["time"])
+		assert.Equal(t, `"latest"`, string(params[1]))
+
+		return []map[string]any{
+			{
+				"number": "0x10", "hash": common.HexToHash("0x10"), "timestamp": "0x64", "gasUsed": "0xc350", "baseFeePerGas": "0x7",
+				"calls": []map[string]any{
+					{"status": "0x1", "returnData": "0x", "gasUsed": "0x5208", "logs": []*types.Log{transferLog(16, 0), native}},
+					{"status": "0x1", "returnData": hexutil.Encode(common.LeftPadBytes([]byte{1}, 32)), "gasUsed": "0x7148", "logs": []*types.Log{}},
+				},
+			},
+			{
+				"number": "0x11", "hash": common.HexToHash("0x11"), "timestamp": "0x65", "gasUsed": "0x6000",
+				"calls": []map[string]any{
+					{"status": "0x0", "returnData": hexutil.Encode(reverted), "gasUsed": "0x6000", "logs": []*types.Log{transferLog(17, 0)},
+						"error": map[string]any{"code": 3, "message": "execution reverted", "data": hexutil.Encode(reverted)}},
+				},
+			},
+		}, nil
+	})
+
+	approve, err := token.SimCall("approve", nil, eventTo, big.NewInt(5))
+	assert.NoError(t, err)
+	transfer, err := token.SimCall("transfer", big.NewInt(100), eventTo, big.NewInt(5))
+	assert.NoError(t, err)
+	transfer.From = &eventFrom
+	transferFrom, err := token.SimCall("transferFrom", nil, eventFrom, eventTo, big.NewInt(5))
+	assert.NoError(t, err)
+	next := uint64(0x65)
+
+	sim := NewSimulator(w)
+	sim.Blocks = []SimBlock{
+		{
+			StateOverrides: map[common.Address]StateOverride{w.Address: {Balance: big.NewInt(1e18)}},
+			Calls:          []SimCall{approve, transfer},
+		},
+		{BlockOverride: &BlockOverride{Time: &next}, Calls: []SimCall{transferFrom}},
+	}
+	result, err := sim.Run()
+	assert.NoError(t, err)
+	assert.False(t, result.Success())
+	assert.Len(t, result.Blocks, 2)
+	assert.Equal(t, uint64(0x10), result.Blocks[0].Number)
+	assert.Equal(t, big.NewInt(7), result.Blocks[0].BaseFee)
+	assert.True(t, result.Blocks[0].Calls[1].Success)
+	assert.Equal(t, uint64(0x7148), result.Blocks[0].Calls[1].GasUsed)
+	assert.Len(t, result.Blocks[0].Calls[0].Logs, 2)
+
+	failed := result.Blocks[1].Calls[0]
+	assert.False(t, failed.Success)
+	var revert *RevertError
+	assert.ErrorAs(t, failed.Err, &revert)
+	assert.Equal(t, "insufficient allowance", revert.Reason)
+
+	// 失败交易的日志不计入资产变化
+	assert.Equal(t, map[common.Address]*big.Int{eventToken: big.NewInt(-16), NativeTokenAddress: big.NewInt(100)}, result.Changes(eventFrom))
+	assert.Equal(t, map[common.Address]*big.Int{eventToken: big.NewInt(16), NativeTokenAddress: big.NewInt(-100)}, result.Changes(eventTo))
+	assert.Len(t, result.AssetChanges, 4)
+	assert.Equal(t, eventFrom, result.AssetChanges[0].Account)
+
+	_, err = NewSimulator(w).Run()
+	assert.Error(t, err)
+}
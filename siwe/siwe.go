@@ -0,0 +1,327 @@
+// Package siwe 实现 Sign-In with Ethereum（EIP-4361）消息的构造、解析、签名与验证，
+// 用于后端基于钱包签名的登录认证
+package siwe
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-enols/goether"
+)
+
+const (
+	header       = " wants you to sign in with your Ethereum account:"
+	uriTag       = "URI: "
+	versionTag   = "Version: "
+	chainIDTag   = "Chain ID: "
+	nonceTag     = "Nonce: "
+	issuedAtTag  = "Issued At: "
+	expiresTag   = "Expiration Time: "
+	notBeforeTag = "Not Before: "
+	requestIDTag = "Request ID: "
+	resourcesTag = "Resources:"
+)
+
+// nonceAlphabet Nonce 允许的字符，EIP-4361 要求至少 8 个字母或数字
+const nonceAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// 验证失败时返回的错误，可以使用 errors.Is 判断
+var (
+	ErrInvalidMessage   = errors.New("invalid siwe message")
+	ErrInvalidSignature = errors.New("invalid siwe signature")
+	ErrDomainMismatch   = errors.New("siwe domain mismatch")
+	ErrNonceMismatch    = errors.New("siwe nonce mismatch")
+	ErrChainIDMismatch  = errors.New("siwe chain id mismatch")
+	ErrExpired          = errors.New("siwe message expired")
+	ErrNotYetValid      = errors.New("siwe message not yet valid")
+)
+
+// Message EIP-4361 登录消息
+type Message struct {
+	// Scheme 请求来源的 URI scheme，例如 https，可以为空
+	Scheme string
+	// Domain 请求签名的域名，可以包含端口
+	Domain  string
+	Address common.Address
+	// Statement 展示给用户的说明，可以为空，不能包含换行
+	Statement string
+	URI       string
+	// Version 为空时使用 "1"
+	Version string
+	ChainID uint64
+	// Nonce 防重放的随机数，使用 GenerateNonce 生成并保存在服务端会话中
+	Nonce    string
+	IssuedAt time.Time
+	// ExpirationTime 过期时间，为 nil 时不过期
+	ExpirationTime *time.Time
+	// NotBefore 生效时间，为 nil 时立即生效
+	NotBefore *time.Time
+	RequestID string
+	Resources []string
+
+	// raw ParseMessage 解析的原始文本，签名与验证优先使用该文本，
+	// 避免重新格式化时丢失毫秒或改变地址大小写
+	raw string
+}
+
+// NewMessage 创建登录消息，Version 为 "1"，IssuedAt 为当前时间，并生成随机 Nonce
+func NewMessage(domain string, address common.Address, uri string, chainID uint64) (*Message, error) {
+	nonce, err := GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Domain:   domain,
+		Address:  address,
+		URI:      uri,
+		Version:  "1",
+		ChainID:  chainID,
+		Nonce:    nonce,
+		IssuedAt: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// GenerateNonce 生成 16 位由字母与数字组成的随机 Nonce
+func GenerateNonce() (string, error) {
+	size := big.NewInt(int64(len(nonceAlphabet)))
+	nonce := make([]byte, 16)
+	for i := range nonce {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		nonce[i] = nonceAlphabet[n.Int64()]
+	}
+	return string(nonce), nil
+}
+
+// String 按 EIP-4361 格式输出消息，时间精确到秒，地址使用校验和格式。
+// 由 ParseMessage 得到的消息签名与验证使用原始文本而不是 String 的结果
+func (m *Message) String() string {
+	var b strings.Builder
+	if m.Scheme != "" {
+		b.WriteString(m.Scheme + "://")
+	}
+	b.WriteString(m.Domain + header + "\n")
+	b.WriteString(m.Address.Hex() + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+
+	version := m.Version
+	if version == "" {
+		version = "1"
+	}
+	b.WriteString(uriTag + m.URI + "\n")
+	b.WriteString(versionTag + version + "\n")
+	b.WriteString(chainIDTag + strconv.FormatUint(m.ChainID, 10) + "\n")
+	b.WriteString(nonceTag + m.Nonce + "\n")
+	b.WriteString(issuedAtTag + m.IssuedAt.Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		b.WriteString("\n" + expiresTag + m.ExpirationTime.Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		b.WriteString("\n" + notBeforeTag + m.NotBefore.Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		b.WriteString("\n" + requestIDTag + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\n" + resourcesTag)
+		for _, r := range m.Resources {
+			b.WriteString("\n- " + r)
+		}
+	}
+	return b.String()
+}
+
+// ParseMessage 解析 EIP-4361 格式的消息文本
+func ParseMessage(s string) (*Message, error) {
+	lines := strings.Split(s, "\n")
+	if len(lines) < 8 {
+		return nil, fmt.Errorf("%w: too few lines", ErrInvalidMessage)
+	}
+
+	m := new(Message)
+	domain, ok := strings.CutSuffix(lines[0], header)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidMessage)
+	}
+	if scheme, rest, ok := strings.Cut(domain, "://"); ok {
+		m.Scheme, domain = scheme, rest
+	}
+	if domain == "" {
+		return nil, fmt.Errorf("%w: empty domain", ErrInvalidMessage)
+	}
+	m.Domain = domain
+
+	if !common.IsHexAddress(lines[1]) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidMessage, lines[1])
+	}
+	m.Address = common.HexToAddress(lines[1])
+	if lines[2] != "" {
+		return nil, fmt.Errorf("%w: expected empty line after address", ErrInvalidMessage)
+	}
+
+	i := 3
+	if lines[i] != "" {
+		m.Statement = lines[i]
+		i++
+	}
+	if lines[i] != "" {
+		return nil, fmt.Errorf("%w: expected empty line after statement", ErrInvalidMessage)
+	}
+	i++
+
+	// 必需字段按固定顺序出现
+	required := []struct {
+		tag string
+		dst *string
+	}{
+		{uriTag, &m.URI},
+		{versionTag, &m.Version},
+		{chainIDTag, nil},
+		{nonceTag, &m.Nonce},
+		{issuedAtTag, nil},
+	}
+	values := make([]string, len(required))
+	for j, field := range required {
+		if i >= len(lines) || !strings.HasPrefix(lines[i], field.tag) {
+			return nil, fmt.Errorf("%w: missing %q", ErrInvalidMessage, strings.TrimSpace(field.tag))
+		}
+		values[j] = strings.TrimPrefix(lines[i], field.tag)
+		if field.dst != nil {
+			*field.dst = values[j]
+		}
+		i++
+	}
+	if m.Version != "1" {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidMessage, m.Version)
+	}
+	chainID, err := strconv.ParseUint(values[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid chain id %q", ErrInvalidMessage, values[2])
+	}
+	m.ChainID = chainID
+	if len(m.Nonce) < 8 {
+		return nil, fmt.Errorf("%w: nonce is too short", ErrInvalidMessage)
+	}
+	if m.IssuedAt, err = time.Parse(time.RFC3339, values[4]); err != nil {
+		return nil, fmt.Errorf("%w: invalid issued at: %v", ErrInvalidMessage, err)
+	}
+
+	if i < len(lines) && strings.HasPrefix(lines[i], expiresTag) {
+		t, err := time.Parse(time.RFC3339, strings.TrimPrefix(lines[i], expiresTag))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid expiration time: %v", ErrInvalidMessage, err)
+		}
+		m.ExpirationTime = &t
+		i++
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], notBeforeTag) {
+		t, err := time.Parse(time.RFC3339, strings.TrimPrefix(lines[i], notBeforeTag))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid not before: %v", ErrInvalidMessage, err)
+		}
+		m.NotBefore = &t
+		i++
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], requestIDTag) {
+		m.RequestID = strings.TrimPrefix(lines[i], requestIDTag)
+		i++
+	}
+	if i < len(lines) && lines[i] == resourcesTag {
+		for i++; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+			m.Resources = append(m.Resources, strings.TrimPrefix(lines[i], "- "))
+		}
+	}
+	if i != len(lines) {
+		return nil, fmt.Errorf("%w: unexpected line %q", ErrInvalidMessage, lines[i])
+	}
+	m.raw = s
+	return m, nil
+}
+
+// text 返回签名使用的文本，解析得到的消息使用原始文本，否则使用 String
+func (m *Message) text() string {
+	if m.raw != "" {
+		return m.raw
+	}
+	return m.String()
+}
+
+// Sign 使用 signer 对消息进行 EIP-191 personal_sign 签名
+func (m *Message) Sign(signer goether.AccountSigner) ([]byte, error) {
+	return signer.SignMsg([]byte(m.text()))
+}
+
+// VerifyOptions 验证消息时的检查项，字段为空时跳过对应检查
+type VerifyOptions struct {
+	// Domain 服务端期望的域名
+	Domain string
+	// Nonce 服务端会话中保存的 Nonce
+	Nonce string
+	// ChainID 服务端期望的链ID
+	ChainID uint64
+	// Time 检查有效期使用的时间，为零值时使用当前时间
+	Time time.Time
+	// Wallet 设置后通过 Wallet.VerifySignature 验证，支持 EIP-1271 合约账户与 EIP-6492 签名，
+	// 为 nil 时只使用 ecrecover 验证 EOA 签名
+	Wallet *goether.Wallet
+}
+
+// Verify 验证 sig 是否为 m.Address 对消息的签名，并检查域名、Nonce、链ID与有效期
+func (m *Message) Verify(sig []byte, opts VerifyOptions) error {
+	return m.VerifyContext(context.Background(), sig, opts)
+}
+
+// VerifyContext 与 Verify 相同，但设置 Wallet 时的链上查询受 ctx 控制
+func (m *Message) VerifyContext(ctx context.Context, sig []byte, opts VerifyOptions) error {
+	if opts.Domain != "" && opts.Domain != m.Domain {
+		return fmt.Errorf("%w: expected %s, got %s", ErrDomainMismatch, opts.Domain, m.Domain)
+	}
+	if opts.Nonce != "" && opts.Nonce != m.Nonce {
+		return ErrNonceMismatch
+	}
+	if opts.ChainID != 0 && opts.ChainID != m.ChainID {
+		return fmt.Errorf("%w: expected %d, got %d", ErrChainIDMismatch, opts.ChainID, m.ChainID)
+	}
+	now := opts.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return ErrExpired
+	}
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return ErrNotYetValid
+	}
+
+	msg := []byte(m.text())
+	var (
+		ok  bool
+		err error
+	)
+	if opts.Wallet != nil {
+		hash := common.BytesToHash(accounts.TextHash(msg))
+		ok, err = opts.Wallet.VerifySignatureContext(ctx, m.Address, hash, sig)
+	} else {
+		ok, err = goether.VerifyMsg(msg, sig, m.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package siwe
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPrvHex = "8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632"
+
+func TestMessageString(t *testing.T) {
+	issuedAt := time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC)
+	m := &Message{
+		Domain:    "service.org",
+		Address:   common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA"),
+		Statement: "I accept the ServiceOrg Terms of Service: https://service.org/tos",
+		URI:       "https://service.org/login",
+		ChainID:   1,
+		Nonce:     "32891756",
+		IssuedAt:  issuedAt,
+		Resources: []string{"ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/", "https://example.com/my-web2-claim.json"},
+	}
+	expected := `service.org wants you to sign in with your Ethereum account:
+0xab6c371B6c466BcF14d4003601951e5873dF2AcA
+
+I accept the ServiceOrg Terms of Service: https://service.org/tos
+
+URI: https://service.org/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+	assert.Equal(t, expected, m.String())
+
+	parsed, err := ParseMessage(expected)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, parsed.String())
+	assert.Equal(t, m.Address, parsed.Address)
+	assert.True(t, issuedAt.Equal(parsed.IssuedAt))
+
+	// 没有 statement 时地址后是两个空行
+	expires := issuedAt.Add(time.Hour)
+	m.Scheme = "https"
+	m.Statement = ""
+	m.Resources = nil
+	m.ExpirationTime = &expires
+	m.RequestID = "req-1"
+	parsed, err = ParseMessage(m.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "https", parsed.Scheme)
+	assert.Equal(t, "service.org", parsed.Domain)
+	assert.Empty(t, parsed.Statement)
+	assert.True(t, expires.Equal(*parsed.ExpirationTime))
+	assert.Equal(t, "req-1", parsed.RequestID)
+
+	_, err = ParseMessage("hello")
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestMessageVerify(t *testing.T) {
+	signer, err := goether.NewSigner(testPrvHex)
+	assert.NoError(t, err)
+
+	m, err := NewMessage("service.org", signer.Address, "https://service.org/login", 1)
+	assert.NoError(t, err)
+	assert.Len(t, m.Nonce, 16)
+	expires := m.IssuedAt.Add(time.Minute)
+	m.ExpirationTime = &expires
+
+	sig, err := m.Sign(signer)
+	assert.NoError(t, err)
+
+	opts := VerifyOptions{Domain: "service.org", Nonce: m.Nonce, ChainID: 1}
+	assert.NoError(t, m.Verify(sig, opts))
+
+	// 从文本解析后同样可以验证
+	parsed, err := ParseMessage(m.String())
+	assert.NoError(t, err)
+	assert.NoError(t, parsed.Verify(sig, opts))
+
+	assert.ErrorIs(t, m.Verify(sig, VerifyOptions{Domain: "evil.org"}), ErrDomainMismatch)
+	assert.ErrorIs(t, m.Verify(sig, VerifyOptions{Nonce: "other-nonce"}), ErrNonceMismatch)
+	assert.ErrorIs(t, m.Verify(sig, VerifyOptions{ChainID: 5}), ErrChainIDMismatch)
+	assert.ErrorIs(t, m.Verify(sig, VerifyOptions{Time: expires}), ErrExpired)
+
+	notBefore := m.IssuedAt.Add(time.Hour)
+	m.NotBefore = &notBefore
+	assert.ErrorIs(t, m.Verify(sig, VerifyOptions{Time: m.IssuedAt}), ErrNotYetValid)
+	m.NotBefore = nil
+
+	m.Address = common.HexToAddress("0x01")
+	assert.ErrorIs(t, m.Verify(sig, opts), ErrInvalidSignature)
+}
+
+func TestMessageVerifyParsedText(t *testing.T) {
+	signer, err := goether.NewSigner(testPrvHex)
+	assert.NoError(t, err)
+
+	// siwe-js 使用 toISOString 生成带毫秒的时间，地址也可能是小写
+	text := "service.org wants you to sign in with your Ethereum account:\n" +
+		strings.ToLower(signer.Address.Hex()) + "\n\n\n" +
+		"URI: https://service.org/login\n" +
+		"Version: 1\n" +
+		"Chain ID: 1\n" +
+		"Nonce: 32891756\n" +
+		"Issued At: 2021-09-30T16:25:24.123Z"
+	sig, err := signer.SignMsg([]byte(text))
+	assert.NoError(t, err)
+
+	m, err := ParseMessage(text)
+	assert.NoError(t, err)
+	assert.Equal(t, 123*time.Millisecond, time.Duration(m.IssuedAt.Nanosecond()))
+	assert.NotEqual(t, text, m.String())
+	assert.NoError(t, m.Verify(sig, VerifyOptions{Domain: "service.org", Nonce: "32891756", ChainID: 1}))
+
+	resig, err := m.Sign(signer)
+	assert.NoError(t, err)
+	assert.Equal(t, sig, resig)
+}
@@ -0,0 +1,103 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP-1967 代理合约的标准存储槽
+var (
+	// EIP1967ImplementationSlot 逻辑合约地址，keccak256("eip1967.proxy.implementation") - 1
+	EIP1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	// EIP1967AdminSlot 管理员地址，keccak256("eip1967.proxy.admin") - 1
+	EIP1967AdminSlot = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+	// EIP1967BeaconSlot beacon 合约地址，keccak256("eip1967.proxy.beacon") - 1
+	EIP1967BeaconSlot = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+)
+
+// GetCode 获取 address 在 tag 区块的合约代码，EOA 返回空
+func (w *Wallet) GetCode(address common.Address, tag string) ([]byte, error) {
+	return w.GetCodeContext(context.Background(), address, tag)
+}
+
+// GetCodeContext 与 GetCode 相同，但查询受 ctx 控制
+func (w *Wallet) GetCodeContext(ctx context.Context, address common.Address, tag string) ([]byte, error) {
+	code, err := callContext(ctx, func() (string, error) {
+		return w.rpc(ctx).EthGetCode(address.Hex(), tag)
+	})
+	if err != nil {
+		log.Error("Failed to get code", "address", address.Hex(), "error", err)
+		return nil, err
+	}
+	return hexutil.Decode(code)
+}
+
+// IsContract 判断 address 当前是否部署了合约
+//
+// 通过 EIP-7702 委托了代码的 EOA 不算作合约，它们依然可以使用 ECDSA 签名。
+func (w *Wallet) IsContract(address common.Address) (bool, error) {
+	return w.IsContractContext(context.Background(), address)
+}
+
+// IsContractContext 与 IsContract 相同，但查询受 ctx 控制
+func (w *Wallet) IsContractContext(ctx context.Context, address common.Address) (bool, error) {
+	code, err := w.GetCodeContext(ctx, address, "latest")
+	if err != nil {
+		return false, err
+	}
+	if _, ok := types.ParseDelegation(code); ok {
+		return false, nil
+	}
+	return len(code) > 0, nil
+}
+
+// GetStorageAt 读取 address 在 tag 区块的存储槽 slot
+//
+//	// 读取代理合约的逻辑合约地址
+//	value, err := wallet.GetStorageAt(proxy, EIP1967ImplementationSlot, "latest")
+//	impl := common.BytesToAddress(value.Bytes())
+func (w *Wallet) GetStorageAt(address common.Address, slot common.Hash, tag string) (common.Hash, error) {
+	return w.GetStorageAtContext(context.Background(), address, slot, tag)
+}
+
+// GetStorageAtContext 与 GetStorageAt 相同，但查询受 ctx 控制
+func (w *Wallet) GetStorageAtContext(ctx context.Context, address common.Address, slot common.Hash, tag string) (common.Hash, error) {
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		return w.rpc(ctx).Call("eth_getStorageAt", address.Hex(), slot.Hex(), tag)
+	})
+	if err != nil {
+		log.Error("Failed to get storage", "address", address.Hex(), "slot", slot.Hex(), "error", err)
+		return common.Hash{}, err
+	}
+	var value hexutil.Bytes
+	if err = json.Unmarshal(raw, &value); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(value), nil
+}
+
+// Slot 将序号转换为存储槽，例如合约中第一个状态变量位于 Slot(0)
+func Slot(index uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(index))
+}
+
+// MappingSlot 计算 mapping 中 key 对应值的存储槽 keccak256(key . slot)，
+// key 需要按 ABI 补齐到 32 字节，例如地址使用 common.BytesToHash(addr.Bytes())，
+// 嵌套 mapping 可以将结果作为外层的 slot 继续计算
+func MappingSlot(key, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), slot.Bytes())
+}
+
+// ArraySlot 计算动态数组第 index 个元素的存储槽 keccak256(slot) + index * elemSlots，
+// elemSlots 为每个元素占用的存储槽数量，uint256、address 等为 1
+func ArraySlot(slot common.Hash, index, elemSlots uint64) common.Hash {
+	base := new(big.Int).SetBytes(crypto.Keccak256(slot.Bytes()))
+	offset := new(big.Int).Mul(new(big.Int).SetUint64(index), new(big.Int).SetUint64(elemSlots))
+	return common.BigToHash(base.Add(base, offset))
+}
@@ -0,0 +1,86 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsContract(t *testing.T) {
+	contract := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	delegated := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	m := newMockRPC(t)
+	m.On("eth_getCode", func(params []json.RawMessage) (any, error) {
+		var address string
+		json.Unmarshal(params[0], &address)
+		switch common.HexToAddress(address) {
+		case contract:
+			return "0x6080604052", nil
+		case delegated:
+			return "0xef0100" + strings.Repeat("11", 20), nil
+		}
+		return "0x", nil
+	})
+	w := newTestWallet(t, m)
+
+	code, err := w.GetCode(contract, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x60, 0x80, 0x60, 0x40, 0x52}, code)
+
+	ok, err := w.IsContract(contract)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = w.IsContract(delegated)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = w.IsContract(w.Address)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestGetStorageAt(t *testing.T) {
+	proxy := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	impl := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	m := newMockRPC(t)
+	m.On("eth_getStorageAt", func(params []json.RawMessage) (any, error) {
+		var slot string
+		json.Unmarshal(params[1], &slot)
+		if common.HexToHash(slot) == EIP1967ImplementationSlot {
+			return common.BytesToHash(impl.Bytes()).Hex(), nil
+		}
+		return common.Hash{}.Hex(), nil
+	})
+	w := newTestWallet(t, m)
+
+	value, err := w.GetStorageAt(proxy, EIP1967ImplementationSlot, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, impl, common.BytesToAddress(value.Bytes()))
+	value, err = w.GetStorageAt(proxy, Slot(0), "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}, value)
+}
+
+func TestStorageSlots(t *testing.T) {
+	for name, slot := range map[string]common.Hash{
+		"eip1967.proxy.implementation": EIP1967ImplementationSlot,
+		"eip1967.proxy.admin":          EIP1967AdminSlot,
+		"eip1967.proxy.beacon":         EIP1967BeaconSlot,
+	} {
+		expected := new(big.Int).Sub(crypto.Keccak256Hash([]byte(name)).Big(), big.NewInt(1))
+		assert.Equal(t, common.BigToHash(expected), slot, name)
+	}
+
+	assert.Equal(t, common.HexToHash("0x03"), Slot(3))
+	owner := common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA")
+	key := common.BytesToHash(owner.Bytes())
+	assert.Equal(t, crypto.Keccak256Hash(common.LeftPadBytes(owner.Bytes(), 32), common.LeftPadBytes([]byte{1}, 32)), MappingSlot(key, Slot(1)))
+
+	base := crypto.Keccak256Hash(Slot(2).Bytes()).Big()
+	assert.Equal(t, common.BigToHash(base), ArraySlot(Slot(2), 0, 1))
+	assert.Equal(t, common.BigToHash(new(big.Int).Add(base, big.NewInt(6))), ArraySlot(Slot(2), 3, 2))
+}
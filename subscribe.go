@@ -0,0 +1,199 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultReconnectInterval WebSocket 连接断开后重连的间隔
+var DefaultReconnectInterval = 3 * time.Second
+
+// Subscriber 基于 WebSocket 的 eth_subscribe 订阅客户端
+//
+// 同一个 Subscriber 上的所有订阅共享一条连接，连接断开后会自动重连并重新订阅，
+// 订阅返回的通道在 ctx 结束后关闭。
+type Subscriber struct {
+	URL               string
+	ReconnectInterval time.Duration
+
+	mu     sync.Mutex
+	client *rpc.Client
+	closed bool
+}
+
+// NewSubscriber 创建订阅客户端，url 为 ws:// 或 wss:// 地址，连接会在第一次订阅时建立
+func NewSubscriber(url string) *Subscriber {
+	return &Subscriber{
+		URL:               url,
+		ReconnectInterval: DefaultReconnectInterval,
+	}
+}
+
+// SubscribeNewHeads 订阅新区块头
+func (s *Subscriber) SubscribeNewHeads(ctx context.Context) (<-chan *types.Header, error) {
+	return subscribe[*types.Header](ctx, s, nil, "newHeads")
+}
+
+// SubscribeLogs 订阅满足过滤条件的日志，q 中的区块范围会被忽略
+func (s *Subscriber) SubscribeLogs(ctx context.Context, q ethereum.FilterQuery) (<-chan types.Log, error) {
+	return subscribe[types.Log](ctx, s, nil, "logs", toSubscribeFilter(q))
+}
+
+// SubscribePendingTransactions 订阅进入交易池的交易哈希
+func (s *Subscriber) SubscribePendingTransactions(ctx context.Context) (<-chan common.Hash, error) {
+	return subscribe[common.Hash](ctx, s, nil, "newPendingTransactions")
+}
+
+// Close 关闭连接，之后所有订阅都会结束
+func (s *Subscriber) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+// conn 返回当前连接，尚未连接时建立新连接
+func (s *Subscriber) conn(ctx context.Context) (*rpc.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errors.New("subscriber is closed")
+	}
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	log.Debug("Dialing websocket endpoint", "url", s.URL)
+	client, err := rpc.DialContext(ctx, s.URL)
+	if err != nil {
+		log.Error("Failed to dial websocket endpoint", "url", s.URL, "error", err)
+		return nil, err
+	}
+	s.client = client
+	return client, nil
+}
+
+// drop 丢弃已断开的连接，只有当 broken 仍是当前连接时才会关闭它，
+// 避免多个订阅同时重连时互相关闭对方新建的连接
+func (s *Subscriber) drop(broken *rpc.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == broken {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+// subscribe 建立订阅并在后台转发数据，订阅出错时自动重连
+//
+// backfill 不为 nil 时，重连成功后先转发 backfill 返回的数据，用于补齐断线期间错过的数据。
+func subscribe[T any](ctx context.Context, s *Subscriber, backfill func(context.Context) []T, args ...interface{}) (<-chan T, error) {
+	client, sub, in, err := subscribeOnce[T](ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				return
+			case v := <-in:
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				}
+			case err := <-sub.Err():
+				log.Warning("Subscription dropped, reconnecting", "subscription", args[0], "error", err)
+				s.drop(client)
+				client, sub, in, err = resubscribe[T](ctx, s, args...)
+				if err != nil {
+					return
+				}
+				if backfill == nil {
+					continue
+				}
+				for _, v := range backfill(ctx) {
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						sub.Unsubscribe()
+						return
+					}
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func subscribeOnce[T any](ctx context.Context, s *Subscriber, args ...interface{}) (*rpc.Client, *rpc.ClientSubscription, chan T, error) {
+	client, err := s.conn(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ch := make(chan T)
+	sub, err := client.EthSubscribe(ctx, ch, args...)
+	if err != nil {
+		log.Error("Failed to subscribe", "subscription", args[0], "error", err)
+		return client, nil, nil, err
+	}
+	log.Debug("Subscribed", "subscription", args[0])
+	return client, sub, ch, nil
+}
+
+// resubscribe 按 ReconnectInterval 不断重试直到订阅成功或 ctx 结束
+func resubscribe[T any](ctx context.Context, s *Subscriber, args ...interface{}) (*rpc.Client, *rpc.ClientSubscription, chan T, error) {
+	interval := s.ReconnectInterval
+	if interval <= 0 {
+		interval = DefaultReconnectInterval
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		client, sub, ch, err := subscribeOnce[T](ctx, s, args...)
+		if err == nil {
+			return client, sub, ch, nil
+		}
+		if client != nil {
+			s.drop(client)
+		}
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return nil, nil, nil, err
+		}
+	}
+}
+
+// toSubscribeFilter 将 FilterQuery 转换为 logs 订阅的参数
+func toSubscribeFilter(q ethereum.FilterQuery) interface{} {
+	arg := map[string]interface{}{}
+	if len(q.Addresses) > 0 {
+		arg["address"] = q.Addresses
+	}
+	if len(q.Topics) > 0 {
+		arg["topics"] = q.Topics
+	}
+	return arg
+}
@@ -0,0 +1,67 @@
+package goether
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEthAPI struct{}
+
+func (testEthAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		for i := int64(1); ; i++ {
+			select {
+			case <-sub.Err():
+				return
+			case <-time.After(10 * time.Millisecond):
+				notifier.Notify(sub.ID, &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(0)})
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func TestSubscriber(t *testing.T) {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", testEthAPI{}))
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer srv.Close()
+
+	s := NewSubscriber("ws" + strings.TrimPrefix(srv.URL, "http"))
+	s.ReconnectInterval = 10 * time.Millisecond
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	heads, err := s.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+
+	head := <-heads
+	assert.Equal(t, int64(1), head.Number.Int64())
+
+	// 连接断开后自动重连，新的订阅从头开始推送
+	// httptest 不会关闭已升级为 WebSocket 的连接，这里直接关闭当前连接模拟断线
+	s.mu.Lock()
+	s.client.Close()
+	s.mu.Unlock()
+	for head = range heads {
+		if head.Number.Int64() == 1 {
+			break
+		}
+	}
+	assert.Equal(t, int64(1), head.Number.Int64())
+
+	cancel()
+	for range heads {
+	}
+}
@@ -0,0 +1,234 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-enols/ethrpc"
+)
+
+// CallFrame callTracer 返回的调用树中的一次调用
+type CallFrame struct {
+	// Type CALL、DELEGATECALL、STATICCALL、CREATE、CREATE2、SELFDESTRUCT 等
+	Type    string
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+	Gas     uint64
+	GasUsed uint64
+	Input   []byte
+	Output  []byte
+	// Error 调用失败的原因，例如 execution reverted、out of gas，成功时为空
+	Error string
+	// RevertReason 节点解码出的 Error(string) 回滚原因
+	RevertReason string
+	Logs         []CallLog
+	Calls        []*CallFrame
+}
+
+// CallLog 调用过程中产生的日志，只有 callTracer 开启 withLog 时返回
+type CallLog struct {
+	Address common.Address
+	Topics  []common.Hash
+	Data    []byte
+}
+
+func (f *CallFrame) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Type         string          `json:"type"`
+		From         common.Address  `json:"from"`
+		To           *common.Address `json:"to"`
+		Value        *hexutil.Big    `json:"value"`
+		Gas          hexutil.Uint64  `json:"gas"`
+		GasUsed      hexutil.Uint64  `json:"gasUsed"`
+		Input        hexutil.Bytes   `json:"input"`
+		Output       hexutil.Bytes   `json:"output"`
+		Error        string          `json:"error"`
+		RevertReason string          `json:"revertReason"`
+		Logs         []struct {
+			Address common.Address `json:"address"`
+			Topics  []common.Hash  `json:"topics"`
+			Data    hexutil.Bytes  `json:"data"`
+		} `json:"logs"`
+		Calls []*CallFrame `json:"calls"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*f = CallFrame{
+		Type:         dec.Type,
+		From:         dec.From,
+		Value:        (*big.Int)(dec.Value),
+		Gas:          uint64(dec.Gas),
+		GasUsed:      uint64(dec.GasUsed),
+		Input:        dec.Input,
+		Output:       dec.Output,
+		Error:        dec.Error,
+		RevertReason: dec.RevertReason,
+		Calls:        dec.Calls,
+	}
+	if dec.To != nil {
+		f.To = *dec.To
+	}
+	for _, l := range dec.Logs {
+		f.Logs = append(f.Logs, CallLog{Address: l.Address, Topics: l.Topics, Data: l.Data})
+	}
+	return nil
+}
+
+// Failed 调用是否失败
+func (f *CallFrame) Failed() bool {
+	return f.Error != ""
+}
+
+// Err 返回调用失败的原因，回滚时为 *RevertError，成功时返回 nil
+func (f *CallFrame) Err() error {
+	if !f.Failed() {
+		return nil
+	}
+	if len(f.Output) > 0 {
+		return DecodeRevert(f.Output)
+	}
+	if f.RevertReason != "" {
+		return &RevertError{Reason: f.RevertReason}
+	}
+	return errors.New(f.Error)
+}
+
+// Walk 深度优先遍历调用树，depth 从 0 开始，fn 返回 false 时不再进入该调用的子调用
+func (f *CallFrame) Walk(fn func(frame *CallFrame, depth int) bool) {
+	f.walk(fn, 0)
+}
+
+func (f *CallFrame) walk(fn func(frame *CallFrame, depth int) bool, depth int) {
+	if !fn(f, depth) {
+		return
+	}
+	for _, call := range f.Calls {
+		call.walk(fn, depth+1)
+	}
+}
+
+// RootCause 沿着失败的调用向下查找最深的失败调用，即导致整笔交易失败的调用，交易成功时返回 nil
+//
+// 同一层有多个失败的子调用时取最后一个，前面失败的子调用可能已被合约 try/catch 处理。
+func (f *CallFrame) RootCause() *CallFrame {
+	if !f.Failed() {
+		return nil
+	}
+	for i := len(f.Calls) - 1; i >= 0; i-- {
+		if f.Calls[i].Failed() {
+			return f.Calls[i].RootCause()
+		}
+	}
+	return f
+}
+
+// PrestateAccount prestateTracer 返回的账户状态，diff 模式下只包含发生变化的字段
+type PrestateAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// PrestateTrace prestateTracer 的结果，非 diff 模式下只有 Pre，为交易执行前涉及账户的状态
+type PrestateTrace struct {
+	Pre  map[common.Address]*PrestateAccount `json:"pre"`
+	Post map[common.Address]*PrestateAccount `json:"post"`
+}
+
+// TraceTransaction 使用 callTracer 执行 debug_traceTransaction，返回包含日志的调用树，需要节点开启 debug 命名空间
+func (w *Wallet) TraceTransaction(txHash common.Hash) (*CallFrame, error) {
+	return w.TraceTransactionContext(context.Background(), txHash)
+}
+
+// TraceTransactionContext 与 TraceTransaction 相同，但请求受 ctx 控制
+func (w *Wallet) TraceTransactionContext(ctx context.Context, txHash common.Hash) (*CallFrame, error) {
+	var frame CallFrame
+	if err := w.trace(ctx, "debug_traceTransaction", txHash, "", callTracerConfig(), &frame); err != nil {
+		return nil, err
+	}
+	return &frame, nil
+}
+
+// TraceCall 使用 callTracer 执行 debug_traceCall，从钱包地址在 tag 区块上模拟调用并返回调用树
+func (w *Wallet) TraceCall(to common.Address, amount *big.Int, data []byte, tag string) (*CallFrame, error) {
+	return w.TraceCallContext(context.Background(), to, amount, data, tag)
+}
+
+// TraceCallContext 与 TraceCall 相同，但请求受 ctx 控制
+func (w *Wallet) TraceCallContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, tag string) (*CallFrame, error) {
+	var frame CallFrame
+	if err := w.trace(ctx, "debug_traceCall", w.traceMsg(to, amount, data), tag, callTracerConfig(), &frame); err != nil {
+		return nil, err
+	}
+	return &frame, nil
+}
+
+// TracePrestate 使用 prestateTracer 执行 debug_traceTransaction，diff 为 true 时同时返回执行后的状态
+func (w *Wallet) TracePrestate(txHash common.Hash, diff bool) (*PrestateTrace, error) {
+	return w.TracePrestateContext(context.Background(), txHash, diff)
+}
+
+// TracePrestateContext 与 TracePrestate 相同，但请求受 ctx 控制
+func (w *Wallet) TracePrestateContext(ctx context.Context, txHash common.Hash, diff bool) (*PrestateTrace, error) {
+	return w.prestate(ctx, "debug_traceTransaction", txHash, "", diff)
+}
+
+// TraceCallPrestate 使用 prestateTracer 执行 debug_traceCall
+func (w *Wallet) TraceCallPrestate(to common.Address, amount *big.Int, data []byte, tag string, diff bool) (*PrestateTrace, error) {
+	return w.TraceCallPrestateContext(context.Background(), to, amount, data, tag, diff)
+}
+
+// TraceCallPrestateContext 与 TraceCallPrestate 相同，但请求受 ctx 控制
+func (w *Wallet) TraceCallPrestateContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, tag string, diff bool) (*PrestateTrace, error) {
+	return w.prestate(ctx, "debug_traceCall", w.traceMsg(to, amount, data), tag, diff)
+}
+
+func (w *Wallet) prestate(ctx context.Context, method string, target any, tag string, diff bool) (*PrestateTrace, error) {
+	config := map[string]any{"tracer": "prestateTracer", "tracerConfig": map[string]any{"diffMode": diff}}
+	var result PrestateTrace
+	if diff {
+		if err := w.trace(ctx, method, target, tag, config, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+	if err := w.trace(ctx, method, target, tag, config, &result.Pre); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func callTracerConfig() map[string]any {
+	return map[string]any{"tracer": "callTracer", "tracerConfig": map[string]any{"withLog": true}}
+}
+
+func (w *Wallet) traceMsg(to common.Address, amount *big.Int, data []byte) ethrpc.T {
+	return ethrpc.T{
+		From:  w.Address.String(),
+		To:    to.String(),
+		Value: amount,
+		Data:  hexutil.Encode(data),
+	}
+}
+
+// trace 调用 debug_traceTransaction 或 debug_traceCall，tag 仅用于 debug_traceCall
+func (w *Wallet) trace(ctx context.Context, method string, target any, tag string, config map[string]any, out any) error {
+	raw, err := callContext(ctx, func() (json.RawMessage, error) {
+		if method == "debug_traceCall" {
+			return w.rpc(ctx).Call(method, target, tag, config)
+		}
+		return w.rpc(ctx).Call(method, target, config)
+	})
+	if err != nil {
+		log.Error("Failed to trace", "method", method, "tracer", config["tracer"], "error", err)
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
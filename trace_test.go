@@ -0,0 +1,117 @@
+package goether
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// failedCallTrace router 调用 pair，pair 调用 token 时余额不足回滚
+const failedCallTrace = `{
+	"type": "CALL", "from": "0x1111111111111111111111111111111111111111", "to": "0x00000000000000000000000000000000000000a1",
+	"value": "0x0", "gas": "0x30000", "gasUsed": "0x9000", "input": "0x38ed1739",
+	"output": "%s", "error": "execution reverted", "revertReason": "insufficient balance",
+	"calls": [
+		{"type": "STATICCALL", "from": "0x00000000000000000000000000000000000000a1", "to": "0x00000000000000000000000000000000000000a2",
+		 "gas": "0x2000", "gasUsed": "0x800", "input": "0x0902f1ac", "output": "0x"},
+		{"type": "CALL", "from": "0x00000000000000000000000000000000000000a1", "to": "0x00000000000000000000000000000000000000a2",
+		 "value": "0x0", "gas": "0x20000", "gasUsed": "0x5000", "input": "0x022c0d9f", "error": "execution reverted",
+		 "calls": [
+			{"type": "DELEGATECALL", "from": "0x00000000000000000000000000000000000000a2", "to": "0x00000000000000000000000000000000000000a3",
+			 "gas": "0x10000", "gasUsed": "0x3000", "input": "0xa9059cbb", "output": "%s", "error": "execution reverted", "revertReason": "insufficient balance",
+			 "logs": [{"address": "0x00000000000000000000000000000000000000a3", "topics": ["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"], "data": "0x01"}]}
+		 ]}
+	]
+}`
+
+func TestTraceTransaction(t *testing.T) {
+	reverted := hexutil.Encode(revertData(t, "Error(string)", "string", "insufficient balance"))
+	trace := []byte(fmt.Sprintf(failedCallTrace, reverted, reverted))
+	txHash := common.HexToHash("0x01")
+
+	m := newMockRPC(t)
+	m.On("debug_traceTransaction", func(params []json.RawMessage) (any, error) {
+		var config struct {
+			Tracer       string         `json:"tracer"`
+			TracerConfig map[string]any `json:"tracerConfig"`
+		}
+		assert.Equal(t, `"`+txHash.Hex()+`"`, string(params[0]))
+		json.Unmarshal(params[1], &config)
+		if config.Tracer == "prestateTracer" {
+			account := map[string]any{"balance": "0x64", "nonce": 1, "storage": map[string]string{common.Hash{}.Hex(): common.BigToHash(big.NewInt(5)).Hex()}}
+			if config.TracerConfig["diffMode"] == true {
+				return map[string]any{"pre": map[string]any{eventFrom.Hex(): account}, "post": map[string]any{eventFrom.Hex(): map[string]any{"balance": "0x32"}}}, nil
+			}
+			return map[string]any{eventFrom.Hex(): account}, nil
+		}
+		assert.Equal(t, "callTracer", config.Tracer)
+		assert.Equal(t, true, config.TracerConfig["withLog"])
+		return json.RawMessage(trace), nil
+	})
+	m.On("debug_traceCall", func(params []json.RawMessage) (any, error) {
+		var msg map[string]string
+		json.Unmarshal(params[0], &msg)
+		assert.Equal(t, common.HexToAddress("0xa1"), common.HexToAddress(msg["to"]))
+		assert.Equal(t, `"latest"`, string(params[1]))
+		return json.RawMessage(trace), nil
+	})
+	w := newTestWallet(t, m)
+
+	frame, err := w.TraceTransaction(txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, "CALL", frame.Type)
+	assert.Equal(t, eventFrom, frame.From)
+	assert.Equal(t, uint64(0x9000), frame.GasUsed)
+	assert.True(t, frame.Failed())
+	assert.Len(t, frame.Calls, 2)
+	assert.False(t, frame.Calls[0].Failed())
+	assert.Nil(t, frame.Calls[0].Err())
+
+	var types []string
+	var depths []int
+	frame.Walk(func(f *CallFrame, depth int) bool {
+		types = append(types, f.Type)
+		depths = append(depths, depth)
+		return true
+	})
+	assert.Equal(t, []string{"CALL", "STATICCALL", "CALL", "DELEGATECALL"}, types)
+	assert.Equal(t, []int{0, 1, 1, 2}, depths)
+
+	cause := frame.RootCause()
+	assert.Equal(t, "DELEGATECALL", cause.Type)
+	assert.Equal(t, common.HexToAddress("0xa3"), cause.To)
+	assert.Len(t, cause.Logs, 1)
+	assert.Equal(t, transferTopic, cause.Logs[0].Topics[0])
+	var revert *RevertError
+	assert.ErrorAs(t, cause.Err(), &revert)
+	assert.Equal(t, "insufficient balance", revert.Reason)
+	// 没有返回数据和 revertReason 时返回节点给出的错误
+	assert.EqualError(t, frame.Calls[1].Err(), "execution reverted")
+	frame.Output = nil
+	assert.ErrorAs(t, frame.Err(), &revert)
+	assert.Equal(t, "insufficient balance", revert.Reason)
+
+	frame, err = w.TraceCall(common.HexToAddress("0xa1"), nil, []byte{0x38, 0xed, 0x17, 0x39}, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "DELEGATECALL", frame.RootCause().Type)
+
+	// 子调用失败但被捕获，交易本身成功
+	frame.Error = ""
+	assert.Nil(t, frame.RootCause())
+
+	prestate, err := w.TracePrestate(txHash, false)
+	assert.NoError(t, err)
+	assert.Nil(t, prestate.Post)
+	assert.Equal(t, big.NewInt(100), prestate.Pre[eventFrom].Balance.ToInt())
+	assert.Equal(t, uint64(1), prestate.Pre[eventFrom].Nonce)
+	assert.Equal(t, common.BigToHash(big.NewInt(5)), prestate.Pre[eventFrom].Storage[common.Hash{}])
+
+	prestate, err = w.TracePrestate(txHash, true)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(50), prestate.Post[eventFrom].Balance.ToInt())
+}
@@ -0,0 +1,459 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/ethrpc"
+)
+
+// TrackEventType 交易跟踪事件的类型
+type TrackEventType int
+
+const (
+	// TrackIncluded 交易已被打包
+	TrackIncluded TrackEventType = iota
+	// TrackConfirmed 交易达到要求的确认数，ReorgDepth 不大于 Confirmations 时跟踪结束
+	TrackConfirmed
+	// TrackDropped 交易从交易池中消失且 nonce 未被使用，跟踪结束
+	TrackDropped
+	// TrackReplaced 交易从交易池中消失且 nonce 已被其他交易使用（加速、取消或冲突），跟踪结束
+	TrackReplaced
+	// TrackTimeout 超过 Timeout 仍未达到确认数，跟踪结束
+	TrackTimeout
+	// TrackReorged 区块重组使已打包的交易离开了原来的区块，之后会继续跟踪，
+	// 交易被重新打包时再次产生 TrackIncluded
+	TrackReorged
+	// TrackFinalized 交易达到 ReorgDepth 的确认数，不再检测重组，跟踪结束
+	TrackFinalized
+)
+
+func (t TrackEventType) String() string {
+	switch t {
+	case TrackIncluded:
+		return "included"
+	case TrackConfirmed:
+		return "confirmed"
+	case TrackDropped:
+		return "dropped"
+	case TrackReplaced:
+		return "replaced"
+	case TrackTimeout:
+		return "timeout"
+	case TrackReorged:
+		return "reorged"
+	case TrackFinalized:
+		return "finalized"
+	}
+	return fmt.Sprintf("TrackEventType(%d)", int(t))
+}
+
+// TrackEvent 交易跟踪事件
+type TrackEvent struct {
+	Type   TrackEventType
+	TxHash string
+	// Receipt 交易被打包后的回执，TrackReorged 事件中为重组前的回执，其余事件为 nil
+	Receipt *Receipt
+	// Confirmations 当前的确认数，交易所在区块记为 1
+	Confirmations int
+
+	// watching TrackConfirmed 之后是否继续检测重组
+	watching bool
+}
+
+// Final 事件之后是否不会再有新的事件
+func (e TrackEvent) Final() bool {
+	return e.Type != TrackIncluded && e.Type != TrackReorged && !e.watching
+}
+
+// trackMissLimit 之前见过的交易连续多少次查询不到才视为被丢弃，避免负载均衡节点之间的短暂不一致
+const trackMissLimit = 3
+
+// Tracker 在后台统一轮询区块高度并跟踪多笔交易的状态，代替应用自行编写轮询循环
+//
+// 每笔交易依次产生 TrackIncluded 与 TrackConfirmed 事件，或以 TrackDropped、TrackReplaced、
+// TrackTimeout 结束。没有需要跟踪的交易时后台轮询自动停止。
+//
+// 打包后会记录交易所在的区块哈希，区块重组使交易离开该区块时产生 TrackReorged。
+// 重组检测持续到确认数达到 Confirmations 与 ReorgDepth 中较大的一个，ReorgDepth 更大时
+// TrackConfirmed 之后继续跟踪，达到 ReorgDepth 时产生 TrackFinalized。
+type Tracker struct {
+	Wallet *Wallet
+	// Confirmations 需要的确认数，默认 1，即打包后立即确认
+	Confirmations int
+	// ReorgDepth 重组检测的深度（确认数），大于 Confirmations 时确认后继续检测重组，默认 0
+	ReorgDepth int
+	// Timeout 单笔交易的最长跟踪时间，默认 DefaultWaitTimeout
+	Timeout time.Duration
+	// PollInterval 轮询间隔，默认 ReceiptPollInterval
+	PollInterval time.Duration
+	// Contracts 用于解码回执中的事件
+	Contracts []*Contract
+	// Rebroadcast 区块重组使交易回到交易池或被丢弃时，是否自动重新广播原始交易
+	Rebroadcast bool
+
+	mu      sync.Mutex
+	txs     map[string]*trackedTx
+	running bool
+	stop    chan struct{}
+}
+
+type trackedTx struct {
+	hash     string
+	ctx      context.Context
+	timeout  time.Duration
+	deadline time.Time
+	// ch 与 fn 只会设置一个
+	ch   chan TrackEvent
+	fn   func(TrackEvent)
+	quit chan struct{}
+	once sync.Once
+	// mu 保证关闭 ch 时没有正在进行的发送
+	mu     sync.Mutex
+	closed bool
+
+	from      common.Address
+	nonce     int
+	seen      bool
+	misses    int
+	included  bool
+	confirmed bool
+	// receipt 打包后的回执，用于发现区块哈希的变化
+	receipt *Receipt
+	// raw 签名后的原始交易，用于重组后重新广播
+	raw []byte
+}
+
+// emit 发送事件，消费方不读取时发送会阻塞到 ctx 结束或跟踪停止
+func (tx *trackedTx) emit(e TrackEvent) {
+	if tx.fn != nil {
+		tx.fn(e)
+		return
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.closed {
+		return
+	}
+	select {
+	case tx.ch <- e:
+	case <-tx.ctx.Done():
+	case <-tx.quit:
+	}
+}
+
+// close 结束跟踪并关闭通道，可以重复调用
+func (tx *trackedTx) close() {
+	tx.once.Do(func() {
+		close(tx.quit)
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		tx.closed = true
+		if tx.ch != nil {
+			close(tx.ch)
+		}
+	})
+}
+
+// NewTracker 创建使用 w 查询链上状态的交易跟踪器
+func NewTracker(w *Wallet) *Tracker {
+	return &Tracker{
+		Wallet:        w,
+		Confirmations: 1,
+		Timeout:       DefaultWaitTimeout,
+		PollInterval:  ReceiptPollInterval,
+		txs:           make(map[string]*trackedTx),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Track 开始跟踪交易，返回的通道在最后一个事件之后关闭
+func (t *Tracker) Track(txHash string) <-chan TrackEvent {
+	return t.TrackContext(context.Background(), txHash)
+}
+
+// TrackContext 与 Track 相同，ctx 结束后停止跟踪并关闭通道
+func (t *Tracker) TrackContext(ctx context.Context, txHash string) <-chan TrackEvent {
+	ch := make(chan TrackEvent, 4)
+	t.add(ctx, txHash, ch, nil, nil)
+	return ch
+}
+
+// TrackTx 与 Track 相同，但同时保存签名后的交易，开启 Rebroadcast 时不依赖节点的 eth_getRawTransactionByHash
+func (t *Tracker) TrackTx(tx *types.Transaction) (<-chan TrackEvent, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan TrackEvent, 4)
+	t.add(context.Background(), tx.Hash().Hex(), ch, nil, raw)
+	return ch, nil
+}
+
+// TrackFunc 开始跟踪交易，每个事件都会在后台轮询的 goroutine 中调用 fn，fn 不应长时间阻塞
+func (t *Tracker) TrackFunc(txHash string, fn func(TrackEvent)) {
+	t.add(context.Background(), txHash, nil, fn, nil)
+}
+
+// Stop 停止后台轮询，所有正在跟踪的交易的通道都会被关闭
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.running {
+		close(t.stop)
+		t.stop = make(chan struct{})
+		t.running = false
+	}
+	for hash, tx := range t.txs {
+		tx.close()
+		delete(t.txs, hash)
+	}
+}
+
+func (t *Tracker) add(ctx context.Context, txHash string, ch chan TrackEvent, fn func(TrackEvent), raw []byte) {
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+	tx := &trackedTx{
+		hash:     strings.ToLower(txHash),
+		ctx:      ctx,
+		timeout:  timeout,
+		deadline: time.Now().Add(timeout),
+		ch:       ch,
+		fn:       fn,
+		quit:     make(chan struct{}),
+		raw:      raw,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if old, ok := t.txs[tx.hash]; ok {
+		old.close()
+	}
+	t.txs[tx.hash] = tx
+	log.Debug("Tracking transaction", "txHash", txHash, "confirmations", t.Confirmations, "reorgDepth", t.ReorgDepth)
+	if !t.running {
+		t.running = true
+		go t.run(t.stop)
+	}
+}
+
+// run 后台轮询循环，没有需要跟踪的交易时退出
+func (t *Tracker) run(stop chan struct{}) {
+	interval := t.PollInterval
+	if interval <= 0 {
+		interval = ReceiptPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		t.poll()
+
+		t.mu.Lock()
+		if len(t.txs) == 0 || t.stop != stop {
+			// Stop 之后重新 Track 会启动新的循环，旧循环不能修改 running
+			if t.stop == stop {
+				t.running = false
+			}
+			t.mu.Unlock()
+			return
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll 查询一次区块高度并检查所有交易
+func (t *Tracker) poll() {
+	t.mu.Lock()
+	txs := make([]*trackedTx, 0, len(t.txs))
+	for _, tx := range t.txs {
+		txs = append(txs, tx)
+	}
+	t.mu.Unlock()
+
+	head, err := callContext(context.Background(), t.Wallet.Client.EthBlockNumber)
+	if err != nil {
+		log.Warning("Failed to get block number for tracker", "error", err)
+		return
+	}
+	for _, tx := range txs {
+		if t.check(tx, head) {
+			t.finish(tx)
+		}
+	}
+}
+
+// check 检查交易状态并发出事件，返回 true 表示跟踪结束
+func (t *Tracker) check(tx *trackedTx, head int) bool {
+	if tx.ctx.Err() != nil {
+		return true
+	}
+	// 确认后只检测重组，不再受 Timeout 限制
+	if !tx.confirmed && time.Now().After(tx.deadline) {
+		log.Warning("Transaction tracking timed out", "txHash", tx.hash)
+		tx.emit(TrackEvent{Type: TrackTimeout, TxHash: tx.hash})
+		return true
+	}
+
+	receipt, err := t.Wallet.GetReceiptContext(tx.ctx, tx.hash, t.Contracts...)
+	if errors.Is(err, ErrTxNotFound) {
+		if tx.included {
+			t.reorged(tx, true)
+		}
+		return t.checkMissing(tx)
+	}
+	if err != nil {
+		return false
+	}
+	tx.misses = 0
+
+	if tx.included && receipt.BlockHash != tx.receipt.BlockHash {
+		t.reorged(tx, false)
+	}
+	confirmations := head - int(receipt.BlockNumber.Int64()) + 1
+	if !tx.included {
+		tx.included = true
+		tx.receipt = receipt
+		if tx.from == (common.Address{}) {
+			// 开始跟踪时交易已被打包，记录发送方与 nonce，重组后才能判断交易是否被替换
+			t.lookup(tx)
+		}
+		if t.Rebroadcast && tx.raw == nil {
+			tx.raw = t.rawTransaction(tx)
+		}
+		log.Debug("Tracked transaction included", "txHash", tx.hash, "block", receipt.BlockNumber, "blockHash", receipt.BlockHash.Hex())
+		tx.emit(TrackEvent{Type: TrackIncluded, TxHash: tx.hash, Receipt: receipt, Confirmations: confirmations})
+	}
+	depth := max(t.Confirmations, t.ReorgDepth)
+	if !tx.confirmed && confirmations >= t.Confirmations {
+		tx.confirmed = true
+		log.Debug("Tracked transaction confirmed", "txHash", tx.hash, "confirmations", confirmations)
+		tx.emit(TrackEvent{Type: TrackConfirmed, TxHash: tx.hash, Receipt: receipt, Confirmations: confirmations, watching: depth > t.Confirmations})
+	}
+	if confirmations < depth {
+		return false
+	}
+	if depth > t.Confirmations {
+		log.Debug("Tracked transaction finalized", "txHash", tx.hash, "confirmations", confirmations)
+		tx.emit(TrackEvent{Type: TrackFinalized, TxHash: tx.hash, Receipt: receipt, Confirmations: confirmations})
+	}
+	return true
+}
+
+// reorged 交易离开了原来的区块，removed 为 true 表示交易已不在任何区块中
+func (t *Tracker) reorged(tx *trackedTx, removed bool) {
+	log.Warning("Tracked transaction reorged", "txHash", tx.hash, "block", tx.receipt.BlockNumber, "blockHash", tx.receipt.BlockHash.Hex(), "removed", removed)
+	tx.emit(TrackEvent{Type: TrackReorged, TxHash: tx.hash, Receipt: tx.receipt})
+	tx.included = false
+	tx.receipt = nil
+	if tx.confirmed {
+		// 确认后被重组的交易重新开始计算超时
+		tx.confirmed = false
+		tx.deadline = time.Now().Add(tx.timeout)
+	}
+	// 重组后的交易可能回到交易池，也可能因为节点没有重新接收而丢失，按之前见过处理以便发现丢弃或替换
+	tx.seen = true
+	tx.misses = 0
+
+	if removed && t.Rebroadcast && tx.raw != nil {
+		if _, err := t.Wallet.sendRawTransaction(tx.ctx, tx.raw, false); err != nil {
+			log.Warning("Failed to rebroadcast reorged transaction", "txHash", tx.hash, "error", err)
+		} else {
+			log.Info("Reorged transaction rebroadcast", "txHash", tx.hash)
+		}
+	}
+}
+
+// rawTransaction 通过 eth_getRawTransactionByHash 获取签名后的交易，节点不支持时返回 nil
+func (t *Tracker) rawTransaction(tx *trackedTx) []byte {
+	raw, err := callContext(tx.ctx, func() (json.RawMessage, error) {
+		return t.Wallet.rpc(tx.ctx).Call("eth_getRawTransactionByHash", tx.hash)
+	})
+	if err != nil {
+		log.Debug("Failed to get raw transaction", "txHash", tx.hash, "error", err)
+		return nil
+	}
+	var encoded hexutil.Bytes
+	if err = json.Unmarshal(raw, &encoded); err != nil || len(encoded) == 0 {
+		return nil
+	}
+	return encoded
+}
+
+// lookup 查询交易并记录发送方与 nonce，返回节点上是否存在该交易
+func (t *Tracker) lookup(tx *trackedTx) (bool, error) {
+	found, err := callContext(tx.ctx, func() (*ethrpc.Transaction, error) {
+		return t.Wallet.rpc(tx.ctx).EthGetTransactionByHash(tx.hash)
+	})
+	if err != nil {
+		log.Debug("Failed to get tracked transaction", "txHash", tx.hash, "error", err)
+		return false, err
+	}
+	if found.Hash == "" {
+		return false, nil
+	}
+	tx.from = common.HexToAddress(found.From)
+	tx.nonce = found.Nonce
+	return true, nil
+}
+
+// checkMissing 处理没有回执的交易：仍在交易池中时记录发送方与 nonce，消失后判断是被替换还是被丢弃
+func (t *Tracker) checkMissing(tx *trackedTx) bool {
+	pending, err := t.lookup(tx)
+	if err != nil {
+		return false
+	}
+	if pending {
+		tx.seen = true
+		tx.misses = 0
+		return false
+	}
+	if !tx.seen || tx.from == (common.Address{}) {
+		return false
+	}
+
+	used, err := callContext(tx.ctx, func() (int, error) {
+		return t.Wallet.rpc(tx.ctx).EthGetTransactionCount(tx.from.Hex(), "latest")
+	})
+	if err != nil {
+		return false
+	}
+	if used > tx.nonce {
+		log.Warning("Tracked transaction replaced", "txHash", tx.hash, "from", tx.from.Hex(), "nonce", tx.nonce)
+		tx.emit(TrackEvent{Type: TrackReplaced, TxHash: tx.hash})
+		return true
+	}
+	tx.misses++
+	if tx.misses < trackMissLimit {
+		return false
+	}
+	log.Warning("Tracked transaction dropped", "txHash", tx.hash, "from", tx.from.Hex(), "nonce", tx.nonce)
+	tx.emit(TrackEvent{Type: TrackDropped, TxHash: tx.hash})
+	return true
+}
+
+// finish 移除交易并关闭其通道，交易已被重新 Track 时不做处理
+func (t *Tracker) finish(tx *trackedTx) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.txs[tx.hash] == tx {
+		delete(t.txs, tx.hash)
+	}
+	tx.close()
+}
@@ -0,0 +1,310 @@
+package goether
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// trackerChain 模拟节点上的区块高度、回执与交易池
+type trackerChain struct {
+	mu       sync.Mutex
+	head     int
+	receipts map[common.Hash]*types.Receipt
+	pool     map[common.Hash]int
+	// mined 已打包交易的 nonce
+	mined map[common.Hash]int
+	nonce int
+}
+
+func newTrackerChain(m *mockRPC) *trackerChain {
+	c := &trackerChain{
+		head:     100,
+		receipts: map[common.Hash]*types.Receipt{},
+		pool:     map[common.Hash]int{},
+		mined:    map[common.Hash]int{},
+	}
+	hashParam := func(params []json.RawMessage) common.Hash {
+		var hash string
+		json.Unmarshal(params[0], &hash)
+		return common.HexToHash(hash)
+	}
+	m.On("eth_blockNumber", func([]json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.head++
+		return hexutil.EncodeUint64(uint64(c.head)), nil
+	})
+	m.On("eth_getTransactionReceipt", func(params []json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if r, ok := c.receipts[hashParam(params)]; ok {
+			return r, nil
+		}
+		return nil, nil
+	})
+	m.On("eth_getTransactionByHash", func(params []json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		hash := hashParam(params)
+		nonce, ok := c.pool[hash]
+		if !ok {
+			if nonce, ok = c.mined[hash]; !ok {
+				return nil, nil
+			}
+		}
+		return map[string]any{
+			"hash":  hash,
+			"from":  TestSigner.Address,
+			"nonce": hexutil.EncodeUint64(uint64(nonce)),
+			"gas":   "0x5208",
+			"value": "0x0",
+			"input": "0x",
+		}, nil
+	})
+	m.On("eth_getTransactionCount", func([]json.RawMessage) (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return hexutil.EncodeUint64(uint64(c.nonce)), nil
+	})
+	return c
+}
+
+func (c *trackerChain) mine(hash, blockHash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mined[hash] = c.pool[hash]
+	delete(c.pool, hash)
+	c.receipts[hash] = &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            hash,
+		GasUsed:           21000,
+		CumulativeGasUsed: 21000,
+		BlockHash:         blockHash,
+		BlockNumber:       big.NewInt(int64(c.head)),
+		Logs:              []*types.Log{},
+	}
+}
+
+func collectEvents(t *testing.T, ch <-chan TrackEvent) []TrackEventType {
+	var events []TrackEventType
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, e.Type)
+		case <-timeout:
+			t.Fatal("tracker did not finish")
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	m := newMockRPC(t)
+	chain := newTrackerChain(m)
+	w := newTestWallet(t, m)
+	tracker := NewTracker(w)
+	tracker.Confirmations = 3
+	tracker.PollInterval = 5 * time.Millisecond
+	defer tracker.Stop()
+
+	mined := common.HexToHash("0x01")
+	replaced := common.HexToHash("0x02")
+	dropped := common.HexToHash("0x03")
+	chain.pool[mined] = 0
+	chain.pool[replaced] = 1
+	chain.pool[dropped] = 2
+	chain.nonce = 0
+
+	minedEvents := tracker.Track(mined.Hex())
+	replacedEvents := tracker.Track(replaced.Hex())
+	var (
+		mu      sync.Mutex
+		results []TrackEvent
+		done    = make(chan struct{})
+	)
+	tracker.TrackFunc(dropped.Hex(), func(e TrackEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, e)
+		if e.Final() {
+			close(done)
+		}
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	chain.mine(mined, common.HexToHash("0xb10c"))
+	chain.mu.Lock()
+	delete(chain.pool, replaced)
+	delete(chain.pool, dropped)
+	chain.nonce = 2
+	chain.mu.Unlock()
+
+	assert.Equal(t, []TrackEventType{TrackIncluded, TrackConfirmed}, collectEvents(t, minedEvents))
+	assert.Equal(t, []TrackEventType{TrackReplaced}, collectEvents(t, replacedEvents))
+	<-done
+	assert.Len(t, results, 1)
+	assert.Equal(t, TrackDropped, results[0].Type)
+}
+
+func TestTrackerTimeout(t *testing.T) {
+	m := newMockRPC(t)
+	newTrackerChain(m)
+	w := newTestWallet(t, m)
+	tracker := NewTracker(w)
+	tracker.PollInterval = 5 * time.Millisecond
+	tracker.Timeout = 30 * time.Millisecond
+
+	events := tracker.Track(common.HexToHash("0x04").Hex())
+	assert.Equal(t, []TrackEventType{TrackTimeout}, collectEvents(t, events))
+
+	// Stop 会关闭所有正在跟踪的通道
+	tracker.Timeout = time.Minute
+	events = tracker.Track(common.HexToHash("0x05").Hex())
+	tracker.Stop()
+	assert.Empty(t, collectEvents(t, events))
+}
+
+func TestTrackerReorg(t *testing.T) {
+	m := newMockRPC(t)
+	chain := newTrackerChain(m)
+	w := newTestWallet(t, m)
+	tracker := NewTracker(w)
+	tracker.Confirmations = 50
+	tracker.PollInterval = 5 * time.Millisecond
+	tracker.Rebroadcast = true
+	defer tracker.Stop()
+
+	tx, err := TestSigner.SignTx(0, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1), big.NewInt(1), nil, big.NewInt(1))
+	assert.NoError(t, err)
+	rebroadcast := make(chan struct{}, 1)
+	m.On("eth_sendRawTransaction", func(params []json.RawMessage) (any, error) {
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		got := new(types.Transaction)
+		assert.NoError(t, got.UnmarshalBinary(hexutil.MustDecode(raw)))
+		assert.Equal(t, tx.Hash(), got.Hash())
+		rebroadcast <- struct{}{}
+		return got.Hash().Hex(), nil
+	})
+
+	events, err := tracker.TrackTx(tx)
+	assert.NoError(t, err)
+	next := func() TrackEvent {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("tracker did not emit event")
+		}
+		return TrackEvent{}
+	}
+
+	chain.mine(tx.Hash(), common.HexToHash("0xa1"))
+	e := next()
+	assert.Equal(t, TrackIncluded, e.Type)
+	assert.Equal(t, common.HexToHash("0xa1"), e.Receipt.BlockHash)
+
+	// 交易所在的区块被重组掉，自动重新广播后被打包进新的区块
+	chain.mu.Lock()
+	delete(chain.receipts, tx.Hash())
+	chain.mu.Unlock()
+	e = next()
+	assert.Equal(t, TrackReorged, e.Type)
+	assert.Equal(t, common.HexToHash("0xa1"), e.Receipt.BlockHash)
+	<-rebroadcast
+	chain.mine(tx.Hash(), common.HexToHash("0xa2"))
+	e = next()
+	assert.Equal(t, TrackIncluded, e.Type)
+	assert.Equal(t, common.HexToHash("0xa2"), e.Receipt.BlockHash)
+
+	// 交易直接出现在另一个区块中
+	chain.mine(tx.Hash(), common.HexToHash("0xa3"))
+	assert.Equal(t, TrackReorged, next().Type)
+	e = next()
+	assert.Equal(t, TrackIncluded, e.Type)
+	assert.Equal(t, common.HexToHash("0xa3"), e.Receipt.BlockHash)
+
+	e = next()
+	assert.Equal(t, TrackConfirmed, e.Type)
+	assert.True(t, e.Final())
+	assert.Len(t, rebroadcast, 0)
+}
+
+func TestTrackerReorgDepth(t *testing.T) {
+	m := newMockRPC(t)
+	chain := newTrackerChain(m)
+	w := newTestWallet(t, m)
+	tracker := NewTracker(w)
+	tracker.Confirmations = 2
+	tracker.ReorgDepth = 20
+	tracker.PollInterval = 5 * time.Millisecond
+	defer tracker.Stop()
+
+	hash := common.HexToHash("0x06")
+	chain.pool[hash] = 0
+	events := tracker.Track(hash.Hex())
+	next := func() TrackEvent {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("tracker did not emit event")
+		}
+		return TrackEvent{}
+	}
+
+	chain.mine(hash, common.HexToHash("0xb1"))
+	assert.Equal(t, TrackIncluded, next().Type)
+	e := next()
+	assert.Equal(t, TrackConfirmed, e.Type)
+	assert.False(t, e.Final())
+
+	// 确认后的交易仍会检测到重组
+	chain.mine(hash, common.HexToHash("0xb2"))
+	assert.Equal(t, TrackReorged, next().Type)
+	assert.Equal(t, TrackIncluded, next().Type)
+	assert.Equal(t, TrackConfirmed, next().Type)
+	e = next()
+	assert.Equal(t, TrackFinalized, e.Type)
+	assert.True(t, e.Final())
+	assert.GreaterOrEqual(t, e.Confirmations, 20)
+	assert.Equal(t, common.HexToHash("0xb2"), e.Receipt.BlockHash)
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestTrackerMinedBeforeTrack(t *testing.T) {
+	m := newMockRPC(t)
+	chain := newTrackerChain(m)
+	w := newTestWallet(t, m)
+	tracker := NewTracker(w)
+	tracker.Confirmations = 1000
+	tracker.PollInterval = 5 * time.Millisecond
+	defer tracker.Stop()
+
+	// 开始跟踪前交易已被打包，从未在交易池中见过
+	hash := common.HexToHash("0x07")
+	chain.mine(hash, common.HexToHash("0xc1"))
+	chain.nonce = 1
+	events := tracker.Track(hash.Hex())
+	e := <-events
+	assert.Equal(t, TrackIncluded, e.Type)
+
+	// 重组后交易消失且 nonce 已被使用，需要知道发送方才能判断为被替换
+	chain.mu.Lock()
+	delete(chain.receipts, hash)
+	delete(chain.mined, hash)
+	chain.mu.Unlock()
+	assert.Equal(t, []TrackEventType{TrackReorged, TrackReplaced}, collectEvents(t, events))
+}
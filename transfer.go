@@ -0,0 +1,102 @@
+package goether
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// Transfer 向 to 转账 amount 个原生币，amount 为十进制字符串，例如 "1.5" 表示 1.5 ETH，
+// to 可以是十六进制地址或 ENS 名称
+func (w *Wallet) Transfer(to, amount string, opts *TxOpts) (txHash string, err error) {
+	return w.TransferContext(context.Background(), to, amount, opts)
+}
+
+// TransferContext 与 Transfer 相同，但交易的构建与广播受 ctx 控制
+func (w *Wallet) TransferContext(ctx context.Context, to, amount string, opts *TxOpts) (txHash string, err error) {
+	value, err := ParseUnits(amount, 18)
+	if err != nil {
+		return
+	}
+	return w.SendTxToContext(ctx, to, value, nil, opts)
+}
+
+// TransferToken 向 to 转账 amount 个 token 代币，amount 按代币的 decimals 换算，
+// 例如 USDC 的 "100.25" 为 100250000，token 与 to 可以是十六进制地址或 ENS 名称
+func (w *Wallet) TransferToken(token, to, amount string, opts *TxOpts) (txHash string, err error) {
+	return w.TransferTokenContext(context.Background(), token, to, amount, opts)
+}
+
+// TransferTokenContext 与 TransferToken 相同，但查询与交易发送都受 ctx 控制
+func (w *Wallet) TransferTokenContext(ctx context.Context, token, to, amount string, opts *TxOpts) (txHash string, err error) {
+	tokenAddress, err := w.ResolveAddressContext(ctx, token)
+	if err != nil {
+		return
+	}
+	recipient, err := w.ResolveAddressContext(ctx, to)
+	if err != nil {
+		return
+	}
+	erc20, err := NewERC20(tokenAddress, w)
+	if err != nil {
+		return
+	}
+	decimals, err := w.tokenDecimals(ctx, erc20)
+	if err != nil {
+		return
+	}
+	value, err := ParseUnits(amount, decimals)
+	if err != nil {
+		return
+	}
+	log.Debug("Transferring token", "token", tokenAddress.Hex(), "to", recipient.Hex(), "amount", amount, "decimals", decimals)
+	return erc20.ExecMethodContext(ctx, "transfer", opts, recipient, value)
+}
+
+// SweepAll 将钱包的全部原生币余额扣除手续费后转给 to，to 可以是十六进制地址或 ENS 名称
+//
+// 为了让实际手续费与预留的手续费完全相同，SweepAll 发送固定 gasPrice 的旧版交易，
+// gasLimit 使用 eth_estimateGas 的结果且不追加 GasBuffer，实际支付的手续费即为 gasLimit * gasPrice，
+// 可以通过 opts.GasPrice 与 opts.GasLimit 指定。转给执行代码的合约地址时实际消耗的 gas 可能小于估算值，会留下少量余额。
+// 余额不足以支付手续费时返回 ErrInsufficientFunds。
+func (w *Wallet) SweepAll(to string, opts *TxOpts) (txHash string, err error) {
+	return w.SweepAllContext(context.Background(), to, opts)
+}
+
+// SweepAllContext 与 SweepAll 相同，但查询与交易发送都受 ctx 控制
+func (w *Wallet) SweepAllContext(ctx context.Context, to string, opts *TxOpts) (txHash string, err error) {
+	recipient, err := w.ResolveAddressContext(ctx, to)
+	if err != nil {
+		return
+	}
+	balance, err := w.GetBalanceContext(ctx)
+	if err != nil {
+		return
+	}
+
+	// 手续费不依赖转账金额，先用 0 估算 gasLimit 与 gasPrice，再用余额减去手续费得到转账金额
+	sweepOpts := &TxOpts{}
+	if opts != nil {
+		*sweepOpts = *opts
+	}
+	sweepOpts.GasBuffer = &GasBuffer{}
+	managed := w.NonceManager != nil && sweepOpts.Nonce == nil
+	sweepOpts, err = w.InitTxOptsContext(ctx, recipient, big.NewInt(0), nil, sweepOpts)
+	if err != nil {
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(*sweepOpts.Nonce, &err)
+	}
+
+	fee := new(big.Int).Mul(big.NewInt(int64(*sweepOpts.GasLimit)), sweepOpts.GasPrice)
+	amount := new(big.Int).Sub(&balance, fee)
+	if amount.Sign() <= 0 {
+		err = fmt.Errorf("%w: balance %s is not enough to pay fee %s", ErrInsufficientFunds, balance.String(), fee.String())
+		log.Error("Cannot sweep account", "balance", balance.String(), "fee", fee.String())
+		return
+	}
+
+	log.Debug("Sweeping account", "to", recipient.Hex(), "balance", balance.String(), "fee", fee.String(), "amount", amount.String())
+	return w.SendLegacyTxContext(ctx, recipient, amount, nil, sweepOpts)
+}
@@ -0,0 +1,68 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransfer(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	mockContractCalls(m, ERC20ABI, map[string][]interface{}{
+		"decimals": {uint8(6)},
+	})
+	w := newTestWallet(t, m)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	_, err := w.Transfer(to.Hex(), "1.5", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, to, *sent[0].To())
+	assert.Equal(t, "1500000000000000000", sent[0].Value().String())
+
+	_, err = w.TransferToken(token.Hex(), to.Hex(), "100.25", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, token, *sent[1].To())
+	erc20, _ := NewERC20(token, w)
+	_, values, err := erc20.DecodeData(sent[1].Data())
+	assert.NoError(t, err)
+	assert.Equal(t, to, values["to"])
+	assert.Equal(t, big.NewInt(100_250_000), values["value"])
+
+	_, err = w.TransferToken(token.Hex(), to.Hex(), "0.0000001", nil)
+	assert.Error(t, err)
+	_, err = w.Transfer(to.Hex(), "1,5", nil)
+	assert.Error(t, err)
+	assert.Len(t, sent, 2)
+}
+
+func TestSweepAll(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	m.Result("eth_getBalance", "0xde0b6b3a7640000")
+	w := newTestWallet(t, m)
+	// 清空余额时不追加 GasBuffer，否则会留下 gasLimit 与实际消耗之差的余额
+	w.GasBuffer = GasBuffer{Multiplier: 1.2}
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+
+	_, err := w.SweepAll(to.Hex(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	tx := sent[0]
+	assert.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	assert.Equal(t, uint64(21000), tx.Gas())
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+	assert.Equal(t, big.NewInt(1e18), new(big.Int).Add(tx.Value(), fee))
+	assert.Equal(t, "999979000000000000", tx.Value().String())
+
+	m.Result("eth_getBalance", "0x1")
+	_, err = w.SweepAll(to.Hex(), nil)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.Len(t, sent, 1)
+}
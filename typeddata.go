@@ -0,0 +1,287 @@
+package goether
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	addressType = reflect.TypeOf(common.Address{})
+	hashType    = reflect.TypeOf(common.Hash{})
+	bigIntType  = reflect.TypeOf((*big.Int)(nil))
+)
+
+// NewTypedData 根据 Go 结构体生成 EIP-712 类型化数据，PrimaryType 为结构体类型名
+//
+// 字段通过 `eip712:"name,type"` 标签指定名称与 EIP-712 类型，省略名称时使用首字母小写的字段名，
+// 省略类型时按 Go 类型推断：common.Address 为 address，*big.Int 为 uint256，common.Hash 为 bytes32，
+// [N]byte 为 bytesN，[]byte 为 bytes，整数为对应位数的 intN/uintN，嵌套结构体使用其类型名，
+// 切片与数组为对应的 T[] 与 T[N]。标签为 "-" 或未导出的字段会被忽略。
+//
+//	type Mail struct {
+//		From     common.Address `eip712:"from"`
+//		Contents string         `eip712:"contents"`
+//		Amount   *big.Int       `eip712:"amount,uint128"`
+//	}
+//	typedData, err := goether.NewTypedData(apitypes.TypedDataDomain{Name: "Mail", Version: "1"}, Mail{...})
+func NewTypedData(domain apitypes.TypedDataDomain, message any) (apitypes.TypedData, error) {
+	v := reflect.ValueOf(message)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return apitypes.TypedData{}, fmt.Errorf("eip712: message is nil")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return apitypes.TypedData{}, fmt.Errorf("eip712: message must be a struct, got %s", v.Type())
+	}
+
+	types := apitypes.Types{"EIP712Domain": domainTypes(domain)}
+	if err := addStructType(types, v.Type()); err != nil {
+		return apitypes.TypedData{}, err
+	}
+	values, err := structValue(v)
+	if err != nil {
+		return apitypes.TypedData{}, err
+	}
+	log.Debug("Typed data built from struct", "primaryType", v.Type().Name(), "types", len(types))
+	return apitypes.TypedData{
+		Types:       types,
+		PrimaryType: v.Type().Name(),
+		Domain:      domain,
+		Message:     values.(map[string]interface{}),
+	}, nil
+}
+
+// ParseTypedDataJSON 解析 eth_signTypedData_v4 格式的 JSON，并检查类型定义与消息是否一致
+//
+// types 中没有 EIP712Domain 时按 domain 中已设置的字段补全，解析成功的数据可以直接用于
+// SignTypedData、RecoverTypedData 与 VerifyTypedData。
+func ParseTypedDataJSON(data []byte) (apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(data, &typedData); err != nil {
+		return apitypes.TypedData{}, fmt.Errorf("eip712: invalid json: %w", err)
+	}
+	if typedData.PrimaryType == "" {
+		return apitypes.TypedData{}, fmt.Errorf("eip712: primaryType is empty")
+	}
+	if typedData.Types == nil {
+		typedData.Types = apitypes.Types{}
+	}
+	if _, ok := typedData.Types["EIP712Domain"]; !ok {
+		typedData.Types["EIP712Domain"] = domainTypes(typedData.Domain)
+	}
+	fields, ok := typedData.Types[typedData.PrimaryType]
+	if !ok {
+		return apitypes.TypedData{}, fmt.Errorf("eip712: primaryType %q is not defined in types", typedData.PrimaryType)
+	}
+
+	declared := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		declared[f.Name] = true
+		if _, ok := typedData.Message[f.Name]; !ok {
+			return apitypes.TypedData{}, fmt.Errorf("eip712: message is missing field %q of %s", f.Name, typedData.PrimaryType)
+		}
+	}
+	for name := range typedData.Message {
+		if !declared[name] {
+			return apitypes.TypedData{}, fmt.Errorf("eip712: message field %q is not defined in %s", name, typedData.PrimaryType)
+		}
+	}
+
+	// 计算一次哈希以校验类型定义、domain 与各字段的值
+	if _, err := EIP712Hash(typedData); err != nil {
+		return apitypes.TypedData{}, fmt.Errorf("eip712: %w", err)
+	}
+	return typedData, nil
+}
+
+// domainTypes 按 EIP-712 规定的顺序返回 domain 中已设置的字段
+func domainTypes(domain apitypes.TypedDataDomain) []apitypes.Type {
+	var fields []apitypes.Type
+	if domain.Name != "" {
+		fields = append(fields, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		fields = append(fields, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// eip712Field 结构体字段与 EIP-712 成员的对应关系
+type eip712Field struct {
+	index int
+	name  string
+	typ   string
+}
+
+// structFields 解析结构体字段的 eip712 标签
+func structFields(t reflect.Type) ([]eip712Field, error) {
+	var fields []eip712Field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("eip712")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, typ, _ := strings.Cut(tag, ",")
+		if name == "" {
+			r := []rune(f.Name)
+			r[0] = unicode.ToLower(r[0])
+			name = string(r)
+		}
+		if typ == "" {
+			var err error
+			if typ, err = eip712Type(f.Type); err != nil {
+				return nil, fmt.Errorf("eip712: field %s.%s: %w", t.Name(), f.Name, err)
+			}
+		}
+		fields = append(fields, eip712Field{index: i, name: name, typ: typ})
+	}
+	return fields, nil
+}
+
+// eip712Type 推断 Go 类型对应的 EIP-712 类型
+func eip712Type(t reflect.Type) (string, error) {
+	switch t {
+	case addressType:
+		return "address", nil
+	case hashType:
+		return "bytes32", nil
+	case bigIntType:
+		return "uint256", nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return eip712Type(t.Elem())
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("int%d", t.Bits()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("uint%d", t.Bits()), nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "", fmt.Errorf("anonymous struct is not supported")
+		}
+		return t.Name(), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		elem, err := eip712Type(t.Elem())
+		return elem + "[]", err
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Len() <= 32 {
+			return fmt.Sprintf("bytes%d", t.Len()), nil
+		}
+		elem, err := eip712Type(t.Elem())
+		return fmt.Sprintf("%s[%d]", elem, t.Len()), err
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+// addStructType 将结构体及其引用的结构体加入 types
+func addStructType(types apitypes.Types, t reflect.Type) error {
+	if _, ok := types[t.Name()]; ok {
+		return nil
+	}
+	fields, err := structFields(t)
+	if err != nil {
+		return err
+	}
+	members := make([]apitypes.Type, len(fields))
+	for i, f := range fields {
+		members[i] = apitypes.Type{Name: f.name, Type: f.typ}
+	}
+	types[t.Name()] = members
+
+	for _, f := range fields {
+		ft := t.Field(f.index).Type
+		for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != addressType && ft != hashType {
+			if err = addStructType(types, ft); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// structValue 将值转换为 apitypes 可以编码、且可以序列化为 eth_signTypedData_v4 JSON 的形式：
+// 地址与字节为十六进制字符串，整数为十进制字符串，结构体为 map，切片为 []interface{}
+func structValue(v reflect.Value) (interface{}, error) {
+	switch v.Type() {
+	case addressType:
+		return v.Interface().(common.Address).Hex(), nil
+	case bigIntType:
+		if v.IsNil() {
+			return nil, fmt.Errorf("eip712: nil %s", v.Type())
+		}
+		return v.Interface().(*big.Int).String(), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, fmt.Errorf("eip712: nil %s", v.Type())
+		}
+		return structValue(v.Elem())
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()).String(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint()).String(), nil
+	case reflect.Struct:
+		fields, err := structFields(v.Type())
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			if values[f.name], err = structValue(v.Field(f.index)); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && (v.Kind() == reflect.Slice || v.Len() <= 32) {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b), nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := structValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("eip712: unsupported type %s", v.Type())
+}
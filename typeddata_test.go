@@ -0,0 +1,116 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+)
+
+type Order struct {
+	Action       string         `eip712:"action"`
+	OrderHashes  []string       `eip712:"orderHashes"`
+	MakerAddress common.Address `eip712:"makerAddress"`
+	internal     int
+}
+
+type Person struct {
+	Name   string
+	Wallet common.Address
+}
+
+type Mail struct {
+	From     Person
+	To       []Person
+	Amount   *big.Int `eip712:"amount,uint128"`
+	Nonce    uint64
+	Salt     common.Hash
+	Data     []byte
+	Internal string `eip712:"-"`
+}
+
+func TestNewTypedData(t *testing.T) {
+	domain := apitypes.TypedDataDomain{Name: "ZooDex", Version: "1", ChainId: math.NewHexOrDecimal256(42)}
+	typedData, err := NewTypedData(domain, Order{
+		Action:       "cancelOrder",
+		OrderHashes:  []string{"0x123", "0x456", "0x789"},
+		MakerAddress: common.HexToAddress("0xf9593A9d7F735814B87D08e8D8aD624f58d53B10"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Order", typedData.PrimaryType)
+	assert.Len(t, typedData.Types["EIP712Domain"], 3)
+
+	// 与 TestEIP712 中手写 JSON 的哈希相同
+	hash, err := EIP712Hash(typedData)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xcf3985dd9eb11ce656eafc2dddd08ce3058ad00c74669b3d171f31e9a0472d8e", hexutil.Encode(hash))
+
+	mail := &Mail{
+		From:   Person{Name: "Cow", Wallet: common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")},
+		To:     []Person{{Name: "Bob", Wallet: common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")}},
+		Amount: big.NewInt(100),
+		Nonce:  1,
+		Data:   []byte{0x01, 0x02},
+	}
+	typedData, err = NewTypedData(apitypes.TypedDataDomain{Name: "Mail", VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}, mail)
+	assert.NoError(t, err)
+	assert.Equal(t, []apitypes.Type{
+		{Name: "from", Type: "Person"},
+		{Name: "to", Type: "Person[]"},
+		{Name: "amount", Type: "uint128"},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "salt", Type: "bytes32"},
+		{Name: "data", Type: "bytes"},
+	}, typedData.Types["Mail"])
+	assert.Equal(t, []apitypes.Type{{Name: "name", Type: "string"}, {Name: "wallet", Type: "address"}}, typedData.Types["Person"])
+	assert.Equal(t, "100", typedData.Message["amount"])
+	assert.Equal(t, "0x0102", typedData.Message["data"])
+
+	sig, err := TestSigner.SignTypedData(typedData)
+	assert.NoError(t, err)
+	ok, err := VerifyTypedData(typedData, sig, TestSigner.Address)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = NewTypedData(domain, "order")
+	assert.Error(t, err)
+	_, err = NewTypedData(domain, Mail{From: mail.From})
+	assert.Error(t, err)
+}
+
+func TestParseTypedDataJSON(t *testing.T) {
+	raw := `{"types": {"EIP712Domain": [{"name": "name","type": "string"},{"name": "version","type": "string"},{"name": "chainId","type": "uint256"}],"Order": [{"name": "action","type": "string"},{"name": "orderHashes","type": "string[]"},{"name": "makerAddress","type": "address"}]},"primaryType": "Order","domain": {"name": "ZooDex","version": "1","chainId": "42"},"message": {"action": "cancelOrder","orderHashes": ["0x123", "0x456", "0x789"],"makerAddress": "0xf9593A9d7F735814B87D08e8D8aD624f58d53B10"}}`
+	typedData, err := ParseTypedDataJSON([]byte(raw))
+	assert.NoError(t, err)
+	hash, err := EIP712Hash(typedData)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xcf3985dd9eb11ce656eafc2dddd08ce3058ad00c74669b3d171f31e9a0472d8e", hexutil.Encode(hash))
+
+	// 缺少 EIP712Domain 时按 domain 补全
+	typedData, err = ParseTypedDataJSON([]byte(`{"types": {"Order": [{"name": "action","type": "string"},{"name": "orderHashes","type": "string[]"},{"name": "makerAddress","type": "address"}]},"primaryType": "Order","domain": {"name": "ZooDex","version": "1","chainId": 42},"message": {"action": "cancelOrder","orderHashes": ["0x123", "0x456", "0x789"],"makerAddress": "0xf9593A9d7F735814B87D08e8D8aD624f58d53B10"}}`))
+	assert.NoError(t, err)
+	hash, err = EIP712Hash(typedData)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xcf3985dd9eb11ce656eafc2dddd08ce3058ad00c74669b3d171f31e9a0472d8e", hexutil.Encode(hash))
+
+	for name, tc := range map[string]struct {
+		raw string
+		err string
+	}{
+		"json":        {`{`, "invalid json"},
+		"primaryType": {`{"types": {"Order": []}, "domain": {"name": "ZooDex"}, "message": {}}`, "primaryType is empty"},
+		"undefined":   {`{"types": {}, "primaryType": "Order", "domain": {"name": "ZooDex"}, "message": {}}`, `"Order" is not defined`},
+		"missing":     {`{"types": {"Order": [{"name": "action","type": "string"}]}, "primaryType": "Order", "domain": {"name": "ZooDex"}, "message": {}}`, `missing field "action"`},
+		"extra":       {`{"types": {"Order": [{"name": "action","type": "string"}]}, "primaryType": "Order", "domain": {"name": "ZooDex"}, "message": {"action": "a", "amount": "1"}}`, `"amount" is not defined`},
+		"value":       {`{"types": {"Order": [{"name": "maker","type": "address"}]}, "primaryType": "Order", "domain": {"name": "ZooDex"}, "message": {"maker": "bob"}}`, "doesn't match type"},
+	} {
+		_, err := ParseTypedDataJSON([]byte(tc.raw))
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), tc.err, name)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package userop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/ethrpc"
+	"github.com/go-enols/goether"
+)
+
+// ErrReceiptNotFound bundler 尚未返回用户操作的回执，操作可能还在等待打包或已被丢弃
+var ErrReceiptNotFound = errors.New("user operation receipt not found")
+
+// Bundler ERC-4337 bundler 的 JSON-RPC 客户端
+type Bundler struct {
+	Client     *ethrpc.EthRPC
+	EntryPoint common.Address
+}
+
+// NewBundler 创建 bundler 客户端，options 可以使用 goether.WithRetry、goether.WithMiddleware 等
+func NewBundler(url string, entryPoint common.Address, options ...func(rpc *ethrpc.EthRPC)) *Bundler {
+	return &Bundler{Client: ethrpc.New(url, options...), EntryPoint: entryPoint}
+}
+
+// GasEstimate eth_estimateUserOperationGas 的结果
+type GasEstimate struct {
+	PreVerificationGas   *big.Int
+	VerificationGasLimit *big.Int
+	CallGasLimit         *big.Int
+}
+
+// Apply 将估算结果写入 op 的 gas 字段
+func (e *GasEstimate) Apply(op *UserOperation) {
+	op.PreVerificationGas = e.PreVerificationGas
+	op.VerificationGasLimit = e.VerificationGasLimit
+	op.CallGasLimit = e.CallGasLimit
+}
+
+// Receipt eth_getUserOperationReceipt 的结果
+type Receipt struct {
+	UserOpHash    common.Hash
+	EntryPoint    common.Address
+	Sender        common.Address
+	Nonce         *big.Int
+	Paymaster     common.Address
+	ActualGasCost *big.Int
+	ActualGasUsed *big.Int
+	Success       bool
+	// Reason 执行失败时的回滚数据
+	Reason []byte
+	// Logs 该用户操作产生的日志
+	Logs []*types.Log
+	// Receipt 包含该用户操作的 handleOps 交易的回执
+	Receipt *types.Receipt
+}
+
+func (r *Receipt) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		UserOpHash    common.Hash    `json:"userOpHash"`
+		EntryPoint    common.Address `json:"entryPoint"`
+		Sender        common.Address `json:"sender"`
+		Nonce         *hexutil.Big   `json:"nonce"`
+		Paymaster     common.Address `json:"paymaster"`
+		ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+		ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+		Success       bool           `json:"success"`
+		Reason        hexutil.Bytes  `json:"reason"`
+		Logs          []*types.Log   `json:"logs"`
+		Receipt       *types.Receipt `json:"receipt"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*r = Receipt{
+		UserOpHash:    dec.UserOpHash,
+		EntryPoint:    dec.EntryPoint,
+		Sender:        dec.Sender,
+		Nonce:         (*big.Int)(dec.Nonce),
+		Paymaster:     dec.Paymaster,
+		ActualGasCost: (*big.Int)(dec.ActualGasCost),
+		ActualGasUsed: (*big.Int)(dec.ActualGasUsed),
+		Success:       dec.Success,
+		Reason:        dec.Reason,
+		Logs:          dec.Logs,
+		Receipt:       dec.Receipt,
+	}
+	return nil
+}
+
+// Err 执行失败时返回解码后的回滚原因，成功时返回 nil
+func (r *Receipt) Err() error {
+	if r.Success {
+		return nil
+	}
+	return goether.DecodeRevert(r.Reason)
+}
+
+// SendUserOperation 将已签名的用户操作提交给 bundler，返回 userOpHash
+func (b *Bundler) SendUserOperation(op *UserOperation) (common.Hash, error) {
+	return b.SendUserOperationContext(context.Background(), op)
+}
+
+// SendUserOperationContext 与 SendUserOperation 相同，但请求受 ctx 控制
+func (b *Bundler) SendUserOperationContext(ctx context.Context, op *UserOperation) (common.Hash, error) {
+	var hash common.Hash
+	err := call(ctx, b.Client, &hash, "eth_sendUserOperation", op, b.EntryPoint)
+	return hash, err
+}
+
+// EstimateUserOperationGas 估算用户操作的 gas 字段，op 的签名可以是占位签名
+func (b *Bundler) EstimateUserOperationGas(op *UserOperation) (*GasEstimate, error) {
+	return b.EstimateUserOperationGasContext(context.Background(), op)
+}
+
+// EstimateUserOperationGasContext 与 EstimateUserOperationGas 相同，但请求受 ctx 控制
+func (b *Bundler) EstimateUserOperationGasContext(ctx context.Context, op *UserOperation) (*GasEstimate, error) {
+	var dec struct {
+		PreVerificationGas   *hexutil.Big `json:"preVerificationGas"`
+		VerificationGasLimit *hexutil.Big `json:"verificationGasLimit"`
+		CallGasLimit         *hexutil.Big `json:"callGasLimit"`
+	}
+	if err := call(ctx, b.Client, &dec, "eth_estimateUserOperationGas", op, b.EntryPoint); err != nil {
+		return nil, err
+	}
+	return &GasEstimate{
+		PreVerificationGas:   (*big.Int)(dec.PreVerificationGas),
+		VerificationGasLimit: (*big.Int)(dec.VerificationGasLimit),
+		CallGasLimit:         (*big.Int)(dec.CallGasLimit),
+	}, nil
+}
+
+// GetUserOperationReceipt 查询用户操作的回执，尚未打包时返回 ErrReceiptNotFound
+func (b *Bundler) GetUserOperationReceipt(hash common.Hash) (*Receipt, error) {
+	return b.GetUserOperationReceiptContext(context.Background(), hash)
+}
+
+// GetUserOperationReceiptContext 与 GetUserOperationReceipt 相同，但请求受 ctx 控制
+func (b *Bundler) GetUserOperationReceiptContext(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	var receipt *Receipt
+	if err := call(ctx, b.Client, &receipt, "eth_getUserOperationReceipt", hash); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, fmt.Errorf("%w: %s", ErrReceiptNotFound, hash.Hex())
+	}
+	return receipt, nil
+}
+
+// WaitForReceipt 每隔 goether.ReceiptPollInterval 查询一次回执，直到用户操作被打包或 ctx 结束
+func (b *Bundler) WaitForReceipt(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	ticker := time.NewTicker(goether.ReceiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := b.GetUserOperationReceiptContext(ctx, hash)
+		if !errors.Is(err, ErrReceiptNotFound) {
+			return receipt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for user operation %s: %w", hash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// SupportedEntryPoints 返回 bundler 支持的 EntryPoint 地址
+func (b *Bundler) SupportedEntryPoints() ([]common.Address, error) {
+	return b.SupportedEntryPointsContext(context.Background())
+}
+
+// SupportedEntryPointsContext 与 SupportedEntryPoints 相同，但请求受 ctx 控制
+func (b *Bundler) SupportedEntryPointsContext(ctx context.Context) ([]common.Address, error) {
+	var entryPoints []common.Address
+	err := call(ctx, b.Client, &entryPoints, "eth_supportedEntryPoints")
+	return entryPoints, err
+}
+
+// call 在 ctx 的约束下发送请求，bundler 或 paymaster 返回的错误转换为 *goether.RPCError
+func call(ctx context.Context, client *ethrpc.EthRPC, out any, method string, params ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if params == nil {
+		params = []any{}
+	}
+	type result struct {
+		raw json.RawMessage
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		raw, err := client.Call(method, params...)
+		ch <- result{raw, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-ch:
+		var ethErr ethrpc.EthError
+		if errors.As(r.err, &ethErr) {
+			return &goether.RPCError{Code: ethErr.Code, Message: ethErr.Message}
+		}
+		if r.err != nil {
+			return r.err
+		}
+		return json.Unmarshal(r.raw, out)
+	}
+}
@@ -0,0 +1,122 @@
+package userop
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMockBundler 启动按方法名返回结果的 JSON-RPC 服务，handler 返回 *goether.RPCError 时作为错误响应
+func newMockBundler(t *testing.T, handlers map[string]func(params []json.RawMessage) (any, error)) *Bundler {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		h, ok := handlers[req.Method]
+		if !ok {
+			resp["error"] = &goether.RPCError{Code: -32601, Message: "method not found"}
+		} else if result, err := h(req.Params); err != nil {
+			resp["error"] = err
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(rw).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return NewBundler(server.URL, EntryPointV06)
+}
+
+func TestBundler(t *testing.T) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	opHash := common.HexToHash("0x01")
+	polls := 0
+	b := newMockBundler(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_supportedEntryPoints": func(params []json.RawMessage) (any, error) {
+			assert.Len(t, params, 0)
+			return []common.Address{EntryPointV06}, nil
+		},
+		"eth_estimateUserOperationGas": func(params []json.RawMessage) (any, error) {
+			var op UserOperation
+			assert.NoError(t, json.Unmarshal(params[0], &op))
+			assert.Equal(t, sender, op.Sender)
+			var entryPoint common.Address
+			assert.NoError(t, json.Unmarshal(params[1], &entryPoint))
+			assert.Equal(t, EntryPointV06, entryPoint)
+			return map[string]string{"preVerificationGas": "0xc350", "verificationGasLimit": "0x30d40", "callGasLimit": "0x186a0"}, nil
+		},
+		"eth_sendUserOperation": func(params []json.RawMessage) (any, error) {
+			var op UserOperation
+			assert.NoError(t, json.Unmarshal(params[0], &op))
+			if len(op.Signature) == 0 {
+				return nil, &goether.RPCError{Code: -32507, Message: "AA23 reverted: invalid signature"}
+			}
+			return opHash, nil
+		},
+		"eth_getUserOperationReceipt": func(params []json.RawMessage) (any, error) {
+			assert.Equal(t, `"`+opHash.Hex()+`"`, string(params[0]))
+			if polls++; polls < 2 {
+				return nil, nil
+			}
+			return map[string]any{
+				"userOpHash": opHash, "entryPoint": EntryPointV06, "sender": sender, "nonce": "0x3",
+				"paymaster": common.Address{}, "actualGasCost": "0x5208", "actualGasUsed": "0x2710",
+				"success": true, "reason": "0x", "logs": []*types.Log{},
+				"receipt": &types.Receipt{Status: 1, TxHash: common.HexToHash("0x02"), Logs: []*types.Log{}},
+			}, nil
+		},
+	})
+
+	entryPoints, err := b.SupportedEntryPoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{EntryPointV06}, entryPoints)
+
+	op := New(sender, big.NewInt(3), []byte{0x01})
+	estimate, err := b.EstimateUserOperationGas(op)
+	assert.NoError(t, err)
+	estimate.Apply(op)
+	assert.Equal(t, big.NewInt(50000), op.PreVerificationGas)
+	assert.Equal(t, big.NewInt(200000), op.VerificationGasLimit)
+	assert.Equal(t, big.NewInt(100000), op.CallGasLimit)
+
+	// bundler 拒绝时返回 *goether.RPCError
+	_, err = b.SendUserOperation(op)
+	var rpcErr *goether.RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32507, rpcErr.Code)
+
+	op.Signature = []byte{0x01}
+	hash, err := b.SendUserOperation(op)
+	assert.NoError(t, err)
+	assert.Equal(t, opHash, hash)
+
+	_, err = b.GetUserOperationReceipt(hash)
+	assert.ErrorIs(t, err, ErrReceiptNotFound)
+
+	interval := goether.ReceiptPollInterval
+	goether.ReceiptPollInterval = 10 * time.Millisecond
+	defer func() { goether.ReceiptPollInterval = interval }()
+	receipt, err := b.WaitForReceipt(context.Background(), hash)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Success)
+	assert.Nil(t, receipt.Err())
+	assert.Equal(t, big.NewInt(0x5208), receipt.ActualGasCost)
+	assert.Equal(t, common.HexToHash("0x02"), receipt.Receipt.TxHash)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = b.SupportedEntryPointsContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,216 @@
+package userop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-enols/goether"
+	"github.com/go-enols/goether/internal/abiutil"
+)
+
+// userOpComponents ABI 中 UserOperation 结构的字段
+const userOpComponents = `[
+{"name":"sender","type":"address"},{"name":"nonce","type":"uint256"},{"name":"initCode","type":"bytes"},{"name":"callData","type":"bytes"},
+{"name":"callGasLimit","type":"uint256"},{"name":"verificationGasLimit","type":"uint256"},{"name":"preVerificationGas","type":"uint256"},
+{"name":"maxFeePerGas","type":"uint256"},{"name":"maxPriorityFeePerGas","type":"uint256"},{"name":"paymasterAndData","type":"bytes"},{"name":"signature","type":"bytes"}
+]`
+
+// EntryPointABI EntryPoint v0.6 中常用的方法、事件与错误
+const EntryPointABI = `[
+{"inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"account","type":"address"}],"name":"getDepositInfo","outputs":[{"components":[{"name":"deposit","type":"uint112"},{"name":"staked","type":"bool"},{"name":"stake","type":"uint112"},{"name":"unstakeDelaySec","type":"uint32"},{"name":"withdrawTime","type":"uint48"}],"name":"info","type":"tuple"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"components":` + userOpComponents + `,"name":"userOp","type":"tuple"}],"name":"getUserOpHash","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+{"inputs":[{"name":"initCode","type":"bytes"}],"name":"getSenderAddress","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"components":` + userOpComponents + `,"name":"ops","type":"tuple[]"},{"name":"beneficiary","type":"address"}],"name":"handleOps","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"account","type":"address"}],"name":"depositTo","outputs":[],"stateMutability":"payable","type":"function"},
+{"inputs":[{"name":"unstakeDelaySec","type":"uint32"}],"name":"addStake","outputs":[],"stateMutability":"payable","type":"function"},
+{"inputs":[],"name":"unlockStake","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"withdrawAddress","type":"address"}],"name":"withdrawStake","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"withdrawAddress","type":"address"},{"name":"withdrawAmount","type":"uint256"}],"name":"withdrawTo","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"userOpHash","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":true,"name":"paymaster","type":"address"},{"indexed":false,"name":"nonce","type":"uint256"},{"indexed":false,"name":"success","type":"bool"},{"indexed":false,"name":"actualGasCost","type":"uint256"},{"indexed":false,"name":"actualGasUsed","type":"uint256"}],"name":"UserOperationEvent","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"userOpHash","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"nonce","type":"uint256"},{"indexed":false,"name":"revertReason","type":"bytes"}],"name":"UserOperationRevertReason","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"userOpHash","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"factory","type":"address"},{"indexed":false,"name":"paymaster","type":"address"}],"name":"AccountDeployed","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"account","type":"address"},{"indexed":false,"name":"totalDeposit","type":"uint256"}],"name":"Deposited","type":"event"},
+{"inputs":[{"name":"opIndex","type":"uint256"},{"name":"reason","type":"string"}],"name":"FailedOp","type":"error"},
+{"inputs":[{"name":"sender","type":"address"}],"name":"SenderAddressResult","type":"error"}
+]`
+
+var entryPoint = abiutil.MustABI(EntryPointABI)
+
+// EntryPoint EntryPoint v0.6 合约绑定
+type EntryPoint struct {
+	*goether.Contract
+}
+
+// DepositInfo getDepositInfo 返回的押金与质押信息
+type DepositInfo struct {
+	Deposit         *big.Int
+	Staked          bool
+	Stake           *big.Int
+	UnstakeDelaySec uint32
+	WithdrawTime    *big.Int
+}
+
+// NewEntryPoint 创建 EntryPoint 合约实例，address 通常为 EntryPointV06
+func NewEntryPoint(address common.Address, wallet *goether.Wallet) (*EntryPoint, error) {
+	c, err := goether.NewContractFromABIs(address, wallet, EntryPointABI)
+	if err != nil {
+		return nil, err
+	}
+	return &EntryPoint{Contract: c}, nil
+}
+
+// GetNonce 查询 sender 在 key 通道上的下一个 nonce，key 为 nil 时使用默认通道 0
+func (e *EntryPoint) GetNonce(sender common.Address, key *big.Int) (*big.Int, error) {
+	return e.GetNonceContext(context.Background(), sender, key)
+}
+
+// GetNonceContext 与 GetNonce 相同，但请求受 ctx 控制
+func (e *EntryPoint) GetNonceContext(ctx context.Context, sender common.Address, key *big.Int) (*big.Int, error) {
+	var nonce *big.Int
+	err := e.CallMethodIntoContext(ctx, "getNonce", "latest", &nonce, sender, abiutil.BigOrZero(key))
+	return nonce, err
+}
+
+// BalanceOf 查询 account 在 EntryPoint 中的押金，用于支付账户或 paymaster 的 gas
+func (e *EntryPoint) BalanceOf(account common.Address) (*big.Int, error) {
+	return e.BalanceOfContext(context.Background(), account)
+}
+
+// BalanceOfContext 与 BalanceOf 相同，但请求受 ctx 控制
+func (e *EntryPoint) BalanceOfContext(ctx context.Context, account common.Address) (*big.Int, error) {
+	var balance *big.Int
+	err := e.CallMethodIntoContext(ctx, "balanceOf", "latest", &balance, account)
+	return balance, err
+}
+
+// GetDepositInfo 查询 account 的押金与质押状态
+func (e *EntryPoint) GetDepositInfo(account common.Address) (*DepositInfo, error) {
+	return e.GetDepositInfoContext(context.Background(), account)
+}
+
+// GetDepositInfoContext 与 GetDepositInfo 相同，但请求受 ctx 控制
+func (e *EntryPoint) GetDepositInfoContext(ctx context.Context, account common.Address) (*DepositInfo, error) {
+	// 唯一的返回值是结构体时，abi 会将其写入 out 的第一个字段
+	var out struct{ Info DepositInfo }
+	if err := e.CallMethodIntoContext(ctx, "getDepositInfo", "latest", &out, account); err != nil {
+		return nil, err
+	}
+	return &out.Info, nil
+}
+
+// GetUserOpHash 由链上 EntryPoint 计算 userOpHash，可用于核对 UserOperation.Hash
+func (e *EntryPoint) GetUserOpHash(op *UserOperation) (common.Hash, error) {
+	return e.GetUserOpHashContext(context.Background(), op)
+}
+
+// GetUserOpHashContext 与 GetUserOpHash 相同，但请求受 ctx 控制
+func (e *EntryPoint) GetUserOpHashContext(ctx context.Context, op *UserOperation) (common.Hash, error) {
+	var hash [32]byte
+	err := e.CallMethodIntoContext(ctx, "getUserOpHash", "latest", &hash, op.tuple())
+	return hash, err
+}
+
+// SenderAddress 通过 getSenderAddress 计算 initCode 将部署的账户地址，账户部署前即可得到地址
+func (e *EntryPoint) SenderAddress(initCode []byte) (common.Address, error) {
+	return e.SenderAddressContext(context.Background(), initCode)
+}
+
+// SenderAddressContext 与 SenderAddress 相同，但请求受 ctx 控制
+func (e *EntryPoint) SenderAddressContext(ctx context.Context, initCode []byte) (common.Address, error) {
+	_, err := e.CallMethodContext(ctx, "getSenderAddress", "latest", initCode)
+	var contractErr *goether.ContractError
+	if !errors.As(err, &contractErr) || contractErr.Name != "SenderAddressResult" {
+		if err == nil {
+			err = errors.New("getSenderAddress did not revert with SenderAddressResult")
+		}
+		return common.Address{}, err
+	}
+	sender, _ := contractErr.Params["sender"].(common.Address)
+	return sender, nil
+}
+
+// DepositTo 为 account 存入 amount 押金
+func (e *EntryPoint) DepositTo(account common.Address, amount *big.Int, opts *goether.TxOpts) (txHash string, err error) {
+	return e.ExecPayable("depositTo", amount, opts, account)
+}
+
+// HandleOps 解码后的 handleOps 调用
+type HandleOps struct {
+	Ops         []*UserOperation
+	Beneficiary common.Address
+}
+
+// DecodeHandleOps 解码 handleOps 交易的 input，用于分析 bundler 打包的用户操作
+func (e *EntryPoint) DecodeHandleOps(data []byte) (*HandleOps, error) {
+	return DecodeHandleOps(data)
+}
+
+// DecodeHandleOps 解码 handleOps 交易的 input，不需要 EntryPoint 实例
+func DecodeHandleOps(data []byte) (*HandleOps, error) {
+	method := entryPoint.Methods["handleOps"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return nil, errors.New("input is not a handleOps call")
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	var args struct {
+		Ops         []UserOperation
+		Beneficiary common.Address
+	}
+	if err = method.Inputs.Copy(&args, values); err != nil {
+		return nil, fmt.Errorf("failed to decode handleOps: %w", err)
+	}
+	result := &HandleOps{Beneficiary: args.Beneficiary}
+	for i := range args.Ops {
+		result.Ops = append(result.Ops, &args.Ops[i])
+	}
+	return result, nil
+}
+
+// EncodeHandleOps 编码 handleOps(ops, beneficiary) 调用
+func EncodeHandleOps(ops []*UserOperation, beneficiary common.Address) ([]byte, error) {
+	tuples := make([]userOpTupleValue, 0, len(ops))
+	for _, op := range ops {
+		tuples = append(tuples, op.tuple())
+	}
+	return entryPoint.Pack("handleOps", tuples, beneficiary)
+}
+
+// userOpTupleValue 与 ABI 中 UserOperation 结构对应的值，数值字段不能为 nil
+type userOpTupleValue struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+func (op *UserOperation) tuple() userOpTupleValue {
+	return userOpTupleValue{
+		Sender:               op.Sender,
+		Nonce:                abiutil.BigOrZero(op.Nonce),
+		InitCode:             abiutil.BytesOrEmpty(op.InitCode),
+		CallData:             abiutil.BytesOrEmpty(op.CallData),
+		CallGasLimit:         abiutil.BigOrZero(op.CallGasLimit),
+		VerificationGasLimit: abiutil.BigOrZero(op.VerificationGasLimit),
+		PreVerificationGas:   abiutil.BigOrZero(op.PreVerificationGas),
+		MaxFeePerGas:         abiutil.BigOrZero(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: abiutil.BigOrZero(op.MaxPriorityFeePerGas),
+		PaymasterAndData:     abiutil.BytesOrEmpty(op.PaymasterAndData),
+		Signature:            abiutil.BytesOrEmpty(op.Signature),
+	}
+}
@@ -0,0 +1,110 @@
+package userop
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryPoint(t *testing.T) {
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	op := New(sender, big.NewInt(7), []byte{0x01})
+	op.InitCode = []byte{0xfa, 0xc7}
+	op.MaxFeePerGas = big.NewInt(2)
+	op.Signature = []byte{0x51}
+
+	b := newMockBundler(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_call": func(params []json.RawMessage) (any, error) {
+			var msg map[string]string
+			json.Unmarshal(params[0], &msg)
+			assert.Equal(t, EntryPointV06, common.HexToAddress(msg["to"]))
+			data := hexutil.MustDecode(msg["data"])
+			method, err := entryPoint.MethodById(data)
+			assert.NoError(t, err)
+			args, err := method.Inputs.Unpack(data[4:])
+			assert.NoError(t, err)
+
+			var out []byte
+			switch method.Name {
+			case "getNonce":
+				assert.Equal(t, sender, args[0])
+				out, _ = method.Outputs.Pack(new(big.Int).Add(args[1].(*big.Int), big.NewInt(3)))
+			case "balanceOf":
+				out, _ = method.Outputs.Pack(big.NewInt(1e18))
+			case "getDepositInfo":
+				out, _ = method.Outputs.Pack(struct {
+					Deposit         *big.Int
+					Staked          bool
+					Stake           *big.Int
+					UnstakeDelaySec uint32
+					WithdrawTime    *big.Int
+				}{big.NewInt(5), true, big.NewInt(6), 86400, big.NewInt(0)})
+			case "getUserOpHash":
+				hash, _ := op.Hash(EntryPointV06, big.NewInt(1))
+				out, _ = method.Outputs.Pack(hash)
+			case "getSenderAddress":
+				assert.Equal(t, op.InitCode, args[0])
+				senderResult := entryPoint.Errors["SenderAddressResult"]
+				revert, _ := senderResult.Inputs.Pack(sender)
+				revert = append(senderResult.ID.Bytes()[:4], revert...)
+				data, _ := json.Marshal(hexutil.Encode(revert))
+				return nil, &goether.RPCError{Code: 3, Message: "execution reverted", Data: data}
+			}
+			return hexutil.Encode(out), nil
+		},
+	})
+	w, err := goether.NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", b.Client.URL(), big.NewInt(1))
+	assert.NoError(t, err)
+	ep, err := NewEntryPoint(EntryPointV06, w)
+	assert.NoError(t, err)
+
+	nonce, err := ep.GetNonce(sender, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(3), nonce)
+	nonce, err = ep.GetNonce(sender, big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(13), nonce)
+
+	balance, err := ep.BalanceOf(sender)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1e18), balance)
+
+	info, err := ep.GetDepositInfo(sender)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), info.Deposit)
+	assert.True(t, info.Staked)
+	assert.Equal(t, uint32(86400), info.UnstakeDelaySec)
+
+	hash, err := ep.GetUserOpHash(op)
+	assert.NoError(t, err)
+	opHash, err := op.Hash(EntryPointV06, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, opHash, hash)
+
+	address, err := ep.SenderAddress(op.InitCode)
+	assert.NoError(t, err)
+	assert.Equal(t, sender, address)
+
+	beneficiary := common.HexToAddress("0xbe")
+	second := New(common.HexToAddress("0xa3"), nil, nil)
+	data, err := EncodeHandleOps([]*UserOperation{op, second}, beneficiary)
+	assert.NoError(t, err)
+	decoded, err := ep.DecodeHandleOps(data)
+	assert.NoError(t, err)
+	assert.Equal(t, beneficiary, decoded.Beneficiary)
+	assert.Len(t, decoded.Ops, 2)
+	decodedHash, err := decoded.Ops[0].Hash(EntryPointV06, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, opHash, decodedHash)
+	assert.Equal(t, op.Signature, decoded.Ops[0].Signature)
+	assert.Equal(t, common.HexToAddress("0xa3"), decoded.Ops[1].Sender)
+	assert.Zero(t, decoded.Ops[1].Nonce.Sign())
+
+	_, err = DecodeHandleOps([]byte{0x01, 0x02, 0x03, 0x04})
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package userop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/ethrpc"
+	"github.com/go-enols/goether"
+	"github.com/go-enols/goether/internal/abiutil"
+)
+
+var verifyingHashArgs = abiutil.MustArguments(
+	"address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "uint256", "uint256",
+	"uint256", "address", "uint256", "uint48", "uint48",
+)
+
+// VerifyingPaymasterABI VerifyingPaymaster v0.6 中用到的方法
+const VerifyingPaymasterABI = `[
+{"inputs":[{"name":"","type":"address"}],"name":"senderNonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+var validityArgs = abiutil.MustArguments("uint48", "uint48")
+
+// Paymaster 为用户操作填充 PaymasterAndData 的赞助方，需要在账户签名之前调用，
+// 因为 PaymasterAndData 参与 userOpHash 的计算
+type Paymaster interface {
+	Sponsor(ctx context.Context, op *UserOperation, entryPoint common.Address, chainID *big.Int) error
+}
+
+var (
+	_ Paymaster = (*RPCPaymaster)(nil)
+	_ Paymaster = (*VerifyingPaymaster)(nil)
+)
+
+// SponsorAndSign 依次调用 paymaster 填充 PaymasterAndData 并使用 signer 签名
+func SponsorAndSign(ctx context.Context, op *UserOperation, paymaster Paymaster, signer goether.AccountSigner, entryPoint common.Address, chainID *big.Int) error {
+	if err := paymaster.Sponsor(ctx, op, entryPoint, chainID); err != nil {
+		return err
+	}
+	return op.Sign(signer, entryPoint, chainID)
+}
+
+// RPCPaymaster 通过 pm_sponsorUserOperation 接口获取赞助的 paymaster 服务，例如 Pimlico、Alchemy、Stackup
+//
+// 服务返回 gas 字段时会一并写入 op，返回后不要再修改 gas 字段，否则 paymaster 签名失效。
+type RPCPaymaster struct {
+	Client *ethrpc.EthRPC
+	// Method 为空时使用 pm_sponsorUserOperation
+	Method string
+	// Context 作为第三个参数传给服务，例如 {"type": "payg"} 或赞助策略 ID，为 nil 时不传
+	Context any
+}
+
+// NewRPCPaymaster 创建 paymaster 服务客户端
+func NewRPCPaymaster(url string, options ...func(rpc *ethrpc.EthRPC)) *RPCPaymaster {
+	return &RPCPaymaster{Client: ethrpc.New(url, options...)}
+}
+
+// Sponsor 请求服务赞助 op 并写入 PaymasterAndData
+func (p *RPCPaymaster) Sponsor(ctx context.Context, op *UserOperation, entryPoint common.Address, chainID *big.Int) error {
+	method := p.Method
+	if method == "" {
+		method = "pm_sponsorUserOperation"
+	}
+	params := []any{op, entryPoint}
+	if p.Context != nil {
+		params = append(params, p.Context)
+	}
+	var raw json.RawMessage
+	if err := call(ctx, p.Client, &raw, method, params...); err != nil {
+		return err
+	}
+
+	// 部分服务只返回 paymasterAndData 字符串
+	var paymasterAndData hexutil.Bytes
+	if err := json.Unmarshal(raw, &paymasterAndData); err == nil {
+		op.PaymasterAndData = paymasterAndData
+		return nil
+	}
+	var dec struct {
+		PaymasterAndData     hexutil.Bytes `json:"paymasterAndData"`
+		PreVerificationGas   *hexutil.Big  `json:"preVerificationGas"`
+		VerificationGasLimit *hexutil.Big  `json:"verificationGasLimit"`
+		CallGasLimit         *hexutil.Big  `json:"callGasLimit"`
+	}
+	if err := json.Unmarshal(raw, &dec); err != nil {
+		return err
+	}
+	if len(dec.PaymasterAndData) < common.AddressLength {
+		return errors.New("paymaster returned empty paymasterAndData")
+	}
+	op.PaymasterAndData = dec.PaymasterAndData
+	if dec.PreVerificationGas != nil {
+		op.PreVerificationGas = (*big.Int)(dec.PreVerificationGas)
+	}
+	if dec.VerificationGasLimit != nil {
+		op.VerificationGasLimit = (*big.Int)(dec.VerificationGasLimit)
+	}
+	if dec.CallGasLimit != nil {
+		op.CallGasLimit = (*big.Int)(dec.CallGasLimit)
+	}
+	return nil
+}
+
+// VerifyingPaymaster eth-infinitism VerifyingPaymaster 的链下签名方，使用 Signer 为用户操作签发赞助
+//
+// 签名覆盖 gas 字段与合约中 sender 的 senderNonce，估算 gas 前可先调用一次 Sponsor 得到正确长度的
+// PaymasterAndData，写入估算结果后需要再次调用。
+type VerifyingPaymaster struct {
+	Address common.Address
+	Signer  goether.AccountSigner
+	// Wallet 用于查询 paymaster 合约中的 senderNonce
+	Wallet *goether.Wallet
+	// ValidUntil 赞助的过期时间，为零值时不过期
+	ValidUntil time.Time
+	// ValidAfter 赞助的生效时间，为零值时立即生效
+	ValidAfter time.Time
+}
+
+// NewVerifyingPaymaster 创建 VerifyingPaymaster 签名方，wallet 用于查询 senderNonce，
+// validFor 为赞助的有效期，为 0 时不过期
+func NewVerifyingPaymaster(address common.Address, signer goether.AccountSigner, wallet *goether.Wallet, validFor time.Duration) *VerifyingPaymaster {
+	p := &VerifyingPaymaster{Address: address, Signer: signer, Wallet: wallet}
+	if validFor > 0 {
+		p.ValidUntil = time.Now().Add(validFor)
+	}
+	return p
+}
+
+// SenderNonce 查询 paymaster 合约中 sender 的 senderNonce，每次赞助的用户操作执行后加一
+func (p *VerifyingPaymaster) SenderNonce(ctx context.Context, sender common.Address) (*big.Int, error) {
+	if p.Wallet == nil {
+		return nil, errors.New("verifying paymaster has no wallet")
+	}
+	c, err := goether.NewContractFromABIs(p.Address, p.Wallet, VerifyingPaymasterABI)
+	if err != nil {
+		return nil, err
+	}
+	var nonce *big.Int
+	err = c.CallMethodIntoContext(ctx, "senderNonce", "latest", &nonce, sender)
+	return nonce, err
+}
+
+// Hash 计算与 VerifyingPaymaster.getHash 一致的赞助哈希，senderNonce 为合约中 op.Sender 当前的 senderNonce
+func (p *VerifyingPaymaster) Hash(op *UserOperation, chainID *big.Int, senderNonce *big.Int) (common.Hash, error) {
+	if err := abiutil.CheckUint(256, op.Nonce, op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas,
+		op.MaxFeePerGas, op.MaxPriorityFeePerGas, chainID, senderNonce); err != nil {
+		return common.Hash{}, err
+	}
+	if err := abiutil.CheckUint(48, unixOrZero(p.ValidUntil), unixOrZero(p.ValidAfter)); err != nil {
+		return common.Hash{}, err
+	}
+	data, err := verifyingHashArgs.Pack(
+		op.Sender,
+		abiutil.BigOrZero(op.Nonce),
+		crypto.Keccak256Hash(op.InitCode),
+		crypto.Keccak256Hash(op.CallData),
+		abiutil.BigOrZero(op.CallGasLimit),
+		abiutil.BigOrZero(op.VerificationGasLimit),
+		abiutil.BigOrZero(op.PreVerificationGas),
+		abiutil.BigOrZero(op.MaxFeePerGas),
+		abiutil.BigOrZero(op.MaxPriorityFeePerGas),
+		abiutil.BigOrZero(chainID),
+		p.Address,
+		abiutil.BigOrZero(senderNonce),
+		unixOrZero(p.ValidUntil),
+		unixOrZero(p.ValidAfter),
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// Sponsor 对 op 签发赞助，PaymasterAndData 为 paymaster 地址 + abi.encode(validUntil, validAfter) + 签名
+func (p *VerifyingPaymaster) Sponsor(ctx context.Context, op *UserOperation, entryPoint common.Address, chainID *big.Int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.Signer == nil {
+		return errors.New("verifying paymaster has no signer")
+	}
+	senderNonce, err := p.SenderNonce(ctx, op.Sender)
+	if err != nil {
+		return fmt.Errorf("failed to get paymaster sender nonce: %w", err)
+	}
+	hash, err := p.Hash(op, chainID, senderNonce)
+	if err != nil {
+		return err
+	}
+	sig, err := p.Signer.SignMsg(hash.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to sign paymaster data: %w", err)
+	}
+	validity, err := validityArgs.Pack(unixOrZero(p.ValidUntil), unixOrZero(p.ValidAfter))
+	if err != nil {
+		return err
+	}
+	op.PaymasterAndData = PaymasterAndData(p.Address, append(validity, sig...))
+	return nil
+}
+
+func unixOrZero(t time.Time) *big.Int {
+	if t.IsZero() {
+		return new(big.Int)
+	}
+	return big.NewInt(t.Unix())
+}
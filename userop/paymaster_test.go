@@ -0,0 +1,123 @@
+package userop
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyingPaymaster(t *testing.T) {
+	owner, err := goether.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcac78d8a6e0a8f2f9")
+	assert.NoError(t, err)
+	sponsor, err := goether.NewSigner("59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d")
+	assert.NoError(t, err)
+	paymaster := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	chainID := big.NewInt(1)
+
+	op := New(common.HexToAddress("0xa2"), big.NewInt(1), []byte{0x01})
+	op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas = big.NewInt(1), big.NewInt(2), big.NewInt(3)
+	op.MaxFeePerGas, op.MaxPriorityFeePerGas = big.NewInt(4), big.NewInt(5)
+
+	b := newMockBundler(t, map[string]func(params []json.RawMessage) (any, error){
+		"eth_call": func(params []json.RawMessage) (any, error) {
+			var msg map[string]string
+			json.Unmarshal(params[0], &msg)
+			assert.Equal(t, paymaster, common.HexToAddress(msg["to"]))
+			data := hexutil.MustDecode(msg["data"])
+			// senderNonce(address)
+			assert.Equal(t, crypto.Keccak256([]byte("senderNonce(address)"))[:4], data[:4])
+			assert.Equal(t, common.LeftPadBytes(op.Sender.Bytes(), 32), data[4:])
+			return hexutil.Encode(common.BigToHash(big.NewInt(7)).Bytes()), nil
+		},
+	})
+	w, err := goether.NewWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", b.Client.URL(), big.NewInt(1))
+	assert.NoError(t, err)
+
+	pm := &VerifyingPaymaster{Address: paymaster, Signer: sponsor, Wallet: w, ValidUntil: time.Unix(1700000000, 0)}
+	word := func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+	var encoded []byte
+	encoded = append(encoded, common.LeftPadBytes(op.Sender.Bytes(), 32)...)
+	encoded = append(encoded, word(1)...)
+	encoded = append(encoded, crypto.Keccak256(nil)...)
+	encoded = append(encoded, crypto.Keccak256([]byte{0x01})...)
+	for i := int64(1); i <= 5; i++ {
+		encoded = append(encoded, word(i)...)
+	}
+	encoded = append(encoded, word(1)...)
+	encoded = append(encoded, common.LeftPadBytes(paymaster.Bytes(), 32)...)
+	encoded = append(encoded, word(7)...)
+	encoded = append(encoded, word(1700000000)...)
+	encoded = append(encoded, word(0)...)
+	hash, err := pm.Hash(op, chainID, big.NewInt(7))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(encoded), hash)
+	// 固定向量，防止编码布局被意外修改
+	assert.Equal(t, "0x8e7241751c3a37a3e7c27e1feca2d083bb535140aacb48b38b39ed4fe47447fd", hash.Hex())
+
+	assert.NoError(t, SponsorAndSign(context.Background(), op, pm, owner, EntryPointV06, chainID))
+	assert.Len(t, op.PaymasterAndData, 20+64+65)
+	assert.Equal(t, paymaster.Bytes(), op.PaymasterAndData[:20])
+	assert.Equal(t, word(1700000000), op.PaymasterAndData[20:52])
+	signer, err := goether.RecoverMsg(hash.Bytes(), op.PaymasterAndData[84:])
+	assert.NoError(t, err)
+	assert.Equal(t, sponsor.Address, signer)
+
+	// 账户签名覆盖 PaymasterAndData
+	recovered, err := op.Recover(EntryPointV06, chainID)
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Address, recovered)
+
+	assert.WithinDuration(t, time.Now().Add(time.Hour), NewVerifyingPaymaster(paymaster, sponsor, w, time.Hour).ValidUntil, time.Minute)
+	assert.True(t, NewVerifyingPaymaster(paymaster, sponsor, w, 0).ValidUntil.IsZero())
+	assert.Error(t, (&VerifyingPaymaster{Address: paymaster, Wallet: w}).Sponsor(context.Background(), op, EntryPointV06, chainID))
+	assert.ErrorContains(t, (&VerifyingPaymaster{Address: paymaster, Signer: sponsor}).Sponsor(context.Background(), op, EntryPointV06, chainID), "no wallet")
+}
+
+func TestRPCPaymaster(t *testing.T) {
+	paymasterAndData := append(common.HexToAddress("0xa1").Bytes(), 0x01, 0x02)
+	full := true
+	b := newMockBundler(t, map[string]func(params []json.RawMessage) (any, error){
+		"pm_sponsorUserOperation": func(params []json.RawMessage) (any, error) {
+			var op UserOperation
+			assert.NoError(t, json.Unmarshal(params[0], &op))
+			assert.Equal(t, common.HexToAddress("0xa2"), op.Sender)
+			if !full {
+				assert.Len(t, params, 2)
+				return hexutil.Encode(paymasterAndData), nil
+			}
+			assert.JSONEq(t, `{"type":"payg"}`, string(params[2]))
+			return map[string]string{
+				"paymasterAndData":     hexutil.Encode(paymasterAndData),
+				"preVerificationGas":   "0xc350",
+				"verificationGasLimit": "0x30d40",
+				"callGasLimit":         "0x186a0",
+			}, nil
+		},
+	})
+
+	pm := &RPCPaymaster{Client: b.Client, Context: map[string]string{"type": "payg"}}
+	op := New(common.HexToAddress("0xa2"), big.NewInt(1), nil)
+	assert.NoError(t, pm.Sponsor(context.Background(), op, EntryPointV06, big.NewInt(1)))
+	assert.Equal(t, paymasterAndData, op.PaymasterAndData)
+	assert.Equal(t, big.NewInt(50000), op.PreVerificationGas)
+	assert.Equal(t, big.NewInt(200000), op.VerificationGasLimit)
+	assert.Equal(t, big.NewInt(100000), op.CallGasLimit)
+
+	full = false
+	op = New(common.HexToAddress("0xa2"), big.NewInt(1), nil)
+	pm.Context = nil
+	assert.NoError(t, pm.Sponsor(context.Background(), op, EntryPointV06, big.NewInt(1)))
+	assert.Equal(t, paymasterAndData, op.PaymasterAndData)
+	assert.Nil(t, op.CallGasLimit)
+
+	pm.Method = "pm_unknown"
+	assert.Error(t, pm.Sponsor(context.Background(), op, EntryPointV06, big.NewInt(1)))
+}
@@ -0,0 +1,203 @@
+// Package userop 构建与签名 ERC-4337 UserOperation（EntryPoint v0.6 格式），
+// 签名使用 goether 的签名器，使智能账户可以复用现有的私钥、KMS 或 MPC 签名
+package userop
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/go-enols/goether/internal/abiutil"
+)
+
+// EntryPointV06 EntryPoint v0.6 在各链上的部署地址
+var EntryPointV06 = common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+// SimpleAccountABI eth-infinitism SimpleAccount 的 execute 与 executeBatch 方法
+const SimpleAccountABI = `[
+{"inputs":[{"name":"dest","type":"address"},{"name":"value","type":"uint256"},{"name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"dest","type":"address[]"},{"name":"func","type":"bytes[]"}],"name":"executeBatch","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// SimpleAccountFactoryABI eth-infinitism SimpleAccountFactory 的 createAccount 与 getAddress 方法
+const SimpleAccountFactoryABI = `[
+{"inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"name":"createAccount","outputs":[{"name":"ret","type":"address"}],"stateMutability":"nonpayable","type":"function"},
+{"inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"name":"getAddress","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+var (
+	simpleAccount        = abiutil.MustABI(SimpleAccountABI)
+	simpleAccountFactory = abiutil.MustABI(SimpleAccountFactoryABI)
+	packArgs             = abiutil.MustArguments("address", "uint256", "bytes32", "bytes32", "uint256", "uint256", "uint256", "uint256", "uint256", "bytes32")
+	hashArgs             = abiutil.MustArguments("bytes32", "address", "uint256")
+)
+
+// UserOperation EntryPoint v0.6 的用户操作，数值字段为 nil 时按 0 处理
+type UserOperation struct {
+	Sender   common.Address
+	Nonce    *big.Int
+	InitCode []byte
+	CallData []byte
+
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+
+	PaymasterAndData []byte
+	Signature        []byte
+}
+
+// New 创建 sender 的用户操作，其余字段由调用方或 bundler 的 gas 估算填充
+func New(sender common.Address, nonce *big.Int, callData []byte) *UserOperation {
+	return &UserOperation{Sender: sender, Nonce: nonce, CallData: callData}
+}
+
+// InitCode 拼接工厂地址与创建账户的调用数据，账户尚未部署时作为 UserOperation.InitCode
+func InitCode(factory common.Address, factoryData []byte) []byte {
+	return append(factory.Bytes(), factoryData...)
+}
+
+// SimpleAccountInitCode 返回通过 SimpleAccountFactory.createAccount(owner, salt) 部署账户的 initCode
+func SimpleAccountInitCode(factory, owner common.Address, salt *big.Int) ([]byte, error) {
+	data, err := simpleAccountFactory.Pack("createAccount", owner, abiutil.BigOrZero(salt))
+	if err != nil {
+		return nil, err
+	}
+	return InitCode(factory, data), nil
+}
+
+// PaymasterAndData 拼接 paymaster 地址与 paymaster 自定义数据
+func PaymasterAndData(paymaster common.Address, data []byte) []byte {
+	return append(paymaster.Bytes(), data...)
+}
+
+// ExecuteCallData 编码 SimpleAccount.execute(to, value, data)，兼容该接口的智能账户均可使用
+func ExecuteCallData(to common.Address, value *big.Int, data []byte) ([]byte, error) {
+	return simpleAccount.Pack("execute", to, abiutil.BigOrZero(value), data)
+}
+
+// ExecuteBatchCallData 编码 SimpleAccount.executeBatch(to, data)，按顺序执行多个调用
+func ExecuteBatchCallData(to []common.Address, data [][]byte) ([]byte, error) {
+	if len(to) != len(data) {
+		return nil, errors.New("executeBatch targets and data length mismatch")
+	}
+	return simpleAccount.Pack("executeBatch", to, data)
+}
+
+// Pack 按 EntryPoint v0.6 的规则编码除签名以外的字段，动态字段使用其 keccak256 哈希，
+// 数值字段为负数或超过 uint256 时返回错误
+func (op *UserOperation) Pack() ([]byte, error) {
+	if err := abiutil.CheckUint(256, op.Nonce, op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas, op.MaxFeePerGas, op.MaxPriorityFeePerGas); err != nil {
+		return nil, err
+	}
+	return packArgs.Pack(
+		op.Sender,
+		abiutil.BigOrZero(op.Nonce),
+		crypto.Keccak256Hash(op.InitCode),
+		crypto.Keccak256Hash(op.CallData),
+		abiutil.BigOrZero(op.CallGasLimit),
+		abiutil.BigOrZero(op.VerificationGasLimit),
+		abiutil.BigOrZero(op.PreVerificationGas),
+		abiutil.BigOrZero(op.MaxFeePerGas),
+		abiutil.BigOrZero(op.MaxPriorityFeePerGas),
+		crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+}
+
+// Hash 计算 userOpHash，与 EntryPoint.getUserOpHash 的结果一致
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	if err := abiutil.CheckUint(256, chainID); err != nil {
+		return common.Hash{}, err
+	}
+	packed, err := op.Pack()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	data, err := hashArgs.Pack(crypto.Keccak256Hash(packed), entryPoint, abiutil.BigOrZero(chainID))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// Sign 使用 signer 对 userOpHash 进行 EIP-191 个人消息签名并写入 Signature，
+// 与 SimpleAccount 的 toEthSignedMessageHash 校验方式一致
+func (op *UserOperation) Sign(signer goether.AccountSigner, entryPoint common.Address, chainID *big.Int) error {
+	hash, err := op.Hash(entryPoint, chainID)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignMsg(hash.Bytes())
+	if err != nil {
+		return err
+	}
+	op.Signature = sig
+	return nil
+}
+
+// Recover 从 Signature 恢复签名者地址
+func (op *UserOperation) Recover(entryPoint common.Address, chainID *big.Int) (common.Address, error) {
+	hash, err := op.Hash(entryPoint, chainID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return goether.RecoverMsg(hash.Bytes(), op.Signature)
+}
+
+type userOperationJSON struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// MarshalJSON 编码为 eth_sendUserOperation、eth_estimateUserOperationGas 使用的十六进制格式
+func (op UserOperation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(userOperationJSON{
+		Sender:               op.Sender,
+		Nonce:                (*hexutil.Big)(abiutil.BigOrZero(op.Nonce)),
+		InitCode:             abiutil.BytesOrEmpty(op.InitCode),
+		CallData:             abiutil.BytesOrEmpty(op.CallData),
+		CallGasLimit:         (*hexutil.Big)(abiutil.BigOrZero(op.CallGasLimit)),
+		VerificationGasLimit: (*hexutil.Big)(abiutil.BigOrZero(op.VerificationGasLimit)),
+		PreVerificationGas:   (*hexutil.Big)(abiutil.BigOrZero(op.PreVerificationGas)),
+		MaxFeePerGas:         (*hexutil.Big)(abiutil.BigOrZero(op.MaxFeePerGas)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(abiutil.BigOrZero(op.MaxPriorityFeePerGas)),
+		PaymasterAndData:     abiutil.BytesOrEmpty(op.PaymasterAndData),
+		Signature:            abiutil.BytesOrEmpty(op.Signature),
+	})
+}
+
+func (op *UserOperation) UnmarshalJSON(input []byte) error {
+	var dec userOperationJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*op = UserOperation{
+		Sender:               dec.Sender,
+		Nonce:                (*big.Int)(dec.Nonce),
+		InitCode:             dec.InitCode,
+		CallData:             dec.CallData,
+		CallGasLimit:         (*big.Int)(dec.CallGasLimit),
+		VerificationGasLimit: (*big.Int)(dec.VerificationGasLimit),
+		PreVerificationGas:   (*big.Int)(dec.PreVerificationGas),
+		MaxFeePerGas:         (*big.Int)(dec.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*big.Int)(dec.MaxPriorityFeePerGas),
+		PaymasterAndData:     dec.PaymasterAndData,
+		Signature:            dec.Signature,
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package userop
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-enols/goether"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserOperation(t *testing.T) {
+	signer, err := goether.NewSigner("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcac78d8a6e0a8f2f9")
+	assert.NoError(t, err)
+	factory := common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454")
+	paymaster := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000a3")
+
+	initCode, err := SimpleAccountInitCode(factory, signer.Address, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, factory.Bytes(), initCode[:20])
+	assert.Equal(t, "0x5fbfb9cf", hexutil.Encode(initCode[20:24]))
+
+	callData, err := ExecuteCallData(to, big.NewInt(1), []byte{0xde, 0xad})
+	assert.NoError(t, err)
+	assert.Equal(t, "0xb61d27f6", hexutil.Encode(callData[:4]))
+	batch, err := ExecuteBatchCallData([]common.Address{to, to}, [][]byte{{1}, {2}})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x18dfb3c7", hexutil.Encode(batch[:4]))
+	_, err = ExecuteBatchCallData([]common.Address{to}, nil)
+	assert.Error(t, err)
+
+	op := New(sender, big.NewInt(3), callData)
+	op.InitCode = initCode
+	op.CallGasLimit = big.NewInt(100000)
+	op.VerificationGasLimit = big.NewInt(200000)
+	op.PreVerificationGas = big.NewInt(50000)
+	op.MaxFeePerGas = big.NewInt(2e9)
+	op.MaxPriorityFeePerGas = big.NewInt(1e9)
+	op.PaymasterAndData = PaymasterAndData(paymaster, []byte{0x01})
+	assert.Len(t, op.PaymasterAndData, 21)
+
+	// 按 EntryPoint 的 abi.encode 规则逐个字段拼接 32 字节字
+	word := func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+	var packed []byte
+	packed = append(packed, common.LeftPadBytes(sender.Bytes(), 32)...)
+	packed = append(packed, word(3)...)
+	packed = append(packed, crypto.Keccak256(initCode)...)
+	packed = append(packed, crypto.Keccak256(callData)...)
+	packed = append(packed, word(100000)...)
+	packed = append(packed, word(200000)...)
+	packed = append(packed, word(50000)...)
+	packed = append(packed, word(2e9)...)
+	packed = append(packed, word(1e9)...)
+	packed = append(packed, crypto.Keccak256(op.PaymasterAndData)...)
+	got, err := op.Pack()
+	assert.NoError(t, err)
+	assert.Equal(t, packed, got)
+
+	var encoded []byte
+	encoded = append(encoded, crypto.Keccak256(packed)...)
+	encoded = append(encoded, common.LeftPadBytes(EntryPointV06.Bytes(), 32)...)
+	encoded = append(encoded, word(1)...)
+	hash, err := op.Hash(EntryPointV06, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(encoded), hash)
+	other, err := op.Hash(EntryPointV06, big.NewInt(137))
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	// 签名不参与哈希
+	assert.NoError(t, op.Sign(signer, EntryPointV06, big.NewInt(1)))
+	assert.Len(t, op.Signature, 65)
+	signed, err := op.Hash(EntryPointV06, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, hash, signed)
+	recovered, err := op.Recover(EntryPointV06, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, recovered)
+
+	raw, err := json.Marshal(op)
+	assert.NoError(t, err)
+	var fields map[string]string
+	assert.NoError(t, json.Unmarshal(raw, &fields))
+	assert.Equal(t, "0x3", fields["nonce"])
+	assert.Equal(t, "0x186a0", fields["callGasLimit"])
+	assert.Equal(t, hexutil.Encode(op.PaymasterAndData), fields["paymasterAndData"])
+	var decoded UserOperation
+	assert.NoError(t, json.Unmarshal(raw, &decoded))
+	decodedHash, err := decoded.Hash(EntryPointV06, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, hash, decodedHash)
+
+	// 空字段编码为 0x
+	raw, err = json.Marshal(New(sender, nil, nil))
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(raw, &fields))
+	assert.Equal(t, "0x", fields["initCode"])
+	assert.Equal(t, "0x0", fields["maxFeePerGas"])
+
+	// 超出 uint256 范围的字段返回错误，不会签名截断后的哈希
+	op.MaxFeePerGas = big.NewInt(-1)
+	_, err = op.Hash(EntryPointV06, big.NewInt(1))
+	assert.Error(t, err)
+	assert.Error(t, op.Sign(signer, EntryPointV06, big.NewInt(1)))
+}
@@ -4,12 +4,14 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
-	"github.com/go-enols/go-log"
 )
 
 func EthToBN(amount float64) (bn *big.Int) {
@@ -28,6 +30,48 @@ func GweiToBN(amount float64) (bn *big.Int) {
 	return bn
 }
 
+// FormatUnits 将最小单位的 amount 按 decimals 转换为十进制字符串，结果精确且去掉末尾的 0
+//
+//	FormatUnits(big.NewInt(1500000), 6) // "1.5"
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	s := new(big.Int).Abs(amount).String()
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals == 0 {
+		return sign + s
+	}
+	if len(s) <= int(decimals) {
+		s = strings.Repeat("0", int(decimals)-len(s)+1) + s
+	}
+	integer, fraction := s[:len(s)-int(decimals)], strings.TrimRight(s[len(s)-int(decimals):], "0")
+	if fraction == "" {
+		return sign + integer
+	}
+	return sign + integer + "." + fraction
+}
+
+// ParseUnits 将十进制字符串按 decimals 转换为最小单位，例如 ParseUnits("1.5", 18)，
+// 小数位数超过 decimals 时返回错误而不是四舍五入
+func ParseUnits(amount string, decimals uint8) (*big.Int, error) {
+	integer, fraction, _ := strings.Cut(strings.TrimSpace(amount), ".")
+	if integer == "" && fraction == "" || strings.Trim(integer+fraction, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	if len(fraction) > int(decimals) {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+	n, _ := new(big.Int).SetString(integer+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	return n, nil
+}
+
+// ToDecimal 将最小单位的 amount 按 decimals 转换为 big.Float
+func ToDecimal(amount *big.Int, decimals uint8) *big.Float {
+	f, _ := new(big.Float).SetPrec(256).SetString(FormatUnits(amount, decimals))
+	return f
+}
+
 func EIP712Hash(typedData apitypes.TypedData) (hash []byte, err error) {
 	log.Debug("Generating EIP712 hash", "primaryType", typedData.PrimaryType, "domain", typedData.Domain.Name)
 	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
@@ -46,8 +90,12 @@ func EIP712Hash(typedData apitypes.TypedData) (hash []byte, err error) {
 	return
 }
 
+// Ecrecover 从签名恢复公钥和地址，signature 可以是 65 字节 [R || S || V] 或 64 字节 EIP-2098 紧凑签名
 func Ecrecover(hash, signature []byte) (publicBy []byte, address common.Address, err error) {
 	log.Debug("Recovering public key from signature", "hashLength", len(hash), "signatureLength", len(signature))
+	if len(signature) == 64 {
+		signature, _ = ExpandSignature(signature)
+	}
 	sig := make([]byte, len(signature))
 	copy(sig, signature)
 	if len(sig) != 65 {
@@ -77,17 +125,81 @@ func Ecrecover(hash, signature []byte) (publicBy []byte, address common.Address,
 	return
 }
 
+// RecoverMsg 从 EIP-191 personal_sign 签名中恢复签名者地址，与 SignMsg 及 MetaMask 的签名对应，
+// 签名的 V 可以是 0/1 或 27/28
+func RecoverMsg(msg, sig []byte) (common.Address, error) {
+	_, address, err := Ecrecover(accounts.TextHash(msg), sig)
+	return address, err
+}
+
+// VerifyMsg 验证 sig 是否为 expected 对 msg 的 EIP-191 personal_sign 签名
+func VerifyMsg(msg, sig []byte, expected common.Address) (bool, error) {
+	address, err := RecoverMsg(msg, sig)
+	if err != nil {
+		return false, err
+	}
+	log.Debug("Message signature verified", "signer", address.Hex(), "expected", expected.Hex())
+	return address == expected, nil
+}
+
+// RecoverTypedData 从 EIP-712 签名中恢复签名者地址，哈希计算与 SignTypedData 相同
+func RecoverTypedData(typedData apitypes.TypedData, sig []byte) (common.Address, error) {
+	hash, err := EIP712Hash(typedData)
+	if err != nil {
+		return common.Address{}, err
+	}
+	_, address, err := Ecrecover(hash, sig)
+	return address, err
+}
+
+// VerifyTypedData 验证 sig 是否为 expected 对 typedData 的 EIP-712 签名
+func VerifyTypedData(typedData apitypes.TypedData, sig []byte, expected common.Address) (bool, error) {
+	address, err := RecoverTypedData(typedData, sig)
+	if err != nil {
+		return false, err
+	}
+	log.Debug("Typed data signature verified", "signer", address.Hex(), "expected", expected.Hex())
+	return address == expected, nil
+}
+
+// ECIESOptions ECIES 加解密参数，与其他实现互通时双方需要使用相同的设置
+type ECIESOptions struct {
+	// SharedInfo1 参与 KDF 的共享信息 s1
+	SharedInfo1 []byte
+	// SharedInfo2 参与 MAC 的共享信息 s2
+	SharedInfo2 []byte
+	// Params 加密参数，为 nil 时使用 ecies.ECIES_AES128_SHA256。
+	// secp256k1 的共享密钥只有 32 字节，KeyLen 不能超过 16，可以组合 AES-128 与其他哈希算法
+	Params *ecies.ECIESParams
+}
+
+// sharedInfo 返回 s1、s2 与加密参数，opts 为 nil 时全部为 nil
+func (o *ECIESOptions) sharedInfo() (s1, s2 []byte, params *ecies.ECIESParams) {
+	if o == nil {
+		return nil, nil, nil
+	}
+	return o.SharedInfo1, o.SharedInfo2, o.Params
+}
+
 // Encrypt encrypt
 func Encrypt(publicKey string, message []byte) ([]byte, error) {
+	return EncryptWithOptions(publicKey, message, nil)
+}
+
+// EncryptWithOptions 使用指定的共享信息与 ECIES 参数加密，publicKey 可以是非压缩或压缩公钥
+func EncryptWithOptions(publicKey string, message []byte, opts *ECIESOptions) ([]byte, error) {
 	log.Debug("Encrypting message", "publicKey", publicKey, "messageLength", len(message))
-	pub := common.FromHex(publicKey)
-	pubKey, err := crypto.UnmarshalPubkey(pub)
+	pubKey, err := parsePublicKey(common.FromHex(publicKey))
 	if err != nil {
 		log.Error("Failed to unmarshal public key", "publicKey", publicKey, "error", err)
 		return nil, err
 	}
+	s1, s2, params := opts.sharedInfo()
 	eciesPub := ecies.ImportECDSAPublic(pubKey)
-	result, err := ecies.Encrypt(rand.Reader, eciesPub, message, nil, nil)
+	if params != nil {
+		eciesPub.Params = params
+	}
+	result, err := ecies.Encrypt(rand.Reader, eciesPub, message, s1, s2)
 	if err != nil {
 		log.Error("Failed to encrypt message", "error", err)
 		return nil, err
@@ -95,3 +207,12 @@ func Encrypt(publicKey string, message []byte) ([]byte, error) {
 	log.Debug("Message encrypted successfully", "resultLength", len(result))
 	return result, nil
 }
+
+// EncryptHex 与 EncryptWithOptions 相同，但返回 0x 开头的十六进制密文
+func EncryptHex(publicKey string, message []byte, opts *ECIESOptions) (string, error) {
+	ct, err := EncryptWithOptions(publicKey, message, opts)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(ct), nil
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
@@ -21,6 +22,66 @@ func TestGweiToBN(t *testing.T) {
 	assert.Equal(t, big.NewInt(1100000000), GweiToBN(1.1))
 }
 
+func TestFormatUnits(t *testing.T) {
+	assert.Equal(t, "1.5", FormatUnits(big.NewInt(1500000), 6))
+	assert.Equal(t, "0.000001", FormatUnits(big.NewInt(1), 6))
+	assert.Equal(t, "2", FormatUnits(big.NewInt(2000000), 6))
+	assert.Equal(t, "0", FormatUnits(big.NewInt(0), 18))
+	assert.Equal(t, "-0.25", FormatUnits(big.NewInt(-25), 2))
+	assert.Equal(t, "42", FormatUnits(big.NewInt(42), 0))
+	assert.Equal(t, "1.5", ToDecimal(big.NewInt(1500000), 6).Text('f', 1))
+}
+
+func TestParseUnits(t *testing.T) {
+	for amount, expected := range map[string]string{
+		"1.5":      "1500000",
+		"100.25":   "100250000",
+		"0.000001": "1",
+		".5":       "500000",
+		"2":        "2000000",
+		"3.":       "3000000",
+	} {
+		n, err := ParseUnits(amount, 6)
+		assert.NoError(t, err, amount)
+		assert.Equal(t, expected, n.String(), amount)
+	}
+	for _, amount := range []string{"", ".", "-1", "1.2.3", "abc", "1e18", "0.0000001"} {
+		_, err := ParseUnits(amount, 6)
+		assert.Error(t, err, amount)
+	}
+	n, err := ParseUnits("0.1234567898765432", 18)
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789876543200", n.String())
+}
+
+func TestVerifyMsg(t *testing.T) {
+	signer, err := NewSigner("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632")
+	assert.NoError(t, err)
+	msg := []byte("hello goether")
+	sig, err := signer.SignMsg(msg)
+	assert.NoError(t, err)
+
+	address, err := RecoverMsg(msg, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, address)
+	ok, err := VerifyMsg(msg, sig, signer.Address)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// V 为 0/1 的签名同样可以验证
+	raw := append([]byte{}, sig...)
+	raw[64] -= 27
+	ok, err = VerifyMsg(msg, raw, signer.Address)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyMsg([]byte("other"), sig, signer.Address)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, err = VerifyMsg(msg, sig[:63], signer.Address)
+	assert.Error(t, err)
+}
+
 func TestEIP712(t *testing.T) {
 	raw := `{"types": {"EIP712Domain": [{"name": "name","type": "string"},{"name": "version","type": "string"},{"name": "chainId","type": "uint256"}],"Order": [{"name": "action","type": "string"},{"name": "orderHashes","type": "string[]"},{"name": "makerAddress","type": "address"}]},"primaryType": "Order","domain": {"name": "ZooDex","version": "1","chainId": "42"},"message": {"action": "cancelOrder","orderHashes": ["0x123", "0x456", "0x789"],"makerAddress": "0xf9593A9d7F735814B87D08e8D8aD624f58d53B10"}}
 	`
@@ -35,6 +96,18 @@ func TestEIP712(t *testing.T) {
 	_, addr, err := Ecrecover(hash, hexutil.MustDecode("0xa9a3e5f72b48651b735d0908f1f240b06eafe7166dbe6b4fc8b57d8b8515ef555fe4b124c2b50d6907423426ec46bc12c5956942dcfd01e02d70912c87a389c41b"))
 	assert.NoError(t, err)
 	assert.Equal(t, "0xf9593A9d7F735814B87D08e8D8aD624f58d53B10", addr.String())
+
+	sig := hexutil.MustDecode("0xa9a3e5f72b48651b735d0908f1f240b06eafe7166dbe6b4fc8b57d8b8515ef555fe4b124c2b50d6907423426ec46bc12c5956942dcfd01e02d70912c87a389c41b")
+	addr, err = RecoverTypedData(typedData, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf9593A9d7F735814B87D08e8D8aD624f58d53B10", addr.String())
+	ok, err := VerifyTypedData(typedData, sig, common.HexToAddress("0xf9593A9d7F735814B87D08e8D8aD624f58d53B10"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	typedData.Message["action"] = "placeOrder"
+	ok, err = VerifyTypedData(typedData, sig, common.HexToAddress("0xf9593A9d7F735814B87D08e8D8aD624f58d53B10"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
 }
 
 func TestEcrecover(t *testing.T) {
@@ -0,0 +1,170 @@
+package goether
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VaultConfig 连接 Vault 的配置
+//
+// Vault 自带的 transit 引擎不支持 secp256k1，需要挂载一个接口与 transit 相同、
+// 且提供 ecdsa-secp256k1 密钥类型的引擎：GET /v1/{Mount}/keys/{Key} 返回密钥类型与 PEM 公钥，
+// POST /v1/{Mount}/sign/{Key} 对预哈希的摘要签名。创建签名器时会校验密钥类型。
+type VaultConfig struct {
+	Addr   string       // Vault 地址，例如 https://vault.example.com:8200
+	Token  string       // Vault 访问令牌
+	Mount  string       // 引擎挂载路径，必填
+	Key    string       // 密钥名称
+	Client *http.Client // 为空时使用 http.DefaultClient
+}
+
+// vaultKeyType VaultSigner 要求的密钥类型
+const vaultKeyType = "ecdsa-secp256k1"
+
+// VaultSigner 将签名委托给 Vault 中的 secp256k1 密钥，私钥不会离开 Vault
+type VaultSigner struct {
+	digestSigner
+
+	config VaultConfig
+	pub    *ecdsa.PublicKey
+}
+
+// NewVaultSigner 读取 Vault 密钥的最新版本公钥并计算地址
+func NewVaultSigner(ctx context.Context, config VaultConfig) (*VaultSigner, error) {
+	if config.Mount == "" {
+		return nil, errors.New("vault mount is required, the built-in transit engine does not support secp256k1")
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	log.Debug("Creating vault signer", "addr", config.Addr, "mount", config.Mount, "key", config.Key)
+
+	s := &VaultSigner{config: config}
+	pub, err := s.publicKey(ctx)
+	if err != nil {
+		log.Error("Failed to get vault public key", "key", config.Key, "error", err)
+		return nil, err
+	}
+	s.pub = pub
+	s.digestSigner = digestSigner{Address: crypto.PubkeyToAddress(*pub), sign: s.SignDigest}
+
+	log.Debug("Vault signer created successfully", "key", config.Key, "address", s.Address.Hex())
+	return s, nil
+}
+
+// GetPublicKey 获取公钥字节数组
+func (s *VaultSigner) GetPublicKey() []byte {
+	return crypto.FromECDSAPub(s.pub)
+}
+
+// SignDigest 对 32 字节摘要签名，返回 [R || S || V] 格式且 V 为 0 或 1 的签名
+func (s *VaultSigner) SignDigest(digest []byte) ([]byte, error) {
+	return s.SignDigestContext(context.Background(), digest)
+}
+
+// SignDigestContext 与 SignDigest 相同，但 Vault 请求受 ctx 控制
+func (s *VaultSigner) SignDigestContext(ctx context.Context, digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("digest must be 32 bytes, got %d", len(digest))
+	}
+	var resp struct {
+		Signature string `json:"signature"`
+	}
+	err := s.do(ctx, http.MethodPost, "sign/"+s.config.Key, map[string]any{
+		"input":                base64.StdEncoding.EncodeToString(digest),
+		"prehashed":            true,
+		"marshaling_algorithm": "asn1",
+	}, &resp)
+	if err != nil {
+		log.Error("Failed to sign with vault", "key", s.config.Key, "error", err)
+		return nil, err
+	}
+
+	// 签名格式为 vault:v{版本}:{base64}
+	parts := strings.Split(resp.Signature, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unexpected vault signature format: %s", resp.Signature)
+	}
+	der, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	return derToEthSignature(der, digest, s.pub)
+}
+
+// publicKey 读取密钥最新版本的公钥
+func (s *VaultSigner) publicKey(ctx context.Context) (*ecdsa.PublicKey, error) {
+	var resp struct {
+		Type          string `json:"type"`
+		LatestVersion int    `json:"latest_version"`
+		Keys          map[string]struct {
+			PublicKey string `json:"public_key"`
+		} `json:"keys"`
+	}
+	if err := s.do(ctx, http.MethodGet, "keys/"+s.config.Key, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Type != vaultKeyType {
+		return nil, fmt.Errorf("vault key %s has type %q, expected %s", s.config.Key, resp.Type, vaultKeyType)
+	}
+	key, ok := resp.Keys[strconv.Itoa(resp.LatestVersion)]
+	if !ok {
+		return nil, fmt.Errorf("vault key %s has no version %d", s.config.Key, resp.LatestVersion)
+	}
+	block, _ := pem.Decode([]byte(key.PublicKey))
+	if block == nil {
+		return nil, errors.New("vault public key is not pem encoded")
+	}
+	return parseSecp256k1PublicKey(block.Bytes)
+}
+
+// do 发送 Vault 请求，并将响应中的 data 字段解码到 out
+func (s *VaultSigner) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	url := strings.TrimRight(s.config.Addr, "/") + "/v1/" + strings.Trim(s.config.Mount, "/") + "/" + path
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.config.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []string        `json:"errors"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("vault %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("vault %s %s: status %d: %s", method, path, resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+	return json.Unmarshal(result.Data, out)
+}
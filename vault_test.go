@@ -0,0 +1,81 @@
+package goether
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultSigner(t *testing.T) {
+	kms := &fakeKMS{key: TestSigner.GetPrivateKey(), highS: true}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			rw.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(rw).Encode(map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secp256k1/keys/eth", "/v1/secp256k1/keys/p256":
+			der, _ := kms.GetPublicKey(r.Context(), "eth")
+			keyType := "ecdsa-secp256k1"
+			if r.URL.Path == "/v1/secp256k1/keys/p256" {
+				keyType = "ecdsa-p256"
+			}
+			json.NewEncoder(rw).Encode(map[string]any{"data": map[string]any{
+				"type":           keyType,
+				"latest_version": 1,
+				"keys": map[string]any{
+					"1": map[string]any{"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+				},
+			}})
+		case "/v1/secp256k1/sign/eth":
+			var req struct {
+				Input     string `json:"input"`
+				Prehashed bool   `json:"prehashed"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			assert.True(t, req.Prehashed)
+			digest, _ := base64.StdEncoding.DecodeString(req.Input)
+			der, _ := kms.Sign(r.Context(), "eth", digest)
+			json.NewEncoder(rw).Encode(map[string]any{"data": map[string]any{
+				"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(der),
+			}})
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	signer, err := NewVaultSigner(context.Background(), VaultConfig{Addr: srv.URL, Token: "token", Mount: "secp256k1", Key: "eth"})
+	assert.NoError(t, err)
+	assert.Equal(t, TestSigner.Address, signer.Address)
+	assert.Equal(t, TestSigner.GetPublicKey(), signer.GetPublicKey())
+
+	sig, err := signer.SignMsg([]byte("123"))
+	assert.NoError(t, err)
+	expected, _ := TestSigner.SignMsg([]byte("123"))
+	assert.Equal(t, expected, sig)
+
+	tx, err := signer.SignTx(1, common.HexToAddress("0xab6c371B6c466BcF14d4003601951e5873dF2AcA"), big.NewInt(0), 21000, big.NewInt(1), big.NewInt(2), nil, big.NewInt(42))
+	assert.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(42)), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address, from)
+
+	_, err = NewVaultSigner(context.Background(), VaultConfig{Addr: srv.URL, Token: "wrong", Mount: "secp256k1", Key: "eth"})
+	assert.ErrorContains(t, err, "permission denied")
+
+	_, err = NewVaultSigner(context.Background(), VaultConfig{Addr: srv.URL, Token: "token", Key: "eth"})
+	assert.ErrorContains(t, err, "vault mount is required")
+	_, err = NewVaultSigner(context.Background(), VaultConfig{Addr: srv.URL, Token: "token", Mount: "secp256k1", Key: "p256"})
+	assert.ErrorContains(t, err, `has type "ecdsa-p256"`)
+}
@@ -0,0 +1,151 @@
+package goether
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-enols/ethrpc"
+)
+
+var (
+	// DefaultWaitTimeout SendTxAndWait 等待交易确认的默认超时时间
+	DefaultWaitTimeout = 5 * time.Minute
+	// ReceiptPollInterval 轮询交易回执与区块高度的间隔
+	ReceiptPollInterval = 2 * time.Second
+)
+
+// SendTxAndWait 发送交易并等待其被打包且达到 confirmations 个确认
+//
+// confirmations 小于等于 1 时只等待交易被打包。超过 DefaultWaitTimeout
+// 仍未达到要求时返回超时错误；交易执行失败时同时返回回执与错误。
+func (w *Wallet) SendTxAndWait(to common.Address, amount *big.Int, data []byte, opts *TxOpts, confirmations int) (*ethrpc.TransactionReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultWaitTimeout)
+	defer cancel()
+	return w.SendTxAndWaitContext(ctx, to, amount, data, opts, confirmations)
+}
+
+// SendTxAndWaitContext 与 SendTxAndWait 相同，超时由 ctx 控制
+func (w *Wallet) SendTxAndWaitContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts, confirmations int) (*ethrpc.TransactionReceipt, error) {
+	txHash, err := w.SendTxContext(ctx, to, amount, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return w.WaitForReceiptContext(ctx, txHash, confirmations)
+}
+
+// WaitForReceipt 等待交易被打包并达到 confirmations 个确认，最多等待 timeout
+func (w *Wallet) WaitForReceipt(txHash string, confirmations int, timeout time.Duration) (*ethrpc.TransactionReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return w.WaitForReceiptContext(ctx, txHash, confirmations)
+}
+
+// WaitForReceiptContext 轮询交易回执直到交易被打包并达到 confirmations 个确认
+func (w *Wallet) WaitForReceiptContext(ctx context.Context, txHash string, confirmations int) (*ethrpc.TransactionReceipt, error) {
+	log.Debug("Waiting for transaction receipt", "txHash", txHash, "confirmations", confirmations)
+
+	ticker := time.NewTicker(ReceiptPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		receipt, err := w.checkReceipt(ctx, txHash, confirmations)
+		if receipt == nil && isTransientError(err) {
+			// 网络错误可能只是暂时的，继续轮询，超时时一并返回
+			if ctx.Err() == nil {
+				log.Debug("Failed to check transaction receipt, retrying", "txHash", txHash, "error", err)
+				lastErr = err
+			}
+		} else if receipt != nil || err != nil {
+			return receipt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Error("Timeout waiting for transaction receipt", "txHash", txHash, "error", ctx.Err(), "lastError", lastErr)
+			return nil, waitTimeoutError(txHash, ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitTimeoutError 等待交易超时的错误，lastErr 为超时前最后一次轮询失败的原因
+func waitTimeoutError(txHash string, ctxErr, lastErr error) error {
+	if lastErr != nil {
+		return fmt.Errorf("timeout waiting for transaction %s: %w (last error: %w)", txHash, ctxErr, lastErr)
+	}
+	return fmt.Errorf("timeout waiting for transaction %s: %w", txHash, ctxErr)
+}
+
+// isTransientError 是否为可以继续轮询的错误：网络错误或超时等没有得到节点 JSON-RPC 响应的错误
+func isTransientError(err error) bool {
+	var rpcErr *RPCError
+	return err != nil && !errors.As(err, &rpcErr)
+}
+
+// checkReceipt 检查交易是否已达到要求的确认数，未达到时返回 nil, nil
+//
+// 节点返回的 JSON-RPC 错误（认证失败、无效哈希等）直接返回，调用方不应继续等待；
+// 网络错误同样返回，由调用方通过 isTransientError 判断是否重试。
+func (w *Wallet) checkReceipt(ctx context.Context, txHash string, confirmations int) (*ethrpc.TransactionReceipt, error) {
+	receipt, err := callContext(ctx, func() (*ethrpc.TransactionReceipt, error) {
+		return w.rpc(ctx).EthGetTransactionReceipt(txHash)
+	})
+	if err != nil && !isReceiptNotFound(err) {
+		log.Error("Failed to get transaction receipt", "txHash", txHash, "error", err)
+		return nil, err
+	}
+	// 节点对尚未打包的交易返回 null，此时回执中没有区块哈希
+	if err != nil || receipt == nil || receipt.BlockHash == "" {
+		log.Debug("Transaction receipt not available yet", "txHash", txHash)
+		return nil, nil
+	}
+
+	if confirmations > 1 {
+		head, err := callContext(ctx, w.rpc(ctx).EthBlockNumber)
+		if err != nil {
+			log.Error("Failed to get block number", "error", err)
+			return nil, err
+		}
+		if head-receipt.BlockNumber+1 < confirmations {
+			log.Debug("Waiting for more confirmations",
+				"txHash", txHash,
+				"confirmations", head-receipt.BlockNumber+1,
+				"required", confirmations)
+			return nil, nil
+		}
+	}
+
+	status, err := ethrpc.ParseInt64(receipt.Status)
+	if err != nil {
+		return receipt, fmt.Errorf("invalid receipt status %q: %w", receipt.Status, err)
+	}
+	if status != 1 {
+		log.Error("Transaction reverted", "txHash", txHash, "block", receipt.BlockNumber)
+		return receipt, fmt.Errorf("%w: %s in block %d", ErrTxReverted, txHash, receipt.BlockNumber)
+	}
+
+	log.Debug("Transaction confirmed", "txHash", txHash, "block", receipt.BlockNumber)
+	return receipt, nil
+}
+
+// isReceiptNotFound 部分节点对尚未打包的交易返回错误而不是 null，
+// -32601 等其他 "not found" 错误不属于该情况，需要返回给调用方
+func isReceiptNotFound(err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code == -32601 {
+		return false
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, s := range []string{"transaction not found", "receipt not found", "indexing is in progress"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
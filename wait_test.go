@@ -0,0 +1,100 @@
+package goether
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func testReceipt(status string) map[string]any {
+	return map[string]any{
+		"transactionHash":   "0x01",
+		"transactionIndex":  "0x0",
+		"blockHash":         "0x02",
+		"blockNumber":       "0x10",
+		"cumulativeGasUsed": "0x5208",
+		"gasUsed":           "0x5208",
+		"logs":              []any{},
+		"status":            status,
+	}
+}
+
+func TestWaitForReceipt(t *testing.T) {
+	ReceiptPollInterval = 10 * time.Millisecond
+
+	m := newMockRPC(t)
+	polls := 0
+	m.On("eth_getTransactionReceipt", func([]json.RawMessage) (any, error) {
+		polls++
+		if polls < 3 {
+			return nil, nil
+		}
+		return testReceipt("0x1"), nil
+	})
+	head := 0x10
+	m.On("eth_blockNumber", func([]json.RawMessage) (any, error) {
+		head++
+		return hexutil.EncodeUint64(uint64(head)), nil
+	})
+	w := newTestWallet(t, m)
+
+	receipt, err := w.WaitForReceipt("0x01", 1, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 0x10, receipt.BlockNumber)
+	assert.Equal(t, 0, m.Calls("eth_blockNumber"))
+
+	receipt, err = w.WaitForReceipt("0x01", 3, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", receipt.Status)
+	assert.GreaterOrEqual(t, m.Calls("eth_blockNumber"), 2)
+
+	m.Result("eth_getTransactionReceipt", testReceipt("0x0"))
+	receipt, err = w.WaitForReceipt("0x01", 1, time.Second)
+	assert.Error(t, err)
+	assert.NotNil(t, receipt)
+
+	m.Result("eth_getTransactionReceipt", nil)
+	_, err = w.WaitForReceipt("0x01", 1, 50*time.Millisecond)
+	assert.ErrorContains(t, err, "timeout")
+
+	// 节点返回的错误直接返回，不再等待到超时
+	m.On("eth_getTransactionReceipt", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32602, Message: "invalid argument 0: hex string has length 2, want 64"}
+	})
+	start := time.Now()
+	_, err = w.WaitForReceipt("0x01", 1, time.Second)
+	assert.ErrorContains(t, err, "invalid argument")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// 节点不支持该方法时同样直接返回
+	m.On("eth_getTransactionReceipt", func([]json.RawMessage) (any, error) {
+		return nil, &mockError{Code: -32601, Message: "method not found: eth_getTransactionReceipt"}
+	})
+	start = time.Now()
+	_, err = w.WaitForReceipt("0x01", 1, time.Second)
+	assert.ErrorContains(t, err, "method not found")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// 部分节点对未打包的交易返回 not found 错误
+	polls = 0
+	m.On("eth_getTransactionReceipt", func([]json.RawMessage) (any, error) {
+		polls++
+		if polls < 3 {
+			return nil, &mockError{Code: -32000, Message: "transaction not found"}
+		}
+		return testReceipt("0x1"), nil
+	})
+	_, err = w.WaitForReceipt("0x01", 1, time.Second)
+	assert.NoError(t, err)
+
+	// 网络错误继续轮询，超时错误中包含最后一次的错误
+	m.Close()
+	_, err = w.WaitForReceipt("0x01", 1, 50*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "last error")
+	assert.ErrorContains(t, err, "connection refused")
+}
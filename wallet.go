@@ -1,16 +1,20 @@
 package goether
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/go-enols/ethrpc"
-	"github.com/go-enols/go-log"
 )
 
 type TxOpts struct {
@@ -19,6 +23,46 @@ type TxOpts struct {
 	GasPrice  *big.Int
 	GasTipCap *big.Int
 	GasFeeCap *big.Int
+
+	// AccessList EIP-2930 访问列表，SendTx 与 SendAccessListTx 会将其附加到交易中
+	AccessList types.AccessList
+	// CreateAccessList 为 true 且未设置 AccessList 时，发送前调用 eth_createAccessList 自动生成
+	CreateAccessList bool
+	// Simulate 为 true 时发送前先用 eth_call 模拟执行，回滚时返回 *RevertError 而不广播
+	Simulate bool
+	// GasBuffer 覆盖钱包的 GasBuffer，仅在 gasLimit 由 eth_estimateGas 估算时生效
+	GasBuffer *GasBuffer
+	// Value Contract.ExecMethod 随交易转入的金额（wei），用于调用 payable 方法，为 nil 时为 0；
+	// SendTx、ExecPayable 等显式传入金额的方法忽略该字段
+	Value *big.Int
+	// Public 为 true 时不使用钱包的 PrivateRelays，通过 Client 广播到公共交易池
+	Public bool
+}
+
+// value 返回 Value，opts 或 Value 为 nil 时返回 0
+func (t *TxOpts) value() *big.Int {
+	if t == nil || t.Value == nil {
+		return big.NewInt(0)
+	}
+	return t.Value
+}
+
+// GasBuffer 在 eth_estimateGas 的结果上追加的余量：gasLimit = ceil(estimate * Multiplier) + Headroom
+//
+// 状态相关的合约在估算与上链之间状态变化时容易 out of gas，例如 Multiplier 设为 1.2。
+type GasBuffer struct {
+	// Multiplier 估算结果的倍数，小于等于 0 时视为 1
+	Multiplier float64
+	// Headroom 额外追加的固定 gas
+	Headroom int
+}
+
+// apply 返回追加余量后的 gasLimit
+func (b GasBuffer) apply(gasLimit int) int {
+	if b.Multiplier > 0 {
+		gasLimit = int(math.Ceil(float64(gasLimit) * b.Multiplier))
+	}
+	return gasLimit + b.Headroom
 }
 
 // GetOldFee 计算出本次如果使用旧版交易时最大消耗Gas手续费
@@ -29,7 +73,7 @@ func (t *TxOpts) GetOldFee() (*big.Int, error) {
 		fee.Mul(t.GasPrice, big.NewInt(int64(*t.GasLimit)))
 		return fee, nil
 	}
-	return nil, errors.New("未设置基础参数")
+	return nil, ErrTxOptsIncomplete
 }
 
 // GetNewFee 计算出本次如果使用新版交易时最大消耗Gas手续费
@@ -43,15 +87,42 @@ func (t *TxOpts) GetNewFee() (*big.Int, error) {
 		fee.Mul(totalCap, big.NewInt(int64(*t.GasLimit)))
 		return fee, nil
 	}
-	return nil, errors.New("未设置基础参数")
+	return nil, ErrTxOptsIncomplete
 }
 
 type Wallet struct {
 	Address common.Address
 	ChainID *big.Int
 
-	Signer *Signer
+	Signer AccountSigner
 	Client *ethrpc.EthRPC
+
+	// NonceManager 本地 nonce 管理器，为 nil 时每笔交易都从链上获取 pending nonce
+	NonceManager *NonceManager
+	// Subscriber WebSocket 订阅客户端，为 nil 时订阅类功能退回到轮询
+	Subscriber *Subscriber
+	// Explorer 区块浏览器客户端，用于查询交易历史，为 nil 时 History 不可用
+	Explorer *Explorer
+	// Offline 离线模式：不访问节点，nonce、gasLimit 与手续费必须在 TxOpts 中提供，
+	// SendTx 等方法返回已签名交易的十六进制编码而不是交易哈希
+	Offline bool
+	// SimulateBeforeSend 为 true 时所有交易发送前都先模拟执行，等同于设置 TxOpts.Simulate
+	SimulateBeforeSend bool
+	// GasBuffer 估算 gasLimit 时追加的余量，可以被 TxOpts.GasBuffer 覆盖
+	GasBuffer GasBuffer
+	// MaxGasPrice 允许的最大 gasPrice / gasFeeCap（wei），为 nil 时不限制
+	MaxGasPrice *big.Int
+	// MaxFeePerTx 单笔交易允许的最大手续费 gasLimit * gasFeeCap（wei），为 nil 时不限制
+	MaxFeePerTx *big.Int
+	// PrivateRelays 非空时交易通过这些私有 relay 发送而不进入公共交易池，可以用 TxOpts.Public 单独关闭
+	PrivateRelays []*PrivateRelay
+
+	mu sync.Mutex
+	// heads 所有 SubscribeNewHeads 调用共享的区块头数据源
+	heads *headFeed
+	// transport transportOf 实际使用的 HTTP 客户端，用于让请求受 ctx 控制，未知时为 nil
+	transport   HTTPClient
+	transportOf *ethrpc.EthRPC
 }
 
 // NewWallet 创建一个新的以太坊钱包实例
@@ -62,9 +133,16 @@ type Wallet struct {
 // 参数:
 //   - prvHex: 私钥的十六进制字符串表示，用于创建签名器
 //   - rpc: 以太坊节点的RPC端点URL
-//   - options: 可变参数，支持以下类型的配置选项：
+//   - options: 可变参数，推荐使用 WithChainID、WithClient、WithRPCOptions、FromWallet 等 WalletOption，
+//     同时兼容以下类型的配置选项，传入其他类型时返回 ErrInvalidOption：
 //   - func(rpc *ethrpc.EthRPC): RPC客户端配置函数
 //   - *ethrpc.EthRPC: 预先配置的RPC客户端实例
+//   - []string: 备用RPC节点列表，rpc 不可用时依次故障转移
+//   - RetryPolicy: RPC 请求失败时的重试策略
+//   - *RateLimiter: 请求限流器，多个钱包可以共享同一个限流器
+//   - Middleware: RPC 中间件，按传入顺序由外到内包装每个请求
+//   - *Subscriber: WebSocket 订阅客户端，用于 eth_subscribe
+//   - *Explorer: 区块浏览器客户端，用于查询交易历史
 //   - string: 网络版本号，用于确定链ID
 //   - *big.Int: 直接指定的链ID
 //   - *Wallet: 从现有钱包复制链ID和客户端配置
@@ -83,91 +161,84 @@ type Wallet struct {
 //
 // 使用示例:
 //
-//		// 基本用法
-//		wallet, err := NewWallet("0x1234...", "https://mainnet.infura.io/v3/YOUR-PROJECT-ID")
-//
-//		// 指定链ID
-//		chainID := big.NewInt(1) // 主网
-//		wallet, err := NewWallet("0x1234...", "https://mainnet.infura.io/v3/YOUR-PROJECT-ID", chainID)
-//
-//		// 使用自定义RPC客户端
-//		client := ethrpc.New("https://mainnet.infura.io/v3/YOUR-PROJECT-ID")
-//		wallet, err := NewWallet("0x1234...", "", client)
-//
-//		// 从现有钱包复制配置
-//		newWallet, err := NewWallet("0x5678...", "", existingWallet)
-//	    • *Wallet: 从现有钱包复制链ID和客户端配置
-//
-// 返回值:
-//   - *Wallet: 创建的钱包实例，包含地址、链ID、签名器和RPC客户端
-//   - error: 创建过程中的错误，如私钥无效、RPC连接失败等
-//
-// 工作流程:
-//  1. 解析可变参数，提取各种配置选项
-//  2. 使用私钥创建签名器，获取钱包地址
-//  3. 如果未提供RPC客户端，则使用RPC URL和配置选项创建新客户端
-//  4. 如果未指定网络版本，则通过RPC调用获取
-//  5. 如果未指定链ID，则从网络版本解析
-//  6. 组装并返回完整的钱包实例
-//
-// 使用示例:
-//
 //	// 基本用法
 //	wallet, err := NewWallet("0x1234...", "https://mainnet.infura.io/v3/YOUR-PROJECT-ID")
 //
 //	// 指定链ID
-//	chainID := big.NewInt(1) // 主网
-//	wallet, err := NewWallet("0x1234...", "https://mainnet.infura.io/v3/YOUR-PROJECT-ID", chainID)
+//	wallet, err := NewWallet("0x1234...", "https://mainnet.infura.io/v3/YOUR-PROJECT-ID", WithChainID(big.NewInt(1)))
 //
 //	// 使用自定义RPC客户端
 //	client := ethrpc.New("https://mainnet.infura.io/v3/YOUR-PROJECT-ID")
-//	wallet, err := NewWallet("0x1234...", "", client)
+//	wallet, err := NewWallet("0x1234...", "", WithClient(client))
 //
 //	// 从现有钱包复制配置(这个只会复制client信息以及节点信息)
-//	newWallet, err := NewWallet("0x5678...", "", existingWallet)
+//	newWallet, err := NewWallet("0x5678...", "", FromWallet(existingWallet))
 func NewWallet(prvHex, rpc string, options ...any) (*Wallet, error) {
+	return NewWalletContext(context.Background(), prvHex, rpc, options...)
+}
+
+// NewWalletContext 与 NewWallet 相同，但获取网络版本时受 ctx 控制
+func NewWalletContext(ctx context.Context, prvHex, rpc string, options ...any) (*Wallet, error) {
+	signer, err := NewSigner(prvHex)
+	if err != nil {
+		log.Error("Failed to create signer for wallet", "error", err)
+		return nil, err
+	}
+	return NewWalletWithSignerContext(ctx, signer, rpc, options...)
+}
+
+// NewWalletWithSigner 使用任意 AccountSigner 实现创建钱包，例如 KMSSigner 或 VaultSigner，
+// options 与 NewWallet 相同
+func NewWalletWithSigner(signer AccountSigner, rpc string, options ...any) (*Wallet, error) {
+	return NewWalletWithSignerContext(context.Background(), signer, rpc, options...)
+}
+
+// NewWalletWithSignerContext 与 NewWalletWithSigner 相同，但获取网络版本时受 ctx 控制
+func NewWalletWithSignerContext(ctx context.Context, signer AccountSigner, rpc string, options ...any) (*Wallet, error) {
 	log.Debug("Creating new wallet", "rpc", rpc, "optionsCount", len(options))
+	if signer == nil {
+		return nil, ErrSignerNil
+	}
 
-	var clientOptions []func(rpc *ethrpc.EthRPC)
-	var client *ethrpc.EthRPC
-	var version string
-	var chainID *big.Int
+	var cfg walletConfig
 	for _, opt := range options {
-		switch data := opt.(type) {
-		case func(rpc *ethrpc.EthRPC):
-			clientOptions = append(clientOptions, data)
-			log.Debug("Added RPC client option function")
-		case *ethrpc.EthRPC:
-			client = data
-			log.Debug("Using provided RPC client")
-		case string:
-			version = data
-			log.Debug("Using provided network version", "version", version)
-		case *big.Int:
-			chainID = data
-			version = data.String()
-			log.Debug("Using provided chain ID", "chainID", chainID.String())
-		case *Wallet:
-			chainID = data.ChainID
-			client = data.Client
-			version = data.ChainID.String()
-			log.Debug("Copying configuration from existing wallet", "chainID", chainID.String())
+		apply, err := walletOption(opt)
+		if err != nil {
+			log.Error("Unsupported wallet option", "type", fmt.Sprintf("%T", opt))
+			return nil, err
 		}
+		apply(&cfg)
 	}
-	signer, err := NewSigner(prvHex)
-	if err != nil {
-		log.Error("Failed to create signer for wallet", "error", err)
+	if err := cfg.validate(); err != nil {
+		log.Error("Conflicting wallet options", "error", err)
 		return nil, err
 	}
 
+	var err error
+	client, version, chainID := cfg.client, cfg.version, cfg.chainID
+	transport := cfg.transport
 	if client == nil {
+		if len(cfg.endpoints) > 0 && rpc != "" {
+			cfg.endpoints = append([]string{rpc}, cfg.endpoints...)
+		}
+		if len(cfg.endpoints) > 0 {
+			rpc = cfg.endpoints[0]
+		}
+		clientOptions := cfg.clientOptions
+		if httpClient := cfg.httpClient(); httpClient != nil {
+			clientOptions = append(clientOptions, ethrpc.WithHttpClient(httpClient))
+			transport = httpClient
+		} else if len(clientOptions) == 0 {
+			// 与 ethrpc.New 的默认客户端相同
+			transport = http.DefaultClient
+		}
 		log.Debug("Creating new RPC client", "rpc", rpc)
 		client = ethrpc.New(rpc, clientOptions...)
 	}
 
 	if version == "" {
 		log.Debug("Fetching network version from RPC")
-		version, err = client.NetVersion()
+		version, err = callContext(ctx, bindRPC(ctx, client, transport).NetVersion)
 		if err != nil {
 			log.Error("Failed to get network version", "error", err)
 			return nil, err
@@ -180,22 +251,29 @@ func NewWallet(prvHex, rpc string, options ...any) (*Wallet, error) {
 		chainID, ok = new(big.Int).SetString(version, 10)
 		if !ok {
 			log.Error("Invalid chain ID format", "version", version)
-			return nil, fmt.Errorf("wrong chainID: %s", version)
+			return nil, fmt.Errorf("%w: %s", ErrInvalidChainID, version)
 		}
 		log.Debug("Chain ID parsed successfully", "chainID", chainID.String())
 	}
 
 	log.Debug("Wallet created successfully",
-		"address", signer.Address.Hex(),
+		"address", signer.GetAddress().Hex(),
 		"chainID", chainID.String(),
 		"rpc", rpc)
 
 	return &Wallet{
-		Address: signer.Address,
+		Address: signer.GetAddress(),
 		ChainID: chainID,
 
-		Signer: signer,
-		Client: client,
+		Signer:     signer,
+		Client:     client,
+		Subscriber: cfg.subscriber,
+		Explorer:   cfg.explorer,
+
+		PrivateRelays: cfg.privateRelays,
+
+		transport:   transport,
+		transportOf: client,
 	}, nil
 }
 
@@ -211,167 +289,525 @@ func NewWalletFromPath(prvPath, rpc string) (*Wallet, error) {
 	return NewWallet(strings.TrimSpace(string(b)), rpc)
 }
 
+// NewOfflineWallet 创建不连接节点的离线钱包，只需要链ID
+func NewOfflineWallet(prvHex string, chainID *big.Int) (*Wallet, error) {
+	signer, err := NewSigner(prvHex)
+	if err != nil {
+		log.Error("Failed to create signer for offline wallet", "error", err)
+		return nil, err
+	}
+	return NewOfflineWalletWithSigner(signer, chainID)
+}
+
+// NewOfflineWalletWithSigner 与 NewOfflineWallet 相同，但使用任意 AccountSigner 实现
+func NewOfflineWalletWithSigner(signer AccountSigner, chainID *big.Int) (*Wallet, error) {
+	if signer == nil {
+		return nil, ErrSignerNil
+	}
+	if chainID == nil {
+		return nil, fmt.Errorf("%w: chainID is nil", ErrInvalidChainID)
+	}
+	log.Debug("Creating offline wallet", "address", signer.GetAddress().Hex(), "chainID", chainID.String())
+	return &Wallet{
+		Address: signer.GetAddress(),
+		ChainID: chainID,
+		Signer:  signer,
+		// 没有可用的节点，需要访问网络的方法会返回错误
+		Client:  ethrpc.New(""),
+		Offline: true,
+	}, nil
+}
+
+// NewRandomWallet 生成新的随机私钥并创建钱包，返回钱包与十六进制私钥，options 与 NewWallet 相同
+func NewRandomWallet(rpc string, options ...any) (*Wallet, string, error) {
+	signer, prvHex, err := NewRandomSigner()
+	if err != nil {
+		return nil, "", err
+	}
+	w, err := NewWalletWithSigner(signer, rpc, options...)
+	if err != nil {
+		return nil, "", err
+	}
+	return w, prvHex, nil
+}
+
 func (w *Wallet) SendTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	return w.SendTxContext(context.Background(), to, amount, data, opts)
+}
+
+// SendTxContext 发送 EIP-1559 交易，所有 RPC 调用都受 ctx 控制
+//
+// ctx 在 eth_sendRawTransaction 请求发出后才被取消时，节点可能已经收到并广播了交易，
+// 此时返回 ctx.Err() 并不代表交易未发送，重试前应当检查 nonce 或交易哈希。
+func (w *Wallet) SendTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
 	log.Debug("Sending dynamic fee transaction",
 		"from", w.Address.Hex(),
 		"to", to.Hex(),
 		"amount", amount.String(),
 		"dataLength", len(data))
 
-	opts, err = w.InitTxOpts(to, amount, data, opts)
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	tx, err := w.BuildTxContext(ctx, to, amount, data, opts)
 	if err != nil {
-		log.Error("Failed to initialize transaction options", "error", err)
 		return
 	}
-
-	if amount == nil {
-		amount = big.NewInt(0)
+	if managed {
+		defer w.releaseNonceOnError(int(tx.Nonce()), &err)
 	}
 
-	tx, err := w.Signer.SignTx(
-		*opts.Nonce, to, amount,
-		*opts.GasLimit, opts.GasTipCap, opts.GasFeeCap,
-		data, w.ChainID)
+	txHash, err = w.sendTx(ctx, tx, opts)
 	if err != nil {
-		log.Error("Failed to sign transaction", "error", err)
+		log.Error("Failed to send raw transaction", "error", err)
 		return
 	}
 
-	raw, err := tx.MarshalBinary()
-	if err != nil {
-		log.Error("Failed to marshal transaction", "error", err)
-		return
-	}
+	log.Debug("Dynamic fee transaction sent successfully", "txHash", txHash)
+	return txHash, nil
+}
+
+// SendTxTo 与 SendTx 相同，但 to 可以是十六进制地址或 ENS 名称
+func (w *Wallet) SendTxTo(to string, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	return w.SendTxToContext(context.Background(), to, amount, data, opts)
+}
 
-	txHash, err = w.Client.EthSendRawTransaction(hexutil.Encode(raw))
+// SendTxToContext 与 SendTxTo 相同，但名称解析与交易发送都受 ctx 控制
+func (w *Wallet) SendTxToContext(ctx context.Context, to string, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	address, err := w.ResolveAddressContext(ctx, to)
 	if err != nil {
-		log.Error("Failed to send raw transaction", "error", err)
+		log.Error("Failed to resolve recipient", "to", to, "error", err)
 		return
 	}
-
-	log.Debug("Dynamic fee transaction sent successfully", "txHash", txHash)
-	return txHash, nil
+	return w.SendTxContext(ctx, address, amount, data, opts)
 }
 
 func (w *Wallet) SendLegacyTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
+	return w.SendLegacyTxContext(context.Background(), to, amount, data, opts)
+}
+
+// SendLegacyTxContext 发送旧版交易，所有 RPC 调用都受 ctx 控制
+func (w *Wallet) SendLegacyTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (txHash string, err error) {
 	log.Debug("Sending legacy transaction",
 		"from", w.Address.Hex(),
 		"to", to.Hex(),
 		"amount", amount.String(),
 		"dataLength", len(data))
 
-	opts, err = w.InitTxOpts(to, amount, data, opts)
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	tx, err := w.BuildLegacyTxContext(ctx, to, amount, data, opts)
+	if err != nil {
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(int(tx.Nonce()), &err)
+	}
+
+	txHash, err = w.sendTx(ctx, tx, opts)
+	if err != nil {
+		log.Error("Failed to send raw legacy transaction", "error", err)
+		return
+	}
+
+	log.Debug("Legacy transaction sent successfully", "txHash", txHash)
+	return txHash, nil
+}
+
+// BuildTx 补全交易参数并签名 EIP-1559 交易，但不广播
+func (w *Wallet) BuildTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (*types.Transaction, error) {
+	return w.BuildTxContext(context.Background(), to, amount, data, opts)
+}
+
+// BuildTxContext 与 BuildTx 相同，但补全参数时的 RPC 调用受 ctx 控制
+//
+// 使用 NonceManager 时分配的 nonce 视为已使用，交易最终未广播时需要调用方自行 Release。
+func (w *Wallet) BuildTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (tx *types.Transaction, err error) {
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	opts, err = w.InitTxOptsContext(ctx, to, amount, data, opts)
+	if err != nil {
+		log.Error("Failed to initialize transaction options", "error", err)
+		return
+	}
+	if managed {
+		defer w.releaseNonceOnError(*opts.Nonce, &err)
+	}
+
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	if _, ok := w.Signer.(ContextSigner); ok || opts.AccessList != nil {
+		tx, err = signTxWith(w.signDigest(ctx), &types.DynamicFeeTx{
+			ChainID:    w.ChainID,
+			Nonce:      uint64(*opts.Nonce),
+			GasTipCap:  opts.GasTipCap,
+			GasFeeCap:  opts.GasFeeCap,
+			Gas:        uint64(*opts.GasLimit),
+			To:         &to,
+			Value:      amount,
+			Data:       data,
+			AccessList: opts.AccessList,
+		}, types.LatestSignerForChainID(w.ChainID))
+	} else {
+		tx, err = w.Signer.SignTx(
+			*opts.Nonce, to, amount,
+			*opts.GasLimit, opts.GasTipCap, opts.GasFeeCap,
+			data, w.ChainID)
+	}
+	if err != nil {
+		log.Error("Failed to sign transaction", "error", err)
+		return
+	}
+	return tx, nil
+}
+
+// BuildLegacyTx 补全交易参数并签名旧版交易，但不广播
+func (w *Wallet) BuildLegacyTx(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (*types.Transaction, error) {
+	return w.BuildLegacyTxContext(context.Background(), to, amount, data, opts)
+}
+
+// BuildLegacyTxContext 与 BuildLegacyTx 相同，但补全参数时的 RPC 调用受 ctx 控制
+func (w *Wallet) BuildLegacyTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (tx *types.Transaction, err error) {
+	managed := w.NonceManager != nil && (opts == nil || opts.Nonce == nil)
+	opts, err = w.InitTxOptsContext(ctx, to, amount, data, opts)
 	if err != nil {
 		log.Error("Failed to initialize legacy transaction options", "error", err)
 		return
 	}
+	if managed {
+		defer w.releaseNonceOnError(*opts.Nonce, &err)
+	}
 
 	if amount == nil {
 		amount = big.NewInt(0)
 	}
-	tx, err := w.Signer.SignLegacyTx(
-		*opts.Nonce, to, amount,
-		*opts.GasLimit, opts.GasPrice,
-		data, w.ChainID)
+	if _, ok := w.Signer.(ContextSigner); ok {
+		tx, err = signTxWith(w.signDigest(ctx), &types.LegacyTx{
+			Nonce:    uint64(*opts.Nonce),
+			GasPrice: opts.GasPrice,
+			Gas:      uint64(*opts.GasLimit),
+			To:       &to,
+			Value:    amount,
+			Data:     data,
+		}, types.NewEIP155Signer(w.ChainID))
+	} else {
+		tx, err = w.Signer.SignLegacyTx(
+			*opts.Nonce, to, amount,
+			*opts.GasLimit, opts.GasPrice,
+			data, w.ChainID)
+	}
 	if err != nil {
 		log.Error("Failed to sign legacy transaction", "error", err)
 		return
 	}
+	return tx, nil
+}
 
-	raw, err := tx.MarshalBinary()
+// SignTxRaw 构建并签名 EIP-1559 交易，返回 RLP 编码的十六进制交易与交易哈希，不广播
+func (w *Wallet) SignTxRaw(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (raw string, txHash string, err error) {
+	return w.SignTxRawContext(context.Background(), to, amount, data, opts)
+}
+
+// SignTxRawContext 与 SignTxRaw 相同，但补全参数时的 RPC 调用受 ctx 控制
+func (w *Wallet) SignTxRawContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (raw string, txHash string, err error) {
+	tx, err := w.BuildTxContext(ctx, to, amount, data, opts)
+	if err != nil {
+		return
+	}
+	b, err := tx.MarshalBinary()
 	if err != nil {
-		log.Error("Failed to marshal legacy transaction", "error", err)
 		return
 	}
+	return hexutil.Encode(b), tx.Hash().Hex(), nil
+}
+
+// SendRawTx 广播已签名的十六进制交易，例如 SignTxRaw 的结果
+func (w *Wallet) SendRawTx(raw string) (txHash string, err error) {
+	return w.SendRawTxContext(context.Background(), raw)
+}
 
-	txHash, err = w.Client.EthSendRawTransaction(hexutil.Encode(raw))
+// SendRawTxContext 与 SendRawTx 相同，但广播受 ctx 控制
+func (w *Wallet) SendRawTxContext(ctx context.Context, raw string) (txHash string, err error) {
+	b, err := hexutil.Decode(raw)
 	if err != nil {
-		log.Error("Failed to send raw legacy transaction", "error", err)
 		return
 	}
+	tx := new(types.Transaction)
+	if err = tx.UnmarshalBinary(b); err != nil {
+		return
+	}
+	if err = w.checkFeeCap(tx); err != nil {
+		return
+	}
+	return w.sendRawTransaction(ctx, b, false)
+}
 
-	log.Debug("Legacy transaction sent successfully", "txHash", txHash)
-	return txHash, nil
+// sendTx 编码并广播已签名的交易，opts.Public 为 true 时不使用私有 relay
+func (w *Wallet) sendTx(ctx context.Context, tx *types.Transaction, opts *TxOpts) (string, error) {
+	if err := w.checkFeeCap(tx); err != nil {
+		return "", err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		log.Error("Failed to marshal transaction", "error", err)
+		return "", err
+	}
+	txHash, err := w.sendRawTransaction(ctx, raw, opts != nil && opts.Public)
+	// 重试时上一次请求可能已经被节点接收
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "already known") {
+		log.Debug("Transaction already known by node", "txHash", tx.Hash().Hex())
+		return tx.Hash().Hex(), nil
+	}
+	return txHash, err
+}
+
+// sendRawTransaction 广播已签名的交易，离线模式下直接返回交易的十六进制编码；
+// 配置了 PrivateRelays 且 public 为 false 时交易只发送给私有 relay
+func (w *Wallet) sendRawTransaction(ctx context.Context, raw []byte, public bool) (string, error) {
+	if w.Offline {
+		return hexutil.Encode(raw), nil
+	}
+	if len(w.PrivateRelays) > 0 && !public {
+		return w.sendPrivate(ctx, raw)
+	}
+	return callContext(ctx, func() (string, error) {
+		return w.rpc(ctx).EthSendRawTransaction(hexutil.Encode(raw))
+	})
 }
 
 func (w *Wallet) InitTxOpts(to common.Address, amount *big.Int, data []byte, opts *TxOpts) (*TxOpts, error) {
+	return w.InitTxOptsContext(context.Background(), to, amount, data, opts)
+}
+
+// InitTxOptsContext 补全 opts 中未设置的 nonce、gasLimit 与手续费参数
+func (w *Wallet) InitTxOptsContext(ctx context.Context, to common.Address, amount *big.Int, data []byte, opts *TxOpts) (*TxOpts, error) {
+	return w.initTxOpts(ctx, &to, amount, data, opts)
+}
+
+// initTxOpts to 为 nil 时按创建合约的交易估算 gas
+func (w *Wallet) initTxOpts(ctx context.Context, to *common.Address, amount *big.Int, data []byte, opts *TxOpts) (*TxOpts, error) {
 	var (
 		nonce, gasLimit int
 		gasPrice        big.Int
 		err             error
 	)
 
+	// 在副本上补全，调用方可以在多个 goroutine 间共享同一个 opts，分配的 nonce 也不会留在其中
 	if opts == nil {
 		opts = &TxOpts{}
+	} else {
+		copied := *opts
+		opts = &copied
+	}
+	if w.Offline {
+		return offlineTxOpts(opts)
 	}
 
 	if opts.Nonce == nil {
-		nonce, err = w.GetPendingNonce()
+		if w.NonceManager != nil {
+			nonce, err = w.NonceManager.Next(ctx)
+		} else {
+			nonce, err = w.GetPendingNonceContext(ctx)
+		}
 		if err != nil {
 			return nil, err
 		}
 		opts.Nonce = &nonce
+		if w.NonceManager != nil {
+			defer w.releaseNonceOnError(nonce, &err)
+		}
+	}
+
+	accessListGas := 0
+	if opts.CreateAccessList && opts.AccessList == nil {
+		opts.AccessList, accessListGas, err = w.createAccessList(ctx, to, amount, data)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if opts.GasLimit == nil {
 		ethrpcTx := ethrpc.T{
 			From:  w.Address.String(),
-			To:    to.String(),
 			Value: amount,
 			Data:  hexutil.Encode(data),
 		}
-		gasLimit, err = w.Client.EthEstimateGas(ethrpcTx)
+		if to != nil {
+			ethrpcTx.To = to.String()
+		}
+		gasLimit, err = callData(ctx, w.Client, w.knownTransport(), func(rpc *ethrpc.EthRPC) (int, error) {
+			return rpc.EthEstimateGas(ethrpcTx)
+		})
 		if err != nil {
+			err = asRevertError(err)
 			return nil, err
 		}
+		// eth_estimateGas 不携带访问列表，取两者中较大的值
+		if gasLimit < accessListGas {
+			gasLimit = accessListGas
+		}
+		buffer := w.GasBuffer
+		if opts.GasBuffer != nil {
+			buffer = *opts.GasBuffer
+		}
+		gasLimit = buffer.apply(gasLimit)
 		opts.GasLimit = &gasLimit
 	}
 
 	if opts.GasPrice == nil {
-		gasPrice, err = w.Client.EthGasPrice()
+		gasPrice, err = callContext(ctx, w.rpc(ctx).EthGasPrice)
 		if err != nil {
 			return nil, err
 		}
 		opts.GasPrice = &gasPrice
 	}
 
-	if opts.GasTipCap == nil || opts.GasFeeCap == nil {
-		opts.GasTipCap = opts.GasPrice
-		opts.GasFeeCap = opts.GasPrice
+	w.fillDynamicFees(ctx, opts)
+
+	if opts.Simulate || w.SimulateBeforeSend {
+		if _, err = w.simulate(ctx, to, amount, data, opts); err != nil {
+			return nil, err
+		}
 	}
 
 	return opts, nil
 }
 
+// offlineTxOpts 校验离线模式下的交易参数，只提供 GasPrice 时同时作为 EIP-1559 的小费与最大手续费
+func offlineTxOpts(opts *TxOpts) (*TxOpts, error) {
+	if opts == nil || opts.Nonce == nil || opts.GasLimit == nil {
+		return nil, errors.New("offline wallet requires Nonce and GasLimit in TxOpts")
+	}
+	switch {
+	case opts.GasTipCap != nil && opts.GasFeeCap != nil:
+		if opts.GasPrice == nil {
+			opts.GasPrice = opts.GasFeeCap
+		}
+	case opts.GasPrice != nil:
+		if opts.GasTipCap == nil {
+			opts.GasTipCap = opts.GasPrice
+		}
+		if opts.GasFeeCap == nil {
+			opts.GasFeeCap = opts.GasPrice
+		}
+	default:
+		return nil, errors.New("offline wallet requires GasPrice or GasTipCap and GasFeeCap in TxOpts")
+	}
+	return opts, nil
+}
+
 func (w *Wallet) GetAddress() string {
 	return w.Address.String()
 }
 
 func (w *Wallet) GetNonce() (nonce int, err error) {
-	return w.Client.EthGetTransactionCount(w.GetAddress(), "latest")
+	return w.GetNonceContext(context.Background())
+}
+
+// GetNonceContext 获取已上链的 nonce
+func (w *Wallet) GetNonceContext(ctx context.Context) (nonce int, err error) {
+	return w.getTransactionCount(ctx, "latest")
 }
 
 func (w *Wallet) GetPendingNonce() (nonce int, err error) {
-	return w.Client.EthGetTransactionCount(w.GetAddress(), "pending")
+	return w.GetPendingNonceContext(context.Background())
+}
+
+// GetPendingNonceContext 获取包含交易池中交易的 nonce
+func (w *Wallet) GetPendingNonceContext(ctx context.Context) (nonce int, err error) {
+	return w.getTransactionCount(ctx, "pending")
+}
+
+func (w *Wallet) getTransactionCount(ctx context.Context, tag string) (int, error) {
+	return callContext(ctx, func() (int, error) {
+		return w.rpc(ctx).EthGetTransactionCount(w.GetAddress(), tag)
+	})
 }
 
 // GetBalance 获取钱包余额 如果传递了 token 则查询 token 余额
 func (w *Wallet) GetBalance(token ...string) (balance big.Int, err error) {
+	return w.GetBalanceContext(context.Background(), token...)
+}
+
+// GetBalanceContext 与 GetBalance 相同，但查询受 ctx 控制
+func (w *Wallet) GetBalanceContext(ctx context.Context, token ...string) (balance big.Int, err error) {
+	return w.balanceOf(ctx, w.Address, token...)
+}
+
+// GetBalanceOf 获取 account 的余额，account 与 token 都可以是十六进制地址或 ENS 名称
+func (w *Wallet) GetBalanceOf(account string, token ...string) (balance big.Int, err error) {
+	return w.GetBalanceOfContext(context.Background(), account, token...)
+}
+
+// GetBalanceOfContext 与 GetBalanceOf 相同，但查询受 ctx 控制
+func (w *Wallet) GetBalanceOfContext(ctx context.Context, account string, token ...string) (balance big.Int, err error) {
+	owner, err := w.ResolveAddressContext(ctx, account)
+	if err != nil {
+		return
+	}
+	return w.balanceOf(ctx, owner, token...)
+}
+
+func (w *Wallet) balanceOf(ctx context.Context, owner common.Address, token ...string) (balance big.Int, err error) {
 	if len(token) > 0 {
-		return w.getTokenBalance(token[0])
+		address, err := w.ResolveAddressContext(ctx, token[0])
+		if err != nil {
+			return balance, err
+		}
+		return GetBalanceOfContext(ctx, w.rpc(ctx), owner, address)
+	}
+	return GetBalanceOfContext(ctx, w.rpc(ctx), owner)
+}
+
+// GetTokenBalanceFormatted 获取钱包持有的 token 余额，按代币的 decimals 换算为十进制字符串，例如 "1.5"
+func (w *Wallet) GetTokenBalanceFormatted(token string) (string, error) {
+	return w.GetTokenBalanceFormattedContext(context.Background(), token)
+}
+
+// GetTokenBalanceFormattedContext 与 GetTokenBalanceFormatted 相同，但查询受 ctx 控制
+func (w *Wallet) GetTokenBalanceFormattedContext(ctx context.Context, token string) (string, error) {
+	address, err := w.ResolveAddressContext(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	erc20, err := NewERC20(address, w)
+	if err != nil {
+		return "", err
+	}
+	decimals, err := w.tokenDecimals(ctx, erc20)
+	if err != nil {
+		return "", err
 	}
-	return w.Client.EthGetBalance(w.GetAddress(), "latest")
+	balance, err := erc20.balanceOf(ctx, w.Address)
+	if err != nil {
+		return "", err
+	}
+	return FormatUnits(balance, decimals), nil
+}
+
+// GetBalanceOf 只使用 RPC 客户端查询 account 的余额，不需要钱包与私钥，适用于监控其他账户，
+// 传递了 token 时查询 ERC-20 代币余额
+func GetBalanceOf(client *ethrpc.EthRPC, account common.Address, token ...common.Address) (balance big.Int, err error) {
+	return GetBalanceOfContext(context.Background(), client, account, token...)
 }
 
-// getTokenBalance 获取 token 代币中本钱包持有的余额
-func (w *Wallet) getTokenBalance(token string) (balance big.Int, err error) {
-	res, err := w.Client.EthCall(ethrpc.T{
-		From: w.GetAddress(),
-		To:   token,
-		Data: fmt.Sprintf("0x70a08231000000000000000000000000%s", w.GetAddress()[2:]),
-	}, "latest")
+// GetBalanceOfContext 与 GetBalanceOf 相同，但查询受 ctx 控制
+func GetBalanceOfContext(ctx context.Context, client *ethrpc.EthRPC, account common.Address, token ...common.Address) (balance big.Int, err error) {
+	if client == nil {
+		return balance, errors.New("client is nil")
+	}
+	if len(token) == 0 {
+		return callContext(ctx, func() (big.Int, error) {
+			return client.EthGetBalance(account.Hex(), "latest")
+		})
+	}
+
+	erc20, err := NewERC20(token[0], nil)
+	if err != nil {
+		return
+	}
+	erc20.Client = client
+	b, err := erc20.balanceOf(ctx, account)
 	if err != nil {
+		log.Error("Failed to get token balance", "token", token[0].Hex(), "account", account.Hex(), "error", err)
 		return
 	}
-	return ethrpc.ParseBigInt(res)
+	return *b, nil
 }
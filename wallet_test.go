@@ -0,0 +1,125 @@
+package goether
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-enols/ethrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfflineWallet(t *testing.T) {
+	w, err := NewOfflineWallet("8eda9cd543eaa0484b70e5dcf03ad23a65c01610e835cbef891bd7c59d965632", big.NewInt(1))
+	assert.NoError(t, err)
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	_, err = w.SendTx(to, big.NewInt(1), nil, nil)
+	assert.Error(t, err)
+	_, err = w.SendTx(to, big.NewInt(1), nil, &TxOpts{Nonce: new(int), GasLimit: new(int)})
+	assert.Error(t, err)
+
+	nonce, gasLimit := 7, 21000
+	raw, err := w.SendTx(to, big.NewInt(1), nil, &TxOpts{Nonce: &nonce, GasLimit: &gasLimit, GasPrice: big.NewInt(1e9)})
+	assert.NoError(t, err)
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode(raw)))
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, big.NewInt(1e9), tx.GasFeeCap())
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, w.Address, from)
+
+	raw, err = w.SendLegacyTx(to, big.NewInt(1), nil, &TxOpts{Nonce: &nonce, GasLimit: &gasLimit, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2e9)})
+	assert.NoError(t, err)
+	assert.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode(raw)))
+	assert.Equal(t, big.NewInt(2e9), tx.GasPrice())
+
+	_, err = w.GetBalance()
+	assert.Error(t, err)
+}
+
+func TestSignTxRaw(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 4, &sent)
+	w := newTestWallet(t, m)
+
+	raw, txHash, err := w.SignTxRaw(common.HexToAddress("0x0000000000000000000000000000000000000001"), big.NewInt(1), nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, sent)
+
+	tx := new(types.Transaction)
+	assert.NoError(t, tx.UnmarshalBinary(hexutil.MustDecode(raw)))
+	assert.Equal(t, txHash, tx.Hash().Hex())
+	assert.Equal(t, uint64(4), tx.Nonce())
+
+	sentHash, err := w.SendRawTx(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, txHash, sentHash)
+	assert.Len(t, sent, 1)
+}
+
+func TestGasBuffer(t *testing.T) {
+	m := newMockRPC(t)
+	var sent []*types.Transaction
+	mockSendRPC(m, 0, &sent)
+	w := newTestWallet(t, m)
+	w.GasBuffer = GasBuffer{Multiplier: 1.2}
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	opts, err := w.InitTxOpts(to, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 25200, *opts.GasLimit)
+
+	opts, err = w.InitTxOpts(to, nil, nil, &TxOpts{GasBuffer: &GasBuffer{Headroom: 1000}})
+	assert.NoError(t, err)
+	assert.Equal(t, 22000, *opts.GasLimit)
+
+	gasLimit := 30000
+	_, err = w.SendTx(to, nil, nil, &TxOpts{GasLimit: &gasLimit})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(30000), sent[0].Gas())
+}
+
+func TestGetBalanceOf(t *testing.T) {
+	m := newMockRPC(t)
+	m.Result("eth_getBalance", "0x64")
+	mockContractCalls(m, ERC20ABI, map[string][]interface{}{
+		"balanceOf": {big.NewInt(42)},
+	})
+	client := ethrpc.New(m.URL)
+	account := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	token := common.HexToAddress("0x0000000000000000000000000000000000000003")
+
+	balance, err := GetBalanceOf(client, account)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), balance.Int64())
+
+	balance, err = GetBalanceOf(client, account, token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), balance.Int64())
+
+	w := newTestWallet(t, m)
+	balance, err = w.GetBalanceOf(account.Hex(), token.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), balance.Int64())
+
+	_, err = GetBalanceOf(nil, account)
+	assert.Error(t, err)
+}
+
+func TestGetTokenBalanceFormatted(t *testing.T) {
+	m := newMockRPC(t)
+	mockContractCalls(m, ERC20ABI, map[string][]interface{}{
+		"decimals":  {uint8(6)},
+		"balanceOf": {big.NewInt(1234500)},
+	})
+	w := newTestWallet(t, m)
+
+	balance, err := w.GetTokenBalanceFormatted("0x0000000000000000000000000000000000000003")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2345", balance)
+}